@@ -0,0 +1,123 @@
+package backup
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+const archiveFormat = "torq-backup"
+
+// archiveFormatVersion changes when the layout of the archive changes, not when the database schema changes.
+const archiveFormatVersion = 1
+
+const manifestFileName = "manifest.json"
+
+type SecretsMode string
+
+const (
+	SecretsEncrypted SecretsMode = "encrypted"
+	SecretsExcluded  SecretsMode = "excluded"
+)
+
+// Manifest is stored as manifest.json next to one JSON lines file per table.
+type Manifest struct {
+	Format           string        `json:"format"`
+	FormatVersion    int           `json:"formatVersion"`
+	TorqVersion      string        `json:"torqVersion"`
+	MigrationVersion uint          `json:"migrationVersion"`
+	CreatedOn        time.Time     `json:"createdOn"`
+	IncludesHistory  bool          `json:"includesHistory"`
+	Secrets          SecretsMode   `json:"secrets"`
+	Encryption       *Encryption   `json:"encryption,omitempty"`
+	Tables           []ManifestRow `json:"tables"`
+}
+
+type ManifestRow struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Rows int64  `json:"rows"`
+}
+
+func tableFileName(table string) string {
+	return "tables/" + table + ".jsonl"
+}
+
+func (manifest Manifest) validate() error {
+	if manifest.Format != archiveFormat {
+		return errors.New("Not a Torq backup archive")
+	}
+	if manifest.FormatVersion > archiveFormatVersion {
+		return errors.Newf("Archive format version %v is newer than this Torq supports (%v)",
+			manifest.FormatVersion, archiveFormatVersion)
+	}
+	if manifest.Secrets == SecretsEncrypted && manifest.Encryption == nil {
+		return errors.New("Archive has encrypted secrets but no encryption details")
+	}
+	for _, table := range manifest.Tables {
+		if _, known := getBackupTable(table.Name); !known {
+			return errors.Newf("Archive contains unknown table %v", table.Name)
+		}
+	}
+	return nil
+}
+
+// transformSecrets encrypts (or removes) the secret columns of a row exported by to_jsonb.
+func transformSecrets(row []byte, table backupTable, sc *secretCipher) ([]byte, error) {
+	if len(table.secretColumns) == 0 {
+		return row, nil
+	}
+	var columns map[string]json.RawMessage
+	if err := json.Unmarshal(row, &columns); err != nil {
+		return nil, errors.Wrapf(err, "Parsing row of %v", table.name)
+	}
+	for _, secretColumn := range table.secretColumns {
+		value, exists := columns[secretColumn]
+		if !exists || string(value) == "null" {
+			continue
+		}
+		if sc == nil {
+			columns[secretColumn] = json.RawMessage("null")
+			continue
+		}
+		encrypted, err := sc.encrypt(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Encrypting %v of %v", secretColumn, table.name)
+		}
+		columns[secretColumn], err = json.Marshal(encrypted)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Encoding %v of %v", secretColumn, table.name)
+		}
+	}
+	transformed, err := json.Marshal(columns)
+	return transformed, errors.Wrapf(err, "Encoding row of %v", table.name)
+}
+
+// restoreSecrets decrypts the secret columns encrypted by transformSecrets.
+func restoreSecrets(row []byte, table backupTable, sc *secretCipher) ([]byte, error) {
+	if len(table.secretColumns) == 0 || sc == nil {
+		return row, nil
+	}
+	var columns map[string]json.RawMessage
+	if err := json.Unmarshal(row, &columns); err != nil {
+		return nil, errors.Wrapf(err, "Parsing row of %v", table.name)
+	}
+	for _, secretColumn := range table.secretColumns {
+		value, exists := columns[secretColumn]
+		if !exists || string(value) == "null" {
+			continue
+		}
+		var encrypted string
+		if err := json.Unmarshal(value, &encrypted); err != nil {
+			return nil, errors.Wrapf(err, "Parsing %v of %v", secretColumn, table.name)
+		}
+		decrypted, err := sc.decrypt(encrypted)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Decrypting %v of %v", secretColumn, table.name)
+		}
+		columns[secretColumn] = decrypted
+	}
+	restored, err := json.Marshal(columns)
+	return restored, errors.Wrapf(err, "Encoding row of %v", table.name)
+}
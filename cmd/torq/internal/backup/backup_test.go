@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSecretCipher(t *testing.T) {
+	encryption, sc, err := newEncryption("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := sc.encrypt([]byte(`"macaroon"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encrypted, encryptedPrefix) || strings.Contains(encrypted, "macaroon") {
+		t.Fatalf("unexpected encrypted value %v", encrypted)
+	}
+
+	restoreCipher, err := newSecretCipher(encryption, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := restoreCipher.decrypt(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decrypted) != `"macaroon"` {
+		t.Errorf("expected the original value, got %v", string(decrypted))
+	}
+
+	if _, err = newSecretCipher(encryption, "wrong horse"); err == nil {
+		t.Error("expected an error for a wrong passphrase")
+	}
+	if _, err = newSecretCipher(encryption, ""); err == nil {
+		t.Error("expected an error for an empty passphrase")
+	}
+}
+
+func TestTransformSecrets(t *testing.T) {
+	table, _ := getBackupTable("node_connection_details")
+	row := []byte(`{"node_id":1,"macaroon_data":"\\x0201","key_data":null,"name":"Node"}`)
+
+	_, sc, err := newEncryption("passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := transformSecrets(row, table, sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var columns map[string]interface{}
+	if err = json.Unmarshal(encrypted, &columns); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(columns["macaroon_data"].(string), encryptedPrefix) {
+		t.Errorf("expected macaroon_data to be encrypted, got %v", columns["macaroon_data"])
+	}
+	if columns["key_data"] != nil || columns["name"] != "Node" {
+		t.Errorf("expected the other columns to be unchanged, got %v", columns)
+	}
+
+	restored, err := restoreSecrets(encrypted, table, sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal(restored, &columns); err != nil {
+		t.Fatal(err)
+	}
+	if columns["macaroon_data"] != `\x0201` {
+		t.Errorf("expected the original macaroon_data, got %v", columns["macaroon_data"])
+	}
+
+	excluded, err := transformSecrets(row, table, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal(excluded, &columns); err != nil {
+		t.Fatal(err)
+	}
+	if columns["macaroon_data"] != nil {
+		t.Errorf("expected macaroon_data to be excluded, got %v", columns["macaroon_data"])
+	}
+}
+
+func TestManifestValidate(t *testing.T) {
+	manifest := Manifest{
+		Format:        archiveFormat,
+		FormatVersion: archiveFormatVersion,
+		Secrets:       SecretsExcluded,
+		Tables:        []ManifestRow{{Name: "node", File: tableFileName("node")}},
+	}
+	if err := manifest.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	newer := manifest
+	newer.FormatVersion = archiveFormatVersion + 1
+	if err := newer.validate(); err == nil {
+		t.Error("expected an error for a newer archive format")
+	}
+
+	unknownTable := manifest
+	unknownTable.Tables = []ManifestRow{{Name: "pg_authid"}}
+	if err := unknownTable.validate(); err == nil {
+		t.Error("expected an error for an unknown table")
+	}
+
+	missingEncryption := manifest
+	missingEncryption.Secrets = SecretsEncrypted
+	if err := missingEncryption.validate(); err == nil {
+		t.Error("expected an error for encrypted secrets without encryption details")
+	}
+}
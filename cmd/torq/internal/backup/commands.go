@@ -0,0 +1,126 @@
+package backup
+
+import (
+	"os"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/lncapital/torq/internal/database"
+)
+
+const passphraseEnvVar = "TORQ_BACKUP_PASSPHRASE"
+
+// BackupCommand exports the configuration (and optionally the history) of Torq to an archive.
+// The global db.* flags (or the TOML config) select the database, getDatabaseSettings reads them.
+func BackupCommand(getDatabaseSettings func(c *cli.Context) database.ConnectionSettings) *cli.Command {
+	return &cli.Command{
+		Name:  "backup",
+		Usage: "Export the Torq configuration to a backup archive",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "file",
+				Usage:    "Archive to create, an existing file is never overwritten",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "history",
+				Usage: "Include forwards, payments, invoices, events and the other history (can be large)",
+			},
+			&cli.BoolFlag{
+				Name:  "exclude-secrets",
+				Usage: "Leave out macaroons, keys and notification tokens instead of encrypting them",
+			},
+			&cli.StringFlag{
+				Name:  "passphrase-file",
+				Usage: "File containing the passphrase for the secrets (or set " + passphraseEnvVar + ")",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			options := exportOptions{
+				file:           c.String("file"),
+				includeHistory: c.Bool("history"),
+				excludeSecrets: c.Bool("exclude-secrets"),
+			}
+			if !options.excludeSecrets {
+				var err error
+				options.passphrase, err = getPassphrase(c)
+				if err != nil {
+					return err
+				}
+			}
+			return withDatabase(getDatabaseSettings(c), func(db *sqlx.DB) error {
+				err := export(c.Context, db, options)
+				if err != nil {
+					return errors.Wrap(err, "Creating backup")
+				}
+				log.Info().Msgf("Backup written to %v", options.file)
+				return nil
+			})
+		},
+	}
+}
+
+// RestoreCommand replaces the Torq configuration (and history when present) with the content of an archive.
+// Torq must be stopped while restoring.
+func RestoreCommand(getDatabaseSettings func(c *cli.Context) database.ConnectionSettings) *cli.Command {
+	return &cli.Command{
+		Name:  "restore",
+		Usage: "Restore a backup archive created with torq backup (stop Torq first)",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "file",
+				Usage:    "Archive to restore",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "passphrase-file",
+				Usage: "File containing the passphrase for the secrets (or set " + passphraseEnvVar + ")",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			passphrase, err := getPassphrase(c)
+			if err != nil {
+				return err
+			}
+			return withDatabase(getDatabaseSettings(c), func(db *sqlx.DB) error {
+				err := restore(c.Context, db, restoreOptions{file: c.String("file"), passphrase: passphrase})
+				if err != nil {
+					return errors.Wrap(err, "Restoring backup")
+				}
+				log.Info().Msgf("Backup %v restored", c.String("file"))
+				return nil
+			})
+		},
+	}
+}
+
+// getPassphrase reads the passphrase from the file or the environment, it's never accepted as a flag value so it
+// doesn't end up in the shell history or the process list.
+func getPassphrase(c *cli.Context) (string, error) {
+	if c.String("passphrase-file") != "" {
+		passphrase, err := os.ReadFile(c.String("passphrase-file"))
+		if err != nil {
+			return "", errors.Wrap(err, "Reading the passphrase file")
+		}
+		return strings.TrimRight(string(passphrase), "\r\n"), nil
+	}
+	return os.Getenv(passphraseEnvVar), nil
+}
+
+func withDatabase(settings database.ConnectionSettings, action func(db *sqlx.DB) error) (err error) {
+	db, err := database.PgConnect(settings)
+	if err != nil {
+		return errors.Wrap(err, "Database connect")
+	}
+	defer func() {
+		cerr := db.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+	return action(db)
+}
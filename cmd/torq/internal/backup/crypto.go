@@ -0,0 +1,111 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+const encryptedPrefix = "torq-encrypted:"
+const encryptionCheck = "torq-backup"
+
+// Encryption describes how the secrets in the archive are encrypted, the key is derived from the passphrase.
+type Encryption struct {
+	Algorithm string `json:"algorithm"`
+	Kdf       string `json:"kdf"`
+	Salt      []byte `json:"salt"`
+	N         int    `json:"n"`
+	R         int    `json:"r"`
+	P         int    `json:"p"`
+	// Check is a known value encrypted with the key so a wrong passphrase is detected before restoring anything
+	Check string `json:"check"`
+}
+
+type secretCipher struct {
+	aead cipher.AEAD
+}
+
+func newEncryption(passphrase string) (Encryption, *secretCipher, error) {
+	encryption := Encryption{
+		Algorithm: "AES-256-GCM",
+		Kdf:       "scrypt",
+		Salt:      make([]byte, 16),
+		N:         32768,
+		R:         8,
+		P:         1,
+	}
+	if _, err := rand.Read(encryption.Salt); err != nil {
+		return Encryption{}, nil, errors.Wrap(err, "Generating salt")
+	}
+	sc, err := newSecretCipher(encryption, passphrase)
+	if err != nil {
+		return Encryption{}, nil, err
+	}
+	encryption.Check, err = sc.encrypt([]byte(encryptionCheck))
+	if err != nil {
+		return Encryption{}, nil, err
+	}
+	return encryption, sc, nil
+}
+
+func newSecretCipher(encryption Encryption, passphrase string) (*secretCipher, error) {
+	if encryption.Algorithm != "AES-256-GCM" || encryption.Kdf != "scrypt" {
+		return nil, errors.Newf("Unsupported encryption %v with %v", encryption.Algorithm, encryption.Kdf)
+	}
+	if passphrase == "" {
+		return nil, errors.New("A passphrase is required for the secrets")
+	}
+	key, err := scrypt.Key([]byte(passphrase), encryption.Salt, encryption.N, encryption.R, encryption.P, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, "Deriving key from passphrase")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "Creating cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "Creating GCM")
+	}
+	sc := &secretCipher{aead: aead}
+	if encryption.Check != "" {
+		check, err := sc.decrypt(encryption.Check)
+		if err != nil || string(check) != encryptionCheck {
+			return nil, errors.New("Incorrect passphrase")
+		}
+	}
+	return sc, nil
+}
+
+func (sc *secretCipher) encrypt(plaintext []byte) (string, error) {
+	nonce := make([]byte, sc.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Wrap(err, "Generating nonce")
+	}
+	sealed := sc.aead.Seal(nonce, nonce, plaintext, nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (sc *secretCipher) decrypt(value string) ([]byte, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return nil, errors.New("Value is not encrypted")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return nil, errors.Wrap(err, "Decoding encrypted value")
+	}
+	if len(sealed) < sc.aead.NonceSize() {
+		return nil, errors.New("Encrypted value is too short")
+	}
+	nonce, ciphertext := sealed[:sc.aead.NonceSize()], sealed[sc.aead.NonceSize():]
+	plaintext, err := sc.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Decrypting value")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/lncapital/torq/build"
+	"github.com/lncapital/torq/internal/database"
+)
+
+type exportOptions struct {
+	file           string
+	includeHistory bool
+	excludeSecrets bool
+	passphrase     string
+}
+
+func export(ctx context.Context, db *sqlx.DB, options exportOptions) (err error) {
+	migrationVersion, dirty, err := database.MigrationVersion(db)
+	if err != nil {
+		return errors.Wrap(err, "Obtaining the migration version")
+	}
+	if dirty {
+		return errors.Newf("The database migration %v is dirty, run migrate_up first", migrationVersion)
+	}
+
+	manifest := Manifest{
+		Format:           archiveFormat,
+		FormatVersion:    archiveFormatVersion,
+		TorqVersion:      build.ExtendedVersion(),
+		MigrationVersion: migrationVersion,
+		CreatedOn:        time.Now().UTC(),
+		IncludesHistory:  options.includeHistory,
+		Secrets:          SecretsExcluded,
+	}
+	var sc *secretCipher
+	if !options.excludeSecrets {
+		encryption, encryptionCipher, err := newEncryption(options.passphrase)
+		if err != nil {
+			return err
+		}
+		manifest.Secrets = SecretsEncrypted
+		manifest.Encryption = &encryption
+		sc = encryptionCipher
+	}
+
+	tables := getConfigurationTables()
+	if options.includeHistory {
+		tables = append(tables, getHistoryTables()...)
+	}
+
+	file, err := os.OpenFile(options.file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "Creating %v", options.file)
+	}
+	defer func() {
+		cerr := file.Close()
+		if err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(options.file)
+		}
+	}()
+	archive := zip.NewWriter(file)
+
+	// A single snapshot makes sure the tables are consistent with each other while Torq keeps running.
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return errors.Wrap(err, database.SqlBeginTransactionError)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = checkTableCoverage(ctx, tx); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		rows, err := exportTable(ctx, tx, archive, table, sc)
+		if err != nil {
+			return err
+		}
+		manifest.Tables = append(manifest.Tables, ManifestRow{Name: table.name, File: tableFileName(table.name), Rows: rows})
+		log.Info().Msgf("Backup: %v rows of %v", rows, table.name)
+	}
+
+	manifestWriter, err := archive.Create(manifestFileName)
+	if err != nil {
+		return errors.Wrap(err, "Adding the manifest")
+	}
+	encoder := json.NewEncoder(manifestWriter)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(manifest); err != nil {
+		return errors.Wrap(err, "Writing the manifest")
+	}
+	return errors.Wrap(archive.Close(), "Closing the archive")
+}
+
+func exportTable(ctx context.Context, tx *sqlx.Tx, archive *zip.Writer, table backupTable,
+	sc *secretCipher) (int64, error) {
+
+	tableWriter, err := archive.Create(tableFileName(table.name))
+	if err != nil {
+		return 0, errors.Wrapf(err, "Adding %v", table.name)
+	}
+	writer := bufio.NewWriter(tableWriter)
+
+	// The table name comes from the list of known tables and not from user input.
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT to_jsonb(t) FROM %v t;`, table.name))
+	if err != nil {
+		return 0, errors.Wrapf(err, "Reading %v", table.name)
+	}
+	defer rows.Close()
+
+	var count int64
+	for rows.Next() {
+		var row []byte
+		if err = rows.Scan(&row); err != nil {
+			return 0, errors.Wrapf(err, "Reading %v", table.name)
+		}
+		row, err = transformSecrets(row, table, sc)
+		if err != nil {
+			return 0, err
+		}
+		if err = writeLine(writer, row); err != nil {
+			return 0, errors.Wrapf(err, "Writing %v", table.name)
+		}
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		return 0, errors.Wrapf(err, "Reading %v", table.name)
+	}
+	return count, errors.Wrapf(writer.Flush(), "Writing %v", table.name)
+}
+
+func writeLine(writer io.Writer, line []byte) error {
+	if _, err := writer.Write(line); err != nil {
+		return err
+	}
+	_, err := writer.Write([]byte("\n"))
+	return err
+}
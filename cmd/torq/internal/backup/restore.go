@@ -0,0 +1,248 @@
+package backup
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/database"
+)
+
+// Rows are inserted in batches so a large history doesn't end up in a single statement.
+const restoreBatchSize = 500
+
+// The longest line is a row with the node connection details (macaroon and certificate).
+const restoreMaxLineBytes = 16 * 1024 * 1024
+
+type restoreOptions struct {
+	file       string
+	passphrase string
+}
+
+func readManifest(archive *zip.ReadCloser) (Manifest, error) {
+	manifestFile, err := archive.Open(manifestFileName)
+	if err != nil {
+		return Manifest{}, errors.Wrap(err, "Opening the manifest")
+	}
+	defer manifestFile.Close()
+	var manifest Manifest
+	if err = json.NewDecoder(manifestFile).Decode(&manifest); err != nil {
+		return Manifest{}, errors.Wrap(err, "Reading the manifest")
+	}
+	return manifest, manifest.validate()
+}
+
+func restore(ctx context.Context, db *sqlx.DB, options restoreOptions) error {
+	archive, err := zip.OpenReader(options.file)
+	if err != nil {
+		return errors.Wrapf(err, "Opening %v", options.file)
+	}
+	defer archive.Close()
+
+	manifest, err := readManifest(archive)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Restoring backup of %v created by Torq %v (migration %v)",
+		manifest.CreatedOn.Format("2006-01-02 15:04:05"), manifest.TorqVersion, manifest.MigrationVersion)
+
+	var sc *secretCipher
+	if manifest.Secrets == SecretsEncrypted {
+		sc, err = newSecretCipher(*manifest.Encryption, options.passphrase)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = database.MigrateUp(db)
+	if err != nil {
+		return errors.Wrap(err, "Migrating database up")
+	}
+	migrationVersion, dirty, err := database.MigrationVersion(db)
+	if err != nil {
+		return errors.Wrap(err, "Obtaining the migration version")
+	}
+	if dirty || migrationVersion != manifest.MigrationVersion {
+		return errors.Newf("The backup requires database migration %v but this Torq is at migration %v, "+
+			"restore it with the Torq version that created it (%v)",
+			manifest.MigrationVersion, migrationVersion, manifest.TorqVersion)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, database.SqlBeginTransactionError)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = checkTableCoverage(ctx, tx); err != nil {
+		return err
+	}
+	if err = checkTablesOutsideManifest(ctx, tx, manifest); err != nil {
+		return err
+	}
+	for _, table := range getExcludedTables() {
+		if !table.clearOnRestore {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %v;`, table.name))
+		if err != nil {
+			return errors.Wrapf(err, "Clearing %v", table.name)
+		}
+	}
+	// Delete in reverse order so the foreign keys are never violated.
+	for i := len(manifest.Tables) - 1; i >= 0; i-- {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %v;`, manifest.Tables[i].Name))
+		if err != nil {
+			return errors.Wrapf(err, "Clearing %v", manifest.Tables[i].Name)
+		}
+	}
+	for _, manifestRow := range manifest.Tables {
+		table, _ := getBackupTable(manifestRow.Name)
+		rows, err := restoreTable(ctx, tx, archive, manifestRow, table, sc)
+		if err != nil {
+			return err
+		}
+		if rows != manifestRow.Rows {
+			return errors.Newf("Expected %v rows of %v but the archive contains %v", manifestRow.Rows, table.name, rows)
+		}
+		if err = resetSequences(ctx, tx, table.name); err != nil {
+			return err
+		}
+		log.Info().Msgf("Restore: %v rows of %v", rows, table.name)
+	}
+
+	return errors.Wrap(tx.Commit(), database.SqlCommitTransactionError)
+}
+
+// checkTablesOutsideManifest refuses to restore when a table that's not in the archive contains rows. A backup without
+// history only replaces the configuration, the history rows would reference nodes and channels that no longer exist.
+func checkTablesOutsideManifest(ctx context.Context, tx *sqlx.Tx, manifest Manifest) error {
+	var populated []string
+	for _, table := range append(getConfigurationTables(), getHistoryTables()...) {
+		if slices.ContainsFunc(manifest.Tables, func(row ManifestRow) bool { return row.Name == table.name }) {
+			continue
+		}
+		var exists bool
+		err := tx.GetContext(ctx, &exists, fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %v);`, table.name))
+		if err != nil {
+			return errors.Wrap(err, database.SqlExecutionError)
+		}
+		if exists {
+			populated = append(populated, table.name)
+		}
+	}
+	if len(populated) != 0 {
+		return errors.Newf("The database contains rows in %v which are not part of the backup, "+
+			"restore a backup created with --history or restore into a new database", populated)
+	}
+	return nil
+}
+
+func restoreTable(ctx context.Context, tx *sqlx.Tx, archive *zip.ReadCloser, manifestRow ManifestRow,
+	table backupTable, sc *secretCipher) (int64, error) {
+
+	columns, err := getInsertableColumns(ctx, tx, table.name)
+	if err != nil {
+		return 0, err
+	}
+	columnList := strings.Join(columns, ", ")
+	// jsonb_populate_recordset converts every JSON field to the type of the column with the same name.
+	insertSql := fmt.Sprintf(`INSERT INTO %v (%v) SELECT %v FROM jsonb_populate_recordset(NULL::%v, $1::jsonb);`,
+		table.name, columnList, columnList, table.name)
+
+	tableFile, err := archive.Open(manifestRow.File)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Opening %v", manifestRow.File)
+	}
+	defer tableFile.Close()
+
+	var count int64
+	var batch []json.RawMessage
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		rows, err := json.Marshal(batch)
+		if err != nil {
+			return errors.Wrapf(err, "Encoding rows of %v", table.name)
+		}
+		if _, err = tx.ExecContext(ctx, insertSql, string(rows)); err != nil {
+			return errors.Wrapf(err, "Inserting rows of %v", table.name)
+		}
+		count += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(tableFile)
+	scanner.Buffer(make([]byte, 0, 64*1024), restoreMaxLineBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		row, err := restoreSecrets(append([]byte(nil), line...), table, sc)
+		if err != nil {
+			return 0, err
+		}
+		batch = append(batch, row)
+		if len(batch) >= restoreBatchSize {
+			if err = flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return 0, errors.Wrapf(err, "Reading %v", manifestRow.File)
+	}
+	return count, flush()
+}
+
+// getInsertableColumns leaves out generated columns, those can't be inserted.
+func getInsertableColumns(ctx context.Context, tx *sqlx.Tx, table string) ([]string, error) {
+	var columns []string
+	err := tx.SelectContext(ctx, &columns, `
+		SELECT quote_ident(column_name)
+		FROM information_schema.columns
+		WHERE table_schema=current_schema() AND table_name=$1 AND is_generated='NEVER'
+		ORDER BY ordinal_position;`, table)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	if len(columns) == 0 {
+		return nil, errors.Newf("Table %v does not exist", table)
+	}
+	return columns, nil
+}
+
+// resetSequences moves the serial sequences past the restored ids so new rows don't collide with them.
+func resetSequences(ctx context.Context, tx *sqlx.Tx, table string) error {
+	var columns []string
+	err := tx.SelectContext(ctx, &columns, `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_schema=current_schema() AND table_name=$1 AND column_default LIKE 'nextval(%';`, table)
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	for _, column := range columns {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+			SELECT setval(pg_get_serial_sequence($1, $2), COALESCE((SELECT max(%v) FROM %v), 0) + 1, false);`,
+			column, table), table, column)
+		if err != nil {
+			return errors.Wrapf(err, "Resetting the sequence of %v.%v", table, column)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package backup
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/database/migrations"
+	"github.com/lncapital/torq/testutil"
+)
+
+//nolint:gochecknoglobals
+var migrationTableStatement = regexp.MustCompile(
+	`(?i)(create table (?:if not exists )?|drop table (?:if exists )?|alter table )(\w+)(?: rename to (\w+))?`)
+
+// getMigratedTables replays the table statements of the up migrations.
+func getMigratedTables(t *testing.T) []string {
+	files, err := fs.Glob(migrations.MigrationFiles, "*.up.psql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	tables := map[string]bool{"schema_migrations": true}
+	for _, file := range files {
+		content, err := fs.ReadFile(migrations.MigrationFiles, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, statement := range migrationTableStatement.FindAllStringSubmatch(string(content), -1) {
+			table := strings.ToLower(statement[2])
+			switch strings.ToLower(strings.TrimSpace(statement[1]))[:4] {
+			case "crea":
+				tables[table] = true
+			case "drop":
+				delete(tables, table)
+			case "alte":
+				if statement[3] != "" {
+					delete(tables, table)
+					tables[strings.ToLower(statement[3])] = true
+				}
+			}
+		}
+	}
+	var names []string
+	for table := range tables {
+		names = append(names, table)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestTableCoverage(t *testing.T) {
+	tables := getMigratedTables(t)
+	if uncovered := getUncoveredTables(tables); len(uncovered) != 0 {
+		t.Errorf("tables %v are neither backed up nor excluded, add them to tables.go", uncovered)
+	}
+	for _, table := range append(getConfigurationTables(), getHistoryTables()...) {
+		if !slices.Contains(tables, table.name) {
+			t.Errorf("backed up table %v is not created by the migrations", table.name)
+		}
+	}
+	for _, table := range getExcludedTables() {
+		if !slices.Contains(tables, table.name) {
+			t.Errorf("excluded table %v is not created by the migrations", table.name)
+		}
+	}
+}
+
+func TestRestorePopulatedDatabase(t *testing.T) {
+	srv, err := testutil.InitTestDBConn()
+	if err != nil {
+		panic(err)
+	}
+
+	db, cancel, err := srv.NewTestDatabase()
+	defer cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	nodeId, _ := testutil.Setup(db, cancel)
+	_, err = db.Exec(`INSERT INTO force_close_notification (node_id, channel_point, event, created_on)
+		VALUES ($1, $2, $3, $4);`, nodeId, "txid:0", "matured", time.Now().UTC())
+	if err != nil {
+		t.Fatalf("inserting force close notification: %v", err)
+	}
+	ctx := context.Background()
+	directory := t.TempDir()
+
+	testutil.Given(t, "a backup without history of a database with history")
+	configurationFile := filepath.Join(directory, "configuration.zip")
+	err = export(ctx, db, exportOptions{file: configurationFile, excludeSecrets: true})
+	if err != nil {
+		testutil.Fatalf(t, "export: %v", err)
+	}
+	err = restore(ctx, db, restoreOptions{file: configurationFile})
+	if err == nil || !strings.Contains(err.Error(), "force_close_notification") {
+		testutil.Fatalf(t, "expected the restore to be refused because of the history, got %v", err)
+	}
+	testutil.Successf(t, "the restore is refused instead of violating the foreign keys")
+
+	testutil.Given(t, "a backup with history")
+	historyFile := filepath.Join(directory, "history.zip")
+	err = export(ctx, db, exportOptions{file: historyFile, includeHistory: true, excludeSecrets: true})
+	if err != nil {
+		testutil.Fatalf(t, "export: %v", err)
+	}
+	err = restore(ctx, db, restoreOptions{file: historyFile})
+	if err != nil {
+		testutil.Fatalf(t, "restore: %v", err)
+	}
+	var notifications int
+	err = db.Get(&notifications, `SELECT count(*) FROM force_close_notification WHERE node_id=$1;`, nodeId)
+	if err != nil {
+		testutil.Fatalf(t, "counting force close notifications: %v", err)
+	}
+	if notifications != 1 {
+		testutil.Errorf(t, "expected the force close notification to be restored, got %v", notifications)
+	}
+	testutil.Successf(t, "the configuration and history replace the populated database")
+}
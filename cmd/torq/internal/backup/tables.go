@@ -0,0 +1,140 @@
+package backup
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/database"
+)
+
+// backupTable is a table in the archive, the tables are restored in the order of getConfigurationTables and
+// getHistoryTables so foreign keys are always satisfied.
+type backupTable struct {
+	name string
+	// secretColumns are encrypted with the passphrase (or left out with --exclude-secrets)
+	secretColumns []string
+}
+
+func getConfigurationTables() []backupTable {
+	return []backupTable{
+		{name: "node"},
+		{name: "channel"},
+		{
+			name:          "node_connection_details",
+			secretColumns: []string{"macaroon_data", "key_data"},
+		},
+		{
+			name: "settings",
+			secretColumns: []string{"slack_oauth_token", "slack_bot_app_token",
+				"telegram_high_priority_credentials", "telegram_low_priority_credentials"},
+		},
+		{name: "category"},
+		{name: "tag"},
+		{name: "tagged_entity"},
+		{name: "corridor"},
+		{name: "workflow"},
+		{name: "workflow_version"},
+		{name: "workflow_version_node"},
+		{name: "workflow_version_node_link"},
+		{name: "table_view"},
+		{name: "table_view_column"},
+		{name: "table_view_filter"},
+		{name: "table_view_sorting"},
+		{name: "communication"},
+	}
+}
+
+// getHistoryTables are only part of the archive with --history. The continuous aggregates are not part of the
+// archive, the maintenance service rebuilds them from the raw rows.
+func getHistoryTables() []backupTable {
+	return []backupTable{
+		{name: "forward"},
+		{name: "htlc_event"},
+		{name: "channel_event"},
+		{name: "routing_policy"},
+		{name: "node_event"},
+		{name: "tx"},
+		{name: "invoice"},
+		{name: "payment"},
+		{name: "rebalance"},
+		{name: "rebalance_log"},
+		{name: "workflow_version_node_log"},
+		{name: "node_connection_history"},
+		{name: "fiat_price"},
+		{name: "graph_snapshot"},
+		{name: "graph_snapshot_node"},
+		{name: "fee_estimate"},
+		{name: "deferred_operation"},
+		{name: "unconfirmed_transaction"},
+		{name: "force_close_notification"},
+	}
+}
+
+// excludedTable is a table of the schema that's deliberately not part of the archive.
+type excludedTable struct {
+	name string
+	// clearOnRestore empties the table on restore because its rows belong to the replaced database
+	clearOnRestore bool
+}
+
+// getExcludedTables must list every table that's not in getConfigurationTables or getHistoryTables, a new table has
+// to be added to one of the three.
+func getExcludedTables() []excludedTable {
+	return []excludedTable{
+		// maintained by the migrations
+		{name: "schema_migrations"},
+		// unused since the categories were introduced
+		{name: "channel_group", clearOnRestore: true},
+		// only read to migrate the old views
+		{name: "table_view_legacy"},
+		// describes the raw rows deleted from this database, the continuous aggregates are rebuilt after a restore
+		{name: "data_retention", clearOnRestore: true},
+		// executing the operations of another database on the next start could open channels twice
+		{name: "interrupted_operation", clearOnRestore: true},
+	}
+}
+
+func getBackupTable(name string) (backupTable, bool) {
+	for _, table := range append(getConfigurationTables(), getHistoryTables()...) {
+		if table.name == name {
+			return table, true
+		}
+	}
+	return backupTable{}, false
+}
+
+// getUncoveredTables returns the tables that are neither part of the archive nor excluded.
+func getUncoveredTables(tables []string) []string {
+	var uncovered []string
+	for _, table := range tables {
+		if _, known := getBackupTable(table); known {
+			continue
+		}
+		if !slices.ContainsFunc(getExcludedTables(), func(excluded excludedTable) bool { return excluded.name == table }) {
+			uncovered = append(uncovered, table)
+		}
+	}
+	return uncovered
+}
+
+// checkTableCoverage fails when the schema contains a table that's neither part of the archive nor excluded, a backup
+// without it would be incomplete and a restore would violate its foreign keys.
+func checkTableCoverage(ctx context.Context, tx *sqlx.Tx) error {
+	var tables []string
+	err := tx.SelectContext(ctx, &tables, `
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema=current_schema() AND table_type='BASE TABLE'
+		ORDER BY table_name;`)
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	uncovered := getUncoveredTables(tables)
+	if len(uncovered) != 0 {
+		return errors.Newf("Tables %v are neither part of the backup nor excluded from it", uncovered)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/lncapital/torq/pkg/server_errors"
+)
+
+// Client talks to the REST API of a running Torq instance.
+// The session cookie obtained during login is kept in the cookie jar.
+type Client struct {
+	baseUrl    string
+	httpClient *http.Client
+}
+
+// New creates a client, certPath adds a (self-signed) certificate of the Torq instance to the trusted roots.
+func New(baseUrl string, certPath string) (*Client, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Creating cookie jar")
+	}
+	httpClient := &http.Client{Jar: jar, Timeout: 2 * time.Minute}
+	if certPath != "" {
+		certPem, err := os.ReadFile(certPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Reading TLS certificate %v", certPath)
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(certPem) {
+			return nil, errors.Newf("No certificate found in %v", certPath)
+		}
+		httpClient.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12},
+		}
+	}
+	return &Client{
+		baseUrl:    strings.TrimSuffix(baseUrl, "/") + "/api",
+		httpClient: httpClient,
+	}, nil
+}
+
+// LoginWithPassword uses the same credentials as the web interface.
+func (c *Client) LoginWithPassword(password string) error {
+	form := url.Values{}
+	form.Set("username", "admin")
+	form.Set("password", password)
+	response, err := c.httpClient.PostForm(c.baseUrl+"/login", form)
+	if err != nil {
+		return errors.Wrap(err, "Logging in")
+	}
+	return decodeResponse(response, nil)
+}
+
+// LoginWithCookieFile reads the access key from the cookie file of the Torq instance.
+// Torq rotates the cookie file after every successful login.
+func (c *Client) LoginWithCookieFile(cookiePath string) error {
+	cookieFile, err := os.ReadFile(cookiePath)
+	if err != nil {
+		return errors.Wrapf(err, "Reading cookie file %v", cookiePath)
+	}
+	accessKey := regexp.MustCompile(`[^a-zA-Z0-9]+`).ReplaceAllString(string(cookieFile), "")
+	return c.Send(http.MethodPost, "/cookie-login", map[string]string{"accessKey": accessKey}, nil)
+}
+
+func (c *Client) Get(path string, query url.Values, out interface{}) error {
+	requestUrl := c.baseUrl + path
+	if len(query) != 0 {
+		requestUrl += "?" + query.Encode()
+	}
+	response, err := c.httpClient.Get(requestUrl)
+	if err != nil {
+		return errors.Wrapf(err, "GET %v", path)
+	}
+	return decodeResponse(response, out)
+}
+
+func (c *Client) Send(method string, path string, body interface{}, out interface{}) error {
+	requestBody, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrap(err, "JSON marshal request body")
+	}
+	request, err := http.NewRequest(method, c.baseUrl+path, bytes.NewReader(requestBody))
+	if err != nil {
+		return errors.Wrapf(err, "Creating %v %v request", method, path)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "%v %v", method, path)
+	}
+	return decodeResponse(response, out)
+}
+
+func decodeResponse(response *http.Response, out interface{}) error {
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return errors.Wrap(err, "Reading response body")
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.Newf("%v: %v", response.Status, parseErrorBody(body))
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	return errors.Wrap(json.Unmarshal(body, out), "JSON unmarshal response body")
+}
+
+// parseErrorBody understands both the ServerError format and the plain {"error": "..."} responses.
+func parseErrorBody(body []byte) string {
+	serverError := server_errors.ServerError{}
+	if json.Unmarshal(body, &serverError) == nil {
+		var messages []string
+		for _, serverErr := range serverError.Errors.Server {
+			messages = append(messages, describe(serverErr))
+		}
+		for field, fieldErrs := range serverError.Errors.Fields {
+			for _, fieldErr := range fieldErrs {
+				messages = append(messages, field+": "+describe(fieldErr))
+			}
+		}
+		if len(messages) != 0 {
+			return strings.Join(messages, "; ")
+		}
+	}
+	plainError := struct {
+		Error string `json:"error"`
+	}{}
+	if json.Unmarshal(body, &plainError) == nil && plainError.Error != "" {
+		return plainError.Error
+	}
+	return strings.TrimSpace(string(body))
+}
+
+func describe(errorCodeOrDescription server_errors.ErrorCodeOrDescription) string {
+	if errorCodeOrDescription.Description != "" {
+		return errorCodeOrDescription.Description
+	}
+	return errorCodeOrDescription.Code
+}
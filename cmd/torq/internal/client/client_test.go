@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseErrorBody(t *testing.T) {
+	testCases := []struct {
+		body string
+		want string
+	}{
+		{`{"errors":{"server":[{"code":"","description":"Can't process network"}]}}`, "Can't process network"},
+		{`{"errors":{"fields":{"channelId":[{"code":"required"}]}}}`, "channelId: required"},
+		{`{"error":"Authentication failed"}`, "Authentication failed"},
+		{"404 page not found\n", "404 page not found"},
+	}
+	for _, tc := range testCases {
+		if got := parseErrorBody([]byte(tc.body)); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestFilterChannels(t *testing.T) {
+	channels := []map[string]interface{}{
+		{"nodeId": 1.0, "peerAlias": "ACINQ", "active": true, "gauge": 80.0,
+			"tags": []interface{}{map[string]interface{}{"name": "Sink"}}},
+		{"nodeId": 1.0, "peerAlias": "Kraken", "active": false, "gauge": 10.0, "tags": nil},
+		{"nodeId": 2.0, "peerAlias": "acinq-2", "active": true, "gauge": 50.0, "tags": nil},
+	}
+	noGauge := channelFilter{minGauge: -1, maxGauge: -1}
+
+	filter := noGauge
+	filter.peer = "acinq"
+	if got := filterChannels(channels, filter); len(got) != 2 {
+		t.Errorf("peer filter: got %v channels, want 2", len(got))
+	}
+	filter = noGauge
+	filter.tag = "sink"
+	if got := filterChannels(channels, filter); len(got) != 1 || got[0]["peerAlias"] != "ACINQ" {
+		t.Errorf("tag filter: got %v", got)
+	}
+	filter = noGauge
+	filter.inactive = true
+	if got := filterChannels(channels, filter); len(got) != 1 || got[0]["peerAlias"] != "Kraken" {
+		t.Errorf("inactive filter: got %v", got)
+	}
+	filter = channelFilter{nodeId: 1, minGauge: 50, maxGauge: -1}
+	if got := filterChannels(channels, filter); len(got) != 1 || got[0]["peerAlias"] != "ACINQ" {
+		t.Errorf("node and gauge filter: got %v", got)
+	}
+}
+
+func TestPrintTable(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"channelId": 12.0, "gauge": 33.333, "peerAlias": ""},
+	}
+	sortRows(rows, "gauge", true)
+	var buffer bytes.Buffer
+	err := printTable(&buffer, rows, []column{{"ID", "channelId"}, {"GAUGE", "gauge"}, {"PEER", "peerAlias"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 || strings.Join(strings.Fields(lines[1]), " ") != "12 33.33 -" {
+		t.Errorf("unexpected table %q", buffer.String())
+	}
+}
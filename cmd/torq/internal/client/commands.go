@@ -0,0 +1,747 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/urfave/cli/v2"
+
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+	"github.com/lncapital/torq/internal/workflow_helpers"
+)
+
+const dateFormat = "2006-01-02"
+
+var channelColumns = []column{ //nolint:gochecknoglobals
+	{"NODE", "nodeName"},
+	{"CHANNEL_ID", "channelId"},
+	{"SHORT_CHANNEL_ID", "shortChannelId"},
+	{"PEER", "peerAlias"},
+	{"ACTIVE", "active"},
+	{"CAPACITY", "capacity"},
+	{"LOCAL", "localBalance"},
+	{"REMOTE", "remoteBalance"},
+	{"GAUGE", "gauge"},
+	{"FEE_RATE", "feeRateMilliMsat"},
+	{"FEE_BASE", "feeBase"},
+	{"TAGS", "tags"},
+}
+
+var forwardColumns = []column{ //nolint:gochecknoglobals
+	{"NODE", "torqNodeName"},
+	{"CHANNEL_ID", "channelId"},
+	{"SHORT_CHANNEL_ID", "shortChannelId"},
+	{"PEER", "alias"},
+	{"AMOUNT_OUT", "amountOut"},
+	{"AMOUNT_IN", "amountIn"},
+	{"REVENUE_OUT", "revenueOut"},
+	{"REVENUE_IN", "revenueIn"},
+	{"COUNT_OUT", "countOut"},
+	{"COUNT_IN", "countIn"},
+}
+
+var flowColumns = []column{ //nolint:gochecknoglobals
+	{"CHANNEL_ID", "channelId"},
+	{"PEER", "alias"},
+	{"AMOUNT_OUT", "amountOut"},
+	{"AMOUNT_IN", "amountIn"},
+	{"REVENUE_OUT", "revenueOut"},
+	{"REVENUE_IN", "revenueIn"},
+	{"COUNT_OUT", "countOut"},
+	{"COUNT_IN", "countIn"},
+}
+
+var workflowColumns = []column{ //nolint:gochecknoglobals
+	{"WORKFLOW_ID", "workflowId"},
+	{"NAME", "workflowName"},
+	{"STATUS", "workflowStatus"},
+	{"ACTIVE_VERSION_ID", "activeWorkflowVersionId"},
+	{"LATEST_VERSION_ID", "latestWorkflowVersionId"},
+}
+
+var serviceColumns = []column{ //nolint:gochecknoglobals
+	{"SERVICE", "typeString"},
+	{"NODE_ID", "nodeId"},
+	{"STATUS", "statusString"},
+	{"BOOT_TIME", "bootTime"},
+}
+
+// Command returns the client command that scripts a running Torq instance through its REST API.
+// The global torq.port, torq.password and torq.cookie-path flags (or the TOML config) act as defaults.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:    "client",
+		Aliases: []string{"ctl"},
+		Usage:   "Query and control a running Torq instance through its API",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "url",
+				Usage: "Base URL of the Torq instance (defaults to http://localhost:<torq.port>)",
+			},
+			&cli.StringFlag{
+				Name:    "api-password",
+				Usage:   "Password or API token used to log in (defaults to torq.password)",
+				EnvVars: []string{"TORQ_API_PASSWORD"},
+			},
+			&cli.StringFlag{
+				Name:  "api-cookie-path",
+				Usage: "Cookie file used to log in when no password is available (defaults to torq.cookie-path)",
+			},
+			&cli.StringFlag{
+				Name:  "network",
+				Value: "mainnet",
+				Usage: "Bitcoin network: mainnet, testnet, regtest, signet or simnet",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the raw JSON response instead of a table",
+			},
+		},
+		Subcommands: []*cli.Command{
+			statusCommand(),
+			channelsCommand(),
+			forwardsCommand(),
+			flowCommand(),
+			policyCommand(),
+			openCommand(),
+			closeCommand(),
+			workflowCommand(),
+			rebalanceCommand(),
+		},
+	}
+}
+
+// connect creates an authenticated client. Without password or cookie file the instance must run with auto login.
+func connect(c *cli.Context) (*Client, error) {
+	client, err := newClient(c)
+	if err != nil {
+		return nil, err
+	}
+	password := c.String("api-password")
+	if password == "" {
+		password = c.String("torq.password")
+	}
+	cookiePath := c.String("api-cookie-path")
+	if cookiePath == "" {
+		cookiePath = c.String("torq.cookie-path")
+	}
+	switch {
+	case password != "":
+		err = client.LoginWithPassword(password)
+	case cookiePath != "":
+		err = client.LoginWithCookieFile(cookiePath)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Logging in to Torq")
+	}
+	return client, nil
+}
+
+func parseNetwork(network string) (core.Network, error) {
+	switch strings.ToLower(network) {
+	case "mainnet":
+		return core.MainNet, nil
+	case "testnet":
+		return core.TestNet, nil
+	case "regtest":
+		return core.RegTest, nil
+	case "signet":
+		return core.SigNet, nil
+	case "simnet":
+		return core.SimNet, nil
+	}
+	return 0, errors.Newf("Unknown network %v", network)
+}
+
+func networkQuery(c *cli.Context) (url.Values, error) {
+	network, err := parseNetwork(c.String("network"))
+	if err != nil {
+		return nil, err
+	}
+	return url.Values{"network": []string{strconv.Itoa(int(network))}}, nil
+}
+
+func printRows(c *cli.Context, rows []map[string]interface{}, columns []column) error {
+	if c.Bool("json") {
+		return printJson(c.App.Writer, rows)
+	}
+	return printTable(c.App.Writer, rows, columns)
+}
+
+func dateRangeFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "from", Usage: "First day (YYYY-MM-DD), defaults to 7 days ago"},
+		&cli.StringFlag{Name: "to", Usage: "Last day (YYYY-MM-DD), defaults to today"},
+	}
+}
+
+func dateRange(c *cli.Context, query url.Values) error {
+	from := c.String("from")
+	if from == "" {
+		from = time.Now().AddDate(0, 0, -7).Format(dateFormat)
+	}
+	to := c.String("to")
+	if to == "" {
+		to = time.Now().Format(dateFormat)
+	}
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse(dateFormat, date); err != nil {
+			return errors.Newf("Invalid date %v, expected YYYY-MM-DD", date)
+		}
+	}
+	query.Set("from", from)
+	query.Set("to", to)
+	return nil
+}
+
+func statusCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "status",
+		Usage: "Show the state of the Torq services",
+		Action: func(c *cli.Context) error {
+			client, err := newClient(c)
+			if err != nil {
+				return err
+			}
+			// The service status is available without logging in
+			var services map[string]interface{}
+			if err = client.Get("/services/status", nil, &services); err != nil {
+				return err
+			}
+			if c.Bool("json") {
+				return printJson(c.App.Writer, services)
+			}
+			fmt.Fprintf(c.App.Writer, "Version: %v\n\n", formatValue(services["version"]))
+			var rows []map[string]interface{}
+			if mainService, ok := services["mainService"].(map[string]interface{}); ok {
+				rows = append(rows, mainService)
+			}
+			for _, key := range []string{"torqServices", "lndServices"} {
+				serviceList, _ := services[key].([]interface{})
+				for _, service := range serviceList {
+					if row, ok := service.(map[string]interface{}); ok {
+						rows = append(rows, row)
+					}
+				}
+			}
+			return printTable(c.App.Writer, rows, serviceColumns)
+		},
+	}
+}
+
+// newClient trusts the TLS certificate of the local Torq instance when it serves HTTPS.
+func newClient(c *cli.Context) (*Client, error) {
+	baseUrl := c.String("url")
+	if baseUrl == "" {
+		scheme := "http"
+		if c.Bool("torq.tls.enabled") {
+			scheme = "https"
+		}
+		baseUrl = fmt.Sprintf("%v://localhost:%v", scheme, c.Int("torq.port"))
+	}
+	certPath := ""
+	if c.Bool("torq.tls.enabled") && strings.HasPrefix(baseUrl, "https://") {
+		certPath = c.String("torq.tls.cert-path")
+	}
+	return New(baseUrl, certPath)
+}
+
+func channelsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "channels",
+		Usage: "List the open channels",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "node-id", Usage: "Only channels of this Torq node"},
+			&cli.StringFlag{Name: "peer", Usage: "Only channels where the peer alias contains this text"},
+			&cli.StringFlag{Name: "tag", Usage: "Only channels with this channel or peer tag"},
+			&cli.BoolFlag{Name: "active", Usage: "Only active channels"},
+			&cli.BoolFlag{Name: "inactive", Usage: "Only inactive channels"},
+			&cli.Float64Flag{Name: "min-gauge", Value: -1, Usage: "Only channels with at least this local balance percentage"},
+			&cli.Float64Flag{Name: "max-gauge", Value: -1, Usage: "Only channels with at most this local balance percentage"},
+			&cli.StringFlag{Name: "sort", Value: "gauge", Usage: "Column (JSON field name) to sort on"},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := connect(c)
+			if err != nil {
+				return err
+			}
+			channels, err := getChannels(c, client)
+			if err != nil {
+				return err
+			}
+			channels = filterChannels(channels, channelFilter{
+				nodeId:   c.Int("node-id"),
+				peer:     c.String("peer"),
+				tag:      c.String("tag"),
+				active:   c.Bool("active"),
+				inactive: c.Bool("inactive"),
+				minGauge: c.Float64("min-gauge"),
+				maxGauge: c.Float64("max-gauge"),
+			})
+			sortRows(channels, c.String("sort"), false)
+			return printRows(c, channels, channelColumns)
+		},
+	}
+}
+
+func getChannels(c *cli.Context, client *Client) ([]map[string]interface{}, error) {
+	query, err := networkQuery(c)
+	if err != nil {
+		return nil, err
+	}
+	var channels []map[string]interface{}
+	return channels, client.Get("/channels", query, &channels)
+}
+
+type channelFilter struct {
+	nodeId   int
+	peer     string
+	tag      string
+	active   bool
+	inactive bool
+	// minGauge and maxGauge are ignored when negative
+	minGauge float64
+	maxGauge float64
+}
+
+func filterChannels(channels []map[string]interface{}, filter channelFilter) []map[string]interface{} {
+	filtered := make([]map[string]interface{}, 0, len(channels))
+	for _, channel := range channels {
+		if filter.nodeId != 0 && channel["nodeId"] != float64(filter.nodeId) {
+			continue
+		}
+		alias, _ := channel["peerAlias"].(string)
+		if filter.peer != "" && !strings.Contains(strings.ToLower(alias), strings.ToLower(filter.peer)) {
+			continue
+		}
+		if filter.tag != "" && !containsString(strings.Split(formatValue(channel["tags"]), ","), filter.tag) {
+			continue
+		}
+		active, _ := channel["active"].(bool)
+		if (filter.active && !active) || (filter.inactive && active) {
+			continue
+		}
+		gauge, _ := channel["gauge"].(float64)
+		if (filter.minGauge >= 0 && gauge < filter.minGauge) || (filter.maxGauge >= 0 && gauge > filter.maxGauge) {
+			continue
+		}
+		filtered = append(filtered, channel)
+	}
+	return filtered
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortRows sorts numerically when both values are numbers and alphabetically otherwise.
+func sortRows(rows []map[string]interface{}, key string, descending bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if descending {
+			i, j = j, i
+		}
+		left, leftIsNumber := rows[i][key].(float64)
+		right, rightIsNumber := rows[j][key].(float64)
+		if leftIsNumber && rightIsNumber {
+			return left < right
+		}
+		return formatValue(rows[i][key]) < formatValue(rows[j][key])
+	})
+}
+
+func forwardsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "forwards",
+		Usage: "Show the forwarding summary per channel for a date range",
+		Flags: dateRangeFlags(),
+		Action: func(c *cli.Context) error {
+			client, err := connect(c)
+			if err != nil {
+				return err
+			}
+			query, err := networkQuery(c)
+			if err != nil {
+				return err
+			}
+			if err = dateRange(c, query); err != nil {
+				return err
+			}
+			var forwards []map[string]interface{}
+			if err = client.Get("/forwards", query, &forwards); err != nil {
+				return err
+			}
+			sortRows(forwards, "revenueOut", true)
+			return printRows(c, forwards, forwardColumns)
+		},
+	}
+}
+
+func flowCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "flow",
+		Usage: "Show the flow per peer for a date range",
+		Flags: append(dateRangeFlags(),
+			&cli.IntSliceFlag{Name: "channel-id", Usage: "Channel to include (repeatable), defaults to all channels"},
+		),
+		Action: func(c *cli.Context) error {
+			client, err := connect(c)
+			if err != nil {
+				return err
+			}
+			query, err := networkQuery(c)
+			if err != nil {
+				return err
+			}
+			if err = dateRange(c, query); err != nil {
+				return err
+			}
+			chanIds := "all"
+			if len(c.IntSlice("channel-id")) != 0 {
+				var ids []string
+				for _, channelId := range c.IntSlice("channel-id") {
+					ids = append(ids, strconv.Itoa(channelId))
+				}
+				chanIds = strings.Join(ids, ",")
+			}
+			query.Set("chanIds", chanIds)
+			var flow []map[string]interface{}
+			if err = client.Get("/flow", query, &flow); err != nil {
+				return err
+			}
+			sortRows(flow, "amountOut", true)
+			return printRows(c, flow, flowColumns)
+		},
+	}
+}
+
+// resolveNodeId finds the Torq node of a channel when the node is not provided.
+func resolveNodeId(c *cli.Context, client *Client, channelId int) (int, error) {
+	if c.Int("node-id") != 0 {
+		return c.Int("node-id"), nil
+	}
+	channels, err := getChannels(c, client)
+	if err != nil {
+		return 0, err
+	}
+	for _, channel := range channels {
+		if channel["channelId"] == float64(channelId) {
+			nodeId, _ := channel["nodeId"].(float64)
+			return int(nodeId), nil
+		}
+	}
+	return 0, errors.Newf("Channel %v is not an open channel on the %v network, provide --node-id",
+		channelId, c.String("network"))
+}
+
+func policyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "policy",
+		Usage: "Update the routing policy of a channel",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "channel-id", Required: true},
+			&cli.IntFlag{Name: "node-id", Usage: "Torq node of the channel, looked up when omitted"},
+			&cli.Int64Flag{Name: "fee-rate", Usage: "Fee rate in ppm (milli msat)"},
+			&cli.Int64Flag{Name: "base-fee", Usage: "Base fee in msat"},
+			&cli.Uint64Flag{Name: "min-htlc", Usage: "Minimum HTLC in msat"},
+			&cli.Uint64Flag{Name: "max-htlc", Usage: "Maximum HTLC in msat"},
+			&cli.UintFlag{Name: "time-lock-delta"},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := connect(c)
+			if err != nil {
+				return err
+			}
+			request := lightning_helpers.RoutingPolicyUpdateRequest{ChannelId: c.Int("channel-id")}
+			if request.NodeId, err = resolveNodeId(c, client, request.ChannelId); err != nil {
+				return err
+			}
+			if c.IsSet("fee-rate") {
+				feeRate := c.Int64("fee-rate")
+				request.FeeRateMilliMsat = &feeRate
+			}
+			if c.IsSet("base-fee") {
+				baseFee := c.Int64("base-fee")
+				request.FeeBaseMsat = &baseFee
+			}
+			if c.IsSet("min-htlc") {
+				minHtlc := c.Uint64("min-htlc")
+				request.MinHtlcMsat = &minHtlc
+			}
+			if c.IsSet("max-htlc") {
+				maxHtlc := c.Uint64("max-htlc")
+				request.MaxHtlcMsat = &maxHtlc
+			}
+			if c.IsSet("time-lock-delta") {
+				timeLockDelta := uint32(c.Uint("time-lock-delta"))
+				request.TimeLockDelta = &timeLockDelta
+			}
+			if request.FeeRateMilliMsat == nil && request.FeeBaseMsat == nil && request.MinHtlcMsat == nil &&
+				request.MaxHtlcMsat == nil && request.TimeLockDelta == nil {
+				return errors.New("Nothing to update, provide at least one policy flag")
+			}
+			var response interface{}
+			if err = client.Send(http.MethodPut, "/lightning/updateRoutingPolicy", request, &response); err != nil {
+				return err
+			}
+			return printJson(c.App.Writer, response)
+		},
+	}
+}
+
+func openCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "open",
+		Usage: "Open a channel",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "node-id", Required: true, Usage: "Torq node that opens the channel"},
+			&cli.StringFlag{Name: "pubkey", Required: true, Usage: "Public key of the peer"},
+			&cli.StringFlag{Name: "host", Usage: "Host of the peer when not connected yet"},
+			&cli.Int64Flag{Name: "amount", Required: true, Usage: "Local funding amount in sat"},
+			&cli.Int64Flag{Name: "push-sat"},
+			&cli.Uint64Flag{Name: "sat-per-vbyte"},
+			&cli.IntFlag{Name: "target-conf"},
+			&cli.BoolFlag{Name: "private"},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := connect(c)
+			if err != nil {
+				return err
+			}
+			request := lightning_helpers.OpenChannelRequest{
+				CommunicationRequest: lightning_helpers.CommunicationRequest{NodeId: c.Int("node-id")},
+				NodePubKey:           c.String("pubkey"),
+				LocalFundingAmount:   c.Int64("amount"),
+			}
+			if c.IsSet("host") {
+				host := c.String("host")
+				request.Host = &host
+			}
+			if c.IsSet("push-sat") {
+				pushSat := c.Int64("push-sat")
+				request.PushSat = &pushSat
+			}
+			if c.IsSet("sat-per-vbyte") {
+				satPerVbyte := c.Uint64("sat-per-vbyte")
+				request.SatPerVbyte = &satPerVbyte
+			}
+			if c.IsSet("target-conf") {
+				targetConf := int32(c.Int("target-conf"))
+				request.TargetConf = &targetConf
+			}
+			if c.IsSet("private") {
+				private := c.Bool("private")
+				request.Private = &private
+			}
+			var response interface{}
+			if err = client.Send(http.MethodPost, "/lightning/open", request, &response); err != nil {
+				return err
+			}
+			return printJson(c.App.Writer, response)
+		},
+	}
+}
+
+func closeCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "close",
+		Usage: "Close a channel",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "channel-id", Required: true},
+			&cli.IntFlag{Name: "node-id", Usage: "Torq node of the channel, looked up when omitted"},
+			&cli.BoolFlag{Name: "force", Usage: "Force close the channel"},
+			&cli.Uint64Flag{Name: "sat-per-vbyte"},
+			&cli.IntFlag{Name: "target-conf"},
+			&cli.StringFlag{Name: "delivery-address"},
+			&cli.BoolFlag{Name: "yes", Usage: "Do not ask for confirmation"},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := connect(c)
+			if err != nil {
+				return err
+			}
+			request := lightning_helpers.CloseChannelRequest{ChannelId: c.Int("channel-id")}
+			if request.NodeId, err = resolveNodeId(c, client, request.ChannelId); err != nil {
+				return err
+			}
+			if !c.Bool("yes") && !confirm(c, fmt.Sprintf("Close channel %v", request.ChannelId)) {
+				return errors.New("Aborted")
+			}
+			if c.IsSet("force") {
+				force := c.Bool("force")
+				request.Force = &force
+			}
+			if c.IsSet("sat-per-vbyte") {
+				satPerVbyte := c.Uint64("sat-per-vbyte")
+				request.SatPerVbyte = &satPerVbyte
+			}
+			if c.IsSet("target-conf") {
+				targetConf := int32(c.Int("target-conf"))
+				request.TargetConf = &targetConf
+			}
+			if c.IsSet("delivery-address") {
+				deliveryAddress := c.String("delivery-address")
+				request.DeliveryAddress = &deliveryAddress
+			}
+			var response interface{}
+			if err = client.Send(http.MethodPost, "/lightning/close", request, &response); err != nil {
+				return err
+			}
+			return printJson(c.App.Writer, response)
+		},
+	}
+}
+
+func confirm(c *cli.Context, question string) bool {
+	fmt.Fprintf(c.App.Writer, "%v? [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func workflowCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "workflow",
+		Usage: "List or trigger workflows",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List the workflows",
+				Action: func(c *cli.Context) error {
+					client, err := connect(c)
+					if err != nil {
+						return err
+					}
+					var workflows []map[string]interface{}
+					if err = client.Get("/workflows", nil, &workflows); err != nil {
+						return err
+					}
+					return printRows(c, workflows, workflowColumns)
+				},
+			},
+			{
+				Name:  "trigger",
+				Usage: "Trigger the manual trigger of the active version of a workflow",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "workflow-id", Required: true},
+				},
+				Action: triggerWorkflow,
+			},
+		},
+	}
+}
+
+type workflowPage struct {
+	Nodes []struct {
+		WorkflowVersionNodeId int                               `json:"workflowVersionNodeId"`
+		Type                  workflow_helpers.WorkflowNodeType `json:"type"`
+	} `json:"nodes"`
+}
+
+func triggerWorkflow(c *cli.Context) error {
+	client, err := connect(c)
+	if err != nil {
+		return err
+	}
+	workflowId := c.Int("workflow-id")
+	var workflows []map[string]interface{}
+	if err = client.Get("/workflows", nil, &workflows); err != nil {
+		return err
+	}
+	var workflowVersionId int
+	for _, workflow := range workflows {
+		if workflow["workflowId"] == float64(workflowId) {
+			versionId, _ := workflow["activeWorkflowVersionId"].(float64)
+			workflowVersionId = int(versionId)
+		}
+	}
+	if workflowVersionId == 0 {
+		return errors.Newf("Workflow %v does not exist or has no active version", workflowId)
+	}
+
+	var page workflowPage
+	if err = client.Get(fmt.Sprintf("/workflows/%v/versions/%v", workflowId, workflowVersionId), nil, &page); err != nil {
+		return err
+	}
+	for _, node := range page.Nodes {
+		if node.Type != workflow_helpers.WorkflowNodeManualTrigger {
+			continue
+		}
+		request := map[string]int{
+			"workflowId":            workflowId,
+			"workflowVersionId":     workflowVersionId,
+			"workflowVersionNodeId": node.WorkflowVersionNodeId,
+			"type":                  int(workflow_helpers.WorkflowNodeManualTrigger),
+		}
+		var response interface{}
+		if err = client.Send(http.MethodPost, "/workflows/trigger", request, &response); err != nil {
+			return err
+		}
+		return printJson(c.App.Writer, response)
+	}
+	return errors.Newf("Workflow %v has no manual trigger", workflowId)
+}
+
+func rebalanceCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "rebalance",
+		Usage: "Start a manual rebalance towards or from a channel",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "node-id", Usage: "Torq node of the channel, looked up when omitted"},
+			&cli.IntFlag{Name: "incoming-channel-id", Usage: "Channel that receives the liquidity"},
+			&cli.IntFlag{Name: "outgoing-channel-id", Usage: "Channel that sends the liquidity"},
+			&cli.IntSliceFlag{Name: "channel-id", Required: true,
+				Usage: "Channel to use on the other side (repeatable)"},
+			&cli.Uint64Flag{Name: "amount", Required: true, Usage: "Amount in sat"},
+			&cli.Uint64Flag{Name: "max-cost", Required: true, Usage: "Maximum fee in sat"},
+			&cli.IntFlag{Name: "max-concurrency", Value: 1},
+		},
+		Action: func(c *cli.Context) error {
+			incomingChannelId := c.Int("incoming-channel-id")
+			outgoingChannelId := c.Int("outgoing-channel-id")
+			if (incomingChannelId == 0) == (outgoingChannelId == 0) {
+				return errors.New("Provide either --incoming-channel-id or --outgoing-channel-id")
+			}
+			client, err := connect(c)
+			if err != nil {
+				return err
+			}
+			nodeId, err := resolveNodeId(c, client, incomingChannelId+outgoingChannelId)
+			if err != nil {
+				return err
+			}
+			request := lightning_helpers.RebalanceRequests{
+				CommunicationRequest: lightning_helpers.CommunicationRequest{NodeId: nodeId},
+				Requests: []lightning_helpers.RebalanceRequest{{
+					Origin:             lightning_helpers.RebalanceManual,
+					OriginId:           int(time.Now().Unix()),
+					OriginReference:    "torq client",
+					IncomingChannelId:  incomingChannelId,
+					OutgoingChannelId:  outgoingChannelId,
+					ChannelIds:         c.IntSlice("channel-id"),
+					AmountMsat:         c.Uint64("amount") * 1000,
+					MaximumCostMsat:    c.Uint64("max-cost") * 1000,
+					MaximumConcurrency: c.Int("max-concurrency"),
+				}},
+			}
+			var response interface{}
+			if err = client.Send(http.MethodPost, "/automation/rebalance", request, &response); err != nil {
+				return err
+			}
+			return printJson(c.App.Writer, response)
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cockroachdb/errors"
+)
+
+type column struct {
+	heading string
+	key     string
+}
+
+func printJson(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return errors.Wrap(encoder.Encode(value), "JSON encode output")
+}
+
+func printTable(w io.Writer, rows []map[string]interface{}, columns []column) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headings := make([]string, len(columns))
+	for i, col := range columns {
+		headings[i] = col.heading
+	}
+	fmt.Fprintln(tw, strings.Join(headings, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, col := range columns {
+			cells[i] = formatValue(row[col.key])
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return errors.Wrap(tw.Flush(), "Writing table")
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		if v == "" {
+			return "-"
+		}
+		return v
+	case float64:
+		// JSON numbers are decoded as float64, integers are printed without decimals
+		if v == float64(int64(v)) {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		var names []string
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok && m["name"] != nil {
+				names = append(names, formatValue(m["name"]))
+				continue
+			}
+			names = append(names, formatValue(item))
+		}
+		if len(names) == 0 {
+			return "-"
+		}
+		return strings.Join(names, ",")
+	}
+	return fmt.Sprint(value)
+}
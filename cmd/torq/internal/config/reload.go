@@ -0,0 +1,182 @@
+package config
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+
+	"github.com/cockroachdb/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+	"github.com/urfave/cli/v2/altsrc"
+)
+
+// configFlag is the flag that points to the config file, it's never reloaded itself.
+const configFlag = "config"
+
+type ReloadResult struct {
+	// Applied settings changed and are in use now
+	Applied []string `json:"applied"`
+	// RestartRequired settings changed but are only used after Torq restarts
+	RestartRequired []string `json:"restartRequired"`
+	// Failed settings changed but could not be applied, the previous value is still in use
+	Failed []string `json:"failed"`
+}
+
+type changeHandler struct {
+	names []string
+	apply func(c *cli.Context) error
+}
+
+// Reloader re-reads the config file and applies the settings that can change while Torq is running.
+// Settings given on the command line keep their value, exactly like when Torq starts.
+type Reloader struct {
+	flags      []cli.Flag
+	args       []string
+	loadSource func(c *cli.Context) (altsrc.InputSourceContext, error)
+
+	mu       sync.Mutex
+	values   map[string]string
+	handlers []changeHandler
+}
+
+// NewReloader reads the current configuration, args are the global command line arguments Torq was started with.
+func NewReloader(flags []cli.Flag,
+	args []string,
+	loadSource func(c *cli.Context) (altsrc.InputSourceContext, error)) (*Reloader, error) {
+
+	reloader := &Reloader{flags: flags, args: args, loadSource: loadSource}
+	_, values, err := reloader.load()
+	if err != nil {
+		return nil, err
+	}
+	reloader.values = values
+	return reloader, nil
+}
+
+// OnChange registers apply for a group of settings, apply is called once when one or more of them changed.
+// Settings without a handler require a restart.
+func (r *Reloader) OnChange(apply func(c *cli.Context) error, names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers = append(r.handlers, changeHandler{names: names, apply: apply})
+}
+
+// Reload re-reads the configuration and applies the changed settings.
+func (r *Reloader) Reload() (ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, values, err := r.load()
+	if err != nil {
+		return ReloadResult{}, err
+	}
+	changed := make(map[string]bool)
+	for name, value := range values {
+		if r.values[name] != value {
+			changed[name] = true
+		}
+	}
+
+	result := ReloadResult{Applied: []string{}, RestartRequired: []string{}, Failed: []string{}}
+	for _, handler := range r.handlers {
+		var handlerChanges []string
+		for _, name := range handler.names {
+			if changed[name] {
+				handlerChanges = append(handlerChanges, name)
+				delete(changed, name)
+			}
+		}
+		if len(handlerChanges) == 0 {
+			continue
+		}
+		if err := handler.apply(c); err != nil {
+			log.Error().Err(err).Msgf("Failed to apply the reloaded settings %v", handlerChanges)
+			result.Failed = append(result.Failed, handlerChanges...)
+			continue
+		}
+		for _, name := range handlerChanges {
+			r.values[name] = values[name]
+		}
+		result.Applied = append(result.Applied, handlerChanges...)
+	}
+	// The stored value stays the one that is in use so these are reported again until Torq restarts.
+	for name := range changed {
+		result.RestartRequired = append(result.RestartRequired, name)
+	}
+	sort.Strings(result.Applied)
+	sort.Strings(result.RestartRequired)
+	sort.Strings(result.Failed)
+	return result, nil
+}
+
+// WatchHangup reloads the configuration every time Torq receives SIGHUP until ctx is done.
+func (r *Reloader) WatchHangup(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		}
+		result, err := r.Reload()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to reload the configuration, the previous configuration is still used.")
+			continue
+		}
+		logResult(result)
+	}
+}
+
+func logResult(result ReloadResult) {
+	log.Info().Msgf("Configuration reloaded, applied: %v", result.Applied)
+	if len(result.RestartRequired) != 0 {
+		log.Warn().Msgf("Configuration changes that require a restart of Torq: %v", result.RestartRequired)
+	}
+	if len(result.Failed) != 0 {
+		log.Error().Msgf("Configuration changes that could not be applied: %v", result.Failed)
+	}
+}
+
+// load parses the command line arguments and the config file into a new context the same way Torq does when it
+// starts.
+func (r *Reloader) load() (*cli.Context, map[string]string, error) {
+	set := flag.NewFlagSet("torq", flag.ContinueOnError)
+	set.SetOutput(io.Discard)
+	for _, f := range r.flags {
+		if err := f.Apply(set); err != nil {
+			return nil, nil, errors.Wrapf(err, "Applying flag %v", f.Names()[0])
+		}
+	}
+	// Parsing stops at the command (start), so only the global flags are parsed.
+	if err := set.Parse(r.args); err != nil {
+		return nil, nil, errors.Wrap(err, "Parsing the command line arguments")
+	}
+	c := cli.NewContext(nil, set, nil)
+	source, err := r.loadSource(c)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Loading the config file")
+	}
+	if err := altsrc.ApplyInputSourceValues(c, source, r.flags); err != nil {
+		return nil, nil, errors.Wrap(err, "Applying the config file")
+	}
+
+	values := make(map[string]string)
+	for _, f := range r.flags {
+		name := f.Names()[0]
+		if name == configFlag {
+			continue
+		}
+		if setFlag := set.Lookup(name); setFlag != nil {
+			values[name] = setFlag.Value.String()
+		}
+	}
+	return c, values, nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+	"github.com/urfave/cli/v2/altsrc"
+)
+
+func testFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: configFlag},
+		altsrc.NewStringFlag(&cli.StringFlag{Name: "torq.debuglevel", Value: "info"}),
+		altsrc.NewStringFlag(&cli.StringFlag{Name: "torq.port", Value: "8080"}),
+		altsrc.NewIntFlag(&cli.IntFlag{Name: "torq.retention.htlc-events"}),
+	}
+}
+
+func loadTestSource(c *cli.Context) (altsrc.InputSourceContext, error) {
+	return altsrc.NewTomlSourceFromFile(c.String(configFlag))
+}
+
+func TestReload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "torq.conf")
+	writeConfig := func(content string) {
+		if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("[torq]\ndebuglevel = \"info\"\nport = \"8080\"\nretention.htlc-events = 30\n")
+
+	args := []string{"--" + configFlag, configPath, "--torq.retention.htlc-events", "10", "start"}
+	reloader, err := NewReloader(testFlags(), args, loadTestSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var debuglevel string
+	reloader.OnChange(func(c *cli.Context) error {
+		debuglevel = c.String("torq.debuglevel")
+		return nil
+	}, "torq.debuglevel")
+	reloader.OnChange(func(c *cli.Context) error {
+		t.Error("expected the command line value to be kept")
+		return nil
+	}, "torq.retention.htlc-events")
+
+	writeConfig("[torq]\ndebuglevel = \"debug\"\nport = \"8443\"\nretention.htlc-events = 60\n")
+	result, err := reloader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ReloadResult{
+		Applied:         []string{"torq.debuglevel"},
+		RestartRequired: []string{"torq.port"},
+		Failed:          []string{},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+	if debuglevel != "debug" {
+		t.Errorf("expected the debug level to be applied, got %v", debuglevel)
+	}
+
+	// Nothing changed since the last reload, the restart is still required
+	result, err = reloader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected.Applied = []string{}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	writeConfig("[torq\n")
+	if _, err = reloader.Reload(); err == nil {
+		t.Error("expected an error for an invalid config file")
+	}
+}
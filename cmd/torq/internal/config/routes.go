@@ -0,0 +1,23 @@
+package config
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lncapital/torq/pkg/server_errors"
+)
+
+func RegisterConfigRoutes(r *gin.RouterGroup, reloader *Reloader) {
+	r.POST("reload", func(c *gin.Context) { reloadHandler(c, reloader) })
+}
+
+func reloadHandler(c *gin.Context, reloader *Reloader) {
+	result, err := reloader.Reload()
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Reloading the configuration")
+		return
+	}
+	logResult(result)
+	c.JSON(http.StatusOK, result)
+}
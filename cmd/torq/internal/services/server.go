@@ -10,6 +10,12 @@ import (
 
 	"github.com/lncapital/torq/internal/automation"
 	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/database"
+	"github.com/lncapital/torq/internal/fee_estimates"
+	"github.com/lncapital/torq/internal/force_closes"
+	"github.com/lncapital/torq/internal/forecasts"
+	"github.com/lncapital/torq/internal/peer_reliability"
+	"github.com/lncapital/torq/internal/peers"
 	"github.com/lncapital/torq/internal/services_helpers"
 	"github.com/lncapital/torq/internal/workflows"
 )
@@ -158,3 +164,136 @@ func StartCronService(ctx context.Context, db *sqlx.DB) {
 
 	automation.CronTriggerMonitor(ctx, db)
 }
+
+func StartForecastService(ctx context.Context, db *sqlx.DB) {
+
+	serviceType := services_helpers.ForecastService
+
+	defer log.Info().Msgf("%v terminated", serviceType.String())
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error().Msgf("%v is panicking %v", serviceType.String(), string(debug.Stack()))
+			cache.SetFailedCoreServiceState(serviceType)
+			return
+		}
+	}()
+
+	cache.SetActiveCoreServiceState(serviceType)
+
+	forecasts.ForecastServiceStart(ctx, db)
+
+	cache.SetInactiveCoreServiceState(serviceType)
+}
+
+// StartDatabaseService reports the health of the database connection: Pending while the database is unreachable.
+func StartDatabaseService(ctx context.Context, db *sqlx.DB) {
+
+	serviceType := services_helpers.DatabaseService
+
+	defer log.Info().Msgf("%v terminated", serviceType.String())
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error().Msgf("%v is panicking %v", serviceType.String(), string(debug.Stack()))
+			cache.SetFailedCoreServiceState(serviceType)
+			return
+		}
+	}()
+
+	cache.SetActiveCoreServiceState(serviceType)
+
+	database.MonitorConnection(ctx, db, func(healthy bool) {
+		if healthy {
+			cache.SetActiveCoreServiceState(serviceType)
+		} else {
+			cache.SetPendingCoreServiceState(serviceType)
+		}
+	})
+
+	cache.SetInactiveCoreServiceState(serviceType)
+}
+
+func StartPeerReliabilityService(ctx context.Context, db *sqlx.DB) {
+
+	serviceType := services_helpers.PeerReliabilityService
+
+	defer log.Info().Msgf("%v terminated", serviceType.String())
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error().Msgf("%v is panicking %v", serviceType.String(), string(debug.Stack()))
+			cache.SetFailedCoreServiceState(serviceType)
+			return
+		}
+	}()
+
+	cache.SetActiveCoreServiceState(serviceType)
+
+	peer_reliability.PeerReliabilityServiceStart(ctx, db)
+
+	cache.SetInactiveCoreServiceState(serviceType)
+}
+
+func StartPeerReconnectService(ctx context.Context, db *sqlx.DB) {
+
+	serviceType := services_helpers.PeerReconnectService
+
+	defer log.Info().Msgf("%v terminated", serviceType.String())
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error().Msgf("%v is panicking %v", serviceType.String(), string(debug.Stack()))
+			cache.SetFailedCoreServiceState(serviceType)
+			return
+		}
+	}()
+
+	cache.SetActiveCoreServiceState(serviceType)
+
+	peers.PeerReconnectServiceStart(ctx, db)
+
+	cache.SetInactiveCoreServiceState(serviceType)
+}
+
+func StartFeeEstimateService(ctx context.Context, db *sqlx.DB) {
+
+	serviceType := services_helpers.FeeEstimateService
+
+	defer log.Info().Msgf("%v terminated", serviceType.String())
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error().Msgf("%v is panicking %v", serviceType.String(), string(debug.Stack()))
+			cache.SetFailedCoreServiceState(serviceType)
+			return
+		}
+	}()
+
+	cache.SetActiveCoreServiceState(serviceType)
+
+	fee_estimates.FeeEstimateServiceStart(ctx, db)
+
+	cache.SetInactiveCoreServiceState(serviceType)
+}
+
+func StartForceCloseMonitorService(ctx context.Context, db *sqlx.DB) {
+
+	serviceType := services_helpers.ForceCloseMonitorService
+
+	defer log.Info().Msgf("%v terminated", serviceType.String())
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error().Msgf("%v is panicking %v", serviceType.String(), string(debug.Stack()))
+			cache.SetFailedCoreServiceState(serviceType)
+			return
+		}
+	}()
+
+	cache.SetActiveCoreServiceState(serviceType)
+
+	force_closes.ForceCloseMonitorServiceStart(ctx, db)
+
+	cache.SetInactiveCoreServiceState(serviceType)
+}
@@ -265,6 +265,25 @@ func StartLndChannelBalanceCacheMaintenance(ctx context.Context,
 	lnd.ChannelBalanceCacheMaintenance(ctx, lnrpc.NewLightningClient(conn), db, cache.GetNodeSettingsByNodeId(nodeId))
 }
 
+func StartLndGraphSnapshotService(ctx context.Context, conn *grpc.ClientConn, db *sqlx.DB, nodeId int) {
+
+	serviceType := services_helpers.LndServiceGraphSnapshotService
+
+	defer log.Info().Msgf("%v terminated for nodeId: %v", serviceType.String(), nodeId)
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error().Msgf("%v is panicking (nodeId: %v) %v", serviceType.String(), nodeId, string(debug.Stack()))
+			cache.SetFailedNodeServiceState(serviceType, nodeId)
+			return
+		}
+	}()
+
+	cache.SetPendingNodeServiceState(serviceType, nodeId)
+
+	lnd.SubscribeAndStoreGraphSnapshots(ctx, lnrpc.NewLightningClient(conn), db, cache.GetNodeSettingsByNodeId(nodeId))
+}
+
 func StartClnPeersService(ctx context.Context, conn *grpc.ClientConn, db *sqlx.DB, nodeId int) {
 
 	serviceType := services_helpers.ClnServicePeersService
@@ -379,6 +398,25 @@ func StartClnTransactionsService(ctx context.Context, conn *grpc.ClientConn, db
 	cln2.SubscribeAndStoreTransactions(ctx, cln.NewNodeClient(conn), db, cache.GetNodeSettingsByNodeId(nodeId))
 }
 
+func StartClnHtlcsService(ctx context.Context, conn *grpc.ClientConn, db *sqlx.DB, nodeId int) {
+
+	serviceType := services_helpers.ClnServiceHtlcsService
+
+	defer log.Info().Msgf("%v terminated for nodeId: %v", serviceType.String(), nodeId)
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error().Msgf("%v is panicking (nodeId: %v) %v", serviceType.String(), nodeId, string(debug.Stack()))
+			cache.SetFailedNodeServiceState(serviceType, nodeId)
+			return
+		}
+	}()
+
+	cache.SetPendingNodeServiceState(serviceType, nodeId)
+
+	cln2.SubscribeAndProcessHtlcs(ctx, cln.NewNodeClient(conn), cache.GetNodeSettingsByNodeId(nodeId))
+}
+
 func StartClnForwardsService(ctx context.Context, conn *grpc.ClientConn, db *sqlx.DB, nodeId int) {
 
 	serviceType := services_helpers.ClnServiceForwardsService
@@ -435,3 +473,22 @@ func StartClnPaymentsService(ctx context.Context, conn *grpc.ClientConn, db *sql
 
 	cln2.SubscribeAndStorePayments(ctx, cln.NewNodeClient(conn), db, cache.GetNodeSettingsByNodeId(nodeId))
 }
+
+func StartClnGraphSnapshotService(ctx context.Context, conn *grpc.ClientConn, db *sqlx.DB, nodeId int) {
+
+	serviceType := services_helpers.ClnServiceGraphSnapshotService
+
+	defer log.Info().Msgf("%v terminated for nodeId: %v", serviceType.String(), nodeId)
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error().Msgf("%v is panicking (nodeId: %v) %v", serviceType.String(), nodeId, string(debug.Stack()))
+			cache.SetFailedNodeServiceState(serviceType, nodeId)
+			return
+		}
+	}()
+
+	cache.SetPendingNodeServiceState(serviceType, nodeId)
+
+	cln2.SubscribeAndStoreGraphSnapshots(ctx, cln.NewNodeClient(conn), db, cache.GetNodeSettingsByNodeId(nodeId))
+}
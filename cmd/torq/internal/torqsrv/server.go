@@ -1,6 +1,8 @@
 package torqsrv
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/lncapital/torq/internal/move_funds"
 	"net/http"
@@ -22,37 +24,54 @@ import (
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/lncapital/torq/cmd/torq/internal/config"
 	"github.com/lncapital/torq/internal/auth"
 	"github.com/lncapital/torq/internal/automation"
+	"github.com/lncapital/torq/internal/cache"
 	"github.com/lncapital/torq/internal/categories"
 	"github.com/lncapital/torq/internal/channel_history"
 	"github.com/lncapital/torq/internal/channels"
 	"github.com/lncapital/torq/internal/corridors"
+	"github.com/lncapital/torq/internal/fee_bumps"
+	"github.com/lncapital/torq/internal/fee_estimates"
 	"github.com/lncapital/torq/internal/flow"
+	"github.com/lncapital/torq/internal/force_closes"
+	"github.com/lncapital/torq/internal/forecasts"
 	"github.com/lncapital/torq/internal/forwards"
+	"github.com/lncapital/torq/internal/graph_snapshots"
+	"github.com/lncapital/torq/internal/htlcs"
 	"github.com/lncapital/torq/internal/invoices"
 	"github.com/lncapital/torq/internal/lightning"
 	"github.com/lncapital/torq/internal/messages"
 	"github.com/lncapital/torq/internal/nodes"
 	"github.com/lncapital/torq/internal/on_chain_tx"
 	"github.com/lncapital/torq/internal/payments"
+	"github.com/lncapital/torq/internal/peer_reliability"
 	"github.com/lncapital/torq/internal/peers"
+	"github.com/lncapital/torq/internal/reports"
 	"github.com/lncapital/torq/internal/services"
 	"github.com/lncapital/torq/internal/settings"
 	"github.com/lncapital/torq/internal/tags"
+	"github.com/lncapital/torq/internal/utxos"
 	"github.com/lncapital/torq/internal/views"
 	"github.com/lncapital/torq/internal/workflows"
 	"github.com/lncapital/torq/web"
 )
 
-func Start(tp *tracesdk.TracerProvider,
+// Start serves the API until it fails or until ctx is done. When ctx is done the in-flight requests get up to
+// shutdownTimeout to finish.
+func Start(ctx context.Context,
+	shutdownTimeout time.Duration,
+	tp *tracesdk.TracerProvider,
 	host string,
 	port int,
 	apiPswd string,
 	cookiePath string,
 	db *sqlx.DB,
 	autoLogin bool,
-	prometheusPath string) error {
+	prometheusPath string,
+	tlsSettings TLSSettings,
+	reloader *config.Reloader) error {
 
 	var p *Prometheus
 	if prometheusPath != "" {
@@ -134,17 +153,78 @@ func Start(tp *tracesdk.TracerProvider,
 		return errors.Wrap(err, "Refreshing cookie file")
 	}
 
-	err := auth.CreateSession(r, apiPswd)
+	err := auth.CreateSession(r, apiPswd, tlsSettings.Enabled)
 	if err != nil {
 		return errors.Wrap(err, "Creating Gin Session")
 	}
 
-	registerRoutes(r, db, apiPswd, cookiePath, autoLogin)
+	registerRoutes(r, db, apiPswd, cookiePath, autoLogin, reloader)
 
-	fmt.Println("Listening on port " + strconv.Itoa(port))
+	server := &http.Server{
+		Addr:              host + ":" + strconv.Itoa(port),
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	servers := []*http.Server{server}
+	serveErrors := make(chan error, 1)
+	if !tlsSettings.Enabled {
+		fmt.Println("Listening on port " + strconv.Itoa(port))
+		go func() {
+			serveErrors <- errors.Wrap(server.ListenAndServe(), "Running gin webserver")
+		}()
+	} else {
+		certificateReloader, err := newCertificateReloader(tlsSettings)
+		if err != nil {
+			return errors.Wrap(err, "Preparing TLS certificate")
+		}
+		go certificateReloader.watch()
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certificateReloader.GetCertificate,
+		}
+
+		if tlsSettings.RedirectPort != 0 {
+			probes := gin.New()
+			probes.Use(gin.Recovery())
+			services.RegisterHealthRoutes(probes, db)
+			redirectServer := &http.Server{
+				Addr:              host + ":" + strconv.Itoa(tlsSettings.RedirectPort),
+				Handler:           httpsRedirectHandler(port, probes),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			servers = append(servers, redirectServer)
+			go func() {
+				fmt.Println("Redirecting HTTP on port " + strconv.Itoa(tlsSettings.RedirectPort) + " to HTTPS")
+				if err := redirectServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Error().Err(err).Msg("HTTP to HTTPS redirect server stopped")
+				}
+			}()
+		}
+
+		fmt.Println("Listening with TLS on port " + strconv.Itoa(port))
+		go func() {
+			// The certificate is provided by the reloader
+			serveErrors <- errors.Wrap(server.ListenAndServeTLS("", ""), "Running gin webserver with TLS")
+		}()
+	}
+
+	select {
+	case err := <-serveErrors:
+		return err
+	case <-ctx.Done():
+	}
 
-	if err := r.Run(host + ":" + strconv.Itoa(port)); err != nil {
-		return errors.Wrap(err, "Running gin webserver")
+	log.Info().Msgf("Waiting up to %v for the in-flight API requests", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, srv := range servers {
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msgf("Shutting down the webserver on %v", srv.Addr)
+		}
+	}
+	if err := <-serveErrors; !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
 	return nil
 }
@@ -157,6 +237,18 @@ func applyCors(r *gin.Engine) {
 	r.Use(cors.New(corsConfig))
 }
 
+// rejectWhenShuttingDown refuses new work that arrives on open connections after the shutdown started.
+func rejectWhenShuttingDown() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cache.IsShuttingDown() {
+			c.Header("Connection", "close")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Torq is shutting down"})
+			return
+		}
+		c.Next()
+	}
+}
+
 // loginKeyGetter is used to force the Login rate
 // limiter to limit all requests regardless of IP etc.
 func loginKeyGetter(c *gin.Context) string {
@@ -198,18 +290,28 @@ func equalASCIIFold(s, t string) bool {
 	return s == t
 }
 
-func registerRoutes(r *gin.Engine, db *sqlx.DB, apiPwd string, cookiePath string, autoLogin bool) {
+func registerRoutes(r *gin.Engine,
+	db *sqlx.DB,
+	apiPwd string,
+	cookiePath string,
+	autoLogin bool,
+	reloader *config.Reloader) {
+
 	r.Use(gzip.Gzip(gzip.DefaultCompression))
 	applyCors(r)
 	// Websocket
 	ws := r.Group("/ws")
-	ws.Use(auth.AuthRequired(autoLogin))
+	ws.Use(rejectWhenShuttingDown(), auth.AuthRequired(autoLogin))
 	ws.GET("", func(c *gin.Context) {
 		err := WebsocketHandler(c, db)
 		log.Debug().Msgf("WebsocketHandler: %v", err)
 	})
 
+	// Probes for docker and kubernetes
+	services.RegisterHealthRoutes(r, db)
+
 	api := r.Group("/api")
+	api.Use(rejectWhenShuttingDown())
 
 	api.POST("/logout", auth.Logout)
 
@@ -288,6 +390,56 @@ func registerRoutes(r *gin.Engine, db *sqlx.DB, apiPwd string, cookiePath string
 			forwards.RegisterForwardsRoutes(forwardRoutes, db)
 		}
 
+		htlcRoutes := api.Group("/htlcs")
+		{
+			htlcs.RegisterHtlcsRoutes(htlcRoutes, db)
+		}
+
+		reportRoutes := api.Group("/reports")
+		{
+			reports.RegisterReportsRoutes(reportRoutes, db)
+		}
+
+		forecastRoutes := api.Group("/forecasts")
+		{
+			forecasts.RegisterForecastRoutes(forecastRoutes, db)
+		}
+
+		peerReliabilityRoutes := api.Group("/peer-reliability")
+		{
+			peer_reliability.RegisterPeerReliabilityRoutes(peerReliabilityRoutes, db)
+		}
+
+		graphRoutes := api.Group("/graph")
+		{
+			graph_snapshots.RegisterGraphSnapshotRoutes(graphRoutes, db)
+		}
+
+		feeEstimateRoutes := api.Group("/fee-estimates")
+		{
+			fee_estimates.RegisterFeeEstimateRoutes(feeEstimateRoutes, db)
+		}
+
+		deferredOperationRoutes := api.Group("/deferred-operations")
+		{
+			fee_estimates.RegisterDeferredOperationRoutes(deferredOperationRoutes, db)
+		}
+
+		utxoRoutes := api.Group("/utxos")
+		{
+			utxos.RegisterUtxoRoutes(utxoRoutes, db)
+		}
+
+		feeBumpRoutes := api.Group("/fee-bumps")
+		{
+			fee_bumps.RegisterFeeBumpRoutes(feeBumpRoutes, db)
+		}
+
+		forceCloseRoutes := api.Group("/force-closes")
+		{
+			force_closes.RegisterForceCloseRoutes(forceCloseRoutes)
+		}
+
 		flowRoutes := api.Group("/flow")
 		{
 			flow.RegisterFlowRoutes(flowRoutes, db)
@@ -303,6 +455,11 @@ func registerRoutes(r *gin.Engine, db *sqlx.DB, apiPwd string, cookiePath string
 			workflows.RegisterWorkflowRoutes(workflowRoutes, db)
 		}
 
+		interruptedOperationRoutes := api.Group("/interrupted-operations")
+		{
+			workflows.RegisterInterruptedOperationRoutes(interruptedOperationRoutes, db)
+		}
+
 		automationRoutes := api.Group("/automation")
 		{
 			automation.RegisterAutomationRoutes(automationRoutes, db)
@@ -323,6 +480,11 @@ func registerRoutes(r *gin.Engine, db *sqlx.DB, apiPwd string, cookiePath string
 			move_funds.RegisterMoveFundsRoutes(moveFundsRoutes)
 		}
 
+		configRoutes := api.Group("config")
+		{
+			config.RegisterConfigRoutes(configRoutes, reloader)
+		}
+
 		api.GET("/ping", func(c *gin.Context) {
 			c.JSON(200, gin.H{
 				"message": "pong",
@@ -0,0 +1,40 @@
+package torqsrv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lncapital/torq/internal/cache"
+)
+
+func TestRejectWhenShuttingDown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cache.ServiceCacheHandler(cache.ServicesCacheChannel, ctx)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	api := r.Group("/api")
+	api.Use(rejectWhenShuttingDown())
+	api.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func() int {
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/ping", nil))
+		return recorder.Code
+	}
+
+	if code := request(); code != http.StatusOK {
+		t.Fatalf("expected %v before the shutdown, got %v", http.StatusOK, code)
+	}
+	cache.InitiateShutdown()
+	if code := request(); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected %v during the shutdown, got %v", http.StatusServiceUnavailable, code)
+	}
+}
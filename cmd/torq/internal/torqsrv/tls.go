@@ -0,0 +1,259 @@
+package torqsrv
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// selfSignedOrganization marks certificates generated by Torq so they can be renewed when expired
+const selfSignedOrganization = "torq autogenerated cert"
+const selfSignedValidity = 14 * 30 * 24 * time.Hour
+const certificatePollSeconds = 10
+
+type TLSSettings struct {
+	Enabled  bool
+	CertPath string
+	KeyPath  string
+	// ExtraDomains are added to the self-signed certificate next to localhost and the hostname
+	ExtraDomains []string
+	// RedirectPort serves plain HTTP redirects to HTTPS when not 0
+	RedirectPort int
+}
+
+// certificateReloader serves the certificate from disk and picks up a renewed certificate
+// on SIGHUP or when the files change.
+type certificateReloader struct {
+	certPath string
+	keyPath  string
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	modTime     time.Time
+}
+
+func newCertificateReloader(settings TLSSettings) (*certificateReloader, error) {
+	if err := ensureCertificate(settings); err != nil {
+		return nil, err
+	}
+	cr := &certificateReloader{certPath: settings.CertPath, keyPath: settings.KeyPath}
+	if err := cr.reload(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+func (cr *certificateReloader) reload() error {
+	certificate, err := tls.LoadX509KeyPair(cr.certPath, cr.keyPath)
+	if err != nil {
+		return errors.Wrapf(err, "Loading TLS certificate %v and key %v", cr.certPath, cr.keyPath)
+	}
+	modTime, err := cr.latestModTime()
+	if err != nil {
+		return err
+	}
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.certificate = &certificate
+	cr.modTime = modTime
+	return nil
+}
+
+func (cr *certificateReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{cr.certPath, cr.keyPath} {
+		fileInfo, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "Reading file info of %v", path)
+		}
+		if fileInfo.ModTime().After(latest) {
+			latest = fileInfo.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (cr *certificateReloader) changed() bool {
+	modTime, err := cr.latestModTime()
+	if err != nil {
+		// Files are probably being replaced, try again on the next poll
+		return false
+	}
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return !modTime.Equal(cr.modTime)
+}
+
+func (cr *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.certificate, nil
+}
+
+// watch keeps serving the previous certificate when the new one can't be loaded.
+func (cr *certificateReloader) watch() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	ticker := time.NewTicker(certificatePollSeconds * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-hangup:
+		case <-ticker.C:
+			if !cr.changed() {
+				continue
+			}
+		}
+		if err := cr.reload(); err != nil {
+			log.Error().Err(err).Msg("Failed to reload the TLS certificate, the previous certificate is still used.")
+			continue
+		}
+		log.Info().Msgf("Reloaded TLS certificate %v.", cr.certPath)
+	}
+}
+
+// ensureCertificate generates a self-signed certificate when neither the certificate nor the key exist
+// or when the existing self-signed certificate expired.
+func ensureCertificate(settings TLSSettings) error {
+	_, certErr := os.Stat(settings.CertPath)
+	_, keyErr := os.Stat(settings.KeyPath)
+	switch {
+	case os.IsNotExist(certErr) && os.IsNotExist(keyErr):
+		log.Info().Msgf("Generating self-signed TLS certificate %v.", settings.CertPath)
+		return generateSelfSignedCertificate(settings, time.Now())
+	case certErr != nil:
+		return errors.Wrapf(certErr, "Reading TLS certificate %v", settings.CertPath)
+	case keyErr != nil:
+		return errors.Wrapf(keyErr, "Reading TLS key %v", settings.KeyPath)
+	}
+
+	certificate, err := readCertificate(settings.CertPath)
+	if err != nil {
+		return err
+	}
+	if !isExpiredSelfSigned(certificate, time.Now()) {
+		return nil
+	}
+	log.Info().Msgf("Self-signed TLS certificate %v expired, generating a new one.", settings.CertPath)
+	return generateSelfSignedCertificate(settings, time.Now())
+}
+
+func readCertificate(certPath string) (*x509.Certificate, error) {
+	certPem, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Reading TLS certificate %v", certPath)
+	}
+	block, _ := pem.Decode(certPem)
+	if block == nil {
+		return nil, errors.Newf("No PEM data found in TLS certificate %v", certPath)
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Parsing TLS certificate %v", certPath)
+	}
+	return certificate, nil
+}
+
+func isExpiredSelfSigned(certificate *x509.Certificate, now time.Time) bool {
+	selfSigned := len(certificate.Subject.Organization) == 1 &&
+		certificate.Subject.Organization[0] == selfSignedOrganization
+	return selfSigned && now.After(certificate.NotAfter)
+}
+
+func generateSelfSignedCertificate(settings TLSSettings, now time.Time) error {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return errors.Wrap(err, "Generating TLS private key")
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return errors.Wrap(err, "Generating TLS certificate serial number")
+	}
+
+	dnsNames := []string{"localhost"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "localhost" {
+		dnsNames = append(dnsNames, hostname)
+	}
+	ipAddresses := []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	for _, domain := range settings.ExtraDomains {
+		if ip := net.ParseIP(domain); ip != nil {
+			ipAddresses = append(ipAddresses, ip)
+			continue
+		}
+		dnsNames = append(dnsNames, domain)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{selfSignedOrganization},
+			CommonName:   dnsNames[len(dnsNames)-1],
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              dnsNames,
+		IPAddresses:           ipAddresses,
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return errors.Wrap(err, "Creating TLS certificate")
+	}
+	keyDer, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return errors.Wrap(err, "Marshalling TLS private key")
+	}
+
+	for _, path := range []string{settings.CertPath, settings.KeyPath} {
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return errors.Wrapf(err, "Creating directory for %v", path)
+		}
+	}
+	err = os.WriteFile(settings.KeyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	if err != nil {
+		return errors.Wrapf(err, "Writing TLS key %v", settings.KeyPath)
+	}
+	err = os.WriteFile(settings.CertPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}), 0644) //nolint:gosec
+	if err != nil {
+		return errors.Wrapf(err, "Writing TLS certificate %v", settings.CertPath)
+	}
+	return nil
+}
+
+// httpsRedirectHandler sends every plain HTTP request to the same path on the HTTPS port.
+func httpsRedirectHandler(httpsPort int, probes http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Probes are answered over plain HTTP so orchestrators don't need to trust the certificate
+		if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+			probes.ServeHTTP(w, r)
+			return
+		}
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		target := "https://" + net.JoinHostPort(host, strconv.Itoa(httpsPort)) + r.URL.RequestURI()
+		// 308 keeps the method and body of API calls
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,99 @@
+package torqsrv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSelfSignedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	settings := TLSSettings{
+		Enabled:      true,
+		CertPath:     filepath.Join(dir, "tls.cert"),
+		KeyPath:      filepath.Join(dir, "tls.key"),
+		ExtraDomains: []string{"torq.example.com", "192.168.1.10"},
+	}
+	cr, err := newCertificateReloader(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := readCertificate(settings.CertPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = certificate.VerifyHostname("torq.example.com"); err != nil {
+		t.Errorf("extra domain missing: %v", err)
+	}
+	if err = certificate.VerifyHostname("192.168.1.10"); err != nil {
+		t.Errorf("extra IP address missing: %v", err)
+	}
+	if isExpiredSelfSigned(certificate, time.Now()) {
+		t.Errorf("new certificate should not be expired")
+	}
+	if !isExpiredSelfSigned(certificate, time.Now().Add(selfSignedValidity+time.Hour)) {
+		t.Errorf("self-signed certificate should expire")
+	}
+	if keyInfo, err := os.Stat(settings.KeyPath); err != nil || keyInfo.Mode().Perm() != 0600 {
+		t.Errorf("key should only be readable by the owner")
+	}
+
+	// A regenerated certificate is picked up when the files change
+	served, _ := cr.GetCertificate(nil)
+	if err = generateSelfSignedCertificate(settings, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err = os.Chtimes(settings.CertPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if !cr.changed() {
+		t.Fatalf("changed certificate not detected")
+	}
+	if err = cr.reload(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, _ := cr.GetCertificate(nil)
+	if string(reloaded.Certificate[0]) == string(served.Certificate[0]) {
+		t.Errorf("certificate was not reloaded")
+	}
+	if cr.changed() {
+		t.Errorf("reloaded certificate should not be marked as changed")
+	}
+}
+
+func TestMissingKey(t *testing.T) {
+	dir := t.TempDir()
+	settings := TLSSettings{CertPath: filepath.Join(dir, "tls.cert"), KeyPath: filepath.Join(dir, "tls.key")}
+	if err := os.WriteFile(settings.CertPath, []byte("certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ensureCertificate(settings); err == nil {
+		t.Errorf("a certificate without key should not be replaced")
+	}
+}
+
+func TestHttpsRedirectHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "http://torq.local:8081/api/login?x=1", nil)
+	probes := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	httpsRedirectHandler(8080, probes).ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusPermanentRedirect {
+		t.Errorf("got status %v, want %v", recorder.Code, http.StatusPermanentRedirect)
+	}
+	if location := recorder.Header().Get("Location"); location != "https://torq.local:8080/api/login?x=1" {
+		t.Errorf("got location %v", location)
+	}
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest(http.MethodGet, "http://torq.local:8081"+path, nil)
+		httpsRedirectHandler(8080, probes).ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Errorf("%v: got status %v, want the probe to be served", path, recorder.Code)
+		}
+	}
+}
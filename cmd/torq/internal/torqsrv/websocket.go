@@ -8,16 +8,22 @@ import (
 
 	"github.com/lncapital/torq/internal/lightning"
 	"github.com/lncapital/torq/internal/lightning_helpers"
+	"github.com/lncapital/torq/internal/tracing"
 	"github.com/lncapital/torq/pkg/server_errors"
 
 	"github.com/cockroachdb/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/rs/zerolog/log"
 )
 
+const websocketTracerName = "torq-websocket"
+
 type wsRequest struct {
 	Type              string                               `json:"type"`
 	NewPaymentRequest *lightning_helpers.NewPaymentRequest `json:"newPaymentRequest"`
@@ -32,7 +38,7 @@ type wsError struct {
 	Error server_errors.ServerError `json:"error"`
 }
 
-func processWsReq(webSocketResponseChannel chan<- interface{}, req wsRequest) {
+func processWsReq(ctx context.Context, webSocketResponseChannel chan<- interface{}, req wsRequest) {
 	switch req.Type {
 	case "ping":
 		webSocketResponseChannel <- Pong{Message: "pong"}
@@ -43,8 +49,10 @@ func processWsReq(webSocketResponseChannel chan<- interface{}, req wsRequest) {
 			break
 		}
 		req.NewPaymentRequest.ProgressReportChannel = webSocketResponseChannel
-		// TODO FIXME OTEL instrumentation missing
-		_, err := lightning.NewPayment(context.Background(), *req.NewPaymentRequest)
+		ctx, span := otel.Tracer(websocketTracerName).Start(ctx, "newPayment",
+			trace.WithAttributes(attribute.Int("nodeId", req.NewPaymentRequest.NodeId)))
+		_, err := lightning.NewPayment(ctx, *req.NewPaymentRequest)
+		tracing.End(span, err)
 		if err != nil {
 			sendError(err, req, webSocketResponseChannel)
 		}
@@ -87,7 +95,8 @@ func WebsocketHandler(c *gin.Context, db *sqlx.DB) error {
 		}
 	}(conn)
 
-	go processWebsocketRequests(conn, db, done, webSocketResponseChannel)
+	// Requests outlive the upgrade request, they only keep its trace.
+	go processWebsocketRequests(tracing.Detach(c.Request.Context()), conn, db, done, webSocketResponseChannel)
 
 	for {
 		select {
@@ -103,7 +112,8 @@ func WebsocketHandler(c *gin.Context, db *sqlx.DB) error {
 	}
 }
 
-func processWebsocketRequests(conn *websocket.Conn,
+func processWebsocketRequests(ctx context.Context,
+	conn *websocket.Conn,
 	db *sqlx.DB,
 	done chan<- struct{},
 	webSocketResponseChannel chan<- interface{}) {
@@ -121,7 +131,7 @@ func processWebsocketRequests(conn *websocket.Conn,
 			log.Debug().Err(err).Msg("WebSocket Handshake Error.")
 			return
 		case nil:
-			go processWsReq(webSocketResponseChannel, req)
+			go processWsReq(ctx, webSocketResponseChannel, req)
 		default:
 			serverError := server_errors.SingleServerError("Could not parse request, please check that your JSON is correctly formated.")
 			wsr := wsError{
@@ -6,8 +6,11 @@ import (
 	"net/http"
 	_ "net/http/pprof" //nolint:gosec
 	"os"
+	"os/signal"
 	"runtime"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -18,16 +21,27 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v2"
 	"github.com/urfave/cli/v2/altsrc"
+	otelruntime "go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/metric/global"
 	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.18.0"
 	"google.golang.org/grpc"
 
 	"github.com/lncapital/torq/build"
 	"github.com/lncapital/torq/cmd/torq/internal/amboss_ping"
+	"github.com/lncapital/torq/cmd/torq/internal/backup"
+	"github.com/lncapital/torq/cmd/torq/internal/client"
+	"github.com/lncapital/torq/cmd/torq/internal/config"
 	"github.com/lncapital/torq/cmd/torq/internal/notifications"
 	"github.com/lncapital/torq/cmd/torq/internal/services"
 	"github.com/lncapital/torq/cmd/torq/internal/subscribe"
@@ -37,9 +51,12 @@ import (
 	"github.com/lncapital/torq/internal/core"
 	"github.com/lncapital/torq/internal/corridors"
 	"github.com/lncapital/torq/internal/database"
+	"github.com/lncapital/torq/internal/metrics"
+	services2 "github.com/lncapital/torq/internal/services"
 	"github.com/lncapital/torq/internal/services_helpers"
 	"github.com/lncapital/torq/internal/settings"
 	"github.com/lncapital/torq/internal/tags"
+	"github.com/lncapital/torq/internal/tracing"
 	"github.com/lncapital/torq/internal/vector"
 	"github.com/lncapital/torq/internal/workflows"
 	"github.com/lncapital/torq/pkg/cln_connect"
@@ -59,6 +76,9 @@ var debuglevels = map[string]zerolog.Level{ //nolint:gochecknoglobals
 
 func main() {
 
+	// Log lines written with a traced context carry the trace id
+	log.Logger = log.Logger.Hook(tracing.LogHook{})
+
 	app := cli.NewApp()
 	app.Name = "torq"
 	app.EnableBashCompletion = true
@@ -88,6 +108,11 @@ func main() {
 			Name:  "torq.prometheus.path",
 			Usage: "Set prometheus path",
 		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "torq.prometheus.channel-labels",
+			Value: string(services_helpers.ChannelLabelsChannel),
+			Usage: "Labels of the channel metrics: none (aggregated per node), channel or peer (adds the peer alias)",
+		}),
 		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:  "torq.vector.url",
 			Value: vector.VectorUrl,
@@ -116,6 +141,76 @@ func main() {
 			Value: "8080",
 			Usage: "Port to serve the HTTP API",
 		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:  "torq.tls.enabled",
+			Value: false,
+			Usage: "Serve the HTTP API over TLS",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "torq.tls.cert-path",
+			Value: homedir + "/.torq/tls.cert",
+			Usage: "Path to the TLS certificate, a self-signed certificate is generated when it does not exist",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "torq.tls.key-path",
+			Value: homedir + "/.torq/tls.key",
+			Usage: "Path to the TLS key, generated together with the self-signed certificate",
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "torq.tls.extra-domain",
+			Usage: "Extra domain or IP address added to the self-signed certificate (can be repeated)",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "torq.tls.redirect-port",
+			Usage: "Port to serve plain HTTP redirects to HTTPS, disabled when 0",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "torq.shutdown.grace-period",
+			Value: 25,
+			Usage: "Seconds in-flight API requests and active rebalancers get to finish when Torq shuts down",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "torq.retention.htlc-events",
+			Usage: "Days of raw HTLC events to keep before only the hourly aggregates remain, 0 keeps them forever",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name: "torq.retention.channel-events",
+			Usage: "Days of raw channel active/inactive events to keep before only the daily aggregates remain, " +
+				"0 keeps them forever",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name: "torq.retention.graph-events",
+			Usage: "Days of raw routing policy and node announcement updates to keep before only the daily " +
+				"aggregates remain (the latest update is always kept), 0 keeps them forever",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "torq.retention.failed-payments",
+			Usage: "Days of failed payment attempts to keep before only the daily aggregates remain, 0 keeps them forever",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "torq.peer-reliability.window-days",
+			Value: 7,
+			Usage: "Days of connection history used for the peer reliability scores",
+		}),
+		altsrc.NewFloat64Flag(&cli.Float64Flag{
+			Name:  "torq.peer-reliability.uptime-threshold",
+			Value: 90,
+			Usage: "Send a notification when the uptime percentage of a peer falls below this threshold, 0 disables it",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "torq.reconnect.max-failures",
+			Value: 5,
+			Usage: "Send a notification when reconnecting to an offline channel peer failed this many times, 0 disables it",
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "torq.reconnect.network-order",
+			Value: cli.NewStringSlice("clearnet", "tor"),
+			Usage: "Order in which the clearnet and tor addresses of an offline channel peer are tried",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "torq.readiness.max-nodes-down",
+			Usage: "Number of nodes that can be down while Torq is still reported as ready by /readyz",
+		}),
 		altsrc.NewBoolFlag(&cli.BoolFlag{
 			Name:  "torq.no-sub",
 			Value: false,
@@ -153,6 +248,52 @@ func main() {
 			Value: "password",
 			Usage: "Password used to access the database",
 		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "db.tls.sslmode",
+			Value: "disable",
+			Usage: "TLS for the database connection: disable, require, verify-ca or verify-full",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "db.tls.ca-path",
+			Usage: "CA certificate used to verify the database server (required for verify-ca and verify-full)",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "db.tls.cert-path",
+			Usage: "Client certificate presented to the database server",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "db.tls.key-path",
+			Usage: "Key of the client certificate presented to the database server",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "db.pool.max-open-conns",
+			Value: 25,
+			Usage: "Maximum number of open database connections, 0 is unlimited",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "db.pool.max-idle-conns",
+			Value: 5,
+			Usage: "Maximum number of idle database connections kept in the pool",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "db.pool.conn-max-lifetime",
+			Value: 1800,
+			Usage: "Seconds after which a database connection is replaced, 0 keeps connections forever",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "db.pool.conn-max-idle-time",
+			Value: 300,
+			Usage: "Seconds after which an idle database connection is closed, 0 keeps idle connections forever",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "db.connect-timeout",
+			Value: 10,
+			Usage: "Seconds to wait for a new database connection, 0 waits forever",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:  "db.statement-timeout",
+			Usage: "Seconds after which the database aborts a statement, 0 disables the timeout",
+		}),
 
 		// LND connection details
 		altsrc.NewStringFlag(&cli.StringFlag{
@@ -189,11 +330,25 @@ func main() {
 		// OTEL details
 		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:  "otel.exporter.type",
-			Usage: "Type of OpenTelemetry exporter stdout/file/jaeger",
+			Usage: "Type of OpenTelemetry exporter stdout/file/otlp-grpc/otlp-http/jaeger (jaeger is deprecated)",
 		}),
 		altsrc.NewStringFlag(&cli.StringFlag{
-			Name:  "otel.exporter.endpoint",
-			Usage: "endpoint for jaeger",
+			Name: "otel.exporter.endpoint",
+			Usage: "Endpoint of the collector: host:port for otlp-grpc and otlp-http (defaults to localhost:4317 " +
+				"and localhost:4318), the collector URL for jaeger",
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:  "otel.exporter.insecure",
+			Usage: "Send OTLP traces and metrics without TLS",
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "otel.exporter.header",
+			Usage: "Header sent with the OTLP traces and metrics as key=value, e.g. for authentication (can be repeated)",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "otel.service-name",
+			Value: "torq",
+			Usage: "Service name of the traces",
 		}),
 		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:  "otel.exporter.path",
@@ -219,6 +374,7 @@ func main() {
 
 			var exporter tracesdk.SpanExporter
 			var tp *tracesdk.TracerProvider
+			sampler := tracing.NewSampler(c.Float64("otel.sampler.fraction"))
 			switch strings.ToLower(c.String("otel.exporter.type")) {
 			case "stdout":
 				// Set up OTLP tracing (stdout for debug).
@@ -227,15 +383,30 @@ func main() {
 					log.Error().Err(err).Msgf("OpenTelemetry error: %v", err)
 					os.Exit(1)
 				}
-				tp = setupTracerProvider(c.Float64("otel.sampler.fraction"), exporter)
+				tp = setupTracerProvider(c, exporter, sampler)
 				defer func() { _ = exporter.Shutdown(context.Background()) }()
+			case "otlp-grpc", "otlp-http":
+				exporter, err = newOtlpExporter(c)
+				if err != nil {
+					log.Error().Err(err).Msgf("OpenTelemetry error: %v", err)
+					os.Exit(1)
+				}
+				tp = setupTracerProvider(c, exporter, sampler)
+				defer func() { _ = exporter.Shutdown(context.Background()) }()
+				var mp *sdkmetric.MeterProvider
+				mp, err = setupMeterProvider(c)
+				if err != nil {
+					log.Error().Err(err).Msgf("OpenTelemetry error: %v", err)
+					os.Exit(1)
+				}
+				defer func() { _ = mp.Shutdown(context.Background()) }()
 			case "jaeger":
 				exporter, err = jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(c.String("otel.exporter.endpoint"))))
 				if err != nil {
 					log.Error().Err(err).Msgf("OpenTelemetry error: %v", err)
 					os.Exit(1)
 				}
-				tp = setupTracerProvider(c.Float64("otel.sampler.fraction"), exporter)
+				tp = setupTracerProvider(c, exporter, sampler)
 				defer func() { _ = exporter.Shutdown(context.Background()) }()
 			case "file":
 				var f *os.File
@@ -255,20 +426,25 @@ func main() {
 					log.Error().Err(err).Msgf("OpenTelemetry error: %v", err)
 					os.Exit(1)
 				}
-				tp = setupTracerProvider(c.Float64("otel.sampler.fraction"), exporter)
+				tp = setupTracerProvider(c, exporter, sampler)
 				defer func() { _ = exporter.Shutdown(context.Background()) }()
 			}
 
+			channelLabels, err := services_helpers.ParseChannelLabels(c.String("torq.prometheus.channel-labels"))
+			if err != nil {
+				return errors.Wrap(err, "Parsing prometheus channel labels")
+			}
 			registry := prometheus.NewRegistry()
-			services_helpers.SetMetrics(services_helpers.NewMetrics(registry))
+			services_helpers.SetMetrics(services_helpers.NewMetrics(registry, channelLabels)).
+				SetChannelLabelResolver(metrics.ResolveChannelLabels)
+			registry.MustRegister(metrics.NewCollector(channelLabels))
 			prometheus2.SetRegistry(registry)
 
 			// Print startup message
 			fmt.Printf("Starting Torq %s\n", build.ExtendedVersion())
 
 			fmt.Println("Connecting to the Torq database")
-			db, err := database.PgConnect(c.String("db.name"), c.String("db.user"),
-				c.String("db.password"), c.String("db.host"), c.String("db.port"))
+			db, err := database.PgConnect(getDatabaseSettings(c))
 			if err != nil {
 				return errors.Wrap(err, "Database connect")
 			}
@@ -290,11 +466,41 @@ func main() {
 			go cache.ChannelsCacheHandler(cache.ChannelsCacheChannel, ctxGlobal)
 			go cache.TaggedCacheHandler(cache.TaggedCacheChannel, ctxGlobal)
 			go cache.TriggersCacheHandler(cache.TriggersCacheChannel, ctxGlobal)
+			go cache.ForecastsCacheHandler(cache.ForecastsCacheChannel, ctxGlobal)
+			go cache.CompetitorFeesCacheHandler(cache.CompetitorFeesCacheChannel, ctxGlobal)
+			go cache.PeerReliabilityCacheHandler(cache.PeerReliabilityCacheChannel, ctxGlobal)
+			go cache.PeerReconnectsCacheHandler(cache.PeerReconnectsCacheChannel, ctxGlobal)
 			go tags.TagsCacheHandler(tags.TagsCacheChannel, ctxGlobal)
 			go workflows.RebalanceCacheHandler(workflows.RebalancesCacheChannel, ctxGlobal)
 			go cache.ServiceCacheHandler(cache.ServicesCacheChannel, ctxGlobal)
 
 			cache.SetVectorUrlBase(c.String("torq.vector.url"))
+			cache.SetRetentionDays(cache.RetentionDays{
+				HtlcEvents:     c.Int("torq.retention.htlc-events"),
+				ChannelEvents:  c.Int("torq.retention.channel-events"),
+				GraphEvents:    c.Int("torq.retention.graph-events"),
+				FailedPayments: c.Int("torq.retention.failed-payments"),
+			})
+			cache.SetPeerReliabilitySettings(cache.PeerReliabilitySettings{
+				WindowDays:      c.Int("torq.peer-reliability.window-days"),
+				UptimeThreshold: c.Float64("torq.peer-reliability.uptime-threshold"),
+			})
+			cache.SetPeerReconnectSettings(cache.PeerReconnectSettings{
+				MaxFailures:  c.Int("torq.reconnect.max-failures"),
+				NetworkOrder: c.StringSlice("torq.reconnect.network-order"),
+			})
+
+			services2.SetReadinessSettings(services2.ReadinessSettings{
+				MaxNodesDown: c.Int("torq.readiness.max-nodes-down"),
+			})
+
+			var pprof pprofServer
+			reloader, err := config.NewReloader(cmdFlags, os.Args[1:], loadFlags())
+			if err != nil {
+				return errors.Wrap(err, "Reading the configuration")
+			}
+			registerReloadHandlers(reloader, &pprof, sampler)
+			go reloader.WatchHangup(ctxGlobal)
 
 			cache.InitStates(c.Bool("torq.no-sub"))
 
@@ -310,20 +516,56 @@ func main() {
 
 			// This function initiates the database migration(s) and parses command line parameters
 			// When done the RootService is set to Initialising
-			go migrateAndProcessArguments(db, c)
+			go migrateAndProcessArguments(ctxGlobal, db, c)
 
 			go servicesMonitor(db)
 
-			if c.String("torq.pprof.path") != "" {
-				go pprofStartup(c)
-			}
+			pprof.serve(c.String("torq.pprof.path"))
+
+			// SIGTERM is sent by docker/kubernetes when the container is stopped
+			ctxSignal, stopSignal := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+			defer stopSignal()
+			gracePeriod := time.Duration(c.Int("torq.shutdown.grace-period")) * time.Second
+			ctxServer, cancelServer := context.WithCancel(ctxGlobal)
+			defer cancelServer()
+			workflowsStopped := make(chan struct{})
+			go func() {
+				select {
+				case <-ctxGlobal.Done():
+					return
+				case <-ctxSignal.Done():
+				}
+				log.Info().Msgf("Shutdown requested, waiting up to %v for in-flight work", gracePeriod)
+				cache.InitiateShutdown()
+				cancelServer()
+				var shutdown sync.WaitGroup
+				shutdown.Add(2)
+				go func() {
+					defer shutdown.Done()
+					workflows.ShutdownRebalancers(db, gracePeriod)
+				}()
+				go func() {
+					defer shutdown.Done()
+					workflows.ShutdownLightningOperations(db, gracePeriod)
+				}()
+				shutdown.Wait()
+				close(workflowsStopped)
+			}()
 
-			if err = torqsrv.Start(tp, c.String("torq.network-interface"), c.Int("torq.port"), c.String("torq.password"),
-				c.String("torq.cookie-path"),
-				db, c.Bool("torq.auto-login"), c.String("torq.prometheus.path")); err != nil {
+			if err = torqsrv.Start(ctxServer, gracePeriod, tp, c.String("torq.network-interface"), c.Int("torq.port"),
+				c.String("torq.password"), c.String("torq.cookie-path"),
+				db, c.Bool("torq.auto-login"), c.String("torq.prometheus.path"), torqsrv.TLSSettings{
+					Enabled:      c.Bool("torq.tls.enabled"),
+					CertPath:     c.String("torq.tls.cert-path"),
+					KeyPath:      c.String("torq.tls.key-path"),
+					ExtraDomains: c.StringSlice("torq.tls.extra-domain"),
+					RedirectPort: c.Int("torq.tls.redirect-port"),
+				}, reloader); err != nil {
 				return errors.Wrap(err, "Starting torq webserver")
 			}
 
+			<-workflowsStopped
+			log.Info().Msg("Torq stopped")
 			return nil
 		},
 	}
@@ -332,8 +574,7 @@ func main() {
 		Name:  "migrate_up",
 		Usage: "Migrates the database to the latest version",
 		Action: func(c *cli.Context) error {
-			db, err := database.PgConnect(c.String("db.name"), c.String("db.user"),
-				c.String("db.password"), c.String("db.host"), c.String("db.port"))
+			db, err := database.PgConnect(getDatabaseSettings(c))
 			if err != nil {
 				return errors.Wrap(err, "Database connect")
 			}
@@ -361,6 +602,9 @@ func main() {
 	app.Commands = cli.Commands{
 		start,
 		migrateUp,
+		client.Command(),
+		backup.BackupCommand(getDatabaseSettings),
+		backup.RestoreCommand(getDatabaseSettings),
 	}
 
 	err = app.Run(os.Args)
@@ -369,27 +613,238 @@ func main() {
 	}
 }
 
-func setupTracerProvider(fraction float64, exporter tracesdk.SpanExporter) *tracesdk.TracerProvider {
+func getDatabaseSettings(c *cli.Context) database.ConnectionSettings {
+	return database.ConnectionSettings{
+		Name:             c.String("db.name"),
+		User:             c.String("db.user"),
+		Password:         c.String("db.password"),
+		Host:             c.String("db.host"),
+		Port:             c.String("db.port"),
+		SslMode:          c.String("db.tls.sslmode"),
+		SslRootCert:      c.String("db.tls.ca-path"),
+		SslCert:          c.String("db.tls.cert-path"),
+		SslKey:           c.String("db.tls.key-path"),
+		ConnectTimeout:   time.Duration(c.Int("db.connect-timeout")) * time.Second,
+		StatementTimeout: time.Duration(c.Int("db.statement-timeout")) * time.Second,
+		MaxOpenConns:     c.Int("db.pool.max-open-conns"),
+		MaxIdleConns:     c.Int("db.pool.max-idle-conns"),
+		ConnMaxLifetime:  time.Duration(c.Int("db.pool.conn-max-lifetime")) * time.Second,
+		ConnMaxIdleTime:  time.Duration(c.Int("db.pool.conn-max-idle-time")) * time.Second,
+	}
+}
+
+func setupTracerProvider(c *cli.Context,
+	exporter tracesdk.SpanExporter,
+	sampler *tracing.Sampler) *tracesdk.TracerProvider {
+
 	tp := tracesdk.NewTracerProvider(
-		tracesdk.WithSampler(tracesdk.ParentBased(tracesdk.TraceIDRatioBased(fraction))),
+		tracesdk.WithSampler(sampler),
 		tracesdk.WithBatcher(exporter),
-		tracesdk.WithResource(resource.NewWithAttributes(
-			build.ExtendedVersion(),
-		)),
+		tracesdk.WithResource(newOtelResource(c)),
 	)
 	otel.SetTracerProvider(tp)
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 	return tp
 }
 
-func pprofStartup(c *cli.Context) {
+func newOtelResource(c *cli.Context) *resource.Resource {
+	return resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(c.String("otel.service-name")),
+		semconv.ServiceVersion(build.ExtendedVersion()),
+	)
+}
+
+// setupMeterProvider exports the OpenTelemetry metrics (the Go runtime and the gRPC requests to the nodes) to the same
+// OTLP collector as the traces.
+func setupMeterProvider(c *cli.Context) (*sdkmetric.MeterProvider, error) {
+	exporter, err := newOtlpMetricExporter(c)
+	if err != nil {
+		return nil, err
+	}
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+		sdkmetric.WithResource(newOtelResource(c)),
+	)
+	global.SetMeterProvider(mp)
+	err = otelruntime.Start(otelruntime.WithMeterProvider(mp))
+	if err != nil {
+		return nil, errors.Wrap(err, "Starting the runtime metrics")
+	}
+	return mp, nil
+}
+
+func getOtlpHeaders(c *cli.Context) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, header := range c.StringSlice("otel.exporter.header") {
+		key, value, found := strings.Cut(header, "=")
+		if !found {
+			return nil, errors.Newf("OpenTelemetry header %v is not formatted as key=value", header)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+func newOtlpMetricExporter(c *cli.Context) (sdkmetric.Exporter, error) {
+	headers, err := getOtlpHeaders(c)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if strings.ToLower(c.String("otel.exporter.type")) == "otlp-grpc" {
+		options := []otlpmetricgrpc.Option{otlpmetricgrpc.WithHeaders(headers)}
+		if c.String("otel.exporter.endpoint") != "" {
+			options = append(options, otlpmetricgrpc.WithEndpoint(c.String("otel.exporter.endpoint")))
+		}
+		if c.Bool("otel.exporter.insecure") {
+			options = append(options, otlpmetricgrpc.WithInsecure())
+		}
+		exporter, err := otlpmetricgrpc.New(ctx, options...)
+		return exporter, errors.Wrap(err, "Creating the OTLP gRPC metric exporter")
+	}
+	options := []otlpmetrichttp.Option{otlpmetrichttp.WithHeaders(headers)}
+	if c.String("otel.exporter.endpoint") != "" {
+		options = append(options, otlpmetrichttp.WithEndpoint(c.String("otel.exporter.endpoint")))
+	}
+	if c.Bool("otel.exporter.insecure") {
+		options = append(options, otlpmetrichttp.WithInsecure())
+	}
+	exporter, err := otlpmetrichttp.New(ctx, options...)
+	return exporter, errors.Wrap(err, "Creating the OTLP HTTP metric exporter")
+}
+
+func newOtlpExporter(c *cli.Context) (tracesdk.SpanExporter, error) {
+	headers, err := getOtlpHeaders(c)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if strings.ToLower(c.String("otel.exporter.type")) == "otlp-grpc" {
+		options := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(headers)}
+		if c.String("otel.exporter.endpoint") != "" {
+			options = append(options, otlptracegrpc.WithEndpoint(c.String("otel.exporter.endpoint")))
+		}
+		if c.Bool("otel.exporter.insecure") {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, options...)
+		return exporter, errors.Wrap(err, "Creating the OTLP gRPC exporter")
+	}
+	options := []otlptracehttp.Option{otlptracehttp.WithHeaders(headers)}
+	if c.String("otel.exporter.endpoint") != "" {
+		options = append(options, otlptracehttp.WithEndpoint(c.String("otel.exporter.endpoint")))
+	}
+	if c.Bool("otel.exporter.insecure") {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	return exporter, errors.Wrap(err, "Creating the OTLP HTTP exporter")
+}
+
+// pprofServer serves pprof on the configured address, the address can change when the configuration is reloaded.
+type pprofServer struct {
+	mu     sync.Mutex
+	server *http.Server
+}
+
+// serve stops pprof when address is empty.
+func (ps *pprofServer) serve(address string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.server != nil {
+		if err := ps.server.Close(); err != nil {
+			log.Error().Err(err).Msg("Torq could not stop pprof")
+		}
+		ps.server = nil
+	}
+	if address == "" {
+		return
+	}
 	runtime.SetBlockProfileRate(1)
 	runtime.SetMutexProfileFraction(1)
 	runtime.SetCPUProfileRate(1)
-	err := http.ListenAndServe(c.String("torq.pprof.path"), nil) //nolint:gosec
-	if err != nil {
-		log.Error().Err(err).Msg("Torq could not start pprof")
+	server := &http.Server{
+		Addr:              address,
+		Handler:           http.DefaultServeMux,
+		ReadHeaderTimeout: 10 * time.Second,
 	}
+	ps.server = server
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("Torq could not start pprof")
+		}
+	}()
+}
+
+// registerReloadHandlers registers the settings that are applied without a restart when the configuration is
+// reloaded, all other settings require a restart.
+func registerReloadHandlers(reloader *config.Reloader, pprof *pprofServer, sampler *tracing.Sampler) {
+	reloader.OnChange(func(c *cli.Context) error {
+		debuglevel, ok := debuglevels[strings.ToLower(c.String("torq.debuglevel"))]
+		if !ok {
+			return errors.Newf("unknown debug level %v", c.String("torq.debuglevel"))
+		}
+		zerolog.SetGlobalLevel(debuglevel)
+		return nil
+	}, "torq.debuglevel")
+	reloader.OnChange(func(c *cli.Context) error {
+		cache.SetVectorUrlBase(c.String("torq.vector.url"))
+		return nil
+	}, "torq.vector.url")
+	reloader.OnChange(func(c *cli.Context) error {
+		cache.SetRetentionDays(cache.RetentionDays{
+			HtlcEvents:     c.Int("torq.retention.htlc-events"),
+			ChannelEvents:  c.Int("torq.retention.channel-events"),
+			GraphEvents:    c.Int("torq.retention.graph-events"),
+			FailedPayments: c.Int("torq.retention.failed-payments"),
+		})
+		return nil
+	}, "torq.retention.htlc-events", "torq.retention.channel-events", "torq.retention.graph-events",
+		"torq.retention.failed-payments")
+	reloader.OnChange(func(c *cli.Context) error {
+		if c.Int("torq.peer-reliability.window-days") < 1 {
+			return errors.New("peer reliability window must be at least 1 day")
+		}
+		cache.SetPeerReliabilitySettings(cache.PeerReliabilitySettings{
+			WindowDays:      c.Int("torq.peer-reliability.window-days"),
+			UptimeThreshold: c.Float64("torq.peer-reliability.uptime-threshold"),
+		})
+		return nil
+	}, "torq.peer-reliability.window-days", "torq.peer-reliability.uptime-threshold")
+	reloader.OnChange(func(c *cli.Context) error {
+		for _, network := range c.StringSlice("torq.reconnect.network-order") {
+			if network != "clearnet" && network != "tor" {
+				return errors.Newf("unknown reconnect network %v, use clearnet or tor", network)
+			}
+		}
+		cache.SetPeerReconnectSettings(cache.PeerReconnectSettings{
+			MaxFailures:  c.Int("torq.reconnect.max-failures"),
+			NetworkOrder: c.StringSlice("torq.reconnect.network-order"),
+		})
+		return nil
+	}, "torq.reconnect.max-failures", "torq.reconnect.network-order")
+	reloader.OnChange(func(c *cli.Context) error {
+		pprof.serve(c.String("torq.pprof.path"))
+		return nil
+	}, "torq.pprof.path")
+	reloader.OnChange(func(c *cli.Context) error {
+		fraction := c.Float64("otel.sampler.fraction")
+		if fraction < 0 || fraction > 1 {
+			return errors.Newf("sampler fraction %v is not between 0 and 1", fraction)
+		}
+		sampler.SetFraction(fraction)
+		return nil
+	}, "otel.sampler.fraction")
+	reloader.OnChange(func(c *cli.Context) error {
+		services2.SetReadinessSettings(services2.ReadinessSettings{
+			MaxNodesDown: c.Int("torq.readiness.max-nodes-down"),
+		})
+		return nil
+	}, "torq.readiness.max-nodes-down")
 }
 
 func loadFlags() func(context *cli.Context) (altsrc.InputSourceContext, error) {
@@ -405,7 +860,7 @@ func loadFlags() func(context *cli.Context) (altsrc.InputSourceContext, error) {
 	}
 }
 
-func migrateAndProcessArguments(db *sqlx.DB, c *cli.Context) {
+func migrateAndProcessArguments(ctx context.Context, db *sqlx.DB, c *cli.Context) {
 	fmt.Println("Checking for migrations..")
 	// Check if the database needs to be migrated.
 	err := database.MigrateUp(db)
@@ -546,6 +1001,8 @@ func migrateAndProcessArguments(db *sqlx.DB, c *cli.Context) {
 		break
 	}
 
+	go workflows.ResumeInterruptedOperations(ctx, db)
+
 	cache.SetPendingCoreServiceState(services_helpers.RootService)
 }
 
@@ -693,7 +1150,8 @@ func processTorqInitialBoot(db *sqlx.DB) {
 					services_helpers.LndServiceHtlcEventStream,
 					services_helpers.LndServiceForwardsService,
 					services_helpers.LndServiceInvoiceStream,
-					services_helpers.LndServicePaymentsService:
+					services_helpers.LndServicePaymentsService,
+					services_helpers.LndServiceGraphSnapshotService:
 					active := false
 					for _, cs := range lndServiceType.GetNodeConnectionDetailCustomSettings() {
 						if customSettings&cs != 0 {
@@ -719,7 +1177,8 @@ func processTorqInitialBoot(db *sqlx.DB) {
 					services_helpers.ClnServiceHtlcsService,
 					services_helpers.ClnServiceForwardsService,
 					services_helpers.ClnServiceInvoicesService,
-					services_helpers.ClnServicePaymentsService:
+					services_helpers.ClnServicePaymentsService,
+					services_helpers.ClnServiceGraphSnapshotService:
 					active := false
 					for _, cs := range clnServiceType.GetNodeConnectionDetailCustomSettings() {
 						if customSettings&cs != 0 {
@@ -902,6 +1361,18 @@ func bootService(db *sqlx.DB, serviceType services_helpers.ServiceType, nodeId i
 		go services.StartMaintenanceService(ctx, db)
 	case services_helpers.CronService:
 		go services.StartCronService(ctx, db)
+	case services_helpers.ForecastService:
+		go services.StartForecastService(ctx, db)
+	case services_helpers.PeerReliabilityService:
+		go services.StartPeerReliabilityService(ctx, db)
+	case services_helpers.PeerReconnectService:
+		go services.StartPeerReconnectService(ctx, db)
+	case services_helpers.FeeEstimateService:
+		go services.StartFeeEstimateService(ctx, db)
+	case services_helpers.ForceCloseMonitorService:
+		go services.StartForceCloseMonitorService(ctx, db)
+	case services_helpers.DatabaseService:
+		go services.StartDatabaseService(ctx, db)
 	case services_helpers.NotifierService:
 		go notifications.StartNotifier(ctx, db)
 	case services_helpers.SlackService:
@@ -937,6 +1408,8 @@ func bootService(db *sqlx.DB, serviceType services_helpers.ServiceType, nodeId i
 		go subscribe.StartLndInFlightPaymentsService(ctx, conn, db, nodeId)
 	case services_helpers.LndServiceChannelBalanceCacheService:
 		go subscribe.StartLndChannelBalanceCacheMaintenance(ctx, conn, db, nodeId)
+	case services_helpers.LndServiceGraphSnapshotService:
+		go subscribe.StartLndGraphSnapshotService(ctx, conn, db, nodeId)
 	// CLN NODE SPECIFIC
 	case services_helpers.ClnServiceVectorService:
 		go vector_ping.Start(ctx, conn, core.CLN, nodeId)
@@ -954,12 +1427,16 @@ func bootService(db *sqlx.DB, serviceType services_helpers.ServiceType, nodeId i
 		go subscribe.StartClnNodesService(ctx, conn, db, nodeId)
 	case services_helpers.ClnServiceTransactionsService:
 		go subscribe.StartClnTransactionsService(ctx, conn, db, nodeId)
+	case services_helpers.ClnServiceHtlcsService:
+		go subscribe.StartClnHtlcsService(ctx, conn, db, nodeId)
 	case services_helpers.ClnServiceForwardsService:
 		go subscribe.StartClnForwardsService(ctx, conn, db, nodeId)
 	case services_helpers.ClnServiceInvoicesService:
 		go subscribe.StartClnInvoicesService(ctx, conn, db, nodeId)
 	case services_helpers.ClnServicePaymentsService:
 		go subscribe.StartClnPaymentsService(ctx, conn, db, nodeId)
+	case services_helpers.ClnServiceGraphSnapshotService:
+		go subscribe.StartClnGraphSnapshotService(ctx, conn, db, nodeId)
 	}
 }
 
@@ -975,7 +1452,8 @@ func isBootable(serviceType services_helpers.ServiceType, nodeId int) bool {
 		services_helpers.LndServicePaymentsService,
 		services_helpers.LndServicePeerEventStream,
 		services_helpers.LndServiceInFlightPaymentsService,
-		services_helpers.LndServiceChannelBalanceCacheService:
+		services_helpers.LndServiceChannelBalanceCacheService,
+		services_helpers.LndServiceGraphSnapshotService:
 		nodeConnectionDetails := cache.GetNodeConnectionDetails(nodeId)
 		if nodeConnectionDetails.Implementation == core.LND &&
 			(nodeConnectionDetails.GRPCAddress == "" ||
@@ -994,9 +1472,11 @@ func isBootable(serviceType services_helpers.ServiceType, nodeId int) bool {
 		services_helpers.ClnServiceFundsService,
 		services_helpers.ClnServiceNodesService,
 		services_helpers.ClnServiceTransactionsService,
+		services_helpers.ClnServiceHtlcsService,
 		services_helpers.ClnServiceForwardsService,
 		services_helpers.ClnServiceInvoicesService,
-		services_helpers.ClnServicePaymentsService:
+		services_helpers.ClnServicePaymentsService,
+		services_helpers.ClnServiceGraphSnapshotService:
 		nodeConnectionDetails := cache.GetNodeConnectionDetails(nodeId)
 		if nodeConnectionDetails.Implementation == core.CLN &&
 			(nodeConnectionDetails.GRPCAddress == "" ||
@@ -0,0 +1,152 @@
+package api_helpers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/iancoleman/strcase"
+	"github.com/jmoiron/sqlx"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCsv  = ExportFormat("csv")
+	ExportFormatJson = ExportFormat("json")
+)
+
+// exportFlushInterval is the amount of rows written before the response is flushed to the client
+const exportFlushInterval = 1000
+
+func GetExportFormat(format string) (ExportFormat, error) {
+	switch ExportFormat(strings.ToLower(format)) {
+	case ExportFormatCsv, "":
+		return ExportFormatCsv, nil
+	case ExportFormatJson, "ndjson":
+		return ExportFormatJson, nil
+	}
+	return "", errors.Newf("%v is not a valid export format. Should be either csv or json", format)
+}
+
+// Exporter streams SQL result sets to the client as CSV or newline-delimited JSON.
+// Rows are written while they are read from the database, so the full history never has to fit in memory.
+type Exporter struct {
+	c             *gin.Context
+	format        ExportFormat
+	csvWriter     *csv.Writer
+	jsonEncoder   *json.Encoder
+	headerWritten bool
+	rowCount      int
+}
+
+func NewExporter(c *gin.Context, format ExportFormat, filename string) *Exporter {
+	e := &Exporter{c: c, format: format}
+	switch format {
+	case ExportFormatJson:
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%v.ndjson", filename))
+		e.jsonEncoder = json.NewEncoder(c.Writer)
+	default:
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%v.csv", filename))
+		e.csvWriter = csv.NewWriter(c.Writer)
+	}
+	c.Status(http.StatusOK)
+	return e
+}
+
+// WriteRows can be called multiple times for result sets with the same columns,
+// the CSV header is only written for the first one.
+func (e *Exporter) WriteRows(rows *sqlx.Rows) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return errors.Wrap(err, "Obtaining columns")
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return errors.Wrap(err, "Obtaining column types")
+	}
+	numeric := make([]bool, len(columnTypes))
+	for i, columnType := range columnTypes {
+		numeric[i] = columnType.DatabaseTypeName() == "NUMERIC"
+	}
+
+	if e.csvWriter != nil && !e.headerWritten {
+		err = e.csvWriter.Write(columns)
+		if err != nil {
+			return errors.Wrap(err, "Writing CSV header")
+		}
+	}
+	e.headerWritten = true
+
+	values := make([]interface{}, len(columns))
+	valuePointers := make([]interface{}, len(columns))
+	for i := range values {
+		valuePointers[i] = &values[i]
+	}
+	for rows.Next() {
+		err = rows.Scan(valuePointers...)
+		if err != nil {
+			return errors.Wrap(err, "SQL row scan")
+		}
+		if e.csvWriter != nil {
+			record := make([]string, len(values))
+			for i, value := range values {
+				record[i] = formatCsvValue(value)
+			}
+			err = e.csvWriter.Write(record)
+		} else {
+			object := make(map[string]interface{}, len(values))
+			for i, value := range values {
+				object[strcase.ToLowerCamel(columns[i])] = formatJsonValue(value, numeric[i])
+			}
+			err = e.jsonEncoder.Encode(object)
+		}
+		if err != nil {
+			return errors.Wrap(err, "Writing export row")
+		}
+		e.rowCount++
+		if e.rowCount%exportFlushInterval == 0 {
+			e.Flush()
+		}
+	}
+	return errors.Wrap(rows.Err(), "Iterating SQL rows")
+}
+
+func (e *Exporter) Flush() {
+	if e.csvWriter != nil {
+		e.csvWriter.Flush()
+	}
+	e.c.Writer.Flush()
+}
+
+func formatCsvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func formatJsonValue(value interface{}, numeric bool) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		if numeric {
+			return json.Number(v)
+		}
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return value
+}
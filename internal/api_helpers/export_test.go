@@ -0,0 +1,140 @@
+package api_helpers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/lncapital/torq/testutil"
+)
+
+//language=PostgreSQL
+const exportTestQuery = `
+	SELECT *
+	FROM (VALUES
+		('2023-01-02 03:04:05'::timestamp, 1000::numeric, 'plain', NULL::text),
+		('2023-01-03 03:04:05'::timestamp, 2.5::numeric, 'comma, "quote"' || chr(10) || 'newline', 'x')
+	) AS t(event_time, amount_msat, alias, note)
+	WHERE amount_msat > $1;`
+
+func exportRows(t *testing.T, db *sqlx.DB, format ExportFormat, minimumAmount int) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+
+	rows, err := db.Queryx(exportTestQuery, minimumAmount)
+	if err != nil {
+		t.Fatalf("running export query: %v", err)
+	}
+	defer rows.Close()
+
+	exporter := NewExporter(c, format, "test")
+	err = exporter.WriteRows(rows)
+	if err != nil {
+		t.Fatalf("writing rows: %v", err)
+	}
+	exporter.Flush()
+	return recorder
+}
+
+func TestExporter(t *testing.T) {
+	srv, err := testutil.InitTestDBConn()
+	if err != nil {
+		panic(err)
+	}
+
+	db, cancel, err := srv.NewTestDatabase()
+	defer cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	testutil.Given(t, "a CSV export")
+	recorder := exportRows(t, db, ExportFormatCsv, 0)
+	if recorder.Header().Get("Content-Type") != "text/csv" {
+		testutil.Errorf(t, "unexpected content type %v", recorder.Header().Get("Content-Type"))
+	}
+	records, err := csv.NewReader(strings.NewReader(recorder.Body.String())).ReadAll()
+	if err != nil {
+		testutil.Fatalf(t, "reading CSV: %v", err)
+	}
+	if len(records) != 3 {
+		testutil.Fatalf(t, "expected a header and 2 rows, got %v", records)
+	}
+	if strings.Join(records[0], ",") != "event_time,amount_msat,alias,note" {
+		testutil.Errorf(t, "unexpected header %v", records[0])
+	}
+	if records[1][0] != "2023-01-02T03:04:05Z" || records[1][1] != "1000" || records[1][3] != "" {
+		testutil.Errorf(t, "unexpected first row %v", records[1])
+	}
+	if records[2][2] != "comma, \"quote\"\nnewline" {
+		testutil.Errorf(t, "expected the alias to survive CSV escaping, got %q", records[2][2])
+	}
+	testutil.Successf(t, "the header and rows are encoded and escaped")
+
+	testutil.Given(t, "a JSON export")
+	recorder = exportRows(t, db, ExportFormatJson, 0)
+	lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	if len(lines) != 2 {
+		testutil.Fatalf(t, "expected 2 JSON lines, got %v", lines)
+	}
+	var row map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(lines[1]))
+	decoder.UseNumber()
+	err = decoder.Decode(&row)
+	if err != nil {
+		testutil.Fatalf(t, "decoding JSON line: %v", err)
+	}
+	if row["amountMsat"] != json.Number("2.5") {
+		testutil.Errorf(t, "expected numeric columns as JSON numbers, got %#v", row["amountMsat"])
+	}
+	if row["alias"] != "comma, \"quote\"\nnewline" || row["eventTime"] != "2023-01-03T03:04:05Z" {
+		testutil.Errorf(t, "unexpected JSON row %v", row)
+	}
+	testutil.Successf(t, "rows are encoded as newline-delimited JSON with camel case keys")
+
+	testutil.Given(t, "an empty result set")
+	recorder = exportRows(t, db, ExportFormatCsv, 1_000_000)
+	if recorder.Body.String() != "event_time,amount_msat,alias,note\n" {
+		testutil.Errorf(t, "expected only the CSV header, got %q", recorder.Body.String())
+	}
+	recorder = exportRows(t, db, ExportFormatJson, 1_000_000)
+	if recorder.Body.Len() != 0 {
+		testutil.Errorf(t, "expected an empty JSON export, got %q", recorder.Body.String())
+	}
+	testutil.Successf(t, "an empty result set only writes the CSV header")
+}
+
+func TestGetExportFormat(t *testing.T) {
+	tests := map[string]ExportFormat{"": ExportFormatCsv, "CSV": ExportFormatCsv, "json": ExportFormatJson,
+		"ndjson": ExportFormatJson}
+	for format, expected := range tests {
+		exportFormat, err := GetExportFormat(format)
+		if err != nil || exportFormat != expected {
+			t.Errorf("GetExportFormat(%q) = %v, %v, want %v", format, exportFormat, err, expected)
+		}
+	}
+	if _, err := GetExportFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown export format")
+	}
+}
+
+func TestFormatValues(t *testing.T) {
+	date := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	if formatCsvValue(nil) != "" || formatCsvValue([]byte("12.5")) != "12.5" ||
+		formatCsvValue(date) != "2023-01-02T03:04:05Z" || formatCsvValue(int64(7)) != "7" {
+		t.Error("unexpected CSV value formatting")
+	}
+	if formatJsonValue([]byte("12.5"), true) != json.Number("12.5") ||
+		formatJsonValue([]byte("text"), false) != "text" ||
+		formatJsonValue(date, false) != "2023-01-02T03:04:05Z" || formatJsonValue(nil, false) != nil {
+		t.Error("unexpected JSON value formatting")
+	}
+}
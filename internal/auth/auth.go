@@ -20,7 +20,8 @@ import (
 
 const Userkey = "user"
 
-func CreateSession(r *gin.Engine, apiPwd string) error {
+// CreateSession sets up the cookie store, secure restricts the session cookie to HTTPS.
+func CreateSession(r *gin.Engine, apiPwd string, secure bool) error {
 	cookiePwd := []byte(apiPwd)
 	if len(cookiePwd) == 0 {
 		cookiePwd = make([]byte, 64)
@@ -31,7 +32,7 @@ func CreateSession(r *gin.Engine, apiPwd string) error {
 		log.Debug().Msg("No password set so generated random key for cookie store")
 	}
 	store := sessions.NewCookieStore(cookiePwd)
-	store.Options(sessions.Options{MaxAge: 86400, Path: "/"})
+	store.Options(sessions.Options{MaxAge: 86400, Path: "/", Secure: secure})
 	r.Use(sessions.Sessions("torq_session", store))
 	return nil
 }
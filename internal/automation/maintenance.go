@@ -32,6 +32,7 @@ func MaintenanceServiceStart(ctx context.Context, db *sqlx.DB) {
 			processMissingChannelData(db)
 			processMissingTransactionData(db)
 			deleteWorkflowLogs(db)
+			processDataRetention(ctx, db)
 		}
 	}
 }
@@ -0,0 +1,257 @@
+package automation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/database"
+)
+
+// Raw rows are processed per window so a large backlog doesn't end up in a single statement.
+const retentionWindowDays = 7
+
+// Every continuous aggregate uses buckets of at most a day so a day aligned cutoff never splits a bucket.
+const retentionCutoffAlignment = 24 * time.Hour
+
+type retentionPolicy struct {
+	table      string
+	timeColumn string
+	// aggregate is the continuous aggregate that keeps the rolled up data of the table
+	aggregate string
+	// dropChunks drops complete chunks, this is only possible when all raw rows are allowed to go.
+	dropChunks bool
+	// deleteSql deletes the raw rows between $1 (inclusive) and $2 (exclusive) that are no longer needed.
+	deleteSql string
+	days      func(retentionDays cache.RetentionDays) int
+}
+
+type retentionWindow struct {
+	from time.Time
+	to   time.Time
+}
+
+func getRetentionPolicies() []retentionPolicy {
+	return []retentionPolicy{
+		{
+			table:      "htlc_event",
+			timeColumn: "time",
+			aggregate:  "htlc_event_hourly",
+			dropChunks: true,
+			days:       func(retentionDays cache.RetentionDays) int { return retentionDays.HtlcEvents },
+		},
+		{
+			table:      "channel_event",
+			timeColumn: "time",
+			aggregate:  "channel_event_daily",
+			// Open and close events (0 and 1) are used for the capacity and initial balance of a channel
+			deleteSql: `DELETE FROM channel_event WHERE time >= $1 AND time < $2 AND event_type IN (2, 3);`,
+			days:      func(retentionDays cache.RetentionDays) int { return retentionDays.ChannelEvents },
+		},
+		{
+			table:      "routing_policy",
+			timeColumn: "ts",
+			aggregate:  "routing_policy_daily",
+			// The latest policy of a channel is always kept
+			deleteSql: `
+				DELETE FROM routing_policy rp
+				WHERE rp.ts >= $1 AND rp.ts < $2 AND EXISTS (
+					SELECT 1
+					FROM routing_policy newer
+					WHERE newer.channel_id=rp.channel_id AND
+					      newer.announcing_node_id=rp.announcing_node_id AND
+					      newer.node_id=rp.node_id AND
+					      newer.ts>rp.ts
+				);`,
+			days: func(retentionDays cache.RetentionDays) int { return retentionDays.GraphEvents },
+		},
+		{
+			table:      "node_event",
+			timeColumn: "timestamp",
+			aggregate:  "node_event_daily",
+			// The latest alias, colour and addresses of a node are always kept
+			deleteSql: `
+				DELETE FROM node_event ne
+				WHERE ne.timestamp >= $1 AND ne.timestamp < $2 AND EXISTS (
+					SELECT 1
+					FROM node_event newer
+					WHERE newer.event_node_id=ne.event_node_id AND
+					      newer.node_id=ne.node_id AND
+					      newer.timestamp>ne.timestamp
+				);`,
+			days: func(retentionDays cache.RetentionDays) int { return retentionDays.GraphEvents },
+		},
+		{
+			table:      "payment",
+			timeColumn: "creation_timestamp",
+			aggregate:  "payment_daily",
+			// Successful payments are part of the bookkeeping
+			deleteSql: `DELETE FROM payment WHERE creation_timestamp >= $1 AND creation_timestamp < $2 AND status='FAILED';`,
+			days:      func(retentionDays cache.RetentionDays) int { return retentionDays.FailedPayments },
+		},
+	}
+}
+
+func processDataRetention(ctx context.Context, db *sqlx.DB) {
+	retentionDays := cache.GetSettings().RetentionDays
+	var policies []retentionPolicy
+	for _, policy := range getRetentionPolicies() {
+		if policy.days(retentionDays) > 0 {
+			policies = append(policies, policy)
+		}
+	}
+	for i, policy := range policies {
+		if ctx.Err() != nil {
+			return
+		}
+		days := policy.days(retentionDays)
+		log.Info().Msgf("Data retention (%v/%v): keeping %v days of raw %v rows", i+1, len(policies), days, policy.table)
+		start := time.Now()
+		deletedRows, err := applyRetentionPolicy(ctx, db, policy, retentionCutoff(time.Now(), days))
+		if err != nil {
+			log.Error().Err(err).Msgf("Data retention (%v/%v) failed for %v", i+1, len(policies), policy.table)
+			continue
+		}
+		log.Info().Msgf("Data retention (%v/%v): %v done in %s, %v raw rows removed",
+			i+1, len(policies), policy.table, time.Since(start).Round(time.Second), deletedRows)
+	}
+}
+
+func applyRetentionPolicy(ctx context.Context, db *sqlx.DB, policy retentionPolicy, cutoff time.Time) (int64, error) {
+	oldest, err := getOldestRawRow(db, policy)
+	if err != nil {
+		return 0, err
+	}
+	if oldest == nil {
+		return 0, nil
+	}
+	deletedUntil, err := getDeletedUntil(db, policy.table)
+	if err != nil {
+		return 0, err
+	}
+
+	// Buckets before deletedUntil no longer have all their raw rows, refreshing them would lose data.
+	refreshFrom := oldest.Truncate(retentionCutoffAlignment)
+	if deletedUntil != nil {
+		refreshFrom = *deletedUntil
+	}
+	refreshWindows := getRetentionWindows(refreshFrom, time.Now(), retentionWindowDays*24*time.Hour)
+	for i, window := range refreshWindows {
+		if ctx.Err() != nil {
+			return 0, errors.Wrap(ctx.Err(), "Refreshing continuous aggregate")
+		}
+		// refresh_continuous_aggregate can't run inside a transaction and doesn't accept a parameter for the name.
+		_, err = db.ExecContext(ctx,
+			fmt.Sprintf(`CALL refresh_continuous_aggregate('%v', $1::timestamptz, $2::timestamptz);`, policy.aggregate),
+			window.from, window.to)
+		if err != nil {
+			return 0, errors.Wrapf(err, "Refreshing %v from %v to %v", policy.aggregate, window.from, window.to)
+		}
+		log.Debug().Msgf("Data retention: aggregated %v into %v (%v/%v) until %v",
+			policy.table, policy.aggregate, i+1, len(refreshWindows), window.to.Format(time.RFC3339))
+	}
+
+	var deletedRows int64
+	if policy.dropChunks {
+		var droppedChunks []string
+		err = db.Select(&droppedChunks, `SELECT drop_chunks($1, older_than => $2::timestamptz);`, policy.table, cutoff)
+		if err != nil {
+			return 0, errors.Wrapf(err, "Dropping chunks of %v", policy.table)
+		}
+		if len(droppedChunks) != 0 {
+			log.Info().Msgf("Data retention: dropped %v chunks of %v", len(droppedChunks), policy.table)
+		}
+	} else {
+		deleteWindows := getRetentionWindows(oldest.Truncate(retentionCutoffAlignment), cutoff,
+			retentionWindowDays*24*time.Hour)
+		for i, window := range deleteWindows {
+			if ctx.Err() != nil {
+				break
+			}
+			res, err := db.ExecContext(ctx, policy.deleteSql, window.from, window.to)
+			if err != nil {
+				return deletedRows, errors.Wrapf(err, "Deleting %v from %v to %v", policy.table, window.from, window.to)
+			}
+			rowsAffected, err := res.RowsAffected()
+			if err == nil {
+				deletedRows += rowsAffected
+			}
+			log.Debug().Msgf("Data retention: deleted %v raw %v rows (%v/%v) until %v",
+				rowsAffected, policy.table, i+1, len(deleteWindows), window.to.Format(time.RFC3339))
+		}
+	}
+
+	err = setDeletedUntil(db, policy.table, cutoff, deletedRows)
+	if err != nil {
+		return deletedRows, err
+	}
+	return deletedRows, nil
+}
+
+// retentionCutoff aligns the cutoff to the start of a day (UTC) so the bucket of the cutoff keeps all its raw rows.
+func retentionCutoff(now time.Time, days int) time.Time {
+	return now.UTC().AddDate(0, 0, -days).Truncate(retentionCutoffAlignment)
+}
+
+// getRetentionWindows splits from-to into windows of the given size. A remaining window shorter than a day is
+// merged into the previous one (or skipped) because refresh_continuous_aggregate refuses windows smaller than a bucket.
+func getRetentionWindows(from time.Time, to time.Time, size time.Duration) []retentionWindow {
+	var windows []retentionWindow
+	for windowFrom := from; windowFrom.Before(to); windowFrom = windowFrom.Add(size) {
+		windowTo := windowFrom.Add(size)
+		if windowTo.After(to) {
+			windowTo = to
+		}
+		if windowTo.Sub(windowFrom) < retentionCutoffAlignment {
+			if len(windows) != 0 {
+				windows[len(windows)-1].to = windowTo
+			}
+			break
+		}
+		windows = append(windows, retentionWindow{from: windowFrom, to: windowTo})
+	}
+	return windows
+}
+
+func getOldestRawRow(db *sqlx.DB, policy retentionPolicy) (*time.Time, error) {
+	var oldest *time.Time
+	err := db.Get(&oldest, fmt.Sprintf(`SELECT min(%v) FROM %v;`, policy.timeColumn, policy.table))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Obtaining the oldest row of %v", policy.table)
+	}
+	return oldest, nil
+}
+
+func getDeletedUntil(db *sqlx.DB, table string) (*time.Time, error) {
+	var deletedUntil time.Time
+	err := db.Get(&deletedUntil, `SELECT deleted_until FROM data_retention WHERE table_name=$1;`, table)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return &deletedUntil, nil
+}
+
+func setDeletedUntil(db *sqlx.DB, table string, deletedUntil time.Time, deletedRows int64) error {
+	now := time.Now().UTC()
+	_, err := db.Exec(`
+		INSERT INTO data_retention (table_name, deleted_until, deleted_rows, created_on, updated_on)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (table_name) DO UPDATE
+		SET deleted_until=GREATEST(data_retention.deleted_until, EXCLUDED.deleted_until),
+		    deleted_rows=data_retention.deleted_rows+EXCLUDED.deleted_rows,
+		    updated_on=EXCLUDED.updated_on;`,
+		table, deletedUntil, deletedRows, now)
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	return nil
+}
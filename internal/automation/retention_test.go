@@ -0,0 +1,43 @@
+package automation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionCutoff(t *testing.T) {
+	now := time.Date(2023, 3, 15, 17, 45, 0, 0, time.FixedZone("CET", 3600))
+	cutoff := retentionCutoff(now, 30)
+	expected := time.Date(2023, 2, 13, 0, 0, 0, 0, time.UTC)
+	if !cutoff.Equal(expected) {
+		t.Fatalf("expected %v, got %v", expected, cutoff)
+	}
+}
+
+func TestGetRetentionWindows(t *testing.T) {
+	day := 24 * time.Hour
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	windows := getRetentionWindows(from, from.Add(15*day+6*time.Hour), 7*day)
+	if len(windows) != 3 {
+		t.Fatalf("expected 3 windows, got %v", len(windows))
+	}
+	if !windows[0].from.Equal(from) || !windows[0].to.Equal(from.Add(7*day)) {
+		t.Errorf("unexpected first window %v - %v", windows[0].from, windows[0].to)
+	}
+
+	windows = getRetentionWindows(from, from.Add(14*day+6*time.Hour), 7*day)
+	if len(windows) != 2 {
+		t.Fatalf("expected the short remainder to be merged, got %v windows", len(windows))
+	}
+	if !windows[1].to.Equal(from.Add(14*day + 6*time.Hour)) {
+		t.Errorf("expected the last window to end at the end, got %v", windows[1].to)
+	}
+
+	if windows = getRetentionWindows(from, from.Add(6*time.Hour), 7*day); len(windows) != 0 {
+		t.Errorf("expected no window shorter than a day, got %v", len(windows))
+	}
+	if windows = getRetentionWindows(from, from.Add(-day), 7*day); len(windows) != 0 {
+		t.Errorf("expected no windows when from is after to, got %v", len(windows))
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/lncapital/torq/internal/services_helpers"
 	"github.com/lncapital/torq/internal/workflow_helpers"
@@ -17,11 +20,14 @@ import (
 	"github.com/lncapital/torq/internal/cache"
 	"github.com/lncapital/torq/internal/core"
 	"github.com/lncapital/torq/internal/lnd"
+	"github.com/lncapital/torq/internal/tracing"
 	"github.com/lncapital/torq/internal/workflows"
 )
 
 const workflowTickerSeconds = 10
 
+const name = "automation"
+
 func IntervalTriggerMonitor(ctx context.Context, db *sqlx.DB) {
 
 	ticker := time.NewTicker(workflowTickerSeconds * time.Second)
@@ -182,6 +188,12 @@ func ScheduledTriggerMonitor(ctx context.Context, db *sqlx.DB) {
 		default:
 		}
 
+		// Leave the queued triggers alone, the interval and cron triggers schedule them again after the restart
+		if cache.IsShuttingDown() {
+			delay = true
+			continue
+		}
+
 		scheduledTrigger := cache.GetScheduledTrigger()
 		if scheduledTrigger.SchedulingTime == nil {
 			log.Trace().Msg("ScheduledTriggerMonitor couldn't find any pending triggers")
@@ -322,10 +334,25 @@ func processWorkflowNode(ctx context.Context, db *sqlx.DB,
 	reference string,
 	events []any) {
 
+	ctx, span := otel.Tracer(name).Start(ctx, "ProcessWorkflow", trace.WithAttributes(
+		attribute.Int("workflowVersionId", workflowTriggerNode.WorkflowVersionId),
+		attribute.Int("workflowVersionNodeId", workflowTriggerNode.WorkflowVersionNodeId),
+		attribute.Int("workflowNodeType", int(workflowTriggerNode.Type)),
+		attribute.String("reference", reference)))
 	err := workflows.ProcessWorkflow(ctx, db, workflowTriggerNode, reference, events)
+	tracing.End(span, err)
 	if err != nil {
-		log.Error().Err(err).Msgf(
+		tracing.Log(ctx).Error().Err(err).Msgf(
 			"ScheduledTriggerMonitor failed to trigger nodes for WorkflowVersionNodeId: %v",
 			workflowTriggerNode.WorkflowVersionNodeId)
 	}
+	if services_helpers.GetMetrics() != nil {
+		workflowVersion, versionErr := workflows.GetWorkflowVersionById(db, workflowTriggerNode.WorkflowVersionId)
+		if versionErr != nil {
+			log.Error().Err(versionErr).Msgf("Failed to obtain the workflow of WorkflowVersionId: %v",
+				workflowTriggerNode.WorkflowVersionId)
+			return
+		}
+		services_helpers.GetMetrics().AddWorkflowRun(workflowVersion.WorkflowId, err != nil)
+	}
 }
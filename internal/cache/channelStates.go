@@ -658,14 +658,14 @@ func handleChannelStateSettingsOperation(channelStateCache ChannelStateCache,
 						}
 					}
 					if foundIt {
-						channelSetting.UnsettledBalance = channelSetting.UnsettledBalance + int64(*channelStateCache.HtlcEvent.IncomingAmtMsat/1000)
+						channelSetting.UnsettledBalance = channelSetting.UnsettledBalance + int64(*channelStateCache.HtlcEvent.OutgoingAmtMsat/1000)
 					} else {
 						pendingHtlc = append(pendingHtlc, Htlc{
 							Incoming:  false,
 							Amount:    int64((*channelStateCache.HtlcEvent.OutgoingAmtMsat) / 1000),
 							HtlcIndex: *channelStateCache.HtlcEvent.OutgoingHtlcId,
 						})
-						channelSetting.UnsettledBalance = channelSetting.UnsettledBalance - int64(*channelStateCache.HtlcEvent.IncomingAmtMsat/1000)
+						channelSetting.UnsettledBalance = channelSetting.UnsettledBalance - int64(*channelStateCache.HtlcEvent.OutgoingAmtMsat/1000)
 					}
 					channelSetting.PendingHtlcs = pendingHtlc
 					nodeChannels[channelIdType(*channelStateCache.HtlcEvent.OutgoingChannelId)] = channelSetting
@@ -889,6 +889,7 @@ func SetChannelStateBalanceUpdate(nodeId int, channelId int, increaseBalance boo
 
 func SetChannelStateBalanceHtlcEvent(htlcEvent core.HtlcEvent, eventOrigin core.BalanceUpdateEventOrigin) {
 	ChannelStatesCacheChannel <- ChannelStateCache{
+		NodeId:                   htlcEvent.NodeId,
 		BalanceUpdateEventOrigin: eventOrigin,
 		HtlcEvent:                htlcEvent,
 		Type:                     writeChannelStateUpdateHtlcEvent,
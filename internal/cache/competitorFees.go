@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+var CompetitorFeesCacheChannel = make(chan CompetitorFeesCache) //nolint:gochecknoglobals
+
+type CompetitorFeesCacheOperationType uint
+
+const (
+	readCompetitorFees CompetitorFeesCacheOperationType = iota
+	writeCompetitorFees
+)
+
+type Competitor struct {
+	PublicKey        string `json:"publicKey"`
+	Alias            string `json:"alias"`
+	FeeRateMilliMsat int64  `json:"feeRateMilliMsat"`
+	FeeBaseMsat      int64  `json:"feeBaseMsat"`
+	Capacity         int64  `json:"capacity"`
+}
+
+// CompetitorFees is the distribution of the fees other nodes charge to route into a peer.
+// Every competitor counts once with its cheapest channel to the peer.
+type CompetitorFees struct {
+	Count         int   `json:"count"`
+	FeeRateMin    int64 `json:"feeRateMin"`
+	FeeRateP10    int64 `json:"feeRateP10"`
+	FeeRateP20    int64 `json:"feeRateP20"`
+	FeeRateP30    int64 `json:"feeRateP30"`
+	FeeRateP40    int64 `json:"feeRateP40"`
+	FeeRateMedian int64 `json:"feeRateMedian"`
+	FeeRateP60    int64 `json:"feeRateP60"`
+	FeeRateP70    int64 `json:"feeRateP70"`
+	FeeRateP80    int64 `json:"feeRateP80"`
+	FeeRateP90    int64 `json:"feeRateP90"`
+	FeeRateMax    int64 `json:"feeRateMax"`
+	FeeBaseMedian int64 `json:"feeBaseMedian"`
+	// Cheapest are the competitors with the lowest fee rate, cheapest first
+	Cheapest []Competitor `json:"cheapest"`
+	// SortedFeeRates is the fee rate of every competitor from low to high
+	SortedFeeRates []int64   `json:"-"`
+	CalculatedOn   time.Time `json:"calculatedOn"`
+}
+
+type CompetitorFeesCache struct {
+	Type           CompetitorFeesCacheOperationType
+	NodeId         int
+	PublicKey      string
+	CompetitorFees map[string]CompetitorFees
+	Out            chan<- *CompetitorFees
+}
+
+func CompetitorFeesCacheHandler(ch <-chan CompetitorFeesCache, ctx context.Context) {
+	competitorFeesByNodeIdCache := make(map[nodeIdType]map[string]CompetitorFees, 0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case competitorFeesCache := <-ch:
+			handleCompetitorFeesOperation(competitorFeesCache, competitorFeesByNodeIdCache)
+		}
+	}
+}
+
+func handleCompetitorFeesOperation(competitorFeesCache CompetitorFeesCache,
+	competitorFeesByNodeIdCache map[nodeIdType]map[string]CompetitorFees) {
+
+	switch competitorFeesCache.Type {
+	case readCompetitorFees:
+		competitorFees, exists := competitorFeesByNodeIdCache[nodeIdType(competitorFeesCache.NodeId)][competitorFeesCache.PublicKey]
+		if !exists {
+			competitorFeesCache.Out <- nil
+			return
+		}
+		competitorFeesCache.Out <- &competitorFees
+	case writeCompetitorFees:
+		// Every graph snapshot covers all peers so the previous results of the node are replaced entirely
+		competitorFeesByNodeIdCache[nodeIdType(competitorFeesCache.NodeId)] = competitorFeesCache.CompetitorFees
+	}
+}
+
+// GetCompetitorFees returns nil when there is no graph snapshot with channels into the peer.
+func GetCompetitorFees(nodeId int, peerPublicKey string) *CompetitorFees {
+	competitorFeesResponseChannel := make(chan *CompetitorFees)
+	CompetitorFeesCacheChannel <- CompetitorFeesCache{
+		Type:      readCompetitorFees,
+		NodeId:    nodeId,
+		PublicKey: peerPublicKey,
+		Out:       competitorFeesResponseChannel,
+	}
+	return <-competitorFeesResponseChannel
+}
+
+// SetCompetitorFees replaces the competitor fees of all peers of the node, they are keyed by the peer's public key.
+func SetCompetitorFees(nodeId int, competitorFees map[string]CompetitorFees) {
+	CompetitorFeesCacheChannel <- CompetitorFeesCache{
+		Type:           writeCompetitorFees,
+		NodeId:         nodeId,
+		CompetitorFees: competitorFees,
+	}
+}
+
+// FeeRatePercentile returns the share of the competitors (0 to 100) that charge less than feeRateMilliMsat.
+func (competitorFees CompetitorFees) FeeRatePercentile(feeRateMilliMsat int64) float64 {
+	if len(competitorFees.SortedFeeRates) == 0 {
+		return 0
+	}
+	cheaper := 0
+	for _, feeRate := range competitorFees.SortedFeeRates {
+		if feeRate >= feeRateMilliMsat {
+			break
+		}
+		cheaper++
+	}
+	return float64(cheaper) / float64(len(competitorFees.SortedFeeRates)) * 100
+}
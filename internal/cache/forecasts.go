@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+var ForecastsCacheChannel = make(chan ForecastCache) //nolint:gochecknoglobals
+
+type ForecastCacheOperationType uint
+
+const (
+	readChannelFlowRate ForecastCacheOperationType = iota
+	readAllChannelFlowRates
+	writeChannelFlowRates
+)
+
+// ChannelFlowRate is the average balance movement of a channel over the forecast window.
+// Amounts are in satoshis per day.
+type ChannelFlowRate struct {
+	NodeId    int `json:"nodeId"`
+	ChannelId int `json:"channelId"`
+	// Outbound is the rate at which the local balance drains (forwards out, payments, rebalances out)
+	Outbound int64 `json:"outbound"`
+	// Inbound is the rate at which the local balance fills (forwards in, invoices, rebalances in)
+	Inbound      int64     `json:"inbound"`
+	WindowStart  time.Time `json:"windowStart"`
+	CalculatedOn time.Time `json:"calculatedOn"`
+}
+
+type ChannelForecast struct {
+	// Net is positive when the local balance grows
+	Net int64 `json:"net"`
+	// DepletionOn is the moment the local balance is expected to reach zero
+	DepletionOn           *time.Time `json:"depletionOn"`
+	DepletionSecondsDelta *uint64    `json:"depletionSecondsDelta"`
+	// SaturationOn is the moment the remote balance is expected to reach zero
+	SaturationOn           *time.Time `json:"saturationOn"`
+	SaturationSecondsDelta *uint64    `json:"saturationSecondsDelta"`
+}
+
+type ForecastCache struct {
+	Type             ForecastCacheOperationType
+	ChannelId        int
+	ChannelFlowRates []ChannelFlowRate
+	Out              chan<- ChannelFlowRate
+	OutAll           chan<- []ChannelFlowRate
+}
+
+func ForecastsCacheHandler(ch <-chan ForecastCache, ctx context.Context) {
+	flowRatesByChannelIdCache := make(map[channelIdType]ChannelFlowRate, 0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case forecastCache := <-ch:
+			flowRatesByChannelIdCache = handleForecastOperation(forecastCache, flowRatesByChannelIdCache)
+		}
+	}
+}
+
+func handleForecastOperation(forecastCache ForecastCache,
+	flowRatesByChannelIdCache map[channelIdType]ChannelFlowRate) map[channelIdType]ChannelFlowRate {
+
+	switch forecastCache.Type {
+	case readChannelFlowRate:
+		forecastCache.Out <- flowRatesByChannelIdCache[channelIdType(forecastCache.ChannelId)]
+	case readAllChannelFlowRates:
+		flowRates := make([]ChannelFlowRate, 0, len(flowRatesByChannelIdCache))
+		for _, flowRate := range flowRatesByChannelIdCache {
+			flowRates = append(flowRates, flowRate)
+		}
+		forecastCache.OutAll <- flowRates
+	case writeChannelFlowRates:
+		// The calculation always covers all channels so the previous results are replaced entirely
+		flowRatesByChannelIdCache = make(map[channelIdType]ChannelFlowRate, len(forecastCache.ChannelFlowRates))
+		for _, flowRate := range forecastCache.ChannelFlowRates {
+			flowRatesByChannelIdCache[channelIdType(flowRate.ChannelId)] = flowRate
+		}
+	}
+	return flowRatesByChannelIdCache
+}
+
+// GetChannelFlowRate returns an empty ChannelFlowRate when no rate was calculated yet.
+func GetChannelFlowRate(channelId int) ChannelFlowRate {
+	flowRateResponseChannel := make(chan ChannelFlowRate)
+	forecastCache := ForecastCache{
+		ChannelId: channelId,
+		Type:      readChannelFlowRate,
+		Out:       flowRateResponseChannel,
+	}
+	ForecastsCacheChannel <- forecastCache
+	return <-flowRateResponseChannel
+}
+
+func GetChannelFlowRates() []ChannelFlowRate {
+	flowRatesResponseChannel := make(chan []ChannelFlowRate)
+	forecastCache := ForecastCache{
+		Type:   readAllChannelFlowRates,
+		OutAll: flowRatesResponseChannel,
+	}
+	ForecastsCacheChannel <- forecastCache
+	return <-flowRatesResponseChannel
+}
+
+func SetChannelFlowRates(flowRates []ChannelFlowRate) {
+	forecastCache := ForecastCache{
+		ChannelFlowRates: flowRates,
+		Type:             writeChannelFlowRates,
+	}
+	ForecastsCacheChannel <- forecastCache
+}
+
+// forecastMaximumDays is the horizon of the forecast, a linear extrapolation further out means nothing and the moment
+// would overflow time.Duration for very slow rates
+const forecastMaximumDays = 3 * 365
+
+// Forecast extrapolates the flow rate linearly from the current balances, a channel that empties beyond the horizon
+// has no depletion or saturation moment.
+func (flowRate ChannelFlowRate) Forecast(localBalance int64, remoteBalance int64, now time.Time) ChannelForecast {
+	forecast := ChannelForecast{Net: flowRate.Inbound - flowRate.Outbound}
+	switch {
+	case forecast.Net < 0:
+		if seconds, ok := secondsUntilEmpty(localBalance, -forecast.Net); ok {
+			depletionOn := now.Add(time.Duration(seconds) * time.Second)
+			forecast.DepletionSecondsDelta = &seconds
+			forecast.DepletionOn = &depletionOn
+		}
+	case forecast.Net > 0:
+		if seconds, ok := secondsUntilEmpty(remoteBalance, forecast.Net); ok {
+			saturationOn := now.Add(time.Duration(seconds) * time.Second)
+			forecast.SaturationSecondsDelta = &seconds
+			forecast.SaturationOn = &saturationOn
+		}
+	}
+	return forecast
+}
+
+// secondsUntilEmpty returns false when the balance lasts longer than the forecast horizon.
+func secondsUntilEmpty(balance int64, ratePerDay int64) (uint64, bool) {
+	if balance <= 0 {
+		return 0, true
+	}
+	days := float64(balance) / float64(ratePerDay)
+	if days > forecastMaximumDays {
+		return 0, false
+	}
+	return uint64(days * (24 * 60 * 60)), true
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChannelFlowRateForecast(t *testing.T) {
+	now := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	draining := ChannelFlowRate{Outbound: 300_000, Inbound: 100_000}
+	forecast := draining.Forecast(1_000_000, 4_000_000, now)
+	if forecast.Net != -200_000 {
+		t.Errorf("got net %v, want -200000", forecast.Net)
+	}
+	if forecast.DepletionOn == nil || !forecast.DepletionOn.Equal(now.AddDate(0, 0, 5)) {
+		t.Errorf("got depletion %v, want %v", forecast.DepletionOn, now.AddDate(0, 0, 5))
+	}
+	if forecast.SaturationOn != nil {
+		t.Errorf("draining channel should not saturate")
+	}
+
+	filling := ChannelFlowRate{Outbound: 0, Inbound: 500_000}
+	forecast = filling.Forecast(1_000_000, 1_000_000, now)
+	if forecast.SaturationSecondsDelta == nil || *forecast.SaturationSecondsDelta != 2*24*60*60 {
+		t.Errorf("got saturation %v, want 2 days", forecast.SaturationSecondsDelta)
+	}
+	if forecast.DepletionOn != nil {
+		t.Errorf("filling channel should not deplete")
+	}
+
+	balanced := ChannelFlowRate{Outbound: 100, Inbound: 100}
+	forecast = balanced.Forecast(1_000_000, 1_000_000, now)
+	if forecast.DepletionOn != nil || forecast.SaturationOn != nil {
+		t.Errorf("balanced channel should have no forecast")
+	}
+
+	// 1M sat at 9 sat/day would deplete in more than 300 years
+	slow := ChannelFlowRate{Outbound: 9}
+	forecast = slow.Forecast(1_000_000, 1_000_000, now)
+	if forecast.DepletionOn != nil || forecast.DepletionSecondsDelta != nil {
+		t.Errorf("got depletion %v beyond the forecast horizon", forecast.DepletionOn)
+	}
+	forecast = ChannelFlowRate{Inbound: 1}.Forecast(1_000_000, 1_000_000_000, now)
+	if forecast.SaturationOn != nil {
+		t.Errorf("got saturation %v beyond the forecast horizon", forecast.SaturationOn)
+	}
+}
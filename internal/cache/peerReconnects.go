@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+var PeerReconnectsCacheChannel = make(chan PeerReconnectsCache) //nolint:gochecknoglobals
+
+type PeerReconnectsCacheOperationType uint
+
+const (
+	readAllPeerReconnectStates PeerReconnectsCacheOperationType = iota
+	writePeerReconnectStates
+)
+
+type PeerReconnectStatus string
+
+const (
+	PeerReconnectConnected PeerReconnectStatus = "connected"
+	// PeerReconnectWaiting is an offline peer waiting for its next attempt
+	PeerReconnectWaiting PeerReconnectStatus = "waiting"
+	// PeerReconnectNoAddress is an offline peer without a known address
+	PeerReconnectNoAddress PeerReconnectStatus = "noAddress"
+	// PeerReconnectDisabled is an offline peer with the disable reconnect setting
+	PeerReconnectDisabled PeerReconnectStatus = "disabled"
+)
+
+// PeerReconnectState is the reconnection state of a channel peer of one of our nodes.
+type PeerReconnectState struct {
+	NodeId       int                 `json:"nodeId"`
+	PeerNodeId   int                 `json:"peerNodeId"`
+	Status       PeerReconnectStatus `json:"status"`
+	OfflineSince *time.Time          `json:"offlineSince"`
+	// Failures is the number of attempts that failed on every address since the peer went offline
+	Failures    int        `json:"failures"`
+	LastAttempt *time.Time `json:"lastAttempt"`
+	NextAttempt *time.Time `json:"nextAttempt"`
+	// Addresses are the known addresses in the order they are tried
+	Addresses   []string `json:"addresses"`
+	LastAddress string   `json:"lastAddress"`
+	LastError   string   `json:"lastError"`
+	// Notified is true once the failures were escalated to a notification
+	Notified bool `json:"notified"`
+}
+
+type PeerReconnectsCache struct {
+	Type                PeerReconnectsCacheOperationType
+	PeerReconnectStates []PeerReconnectState
+	Out                 chan<- []PeerReconnectState
+}
+
+func PeerReconnectsCacheHandler(ch <-chan PeerReconnectsCache, ctx context.Context) {
+	var peerReconnectStates []PeerReconnectState
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case peerReconnectsCache := <-ch:
+			switch peerReconnectsCache.Type {
+			case readAllPeerReconnectStates:
+				peerReconnectsCache.Out <- append([]PeerReconnectState{}, peerReconnectStates...)
+			case writePeerReconnectStates:
+				peerReconnectStates = peerReconnectsCache.PeerReconnectStates
+			}
+		}
+	}
+}
+
+func GetPeerReconnectStates() []PeerReconnectState {
+	peerReconnectStatesResponseChannel := make(chan []PeerReconnectState)
+	PeerReconnectsCacheChannel <- PeerReconnectsCache{
+		Type: readAllPeerReconnectStates,
+		Out:  peerReconnectStatesResponseChannel,
+	}
+	return <-peerReconnectStatesResponseChannel
+}
+
+// SetPeerReconnectStates replaces the states of all peers, the reconnect service owns them.
+func SetPeerReconnectStates(peerReconnectStates []PeerReconnectState) {
+	PeerReconnectsCacheChannel <- PeerReconnectsCache{
+		Type:                writePeerReconnectStates,
+		PeerReconnectStates: peerReconnectStates,
+	}
+}
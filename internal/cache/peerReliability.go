@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+var PeerReliabilityCacheChannel = make(chan PeerReliabilityCache) //nolint:gochecknoglobals
+
+type PeerReliabilityCacheOperationType uint
+
+const (
+	readPeerReliability PeerReliabilityCacheOperationType = iota
+	readAllPeerReliabilities
+	writePeerReliabilities
+)
+
+// PeerReliability summarises the connection history of a peer over the reliability window.
+// Periods before the first known connection status are not part of the window.
+type PeerReliability struct {
+	NodeId     int `json:"nodeId"`
+	PeerNodeId int `json:"peerNodeId"`
+	// UptimePercentage is the share of the window (0 to 100) the peer was connected
+	UptimePercentage float64 `json:"uptimePercentage"`
+	// FlapCount is the number of disconnects
+	FlapCount int `json:"flapCount"`
+	// MeanSecondsBetweenDisconnects is the connected time divided by the number of disconnects, nil without disconnects
+	MeanSecondsBetweenDisconnects *int64 `json:"meanSecondsBetweenDisconnects"`
+	LongestOutageSeconds          int64  `json:"longestOutageSeconds"`
+	// ChannelActivePercentage is the average share of the window (0 to 100) the channels with the peer were active
+	ChannelActivePercentage *float64 `json:"channelActivePercentage"`
+	// ChannelFlapCount is the highest number of active to inactive flips of any of the channels with the peer
+	ChannelFlapCount int `json:"channelFlapCount"`
+	// Score combines the uptime and the flaps (0 to 100), higher is more reliable
+	Score        float64   `json:"score"`
+	WindowDays   int       `json:"windowDays"`
+	WindowStart  time.Time `json:"windowStart"`
+	CalculatedOn time.Time `json:"calculatedOn"`
+}
+
+type peerReliabilityKey struct {
+	nodeId     nodeIdType
+	peerNodeId nodeIdType
+}
+
+type PeerReliabilityCache struct {
+	Type              PeerReliabilityCacheOperationType
+	NodeId            int
+	PeerNodeId        int
+	PeerReliabilities []PeerReliability
+	Out               chan<- *PeerReliability
+	OutAll            chan<- []PeerReliability
+}
+
+func PeerReliabilityCacheHandler(ch <-chan PeerReliabilityCache, ctx context.Context) {
+	peerReliabilityCache := make(map[peerReliabilityKey]PeerReliability, 0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case peerReliabilityOperation := <-ch:
+			peerReliabilityCache = handlePeerReliabilityOperation(peerReliabilityOperation, peerReliabilityCache)
+		}
+	}
+}
+
+func handlePeerReliabilityOperation(peerReliabilityOperation PeerReliabilityCache,
+	peerReliabilityCache map[peerReliabilityKey]PeerReliability) map[peerReliabilityKey]PeerReliability {
+
+	switch peerReliabilityOperation.Type {
+	case readPeerReliability:
+		peerReliability, exists := peerReliabilityCache[peerReliabilityKey{
+			nodeId:     nodeIdType(peerReliabilityOperation.NodeId),
+			peerNodeId: nodeIdType(peerReliabilityOperation.PeerNodeId),
+		}]
+		if !exists {
+			peerReliabilityOperation.Out <- nil
+			return peerReliabilityCache
+		}
+		peerReliabilityOperation.Out <- &peerReliability
+	case readAllPeerReliabilities:
+		peerReliabilities := make([]PeerReliability, 0, len(peerReliabilityCache))
+		for _, peerReliability := range peerReliabilityCache {
+			peerReliabilities = append(peerReliabilities, peerReliability)
+		}
+		peerReliabilityOperation.OutAll <- peerReliabilities
+	case writePeerReliabilities:
+		// The calculation always covers all peers so the previous results are replaced entirely
+		peerReliabilityCache = make(map[peerReliabilityKey]PeerReliability,
+			len(peerReliabilityOperation.PeerReliabilities))
+		for _, peerReliability := range peerReliabilityOperation.PeerReliabilities {
+			peerReliabilityCache[peerReliabilityKey{
+				nodeId:     nodeIdType(peerReliability.NodeId),
+				peerNodeId: nodeIdType(peerReliability.PeerNodeId),
+			}] = peerReliability
+		}
+	}
+	return peerReliabilityCache
+}
+
+// GetPeerReliability returns nil when there is no connection history for the peer.
+func GetPeerReliability(nodeId int, peerNodeId int) *PeerReliability {
+	peerReliabilityResponseChannel := make(chan *PeerReliability)
+	PeerReliabilityCacheChannel <- PeerReliabilityCache{
+		Type:       readPeerReliability,
+		NodeId:     nodeId,
+		PeerNodeId: peerNodeId,
+		Out:        peerReliabilityResponseChannel,
+	}
+	return <-peerReliabilityResponseChannel
+}
+
+func GetPeerReliabilities() []PeerReliability {
+	peerReliabilitiesResponseChannel := make(chan []PeerReliability)
+	PeerReliabilityCacheChannel <- PeerReliabilityCache{
+		Type:   readAllPeerReliabilities,
+		OutAll: peerReliabilitiesResponseChannel,
+	}
+	return <-peerReliabilitiesResponseChannel
+}
+
+func SetPeerReliabilities(peerReliabilities []PeerReliability) {
+	PeerReliabilityCacheChannel <- PeerReliabilityCache{
+		Type:              writePeerReliabilities,
+		PeerReliabilities: peerReliabilities,
+	}
+}
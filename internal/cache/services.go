@@ -42,6 +42,8 @@ const (
 	writeNodeConnectionDetails
 	cancelCoreService
 	cancelNodeService
+	readShuttingDown
+	writeShuttingDown
 )
 
 type ServiceCache struct {
@@ -65,6 +67,7 @@ type ServiceCache struct {
 
 type SystemServiceState struct {
 	NoSubscriptions   bool
+	ShuttingDown      bool
 	CoreServiceStates map[services_helpers.ServiceType]ServiceState
 	NodeServiceStates map[core.Implementation]map[nodeIdType]map[services_helpers.ServiceType]ServiceState
 }
@@ -299,6 +302,10 @@ func handleServiceOperation(
 			}
 		}
 		serviceCache.BoolOut <- s
+	case readShuttingDown:
+		serviceCache.BoolOut <- torqDesiredStateCache.ShuttingDown
+	case writeShuttingDown:
+		torqDesiredStateCache.ShuttingDown = true
 	case readLndNodeIds:
 		serviceCache.IntsOut <- readNodeIds(core.LND, torqDesiredStateCache.NodeServiceStates[core.LND],
 			torqCurrentStateCache.NodeServiceStates[core.LND],
@@ -656,6 +663,24 @@ func IsChannelBalanceCacheStreamActive(nodeId int) bool {
 	return <-responseChannel
 }
 
+// IsShuttingDown is true once Torq received a shutdown signal, no new API or workflow work should start from then on.
+func IsShuttingDown() bool {
+	responseChannel := make(chan bool)
+	serviceCache := ServiceCache{
+		Type:    readShuttingDown,
+		BoolOut: responseChannel,
+	}
+	ServicesCacheChannel <- serviceCache
+	return <-responseChannel
+}
+
+func InitiateShutdown() {
+	log.Info().Msg("Torq is shutting down, no new API or workflow work is accepted.")
+	ServicesCacheChannel <- ServiceCache{
+		Type: writeShuttingDown,
+	}
+}
+
 func SetDesiredCoreServiceState(serviceType services_helpers.ServiceType, serviceStatus services_helpers.ServiceStatus) {
 	log.Info().Msgf("%v desired state is now %v.", serviceType.String(), serviceStatus.String())
 	serviceCache := ServiceCache{
@@ -1007,7 +1032,9 @@ func ActivateLndService(ctx context.Context,
 			services_helpers.LndServiceInvoiceStream,
 			services_helpers.ClnServiceInvoicesService,
 			services_helpers.LndServicePaymentsService,
-			services_helpers.ClnServicePaymentsService:
+			services_helpers.ClnServicePaymentsService,
+			services_helpers.LndServiceGraphSnapshotService,
+			services_helpers.ClnServiceGraphSnapshotService:
 			active := false
 			for _, cs := range serviceType.GetNodeConnectionDetailCustomSettings() {
 				if customSettings&cs != 0 {
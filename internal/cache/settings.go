@@ -15,8 +15,34 @@ const (
 	writeSettings
 	writeBlockHeight
 	writeVectorUrl
+	writeRetentionDays
+	writePeerReliabilitySettings
+	writePeerReconnectSettings
 )
 
+// RetentionDays holds the number of days raw rows are kept before they are aggregated and deleted.
+// Zero keeps the raw rows forever.
+type RetentionDays struct {
+	HtlcEvents     int
+	ChannelEvents  int
+	GraphEvents    int
+	FailedPayments int
+}
+
+// PeerReliabilitySettings holds the window of the peer reliability scores and the uptime percentage below which a
+// notification is sent, zero disables the notification.
+type PeerReliabilitySettings struct {
+	WindowDays      int
+	UptimeThreshold float64
+}
+
+// PeerReconnectSettings holds the number of failed reconnection attempts after which a notification is sent and the
+// order in which the address networks ("clearnet" and "tor") are tried.
+type PeerReconnectSettings struct {
+	MaxFailures  int
+	NetworkOrder []string
+}
+
 type SettingsCache struct {
 	Type                            SettingsCacheOperationType
 	DefaultLanguage                 string
@@ -31,6 +57,9 @@ type SettingsCache struct {
 	TelegramLowPriorityCredentials  *string
 	BlockHeight                     uint32
 	VectorUrl                       string
+	RetentionDays                   RetentionDays
+	PeerReliabilitySettings         PeerReliabilitySettings
+	PeerReconnectSettings           PeerReconnectSettings
 	Out                             chan<- SettingsCache
 }
 
@@ -47,6 +76,9 @@ type SettingsDataCache struct {
 	TelegramLowPriorityCredentials  *string
 	BlockHeight                     uint32
 	VectorUrl                       string
+	RetentionDays                   RetentionDays
+	PeerReliabilitySettings         PeerReliabilitySettings
+	PeerReconnectSettings           PeerReconnectSettings
 }
 
 func (s SettingsCache) GetTelegramCredential(highPriority bool) string {
@@ -113,6 +145,9 @@ func handleSettingsOperation(settingsCache SettingsCache, data SettingsDataCache
 		settingsCache.TelegramLowPriorityCredentials = data.TelegramLowPriorityCredentials
 		settingsCache.BlockHeight = data.BlockHeight
 		settingsCache.VectorUrl = data.VectorUrl
+		settingsCache.RetentionDays = data.RetentionDays
+		settingsCache.PeerReliabilitySettings = data.PeerReliabilitySettings
+		settingsCache.PeerReconnectSettings = data.PeerReconnectSettings
 		settingsCache.Out <- settingsCache
 	case writeSettings:
 		data.DefaultLanguage = settingsCache.DefaultLanguage
@@ -129,6 +164,12 @@ func handleSettingsOperation(settingsCache SettingsCache, data SettingsDataCache
 		data.VectorUrl = settingsCache.VectorUrl
 	case writeBlockHeight:
 		data.BlockHeight = settingsCache.BlockHeight
+	case writeRetentionDays:
+		data.RetentionDays = settingsCache.RetentionDays
+	case writePeerReliabilitySettings:
+		data.PeerReliabilitySettings = settingsCache.PeerReliabilitySettings
+	case writePeerReconnectSettings:
+		data.PeerReconnectSettings = settingsCache.PeerReconnectSettings
 	}
 	return data
 }
@@ -191,6 +232,30 @@ func SetVectorUrlBase(vectorUrlBase string) {
 	SettingsCacheChannel <- settingsCache
 }
 
+func SetRetentionDays(retentionDays RetentionDays) {
+	settingsCache := SettingsCache{
+		RetentionDays: retentionDays,
+		Type:          writeRetentionDays,
+	}
+	SettingsCacheChannel <- settingsCache
+}
+
+func SetPeerReliabilitySettings(peerReliabilitySettings PeerReliabilitySettings) {
+	settingsCache := SettingsCache{
+		PeerReliabilitySettings: peerReliabilitySettings,
+		Type:                    writePeerReliabilitySettings,
+	}
+	SettingsCacheChannel <- settingsCache
+}
+
+func SetPeerReconnectSettings(peerReconnectSettings PeerReconnectSettings) {
+	settingsCache := SettingsCache{
+		PeerReconnectSettings: peerReconnectSettings,
+		Type:                  writePeerReconnectSettings,
+	}
+	SettingsCacheChannel <- settingsCache
+}
+
 func GetVectorUrlBase() string {
 	settingsResponseChannel := make(chan SettingsCache)
 	settingsCache := SettingsCache{
@@ -88,6 +88,40 @@ type ChannelBody struct {
 	PeerAlias                    string              `json:"peerAlias"`
 	Private                      bool                `json:"private"`
 	NodeCssColour                *string             `json:"nodeCssColour"`
+	// Liquidity forecast based on the balance movement of the last days (amounts in sats per day)
+	OutboundFlowPerDay     int64      `json:"outboundFlowPerDay"`
+	InboundFlowPerDay      int64      `json:"inboundFlowPerDay"`
+	NetFlowPerDay          int64      `json:"netFlowPerDay"`
+	DepletionOn            *time.Time `json:"depletionOn"`
+	DepletionSecondsDelta  *uint64    `json:"depletionSecondsDelta"`
+	SaturationOn           *time.Time `json:"saturationOn"`
+	SaturationSecondsDelta *uint64    `json:"saturationSecondsDelta"`
+	// Fees other nodes charge to route into the peer (fee rates in ppm) based on the latest graph snapshot, nil
+	// without a graph snapshot or when the peer has no other enabled channels.
+	CompetitorCount         *int               `json:"competitorCount"`
+	CompetitorFeeRateMin    *int64             `json:"competitorFeeRateMin"`
+	CompetitorFeeRateP10    *int64             `json:"competitorFeeRateP10"`
+	CompetitorFeeRateP20    *int64             `json:"competitorFeeRateP20"`
+	CompetitorFeeRateP30    *int64             `json:"competitorFeeRateP30"`
+	CompetitorFeeRateP40    *int64             `json:"competitorFeeRateP40"`
+	CompetitorFeeRateMedian *int64             `json:"competitorFeeRateMedian"`
+	CompetitorFeeRateP60    *int64             `json:"competitorFeeRateP60"`
+	CompetitorFeeRateP70    *int64             `json:"competitorFeeRateP70"`
+	CompetitorFeeRateP80    *int64             `json:"competitorFeeRateP80"`
+	CompetitorFeeRateP90    *int64             `json:"competitorFeeRateP90"`
+	CompetitorFeeRateMax    *int64             `json:"competitorFeeRateMax"`
+	CompetitorFeeBaseMedian *int64             `json:"competitorFeeBaseMedian"`
+	CheapestCompetitors     []cache.Competitor `json:"cheapestCompetitors"`
+	// FeeRateCompetitorPercentile is the share of the competitors (0 to 100) that charge a lower fee rate than our node
+	FeeRateCompetitorPercentile *float64 `json:"feeRateCompetitorPercentile"`
+	// Reliability of the peer over the peer reliability window, nil without connection history
+	PeerUptimePercentage              *float64 `json:"peerUptimePercentage"`
+	PeerFlapCount                     *int     `json:"peerFlapCount"`
+	PeerMeanSecondsBetweenDisconnects *int64   `json:"peerMeanSecondsBetweenDisconnects"`
+	PeerLongestOutageSeconds          *int64   `json:"peerLongestOutageSeconds"`
+	PeerChannelActivePercentage       *float64 `json:"peerChannelActivePercentage"`
+	PeerChannelFlapCount              *int     `json:"peerChannelFlapCount"`
+	PeerReliabilityScore              *float64 `json:"peerReliabilityScore"`
 }
 
 type PendingHtlcs struct {
@@ -293,6 +327,48 @@ func GetChannelsByIds(nodeId int, channelIds []int) ([]ChannelBody, error) {
 			deltaSeconds := uint64(time.Since(*channelSettings.ClosedOn).Seconds())
 			chanBody.ClosedOnSecondsDelta = &deltaSeconds
 		}
+
+		flowRate := cache.GetChannelFlowRate(channelSettings.ChannelId)
+		forecast := flowRate.Forecast(channel.LocalBalance, channel.RemoteBalance, time.Now())
+		chanBody.OutboundFlowPerDay = flowRate.Outbound
+		chanBody.InboundFlowPerDay = flowRate.Inbound
+		chanBody.NetFlowPerDay = forecast.Net
+		chanBody.DepletionOn = forecast.DepletionOn
+		chanBody.DepletionSecondsDelta = forecast.DepletionSecondsDelta
+		chanBody.SaturationOn = forecast.SaturationOn
+		chanBody.SaturationSecondsDelta = forecast.SaturationSecondsDelta
+
+		competitorFees := cache.GetCompetitorFees(nodeId, chanBody.RemotePubkey)
+		if competitorFees != nil {
+			feeRateCompetitorPercentile := competitorFees.FeeRatePercentile(chanBody.FeeRateMilliMsat)
+			chanBody.CompetitorCount = &competitorFees.Count
+			chanBody.CompetitorFeeRateMin = &competitorFees.FeeRateMin
+			chanBody.CompetitorFeeRateP10 = &competitorFees.FeeRateP10
+			chanBody.CompetitorFeeRateP20 = &competitorFees.FeeRateP20
+			chanBody.CompetitorFeeRateP30 = &competitorFees.FeeRateP30
+			chanBody.CompetitorFeeRateP40 = &competitorFees.FeeRateP40
+			chanBody.CompetitorFeeRateMedian = &competitorFees.FeeRateMedian
+			chanBody.CompetitorFeeRateP60 = &competitorFees.FeeRateP60
+			chanBody.CompetitorFeeRateP70 = &competitorFees.FeeRateP70
+			chanBody.CompetitorFeeRateP80 = &competitorFees.FeeRateP80
+			chanBody.CompetitorFeeRateP90 = &competitorFees.FeeRateP90
+			chanBody.CompetitorFeeRateMax = &competitorFees.FeeRateMax
+			chanBody.CompetitorFeeBaseMedian = &competitorFees.FeeBaseMedian
+			chanBody.CheapestCompetitors = competitorFees.Cheapest
+			chanBody.FeeRateCompetitorPercentile = &feeRateCompetitorPercentile
+		}
+
+		peerReliability := cache.GetPeerReliability(nodeId, chanBody.PeerNodeId)
+		if peerReliability != nil {
+			chanBody.PeerUptimePercentage = &peerReliability.UptimePercentage
+			chanBody.PeerFlapCount = &peerReliability.FlapCount
+			chanBody.PeerMeanSecondsBetweenDisconnects = peerReliability.MeanSecondsBetweenDisconnects
+			chanBody.PeerLongestOutageSeconds = &peerReliability.LongestOutageSeconds
+			chanBody.PeerChannelActivePercentage = peerReliability.ChannelActivePercentage
+			chanBody.PeerChannelFlapCount = &peerReliability.ChannelFlapCount
+			chanBody.PeerReliabilityScore = &peerReliability.Score
+		}
+
 		channelsBody = append(channelsBody, chanBody)
 	}
 	return channelsBody, nil
@@ -181,6 +181,84 @@ func WalletBalance(ctx context.Context,
 	return lightning_helpers.WalletBalanceResponse{}
 }
 
+func FeeEstimate(ctx context.Context,
+	request lightning_helpers.FeeEstimateRequest) lightning_helpers.FeeEstimateResponse {
+	ctx, span := otel.Tracer(name).Start(ctx, "FeeEstimate")
+	defer span.End()
+	responseChan := make(chan any)
+	processConcurrent(ctx, 10, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.FeeEstimateResponse); ok {
+		return res
+	}
+	return lightning_helpers.FeeEstimateResponse{}
+}
+
+func ListUnspent(ctx context.Context,
+	request lightning_helpers.ListUnspentRequest) lightning_helpers.ListUnspentResponse {
+	ctx, span := otel.Tracer(name).Start(ctx, "ListUnspent")
+	defer span.End()
+	responseChan := make(chan any)
+	processConcurrent(ctx, 30, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.ListUnspentResponse); ok {
+		return res
+	}
+	return lightning_helpers.ListUnspentResponse{}
+}
+
+func UnconfirmedTransactions(ctx context.Context,
+	request lightning_helpers.UnconfirmedTransactionsRequest) lightning_helpers.UnconfirmedTransactionsResponse {
+	ctx, span := otel.Tracer(name).Start(ctx, "UnconfirmedTransactions")
+	defer span.End()
+	responseChan := make(chan any)
+	processConcurrent(ctx, 30, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.UnconfirmedTransactionsResponse); ok {
+		return res
+	}
+	return lightning_helpers.UnconfirmedTransactionsResponse{}
+}
+
+func LeaseOutput(ctx context.Context,
+	request lightning_helpers.LeaseOutputRequest) lightning_helpers.LeaseOutputResponse {
+	ctx, span := otel.Tracer(name).Start(ctx, "LeaseOutput")
+	defer span.End()
+	responseChan := make(chan any)
+	processSequential(ctx, 10, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.LeaseOutputResponse); ok {
+		return res
+	}
+	return lightning_helpers.LeaseOutputResponse{}
+}
+
+func BumpFee(ctx context.Context,
+	request lightning_helpers.BumpFeeRequest) lightning_helpers.BumpFeeResponse {
+	ctx, span := otel.Tracer(name).Start(ctx, "BumpFee")
+	defer span.End()
+	responseChan := make(chan any)
+	processSequential(ctx, 30, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.BumpFeeResponse); ok {
+		return res
+	}
+	return lightning_helpers.BumpFeeResponse{}
+}
+
+func ForceClosingChannels(ctx context.Context,
+	request lightning_helpers.ForceClosingChannelsRequest) lightning_helpers.ForceClosingChannelsResponse {
+	ctx, span := otel.Tracer(name).Start(ctx, "ForceClosingChannels")
+	defer span.End()
+	responseChan := make(chan any)
+	processConcurrent(ctx, 30, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.ForceClosingChannelsResponse); ok {
+		return res
+	}
+	return lightning_helpers.ForceClosingChannelsResponse{}
+}
+
 func ListPeers(ctx context.Context,
 	request lightning_helpers.ListPeersRequest) lightning_helpers.ListPeersResponse {
 	ctx, span := otel.Tracer(name).Start(ctx, "ListPeers")
@@ -285,6 +363,19 @@ func NewPayment(ctx context.Context,
 	return lightning_helpers.NewPaymentResponse{}
 }
 
+func PaymentStatus(ctx context.Context,
+	request lightning_helpers.PaymentStatusRequest) lightning_helpers.PaymentStatusResponse {
+	ctx, span := otel.Tracer(name).Start(ctx, "PaymentStatus")
+	defer span.End()
+	responseChan := make(chan any)
+	processConcurrent(ctx, 30, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.PaymentStatusResponse); ok {
+		return res
+	}
+	return lightning_helpers.PaymentStatusResponse{}
+}
+
 func DecodeInvoice(ctx context.Context,
 	request lightning_helpers.DecodeInvoiceRequest) lightning_helpers.DecodeInvoiceResponse {
 	ctx, span := otel.Tracer(name).Start(ctx, "DecodeInvoice")
@@ -416,6 +507,42 @@ func processRequestByType(ctx context.Context, req any, responseChan chan<- any)
 					CommunicationResponse: communicationResponse,
 				}
 				return
+			case lightning_helpers.FeeEstimateRequest:
+				responseChan <- lightning_helpers.FeeEstimateResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
+			case lightning_helpers.ListUnspentRequest:
+				responseChan <- lightning_helpers.ListUnspentResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
+			case lightning_helpers.UnconfirmedTransactionsRequest:
+				responseChan <- lightning_helpers.UnconfirmedTransactionsResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
+			case lightning_helpers.LeaseOutputRequest:
+				responseChan <- lightning_helpers.LeaseOutputResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
+			case lightning_helpers.BumpFeeRequest:
+				responseChan <- lightning_helpers.BumpFeeResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
+			case lightning_helpers.ForceClosingChannelsRequest:
+				responseChan <- lightning_helpers.ForceClosingChannelsResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
 			case lightning_helpers.ListPeersRequest:
 				responseChan <- lightning_helpers.ListPeersResponse{
 					Request:               r,
@@ -452,6 +579,12 @@ func processRequestByType(ctx context.Context, req any, responseChan chan<- any)
 					CommunicationResponse: communicationResponse,
 				}
 				return
+			case lightning_helpers.PaymentStatusRequest:
+				responseChan <- lightning_helpers.PaymentStatusResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
 			case lightning_helpers.NewPaymentRequest:
 				responseChan <- lightning_helpers.NewPaymentResponse{
 					Request:               r,
@@ -498,6 +631,24 @@ func processRequestByType(ctx context.Context, req any, responseChan chan<- any)
 	case lightning_helpers.WalletBalanceRequest:
 		responseChan <- processWalletBalanceRequest(ctx, r)
 		return
+	case lightning_helpers.FeeEstimateRequest:
+		responseChan <- processFeeEstimateRequest(ctx, r)
+		return
+	case lightning_helpers.ListUnspentRequest:
+		responseChan <- processListUnspentRequest(ctx, r)
+		return
+	case lightning_helpers.UnconfirmedTransactionsRequest:
+		responseChan <- processUnconfirmedTransactionsRequest(ctx, r)
+		return
+	case lightning_helpers.LeaseOutputRequest:
+		responseChan <- processLeaseOutputRequest(ctx, r)
+		return
+	case lightning_helpers.BumpFeeRequest:
+		responseChan <- processBumpFeeRequest(ctx, r)
+		return
+	case lightning_helpers.ForceClosingChannelsRequest:
+		responseChan <- processForceClosingChannelsRequest(ctx, r)
+		return
 	case lightning_helpers.ListPeersRequest:
 		responseChan <- processListPeersRequest(ctx, r)
 		return
@@ -516,6 +667,9 @@ func processRequestByType(ctx context.Context, req any, responseChan chan<- any)
 	case lightning_helpers.OnChainPaymentRequest:
 		responseChan <- processOnChainPaymentRequest(ctx, r)
 		return
+	case lightning_helpers.PaymentStatusRequest:
+		responseChan <- processPaymentStatusRequest(ctx, r)
+		return
 	case lightning_helpers.NewPaymentRequest:
 		responseChan <- processNewPaymentRequest(ctx, r)
 		return
@@ -1174,6 +1328,81 @@ func processListPeersRequest(ctx context.Context,
 	return response
 }
 
+func processFeeEstimateRequest(ctx context.Context,
+	request lightning_helpers.FeeEstimateRequest) lightning_helpers.FeeEstimateResponse {
+
+	ctx, span := otel.Tracer(name).Start(ctx, "processFeeEstimateRequest")
+	defer span.End()
+
+	response := lightning_helpers.FeeEstimateResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+
+	rsp, err := cln.NewNodeClient(connection).Feerates(ctx, &cln.FeeratesRequest{Style: cln.FeeratesRequest_PERKB})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	if rsp.Perkb == nil {
+		response.Error = "no fee rates available"
+		if rsp.WarningMissingFeerates != nil {
+			response.Error = *rsp.WarningMissingFeerates
+		}
+		return response
+	}
+
+	satPerKvbyte := getFeeRateForTarget(rsp.Perkb, request.TargetConf)
+	if satPerKvbyte == 0 {
+		response.Error = "no fee estimate available"
+		return response
+	}
+	response.Status = lightning_helpers.Active
+	response.SatPerVbyte = float64(satPerKvbyte) / 1_000
+	return response
+}
+
+// getFeeRateForTarget picks the estimate with the largest block count that still meets the target, or the fastest
+// estimate when none does. Without estimates the opening fee rate is used.
+func getFeeRateForTarget(perkb *cln.FeeratesPerkb, targetConf int32) uint32 {
+	target := uint32(targetConf)
+	var best *cln.FeeratesPerkbEstimates
+	for _, estimate := range perkb.Estimates {
+		if estimate.Blockcount == nil || estimate.Feerate == nil {
+			continue
+		}
+		if best == nil {
+			best = estimate
+			continue
+		}
+		blockCount := *estimate.Blockcount
+		bestBlockCount := *best.Blockcount
+		meetsTarget := blockCount <= target
+		bestMeetsTarget := bestBlockCount <= target
+		if (meetsTarget && !bestMeetsTarget) ||
+			(meetsTarget && bestMeetsTarget && blockCount > bestBlockCount) ||
+			(!meetsTarget && !bestMeetsTarget && blockCount < bestBlockCount) {
+			best = estimate
+		}
+	}
+	if best != nil {
+		return *best.Feerate
+	}
+	if perkb.Opening != nil {
+		return *perkb.Opening
+	}
+	return 0
+}
+
 func processNewAddressRequest(ctx context.Context,
 	request lightning_helpers.NewAddressRequest) lightning_helpers.NewAddressResponse {
 
@@ -1316,6 +1545,13 @@ func prepareOpenRequest(request lightning_helpers.OpenChannelRequest) (*cln.Fund
 	if request.CloseAddress != nil {
 		openChanReq.CloseTo = request.CloseAddress
 	}
+
+	if len(request.Outpoints) != 0 {
+		openChanReq.Utxos, err = getOutpoints(request.Outpoints)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return openChanReq, nil
 }
 
@@ -1487,6 +1723,13 @@ func processOnChainPaymentRequest(ctx context.Context,
 		minConfs := uint32(*request.MinConfs)
 		wr.Minconf = &minConfs
 	}
+	if len(request.Outpoints) != 0 {
+		wr.Utxos, err = getOutpoints(request.Outpoints)
+		if err != nil {
+			response.Error = err.Error()
+			return response
+		}
+	}
 
 	resp, err := cln.NewNodeClient(connection).Withdraw(ctx, wr)
 
@@ -1560,6 +1803,61 @@ func processNewPaymentRequest(ctx context.Context,
 	return response
 }
 
+func processPaymentStatusRequest(ctx context.Context,
+	request lightning_helpers.PaymentStatusRequest) lightning_helpers.PaymentStatusResponse {
+
+	ctx, span := otel.Tracer(name).Start(ctx, "processPaymentStatusRequest")
+	defer span.End()
+
+	response := lightning_helpers.PaymentStatusResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	paymentHash, err := hex.DecodeString(request.PaymentHash)
+	if err != nil {
+		response.Error = "Invalid payment hash"
+		return response
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+
+	pays, err := cln.NewNodeClient(connection).ListPays(ctx, &cln.ListpaysRequest{PaymentHash: paymentHash})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	response.PaymentStatus = getPaymentStatus(pays.Pays)
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+// getPaymentStatus maps the pays of a payment hash to the LND payment status, a completed or pending pay wins over
+// the failed attempts.
+func getPaymentStatus(pays []*cln.ListpaysPays) string {
+	paymentStatus := lightning_helpers.PaymentStatusUnknown
+	for _, pay := range pays {
+		switch pay.Status {
+		case cln.ListpaysPays_COMPLETE:
+			return lightning_helpers.PaymentStatusSucceeded
+		case cln.ListpaysPays_PENDING:
+			paymentStatus = lightning_helpers.PaymentStatusInFlight
+		case cln.ListpaysPays_FAILED:
+			if paymentStatus == lightning_helpers.PaymentStatusUnknown {
+				paymentStatus = lightning_helpers.PaymentStatusFailed
+			}
+		}
+	}
+	return paymentStatus
+}
+
 func processDecodeInvoiceRequest(ctx context.Context,
 	request lightning_helpers.DecodeInvoiceRequest) lightning_helpers.DecodeInvoiceResponse {
 
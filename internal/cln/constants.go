@@ -7,6 +7,7 @@ const streamFundsTickerSeconds = 10
 const streamChannelsTickerSeconds = 1 * 60
 const streamClosedChannelsTickerSeconds = 1 * 60
 const streamPeersTickerSeconds = 1 * 60
+const streamHtlcsTickerSeconds = 1 * 60
 
 // Every 15 minutes
 const streamForwardsTickerSeconds = 15 * 60
@@ -14,3 +15,6 @@ const streamInvoicesTickerSeconds = 15 * 60
 const streamNodesTickerSeconds = 15 * 60
 const streamPaymentsTickerSeconds = 15 * 60
 const streamTransactionsTickerSeconds = 15 * 60
+
+// Every 6 hours
+const streamGraphSnapshotsTickerSeconds = 6 * 60 * 60
@@ -0,0 +1,173 @@
+package cln
+
+import (
+	"context"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+	"github.com/lncapital/torq/proto/cln"
+)
+
+// delayedOutputToUsRegex matches the status of onchaind while our time locked output of a unilateral close matures
+var delayedOutputToUsRegex = regexp.MustCompile(`in (\d+) blocks will spend DELAYED_OUTPUT_TO_US`) //nolint:gochecknoglobals
+
+const (
+	// delayedOutputToUsSpent is in the status of onchaind once our time locked output matured and the sweep waits for
+	// its confirmation
+	delayedOutputToUsSpent = "we spent DELAYED_OUTPUT_TO_US"
+	// delayedOutputToUs is in the status of onchaind for as long as our time locked output is unresolved
+	delayedOutputToUs  = "DELAYED_OUTPUT_TO_US"
+	outputsUnresolved  = "outputs unresolved"
+	allOutputsResolved = "All outputs resolved"
+)
+
+func processForceClosingChannelsRequest(ctx context.Context,
+	request lightning_helpers.ForceClosingChannelsRequest) lightning_helpers.ForceClosingChannelsResponse {
+
+	ctx, span := otel.Tracer(name).Start(ctx, "processForceClosingChannelsRequest")
+	defer span.End()
+
+	response := lightning_helpers.ForceClosingChannelsResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+	client := cln.NewNodeClient(connection)
+
+	info, err := client.Getinfo(ctx, &cln.GetinfoRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	peerChannels, err := client.ListPeerChannels(ctx, &cln.ListpeerchannelsRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	response.Channels = make([]lightning_helpers.ForceClosingChannel, 0)
+	for _, channel := range peerChannels.Channels {
+		if !isForceClose(channel.GetState(), channel.Status) {
+			continue
+		}
+		response.Channels = append(response.Channels, getForceClosingChannel(channel, info.Blockheight))
+	}
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+// isForceClose returns true for a unilateral close, onchaind reports whether it's tracking a unilateral or a mutual
+// close.
+func isForceClose(state cln.ListpeerchannelsChannels_ListpeerchannelsChannelsState, status []string) bool {
+	switch state {
+	case cln.ListpeerchannelsChannels_AWAITING_UNILATERAL:
+		return true
+	case cln.ListpeerchannelsChannels_FUNDING_SPEND_SEEN, cln.ListpeerchannelsChannels_ONCHAIN:
+		for _, description := range status {
+			if strings.Contains(description, "unilateral") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func getForceClosingChannel(channel *cln.ListpeerchannelsChannels,
+	blockHeight uint32) lightning_helpers.ForceClosingChannel {
+
+	forceClosingChannel := lightning_helpers.ForceClosingChannel{
+		RemotePublicKey: hex.EncodeToString(channel.PeerId),
+		WaitingClose:    channel.GetState() == cln.ListpeerchannelsChannels_AWAITING_UNILATERAL,
+		AnchorState:     core.UnknownEnumString,
+		Status:          channel.Status,
+		PendingHtlcs:    make([]lightning_helpers.PendingHtlc, 0, len(channel.Htlcs)),
+	}
+	if channel.FundingTxid != nil && channel.FundingOutnum != nil {
+		forceClosingChannel.ChannelPoint = core.CreateChannelPoint(hex.EncodeToString(channel.FundingTxid),
+			int(*channel.FundingOutnum))
+	}
+	if channel.ToUsMsat != nil {
+		// Our balance stays in limbo until onchaind resolved our time locked output, when it was not time locked or
+		// it has been swept it's back in the wallet.
+		if isDelayedOutputResolved(channel.Status) {
+			forceClosingChannel.RecoveredBalanceSat = int64(channel.ToUsMsat.Msat / 1_000)
+		} else {
+			forceClosingChannel.LimboBalanceSat = int64(channel.ToUsMsat.Msat / 1_000)
+		}
+	}
+	if blocksTilMaturity := getBlocksTilMaturity(channel.Status); blocksTilMaturity != nil {
+		maturityHeight := blockHeight + uint32(*blocksTilMaturity)
+		forceClosingChannel.MaturityHeight = &maturityHeight
+		forceClosingChannel.BlocksTilMaturity = blocksTilMaturity
+	}
+	for _, htlc := range channel.Htlcs {
+		pendingHtlc := lightning_helpers.PendingHtlc{
+			Incoming: htlc.Direction != nil && *htlc.Direction == cln.ListpeerchannelsChannelsHtlcs_IN,
+		}
+		if htlc.AmountMsat != nil {
+			pendingHtlc.AmountSat = int64(htlc.AmountMsat.Msat / 1_000)
+		}
+		if htlc.Expiry != nil {
+			pendingHtlc.MaturityHeight = *htlc.Expiry
+			pendingHtlc.BlocksTilMaturity = int32(int64(*htlc.Expiry) - int64(blockHeight))
+		}
+		forceClosingChannel.PendingHtlcs = append(forceClosingChannel.PendingHtlcs, pendingHtlc)
+	}
+	return forceClosingChannel
+}
+
+// getBlocksTilMaturity parses the blocks until our time locked output can be spent from the onchaind status. Once it
+// matured onchaind reports that it waits for the confirmation of the sweep and the maturity is 0 blocks, the exact
+// maturity height is no longer reported so the current height is used.
+func getBlocksTilMaturity(status []string) *int32 {
+	for _, description := range status {
+		if strings.Contains(description, delayedOutputToUsSpent) {
+			blocksTilMaturity := int32(0)
+			return &blocksTilMaturity
+		}
+		match := delayedOutputToUsRegex.FindStringSubmatch(description)
+		if match == nil {
+			continue
+		}
+		blocks, err := strconv.ParseInt(match[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		blocksTilMaturity := int32(blocks)
+		return &blocksTilMaturity
+	}
+	return nil
+}
+
+// isDelayedOutputResolved returns true when onchaind no longer tracks our time locked output: all outputs are resolved
+// or the unresolved outputs don't include it. Before onchaind reports its outputs the balance is still in limbo.
+func isDelayedOutputResolved(status []string) bool {
+	listsOutputs := false
+	for _, description := range status {
+		if strings.Contains(description, allOutputsResolved) {
+			return true
+		}
+		if strings.Contains(description, delayedOutputToUs) {
+			return false
+		}
+		if strings.Contains(description, outputsUnresolved) {
+			listsOutputs = true
+		}
+	}
+	return listsOutputs
+}
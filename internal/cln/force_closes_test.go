@@ -0,0 +1,107 @@
+package cln
+
+import (
+	"testing"
+
+	"github.com/lncapital/torq/proto/cln"
+)
+
+func TestIsForceClose(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  cln.ListpeerchannelsChannels_ListpeerchannelsChannelsState
+		status []string
+		want   bool
+	}{
+		{"awaiting unilateral", cln.ListpeerchannelsChannels_AWAITING_UNILATERAL, nil, true},
+		{"onchain unilateral", cln.ListpeerchannelsChannels_ONCHAIN,
+			[]string{"ONCHAIN:Tracking our own unilateral close"}, true},
+		{"onchain mutual", cln.ListpeerchannelsChannels_ONCHAIN,
+			[]string{"ONCHAIN:Tracking mutual close transaction"}, false},
+		{"normal", cln.ListpeerchannelsChannels_CHANNELD_NORMAL, []string{"unilateral"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isForceClose(test.state, test.status); got != test.want {
+				t.Errorf("isForceClose() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetBlocksTilMaturity(t *testing.T) {
+	blocksTilMaturity := getBlocksTilMaturity([]string{
+		"ONCHAIN:Tracking our own unilateral close",
+		"ONCHAIN:1 outputs unresolved: in 143 blocks will spend DELAYED_OUTPUT_TO_US (abc:0) using OUR_DELAYED_RETURN_TO_WALLET",
+	})
+	if blocksTilMaturity == nil || *blocksTilMaturity != 143 {
+		t.Fatalf("expected 143 blocks til maturity, got %v", blocksTilMaturity)
+	}
+
+	blocksTilMaturity = getBlocksTilMaturity([]string{
+		"ONCHAIN:1 outputs unresolved: waiting confirmation that we spent DELAYED_OUTPUT_TO_US (abc:0) using OUR_DELAYED_RETURN_TO_WALLET",
+	})
+	if blocksTilMaturity == nil || *blocksTilMaturity != 0 {
+		t.Fatalf("expected 0 blocks til maturity for a matured output, got %v", blocksTilMaturity)
+	}
+
+	if getBlocksTilMaturity([]string{"ONCHAIN:All outputs resolved"}) != nil {
+		t.Fatal("expected no blocks til maturity for resolved outputs")
+	}
+}
+
+func TestGetForceClosingChannelBalance(t *testing.T) {
+	toUs := &cln.Amount{Msat: 150_000_000}
+	tests := []struct {
+		name                  string
+		state                 cln.ListpeerchannelsChannels_ListpeerchannelsChannelsState
+		status                []string
+		wantLimboSat          int64
+		wantRecoveredSat      int64
+		wantBlocksTilMaturity *int32
+	}{
+		{"awaiting unilateral", cln.ListpeerchannelsChannels_AWAITING_UNILATERAL, nil, 150_000, 0, nil},
+		{"maturing", cln.ListpeerchannelsChannels_ONCHAIN, []string{
+			"ONCHAIN:Tracking our own unilateral close",
+			"ONCHAIN:1 outputs unresolved: in 143 blocks will spend DELAYED_OUTPUT_TO_US (abc:0) using OUR_DELAYED_RETURN_TO_WALLET",
+		}, 150_000, 0, int32Pointer(143)},
+		{"matured", cln.ListpeerchannelsChannels_ONCHAIN, []string{
+			"ONCHAIN:Tracking our own unilateral close",
+			"ONCHAIN:1 outputs unresolved: waiting confirmation that we spent DELAYED_OUTPUT_TO_US (abc:0) using OUR_DELAYED_RETURN_TO_WALLET",
+		}, 150_000, 0, int32Pointer(0)},
+		{"swept", cln.ListpeerchannelsChannels_ONCHAIN, []string{
+			"ONCHAIN:Tracking our own unilateral close",
+			"ONCHAIN:All outputs resolved: waiting 90 more blocks before forgetting channel",
+		}, 0, 150_000, nil},
+		{"remote close", cln.ListpeerchannelsChannels_ONCHAIN, []string{
+			"ONCHAIN:Tracking their unilateral close",
+			"ONCHAIN:1 outputs unresolved: in 20 blocks will spend THEIR_HTLC (abc:2) using OUR_HTLC_TIMEOUT_TO_US",
+		}, 0, 150_000, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := test.state
+			channel := getForceClosingChannel(&cln.ListpeerchannelsChannels{
+				State:    &state,
+				ToUsMsat: toUs,
+				Status:   test.status,
+			}, 800_000)
+			if channel.LimboBalanceSat != test.wantLimboSat || channel.RecoveredBalanceSat != test.wantRecoveredSat {
+				t.Errorf("balance = %v limbo %v recovered, want %v limbo %v recovered", channel.LimboBalanceSat,
+					channel.RecoveredBalanceSat, test.wantLimboSat, test.wantRecoveredSat)
+			}
+			if (channel.BlocksTilMaturity == nil) != (test.wantBlocksTilMaturity == nil) ||
+				channel.BlocksTilMaturity != nil && *channel.BlocksTilMaturity != *test.wantBlocksTilMaturity {
+				t.Errorf("BlocksTilMaturity = %v, want %v", channel.BlocksTilMaturity, test.wantBlocksTilMaturity)
+			}
+			if channel.BlocksTilMaturity != nil &&
+				*channel.MaturityHeight != 800_000+uint32(*channel.BlocksTilMaturity) {
+				t.Errorf("MaturityHeight = %v", *channel.MaturityHeight)
+			}
+		})
+	}
+}
+
+func int32Pointer(value int32) *int32 {
+	return &value
+}
@@ -3,7 +3,6 @@ package cln
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -19,14 +18,6 @@ import (
 	"github.com/lncapital/torq/proto/cln"
 )
 
-type forwardStatus int
-
-const (
-	offered = forwardStatus(iota)
-	remoteFailed
-	localFailed
-)
-
 type client_ListForwards interface {
 	ListForwards(ctx context.Context,
 		in *cln.ListforwardsRequest,
@@ -92,45 +83,20 @@ func listAndProcessForwards(ctx context.Context, db *sqlx.DB, client client_List
 		}
 	}
 
-	ctx, span = otel.Tracer(name).Start(ctx, "listAndProcessForwards")
-	span.SetAttributes(attribute.String("Status", cln.ListforwardsRequest_SETTLED.String()))
-	err := processForwards(ctx, db, client, serviceType,
-		cln.ListforwardsRequest_SETTLED, unprocessedShortChannelIds, nodeSettings, bootStrapping)
+	err := processForwards(ctx, db, client, serviceType, unprocessedShortChannelIds, nodeSettings, bootStrapping)
 	if err != nil {
 		return errors.Wrapf(err, "processing of forwards failed")
 	}
-	span.End()
 
 	if cache.GetNodeConnectionDetails(nodeSettings.NodeId).CustomSettings.
 		HasNodeConnectionDetailCustomSettings(core.ImportHtlcEvents) {
 
-		ctx, span = otel.Tracer(name).Start(ctx, "listAndProcessForwards")
-		span.SetAttributes(attribute.String("Status", cln.ListforwardsRequest_OFFERED.String()))
-		err = processForwards(ctx, db, client, serviceType,
-			cln.ListforwardsRequest_OFFERED, unprocessedShortChannelIds, nodeSettings, bootStrapping)
-		if err != nil {
-			return errors.Wrapf(err, "processing of forwards failed")
-		}
-		span.End()
-
-		ctx, span = otel.Tracer(name).Start(ctx, "listAndProcessForwards")
-		span.SetAttributes(attribute.String("Status", cln.ListforwardsRequest_LOCAL_FAILED.String()))
-		err = processForwards(ctx, db, client, serviceType,
-			cln.ListforwardsRequest_LOCAL_FAILED, unprocessedShortChannelIds, nodeSettings, bootStrapping)
-		if err != nil {
-			return errors.Wrapf(err, "processing of forwards failed")
-		}
-		span.End()
-
-		ctx, span = otel.Tracer(name).Start(ctx, "listAndProcessForwards")
-		span.SetAttributes(attribute.String("Status", cln.ListforwardsRequest_FAILED.String()))
-		err = processForwards(ctx, db, client, serviceType,
-			cln.ListforwardsRequest_FAILED, unprocessedShortChannelIds, nodeSettings, bootStrapping)
+		err = listAndProcessFailedHtlcs(ctx, db, client, unprocessedShortChannelIds, nodeSettings)
 		if err != nil {
-			return errors.Wrapf(err, "processing of forwards failed")
+			return errors.Wrapf(err, "processing of failed HTLCs failed")
 		}
-		span.End()
 	}
+
 	for _, closedChannelId := range closedChannelIds {
 		channelSetting := cache.GetChannelSettingByChannelId(closedChannelId)
 		channelSetting.AddChannelFlags(core.ImportedForwards)
@@ -154,11 +120,11 @@ func processForwards(ctx context.Context,
 	db *sqlx.DB,
 	client client_ListForwards,
 	serviceType services_helpers.ServiceType,
-	clnStatus cln.ListforwardsRequest_ListforwardsStatus,
 	unprocessedShortChannelIds []string,
 	nodeSettings cache.NodeSettingsCache,
 	bootStrapping bool) error {
 
+	clnStatus := cln.ListforwardsRequest_SETTLED
 	for ix, shortChannelId := range unprocessedShortChannelIds {
 		ctx, span := otel.Tracer(name).Start(ctx, "processForwards")
 		span.SetAttributes(attribute.String("InChannel-ShortChannelId", shortChannelId))
@@ -166,7 +132,7 @@ func processForwards(ctx context.Context,
 		if err != nil {
 			return errors.Wrapf(err, "listing %v forwards for nodeId: %v", clnStatus.String(), nodeSettings.NodeId)
 		}
-		err = storeForwards(ctx, db, clnStatus, clnForwards.Forwards, shortChannelId, "", nodeSettings)
+		err = storeForwards(ctx, db, clnForwards.Forwards, shortChannelId, "", nodeSettings)
 		if err != nil {
 			return errors.Wrapf(err, "storing %v forwards for nodeId: %v", clnStatus.String(), nodeSettings.NodeId)
 		}
@@ -179,7 +145,7 @@ func processForwards(ctx context.Context,
 			return errors.Wrapf(err, "listing %v forwards for nodeId: %v", clnStatus.String(), nodeSettings.NodeId)
 		}
 
-		err = storeForwards(ctx, db, clnStatus, clnForwards.Forwards, "", shortChannelId, nodeSettings)
+		err = storeForwards(ctx, db, clnForwards.Forwards, "", shortChannelId, nodeSettings)
 		if err != nil {
 			return errors.Wrapf(err, "storing %v forwards for nodeId: %v", clnStatus.String(), nodeSettings.NodeId)
 		}
@@ -193,7 +159,6 @@ func processForwards(ctx context.Context,
 
 func storeForwards(ctx context.Context,
 	db *sqlx.DB,
-	clnStatus cln.ListforwardsRequest_ListforwardsStatus,
 	clnForwards []*cln.ListforwardsForwards,
 	incomingShortChannelId string,
 	outgoingShortChannelId string,
@@ -209,41 +174,16 @@ func storeForwards(ctx context.Context,
 		channelId = cache.GetChannelIdByShortChannelId(&outgoingShortChannelId)
 	}
 
-	var status forwardStatus
 	var latestForward *time.Time
 	var err error
-	if clnStatus == cln.ListforwardsRequest_SETTLED {
-		if incomingShortChannelId != "" {
-			err = db.Get(&latestForward,
-				`SELECT MAX(time) FROM forward WHERE node_id=$1 AND incoming_channel_id=$2;`,
-				nodeSettings.NodeId, channelId)
-		} else {
-			err = db.Get(&latestForward,
-				`SELECT MAX(time) FROM forward WHERE node_id=$1 AND outgoing_channel_id=$2;`,
-				nodeSettings.NodeId, channelId)
-		}
+	if incomingShortChannelId != "" {
+		err = db.Get(&latestForward,
+			`SELECT MAX(time) FROM forward WHERE node_id=$1 AND incoming_channel_id=$2;`,
+			nodeSettings.NodeId, channelId)
 	} else {
-		switch clnStatus {
-		case cln.ListforwardsRequest_OFFERED:
-			status = offered
-		case cln.ListforwardsRequest_FAILED:
-			status = remoteFailed
-		case cln.ListforwardsRequest_LOCAL_FAILED:
-			status = localFailed
-		}
-		if incomingShortChannelId != "" {
-			err = db.Get(&latestForward,
-				`SELECT MAX(time)
-					   FROM htlc_event
-					   WHERE node_id=$1 AND cln_forward_status_id=$2 AND incoming_channel_id=$3;`,
-				nodeSettings.NodeId, status, channelId)
-		} else {
-			err = db.Get(&latestForward,
-				`SELECT MAX(time)
-					   FROM htlc_event
-					   WHERE node_id=$1 AND cln_forward_status_id=$2 AND outgoing_channel_id=$3;`,
-				nodeSettings.NodeId, status, channelId)
-		}
+		err = db.Get(&latestForward,
+			`SELECT MAX(time) FROM forward WHERE node_id=$1 AND outgoing_channel_id=$2;`,
+			nodeSettings.NodeId, channelId)
 	}
 	if err != nil {
 		if !errors.Is(err, sql.ErrNoRows) {
@@ -265,11 +205,7 @@ func storeForwards(ctx context.Context,
 		if eventTime.Before(*latestForward) {
 			continue
 		}
-		if clnStatus == cln.ListforwardsRequest_SETTLED {
-			err = storeForward(db, clnForward, nodeSettings)
-		} else {
-			err = storeHtlc(db, status, clnForward, nodeSettings)
-		}
+		err = storeForward(db, clnForward, nodeSettings)
 		if err != nil {
 			return errors.Wrapf(err, "persisting forward for nodeId: %v", nodeSettings.NodeId)
 		}
@@ -331,63 +267,7 @@ func storeForward(db *sqlx.DB,
 	if err != nil {
 		return errors.Wrap(err, "Executing SQL")
 	}
-	return nil
-}
-
-func storeHtlc(db *sqlx.DB,
-	status forwardStatus,
-	clnForward *cln.ListforwardsForwards,
-	nodeSettings cache.NodeSettingsCache) error {
-
-	forwardTime := time.Unix(int64(clnForward.ReceivedTime), 0).UTC()
-	var inMsat uint64
-	if clnForward.InMsat != nil {
-		inMsat = clnForward.InMsat.Msat
-	}
-	var outMsat uint64
-	if clnForward.OutMsat != nil {
-		outMsat = clnForward.OutMsat.Msat
-	}
-
-	incomingShortChannelId := clnForward.InChannel
-	incomingChannelId := cache.GetChannelIdByShortChannelId(&incomingShortChannelId)
-	incomingChannelIdP := &incomingChannelId
-	if incomingChannelId == 0 {
-		log.Error().Msgf("Forward received for a non existing channel (incomingChannelIdP: %v)",
-			incomingShortChannelId)
-		incomingChannelIdP = nil
-	}
-
-	var outgoingShortChannelId string
-	if clnForward.OutChannel != nil {
-		outgoingShortChannelId = *clnForward.OutChannel
-	}
-	outgoingChannelId := cache.GetChannelIdByShortChannelId(&outgoingShortChannelId)
-	outgoingChannelIdP := &outgoingChannelId
-	if outgoingChannelId == 0 {
-		log.Error().Msgf("Forward received for a non existing channel (outgoingShortChannelId: %v)",
-			outgoingShortChannelId)
-		outgoingChannelIdP = nil
-	}
-
-	jb, err := json.Marshal(clnForward)
-	if err != nil {
-		log.Error().Err(err).Msgf("Marshalling HTLC forward %v", clnForward)
-	}
-	_, err = db.Exec(`
-		INSERT INTO htlc_event
-		    (time, data, event_type,
-		     incoming_channel_id, incoming_amt_msat, incoming_htlc_id,
-		     outgoing_channel_id, outgoing_amt_msat, outgoing_htlc_id,
-		     node_id, cln_forward_status_id
-		) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);`,
-		forwardTime, string(jb), "ForwardFailEvent",
-		incomingChannelIdP, inMsat, clnForward.InHtlcId,
-		outgoingChannelIdP, outMsat, clnForward.OutHtlcId,
-		nodeSettings.NodeId, status,
-	)
-	if err != nil {
-		return errors.Wrap(err, "Executing SQL")
-	}
+	services_helpers.GetMetrics().AddForward(nodeSettings.NodeId, incomingChannelIdP, outgoingChannelIdP,
+		inMsat, outMsat, feeMsat)
 	return nil
 }
@@ -231,6 +231,81 @@ func TestStoreHtlc(t *testing.T) {
 	}
 }
 
+func TestListHtlcs(t *testing.T) {
+
+	srv, err := testutil.InitTestDBConn()
+	if err != nil {
+		panic(err)
+	}
+
+	db, cancel, err := srv.NewTestDatabase()
+	defer cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	nodeId, nodeSettings := testutil.Setup(db, cancel)
+
+	expectedHtlc := getExpectedHtlc(nodeId)
+
+	clnHtlc := constructClnHtlc(expectedHtlc)
+	clnHtlc.Status = cln.ListforwardsForwards_OFFERED
+
+	mclient := stubClnListForwards{
+		Forwards: []*cln.ListforwardsForwards{
+			&clnHtlc,
+		},
+		Status: cln.ListforwardsRequest_OFFERED,
+	}
+
+	pendingForwards := make(map[pendingForwardKey]core.HtlcEvent)
+	// run it twice it should be smart enough to ignore the duplication
+	for i := 0; i < 2; i++ {
+		err = listAndProcessHtlcs(context.Background(), &mclient, services_helpers.ClnServiceHtlcsService,
+			nodeSettings, pendingForwards, false)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Problem in listAndProcessHtlcs: %v", err)
+		}
+	}
+
+	if len(pendingForwards) != 1 {
+		testutil.Fatalf(t, "We expected 1 pending forward but got %d", len(pendingForwards))
+	}
+	htlcEvent, exists := pendingForwards[pendingForwardKey{
+		incomingChannelId: expectedHtlc.IncomingChannelId,
+		incomingHtlcId:    expectedHtlc.IncomingHtlcId,
+	}]
+	if !exists {
+		testutil.Fatalf(t, "We expected a pending forward for incoming HTLC %d", expectedHtlc.IncomingHtlcId)
+	}
+	if htlcEvent.OutgoingChannelId == nil || *htlcEvent.OutgoingChannelId != expectedHtlc.OutgoingChannelId ||
+		htlcEvent.OutgoingHtlcId == nil || *htlcEvent.OutgoingHtlcId != expectedHtlc.OutgoingHtlcId {
+		testutil.Errorf(t, "Got:\n%v\nWant outgoing channel %d and HTLC %d\n",
+			htlcEvent, expectedHtlc.OutgoingChannelId, expectedHtlc.OutgoingHtlcId)
+	}
+
+	// the forward is resolved once it's no longer offered
+	mclient.Forwards = []*cln.ListforwardsForwards{}
+	err = listAndProcessHtlcs(context.Background(), &mclient, services_helpers.ClnServiceHtlcsService,
+		nodeSettings, pendingForwards, false)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Problem in listAndProcessHtlcs: %v", err)
+	}
+	if len(pendingForwards) != 0 {
+		testutil.Errorf(t, "We expected no pending forwards but got %d", len(pendingForwards))
+	}
+
+	var recordCount int
+	err = db.QueryRow("select count(*) from htlc_event;").Scan(&recordCount)
+	if err != nil {
+		testutil.Fatalf(t, "We get an error: %v", err)
+	}
+	if recordCount != 0 {
+		testutil.Errorf(t, "We expected no stored offered forwards but stored %d", recordCount)
+	}
+}
+
 func getExpectedForward(nodeId int) Forward {
 	inChannelId := cache.GetChannelIdByChannelPoint(testutil.TestChannelPoint1)
 	outChannelId := cache.GetChannelIdByChannelPoint(testutil.TestChannelPoint2)
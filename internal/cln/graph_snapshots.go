@@ -0,0 +1,120 @@
+package cln
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/graph_snapshots"
+	"github.com/lncapital/torq/internal/services_helpers"
+	"github.com/lncapital/torq/pkg/grpc_helpers"
+	"github.com/lncapital/torq/proto/cln"
+)
+
+type client_ListGraph interface {
+	client_ListNodes
+	ListChannels(ctx context.Context,
+		in *cln.ListchannelsRequest,
+		opts ...grpc.CallOption) (*cln.ListchannelsResponse, error)
+}
+
+func SubscribeAndStoreGraphSnapshots(ctx context.Context,
+	client client_ListGraph,
+	db *sqlx.DB,
+	nodeSettings cache.NodeSettingsCache) {
+
+	serviceType := services_helpers.ClnServiceGraphSnapshotService
+
+	cache.SetInitializingNodeServiceState(serviceType, nodeSettings.NodeId)
+
+	ticker := time.NewTicker(streamGraphSnapshotsTickerSeconds * time.Second)
+	defer ticker.Stop()
+	tickerChannel := ticker.C
+
+	err := listAndStoreGraphSnapshot(ctx, db, client, serviceType, nodeSettings, true)
+	if err != nil {
+		processError(ctx, serviceType, nodeSettings, err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			cache.SetInactiveNodeServiceState(serviceType, nodeSettings.NodeId)
+			return
+		case <-tickerChannel:
+			err = listAndStoreGraphSnapshot(ctx, db, client, serviceType, nodeSettings, false)
+			if err != nil {
+				processError(ctx, serviceType, nodeSettings, err)
+				return
+			}
+		}
+	}
+}
+
+func listAndStoreGraphSnapshot(ctx context.Context, db *sqlx.DB, client client_ListGraph,
+	serviceType services_helpers.ServiceType,
+	nodeSettings cache.NodeSettingsCache,
+	bootStrapping bool) error {
+
+	ctx, span := otel.Tracer(name).Start(ctx, "listAndStoreGraphSnapshot")
+	defer span.End()
+
+	graph := graph_snapshots.NewGraph()
+	clnNodes, err := client.ListNodes(ctx, &cln.ListnodesRequest{},
+		grpc.MaxCallRecvMsgSize(grpc_helpers.GraphRecvMsgSize))
+	if err != nil {
+		return errors.Wrapf(err, "listing nodes for nodeId: %v", nodeSettings.NodeId)
+	}
+	for _, clnNode := range clnNodes.Nodes {
+		var alias string
+		if clnNode.Alias != nil {
+			alias = *clnNode.Alias
+		}
+		graph.AddNode(hex.EncodeToString(clnNode.Nodeid), alias)
+	}
+
+	// Every direction of a channel is listed separately
+	clnChannels, err := client.ListChannels(ctx, &cln.ListchannelsRequest{},
+		grpc.MaxCallRecvMsgSize(grpc_helpers.GraphRecvMsgSize))
+	if err != nil {
+		return errors.Wrapf(err, "listing channels for nodeId: %v", nodeSettings.NodeId)
+	}
+	for _, clnChannel := range clnChannels.Channels {
+		if !clnChannel.Public {
+			continue
+		}
+		var capacity int64
+		if clnChannel.AmountMsat != nil {
+			capacity = int64(clnChannel.AmountMsat.Msat / 1_000)
+		}
+		graph.AddChannelPolicy(clnChannel.ShortChannelId,
+			hex.EncodeToString(clnChannel.Source), hex.EncodeToString(clnChannel.Destination), capacity,
+			&graph_snapshots.Policy{
+				FeeRateMilliMsat: int64(clnChannel.FeePerMillionth),
+				FeeBaseMsat:      int64(clnChannel.BaseFeeMillisatoshi),
+				Disabled:         !clnChannel.Active,
+			})
+	}
+
+	snapshot, err := graph_snapshots.StoreSnapshot(db, nodeSettings.NodeId, graph, time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "storing the graph snapshot for nodeId: %v", nodeSettings.NodeId)
+	}
+	span.SetAttributes(attribute.Int("nodeCount", snapshot.NodeCount),
+		attribute.Int("channelCount", snapshot.ChannelCount))
+
+	if bootStrapping {
+		log.Info().Msgf("Initial graph snapshot is done for nodeId: %v", nodeSettings.NodeId)
+		cache.SetActiveNodeServiceState(serviceType, nodeSettings.NodeId)
+	}
+	return nil
+}
@@ -0,0 +1,383 @@
+package cln
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/services_helpers"
+	"github.com/lncapital/torq/proto/cln"
+)
+
+type forwardStatus int
+
+const (
+	// offered forwards are not stored, they are tracked in memory until they are resolved
+	offered = forwardStatus(iota)
+	remoteFailed
+	localFailed
+)
+
+// failedHtlcStatuses are the listforwards statuses that are stored as HTLC events by the forwards service, settled
+// forwards are stored as forwards.
+var failedHtlcStatuses = []cln.ListforwardsRequest_ListforwardsStatus{ //nolint:gochecknoglobals
+	cln.ListforwardsRequest_FAILED,
+	cln.ListforwardsRequest_LOCAL_FAILED,
+}
+
+// pendingForwardKey identifies an offered forward, the HTLC id is unique per incoming channel.
+type pendingForwardKey struct {
+	incomingChannelId int
+	incomingHtlcId    uint64
+}
+
+// SubscribeAndProcessHtlcs polls the offered forwards of a CLN node and publishes them as HTLC events to the channel
+// state cache: the HTLCs of an offered forward are added to the pending HTLCs of its channels and removed again once
+// the forward is resolved. The balances are updated by the funds service when the forward settled and the failed
+// forwards are stored by the forwards service.
+func SubscribeAndProcessHtlcs(ctx context.Context,
+	client client_ListForwards,
+	nodeSettings cache.NodeSettingsCache) {
+
+	serviceType := services_helpers.ClnServiceHtlcsService
+
+	cache.SetInitializingNodeServiceState(serviceType, nodeSettings.NodeId)
+
+	ticker := time.NewTicker(streamHtlcsTickerSeconds * time.Second)
+	defer ticker.Stop()
+	tickerChannel := ticker.C
+
+	pendingForwards := make(map[pendingForwardKey]core.HtlcEvent)
+	err := listAndProcessHtlcs(ctx, client, serviceType, nodeSettings, pendingForwards, true)
+	if err != nil {
+		processError(ctx, serviceType, nodeSettings, err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			cache.SetInactiveNodeServiceState(serviceType, nodeSettings.NodeId)
+			return
+		case <-tickerChannel:
+			err = listAndProcessHtlcs(ctx, client, serviceType, nodeSettings, pendingForwards, false)
+			if err != nil {
+				processError(ctx, serviceType, nodeSettings, err)
+				return
+			}
+		}
+	}
+}
+
+func listAndProcessHtlcs(ctx context.Context,
+	client client_ListForwards,
+	serviceType services_helpers.ServiceType,
+	nodeSettings cache.NodeSettingsCache,
+	pendingForwards map[pendingForwardKey]core.HtlcEvent,
+	bootStrapping bool) error {
+
+	ctx, span := otel.Tracer(name).Start(ctx, "listAndProcessHtlcs")
+	defer span.End()
+
+	clnStatus := cln.ListforwardsRequest_OFFERED
+	offeredForwards := make(map[pendingForwardKey]core.HtlcEvent)
+	for _, shortChannelId := range getOpenShortChannelIds(nodeSettings.NodeId) {
+		shortChannelId := shortChannelId
+		// Every forward has an incoming channel so only listing by incoming channel avoids duplicates.
+		clnForwards, err := client.ListForwards(ctx,
+			&cln.ListforwardsRequest{InChannel: &shortChannelId, Status: &clnStatus})
+		if err != nil {
+			return errors.Wrapf(err, "listing %v forwards for nodeId: %v", clnStatus.String(), nodeSettings.NodeId)
+		}
+		for _, clnForward := range clnForwards.Forwards {
+			if key, htlcEvent, ok := getOfferedHtlcEvent(clnForward, nodeSettings.NodeId); ok {
+				offeredForwards[key] = htlcEvent
+			}
+		}
+	}
+
+	offeredHtlcEvents, resolvedHtlcEvents := updatePendingForwards(pendingForwards, offeredForwards)
+	for _, htlcEvent := range offeredHtlcEvents {
+		publishHtlcEvent(htlcEvent, true)
+	}
+	for _, htlcEvent := range resolvedHtlcEvents {
+		publishHtlcEvent(htlcEvent, false)
+	}
+
+	if bootStrapping {
+		log.Info().Msgf("Initial import of HTLC events is done for nodeId: %v", nodeSettings.NodeId)
+		cache.SetActiveNodeServiceState(serviceType, nodeSettings.NodeId)
+	}
+	return nil
+}
+
+func getOpenShortChannelIds(nodeId int) []string {
+	var shortChannelIds []string
+	for _, channel := range cache.GetChannelSettingsByNodeId(nodeId) {
+		if channel.ShortChannelId == nil || channel.Status >= core.CooperativeClosed {
+			continue
+		}
+		shortChannelIds = append(shortChannelIds, *channel.ShortChannelId)
+	}
+	return shortChannelIds
+}
+
+// getOfferedHtlcEvent maps an offered forward on the HTLC event of LND's ForwardEvent.
+func getOfferedHtlcEvent(clnForward *cln.ListforwardsForwards,
+	nodeId int) (pendingForwardKey, core.HtlcEvent, bool) {
+
+	if clnForward == nil || clnForward.InHtlcId == nil {
+		return pendingForwardKey{}, core.HtlcEvent{}, false
+	}
+	incomingShortChannelId := clnForward.InChannel
+	incomingChannelId := cache.GetChannelIdByShortChannelId(&incomingShortChannelId)
+	if incomingChannelId == 0 {
+		return pendingForwardKey{}, core.HtlcEvent{}, false
+	}
+	eventType := "ForwardEvent"
+	htlcEvent := core.HtlcEvent{
+		EventData: core.EventData{
+			EventTime: time.Now().UTC(),
+			NodeId:    nodeId,
+		},
+		Timestamp:         time.Unix(int64(clnForward.ReceivedTime), 0).UTC(),
+		EventType:         &eventType,
+		IncomingChannelId: &incomingChannelId,
+		IncomingHtlcId:    clnForward.InHtlcId,
+	}
+	if clnForward.InMsat != nil {
+		htlcEvent.IncomingAmtMsat = &clnForward.InMsat.Msat
+	}
+	if clnForward.OutChannel != nil && clnForward.OutHtlcId != nil {
+		outgoingChannelId := cache.GetChannelIdByShortChannelId(clnForward.OutChannel)
+		if outgoingChannelId != 0 {
+			htlcEvent.OutgoingChannelId = &outgoingChannelId
+			htlcEvent.OutgoingHtlcId = clnForward.OutHtlcId
+			if clnForward.OutMsat != nil {
+				htlcEvent.OutgoingAmtMsat = &clnForward.OutMsat.Msat
+			}
+		}
+	}
+	return pendingForwardKey{incomingChannelId: incomingChannelId, incomingHtlcId: *clnForward.InHtlcId}, htlcEvent,
+		true
+}
+
+// updatePendingForwards replaces the pending forwards with the offered forwards and returns the HTLC events of the
+// forwards that were offered since the previous poll and of the forwards that were resolved since then.
+func updatePendingForwards(pendingForwards map[pendingForwardKey]core.HtlcEvent,
+	offeredForwards map[pendingForwardKey]core.HtlcEvent) ([]core.HtlcEvent, []core.HtlcEvent) {
+
+	var offeredHtlcEvents []core.HtlcEvent
+	for key, htlcEvent := range offeredForwards {
+		if _, exists := pendingForwards[key]; !exists {
+			pendingForwards[key] = htlcEvent
+			offeredHtlcEvents = append(offeredHtlcEvents, htlcEvent)
+		}
+	}
+	var resolvedHtlcEvents []core.HtlcEvent
+	for key, htlcEvent := range pendingForwards {
+		if _, exists := offeredForwards[key]; !exists {
+			delete(pendingForwards, key)
+			resolvedHtlcEvents = append(resolvedHtlcEvents, htlcEvent)
+		}
+	}
+	return offeredHtlcEvents, resolvedHtlcEvents
+}
+
+// publishHtlcEvent adds (pending) or removes the HTLCs of a forward from the pending HTLCs of its channels. The
+// channels service also sets the pending HTLCs from listpeerchannels so a channel is only updated when it doesn't
+// match yet, the channel state cache toggles an HTLC on every event.
+func publishHtlcEvent(htlcEvent core.HtlcEvent, pending bool) {
+	if htlcEvent.IncomingChannelId != nil && htlcEvent.IncomingAmtMsat != nil &&
+		isPendingHtlc(htlcEvent.NodeId, *htlcEvent.IncomingChannelId, true, *htlcEvent.IncomingHtlcId) != pending {
+
+		incomingHtlcEvent := htlcEvent
+		incomingHtlcEvent.OutgoingChannelId = nil
+		incomingHtlcEvent.OutgoingHtlcId = nil
+		incomingHtlcEvent.OutgoingAmtMsat = nil
+		cache.SetChannelStateBalanceHtlcEvent(incomingHtlcEvent, core.BalanceUpdateForwardEvent)
+	}
+	if htlcEvent.OutgoingChannelId != nil && htlcEvent.OutgoingAmtMsat != nil &&
+		isPendingHtlc(htlcEvent.NodeId, *htlcEvent.OutgoingChannelId, false, *htlcEvent.OutgoingHtlcId) != pending {
+
+		outgoingHtlcEvent := htlcEvent
+		outgoingHtlcEvent.IncomingChannelId = nil
+		outgoingHtlcEvent.IncomingHtlcId = nil
+		outgoingHtlcEvent.IncomingAmtMsat = nil
+		cache.SetChannelStateBalanceHtlcEvent(outgoingHtlcEvent, core.BalanceUpdateForwardEvent)
+	}
+}
+
+func isPendingHtlc(nodeId int, channelId int, incoming bool, htlcIndex uint64) bool {
+	channelState := cache.GetChannelState(nodeId, channelId, true)
+	if channelState == nil {
+		return false
+	}
+	for _, htlc := range channelState.PendingHtlcs {
+		if htlc.Incoming == incoming && htlc.HtlcIndex == htlcIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// listAndProcessFailedHtlcs stores the failed forwards of the channels as HTLC events.
+func listAndProcessFailedHtlcs(ctx context.Context,
+	db *sqlx.DB,
+	client client_ListForwards,
+	shortChannelIds []string,
+	nodeSettings cache.NodeSettingsCache) error {
+
+	for ix := range failedHtlcStatuses {
+		clnStatus := failedHtlcStatuses[ix]
+		for jx, shortChannelId := range shortChannelIds {
+			ctx, span := otel.Tracer(name).Start(ctx, "processFailedHtlcs")
+			span.SetAttributes(attribute.String("Status", clnStatus.String()),
+				attribute.String("InChannel-ShortChannelId", shortChannelId))
+			// Every HTLC event has an incoming channel so only listing by incoming channel avoids duplicates.
+			clnForwards, err := client.ListForwards(ctx,
+				&cln.ListforwardsRequest{InChannel: &shortChannelIds[jx], Status: &clnStatus})
+			if err != nil {
+				span.End()
+				return errors.Wrapf(err, "listing %v forwards for nodeId: %v", clnStatus.String(), nodeSettings.NodeId)
+			}
+			err = storeHtlcs(db, getForwardStatus(clnStatus), clnForwards.Forwards, shortChannelId, nodeSettings)
+			span.End()
+			if err != nil {
+				return errors.Wrapf(err, "storing %v HTLC events for nodeId: %v", clnStatus.String(), nodeSettings.NodeId)
+			}
+		}
+	}
+	return nil
+}
+
+func getForwardStatus(clnStatus cln.ListforwardsRequest_ListforwardsStatus) forwardStatus {
+	if clnStatus == cln.ListforwardsRequest_LOCAL_FAILED {
+		return localFailed
+	}
+	return remoteFailed
+}
+
+func storeHtlcs(db *sqlx.DB,
+	status forwardStatus,
+	clnForwards []*cln.ListforwardsForwards,
+	incomingShortChannelId string,
+	nodeSettings cache.NodeSettingsCache) error {
+
+	channelId := cache.GetChannelIdByShortChannelId(&incomingShortChannelId)
+
+	var latestHtlc *time.Time
+	err := db.Get(&latestHtlc, `
+		SELECT MAX(time)
+		FROM htlc_event
+		WHERE node_id=$1 AND cln_forward_status_id=$2 AND incoming_channel_id=$3;`,
+		nodeSettings.NodeId, status, channelId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return errors.Wrapf(err, "obtaining maximum HTLC event time for nodeId: %v", nodeSettings.NodeId)
+	}
+
+	for _, clnForward := range clnForwards {
+		if clnForward == nil {
+			continue
+		}
+		if latestHtlc != nil {
+			eventTime := time.Unix(int64(clnForward.ReceivedTime), 0).UTC()
+			if eventTime.Before(*latestHtlc) {
+				continue
+			}
+			// The time only has a precision of seconds so the HTLC could already be stored.
+			if eventTime.Equal(*latestHtlc) {
+				var exists bool
+				err = db.Get(&exists, `
+					SELECT EXISTS(
+						SELECT 1
+						FROM htlc_event
+						WHERE node_id=$1 AND cln_forward_status_id=$2 AND incoming_channel_id=$3 AND time=$4 AND
+						      incoming_htlc_id IS NOT DISTINCT FROM $5);`,
+					nodeSettings.NodeId, status, channelId, eventTime, clnForward.InHtlcId)
+				if err != nil {
+					return errors.Wrapf(err, "checking for existing HTLC event for nodeId: %v", nodeSettings.NodeId)
+				}
+				if exists {
+					continue
+				}
+			}
+		}
+		err = storeHtlc(db, status, clnForward, nodeSettings)
+		if err != nil {
+			return errors.Wrapf(err, "persisting HTLC event for nodeId: %v", nodeSettings.NodeId)
+		}
+	}
+	return nil
+}
+
+func storeHtlc(db *sqlx.DB,
+	status forwardStatus,
+	clnForward *cln.ListforwardsForwards,
+	nodeSettings cache.NodeSettingsCache) error {
+
+	forwardTime := time.Unix(int64(clnForward.ReceivedTime), 0).UTC()
+	var inMsat uint64
+	if clnForward.InMsat != nil {
+		inMsat = clnForward.InMsat.Msat
+	}
+	var outMsat uint64
+	if clnForward.OutMsat != nil {
+		outMsat = clnForward.OutMsat.Msat
+	}
+
+	incomingShortChannelId := clnForward.InChannel
+	incomingChannelId := cache.GetChannelIdByShortChannelId(&incomingShortChannelId)
+	incomingChannelIdP := &incomingChannelId
+	if incomingChannelId == 0 {
+		log.Error().Msgf("Forward received for a non existing channel (incomingChannelIdP: %v)",
+			incomingShortChannelId)
+		incomingChannelIdP = nil
+	}
+
+	var outgoingShortChannelId string
+	if clnForward.OutChannel != nil {
+		outgoingShortChannelId = *clnForward.OutChannel
+	}
+	outgoingChannelId := cache.GetChannelIdByShortChannelId(&outgoingShortChannelId)
+	outgoingChannelIdP := &outgoingChannelId
+	if outgoingChannelId == 0 {
+		log.Error().Msgf("Forward received for a non existing channel (outgoingShortChannelId: %v)",
+			outgoingShortChannelId)
+		outgoingChannelIdP = nil
+	}
+
+	eventType := "ForwardFailEvent"
+
+	jb, err := json.Marshal(clnForward)
+	if err != nil {
+		log.Error().Err(err).Msgf("Marshalling HTLC forward %v", clnForward)
+	}
+	_, err = db.Exec(`
+		INSERT INTO htlc_event
+		    (time, data, event_type,
+		     incoming_channel_id, incoming_amt_msat, incoming_htlc_id,
+		     outgoing_channel_id, outgoing_amt_msat, outgoing_htlc_id,
+		     node_id, cln_forward_status_id
+		) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);`,
+		forwardTime, string(jb), eventType,
+		incomingChannelIdP, inMsat, clnForward.InHtlcId,
+		outgoingChannelIdP, outMsat, clnForward.OutHtlcId,
+		nodeSettings.NodeId, status,
+	)
+	if err != nil {
+		return errors.Wrap(err, "Executing SQL")
+	}
+	// CLN does not expose the failure code of a forward
+	services_helpers.GetMetrics().AddHtlcFailure(nodeSettings.NodeId, eventType, clnForward.Status.String())
+	return nil
+}
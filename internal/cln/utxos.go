@@ -0,0 +1,417 @@
+package cln
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/cockroachdb/errors"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+	"github.com/lncapital/torq/proto/cln"
+)
+
+// blockSeconds is the average time between blocks, CLN reserves outputs for a number of blocks
+const blockSeconds = 10 * 60
+
+// defaultReserveBlocks is the CLN default of reserveinputs
+const defaultReserveBlocks = 72
+
+// witnessScaleFactor is the weight of a non-witness byte, the virtual size is the weight divided by it
+const witnessScaleFactor = 4
+
+// cpfpChildVsize is the estimated size of the child of a fee bump, one segwit input and one segwit output
+const cpfpChildVsize = 110
+
+func processListUnspentRequest(ctx context.Context,
+	request lightning_helpers.ListUnspentRequest) lightning_helpers.ListUnspentResponse {
+
+	ctx, span := otel.Tracer(name).Start(ctx, "processListUnspentRequest")
+	defer span.End()
+
+	response := lightning_helpers.ListUnspentResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+	client := cln.NewNodeClient(connection)
+
+	info, err := client.Getinfo(ctx, &cln.GetinfoRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	funds, err := client.ListFunds(ctx, &cln.ListfundsRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	response.Utxos = make([]lightning_helpers.Utxo, 0, len(funds.Outputs))
+	for _, output := range funds.Outputs {
+		if output.Status == cln.ListfundsOutputs_SPENT {
+			continue
+		}
+		var confirmations int64
+		if output.Blockheight != nil && output.Status == cln.ListfundsOutputs_CONFIRMED {
+			confirmations = int64(info.Blockheight) - int64(*output.Blockheight) + 1
+		}
+		if confirmations < int64(request.MinConfs) {
+			continue
+		}
+		utxo := lightning_helpers.Utxo{
+			Outpoint:      core.CreateChannelPoint(hex.EncodeToString(output.Txid), int(output.Output)),
+			TxId:          hex.EncodeToString(output.Txid),
+			OutputIndex:   output.Output,
+			AddressType:   getAddressType(output.Scriptpubkey, len(output.Redeemscript) != 0),
+			Confirmations: confirmations,
+			Leased:        output.Reserved,
+		}
+		if output.AmountMsat != nil {
+			utxo.AmountSat = int64(output.AmountMsat.Msat) / 1_000
+		}
+		if output.Address != nil {
+			utxo.Address = *output.Address
+		}
+		response.Utxos = append(response.Utxos, utxo)
+	}
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+// processLeaseOutputRequest reserves the output through utxopsbt because reserveinputs is not part of the gRPC
+// interface. The reservation is for whole blocks, the expiration is an estimate.
+func processLeaseOutputRequest(ctx context.Context,
+	request lightning_helpers.LeaseOutputRequest) lightning_helpers.LeaseOutputResponse {
+
+	ctx, span := otel.Tracer(name).Start(ctx, "processLeaseOutputRequest")
+	defer span.End()
+
+	response := lightning_helpers.LeaseOutputResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	outpoints, err := getOutpoints([]string{request.Outpoint})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+	client := cln.NewNodeClient(connection)
+
+	info, err := client.Getinfo(ctx, &cln.GetinfoRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	reserveBlocks := uint32(defaultReserveBlocks)
+	if request.ExpirationSeconds != 0 {
+		reserveBlocks = uint32((request.ExpirationSeconds + blockSeconds - 1) / blockSeconds)
+	}
+	rsp, err := client.UtxoPsbt(ctx, &cln.UtxopsbtRequest{
+		Satoshi: &cln.Amount{Msat: 0},
+		Feerate: &cln.Feerate{Style: &cln.Feerate_Slow{Slow: true}},
+		Utxos:   outpoints,
+		Reserve: &reserveBlocks,
+	})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	if len(rsp.Reservations) == 0 {
+		response.Error = "the output was not reserved"
+		return response
+	}
+
+	blocks := int64(rsp.Reservations[0].ReservedToBlock) - int64(info.Blockheight)
+	response.Expiration = time.Now().UTC().Add(time.Duration(blocks*blockSeconds) * time.Second)
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+func getOutpoints(outpoints []string) ([]*cln.Outpoint, error) {
+	clnOutpoints := make([]*cln.Outpoint, 0, len(outpoints))
+	for _, outpoint := range outpoints {
+		txId, outputIndex := core.ParseChannelPoint(outpoint)
+		if txId == nil || outputIndex == nil || *outputIndex < 0 {
+			return nil, errors.Newf("Invalid outpoint %v, use txid:index", outpoint)
+		}
+		txIdBytes, err := hex.DecodeString(*txId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Decoding the txid of outpoint %v", outpoint)
+		}
+		clnOutpoints = append(clnOutpoints, &cln.Outpoint{Txid: txIdBytes, Outnum: uint32(*outputIndex)})
+	}
+	return clnOutpoints, nil
+}
+
+// getAddressType derives the address type from the output script, a pay to script hash output of the wallet is a
+// nested segwit output when it has a redeem script.
+func getAddressType(script []byte, hasRedeemScript bool) string {
+	switch {
+	case len(script) == 22 && script[0] == 0x00 && script[1] == 0x14:
+		return "p2wkh"
+	case len(script) == 34 && script[0] == 0x51 && script[1] == 0x20:
+		return "p2tr"
+	case len(script) == 23 && script[0] == 0xa9 && hasRedeemScript:
+		return "np2wkh"
+	}
+	return core.UnknownEnumString
+}
+
+func processUnconfirmedTransactionsRequest(ctx context.Context,
+	request lightning_helpers.UnconfirmedTransactionsRequest) lightning_helpers.UnconfirmedTransactionsResponse {
+
+	ctx, span := otel.Tracer(name).Start(ctx, "processUnconfirmedTransactionsRequest")
+	defer span.End()
+
+	response := lightning_helpers.UnconfirmedTransactionsResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+
+	client := cln.NewNodeClient(connection)
+	transactions, err := client.ListTransactions(ctx, &cln.ListtransactionsRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	// The spent outputs of the wallet tell which inputs belong to the node
+	spent := true
+	funds, err := client.ListFunds(ctx, &cln.ListfundsRequest{Spent: &spent})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	walletOutpoints := make(map[string]bool)
+	for _, output := range funds.Outputs {
+		walletOutpoints[core.CreateChannelPoint(hex.EncodeToString(output.Txid), int(output.Output))] = true
+	}
+	response.Transactions = make([]lightning_helpers.WalletTransaction, 0)
+	for _, transaction := range transactions.Transactions {
+		if transaction.Blockheight != 0 {
+			continue
+		}
+		walletTransaction := lightning_helpers.WalletTransaction{
+			TxId:     hex.EncodeToString(transaction.Hash),
+			Inputs:   make([]string, 0, len(transaction.Inputs)),
+			Incoming: true,
+		}
+		for _, input := range transaction.Inputs {
+			outpoint := core.CreateChannelPoint(hex.EncodeToString(input.Txid), int(input.Index))
+			walletTransaction.Inputs = append(walletTransaction.Inputs, outpoint)
+			if walletOutpoints[outpoint] {
+				walletTransaction.Incoming = false
+			}
+		}
+		response.Transactions = append(response.Transactions, walletTransaction)
+	}
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+// processBumpFeeRequest spends an unconfirmed output of the node to a new address of the node (CPFP). When an earlier
+// bump already spent the output, the output of that child is spent instead. The fee rate of the child is chosen so the
+// unconfirmed transactions and the child together reach the requested fee rate. CLN bumps the anchors of its force
+// closes itself.
+func processBumpFeeRequest(ctx context.Context,
+	request lightning_helpers.BumpFeeRequest) lightning_helpers.BumpFeeResponse {
+
+	ctx, span := otel.Tracer(name).Start(ctx, "processBumpFeeRequest")
+	defer span.End()
+
+	response := lightning_helpers.BumpFeeResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	if request.SatPerVbyte == nil {
+		response.Error = "CLN requires satPerVbyte to bump the fee"
+		return response
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+	client := cln.NewNodeClient(connection)
+
+	funds, err := client.ListFunds(ctx, &cln.ListfundsRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	transactions, err := client.ListTransactions(ctx, &cln.ListtransactionsRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	chain := getUnconfirmedChain(request.TxId, transactions.Transactions)
+	var outpoint *cln.Outpoint
+	// The last transaction of the chain with an unconfirmed output of the node is bumped together with its ancestors
+	for len(chain) != 0 && outpoint == nil {
+		txId := chain[len(chain)-1]
+		for _, output := range funds.Outputs {
+			if hex.EncodeToString(output.Txid) == txId &&
+				output.Status == cln.ListfundsOutputs_UNCONFIRMED && !output.Reserved {
+				outpoint = &cln.Outpoint{Txid: output.Txid, Outnum: output.Output}
+				break
+			}
+		}
+		if outpoint == nil {
+			chain = chain[:len(chain)-1]
+		}
+	}
+	if outpoint == nil {
+		response.Error = "the transaction has no unconfirmed output of the node to bump the fee with"
+		return response
+	}
+	packageFeeSat, packageVsize := getPackageFeeAndVsize(chain, transactions.Transactions)
+	satPerVbyte := getChildSatPerVbyte(*request.SatPerVbyte, packageFeeSat, packageVsize)
+
+	bech32 := cln.NewaddrRequest_BECH32
+	address, err := client.NewAddr(ctx, &cln.NewaddrRequest{Addresstype: &bech32})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	if address.Bech32 == nil {
+		response.Error = "CLN didn't return a bech32 address"
+		return response
+	}
+
+	minConfs := uint32(0)
+	rsp, err := client.Withdraw(ctx, &cln.WithdrawRequest{
+		Destination: *address.Bech32,
+		Satoshi:     &cln.AmountOrAll{Value: &cln.AmountOrAll_All{All: true}},
+		Feerate:     &cln.Feerate{Style: &cln.Feerate_Perkb{Perkb: uint32(satPerVbyte * 1_000)}},
+		Minconf:     &minConfs,
+		Utxos:       []*cln.Outpoint{outpoint},
+	})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	response.Outpoint = core.CreateChannelPoint(hex.EncodeToString(outpoint.Txid), int(outpoint.Outnum))
+	response.ChildTxId = hex.EncodeToString(rsp.Txid)
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+// getUnconfirmedChain returns the transaction followed by its unconfirmed descendants in the wallet, like the
+// children of earlier bumps.
+func getUnconfirmedChain(txId string, transactions []*cln.ListtransactionsTransactions) []string {
+	chain := []string{txId}
+	for len(chain) <= len(transactions) {
+		child := getUnconfirmedChild(chain[len(chain)-1], transactions)
+		if child == "" || slices.Contains(chain, child) {
+			break
+		}
+		chain = append(chain, child)
+	}
+	return chain
+}
+
+func getUnconfirmedChild(txId string, transactions []*cln.ListtransactionsTransactions) string {
+	for _, transaction := range transactions {
+		if transaction.Blockheight != 0 {
+			continue
+		}
+		for _, input := range transaction.Inputs {
+			if hex.EncodeToString(input.Txid) == txId {
+				return hex.EncodeToString(transaction.Hash)
+			}
+		}
+	}
+	return ""
+}
+
+// getPackageFeeAndVsize returns the fee and the size of the transactions. The fee of a transaction that spends an
+// output outside the wallet, like the funding output of a channel the peer opened, is unknown and counted as 0 so the
+// child pays for it.
+func getPackageFeeAndVsize(txIds []string, transactions []*cln.ListtransactionsTransactions) (int64, int64) {
+	parsed := make(map[string]*wire.MsgTx, len(transactions))
+	for _, transaction := range transactions {
+		var tx wire.MsgTx
+		if tx.Deserialize(bytes.NewReader(transaction.Rawtx)) == nil {
+			parsed[hex.EncodeToString(transaction.Hash)] = &tx
+		}
+	}
+	var packageFeeSat, packageVsize int64
+	for _, txId := range txIds {
+		tx, exists := parsed[txId]
+		if !exists {
+			continue
+		}
+		packageVsize += (int64(tx.SerializeSizeStripped())*(witnessScaleFactor-1) +
+			int64(tx.SerializeSize()) + witnessScaleFactor - 1) / witnessScaleFactor
+		feeSat, known := int64(0), true
+		for _, input := range tx.TxIn {
+			previousTx, exists := parsed[input.PreviousOutPoint.Hash.String()]
+			if !exists || int(input.PreviousOutPoint.Index) >= len(previousTx.TxOut) {
+				known = false
+				break
+			}
+			feeSat += previousTx.TxOut[input.PreviousOutPoint.Index].Value
+		}
+		for _, output := range tx.TxOut {
+			feeSat -= output.Value
+		}
+		if known && feeSat > 0 {
+			packageFeeSat += feeSat
+		}
+	}
+	return packageFeeSat, packageVsize
+}
+
+// getChildSatPerVbyte returns the fee rate of the child that brings the unconfirmed transactions and the child to
+// satPerVbyte, it's never lower than satPerVbyte.
+func getChildSatPerVbyte(satPerVbyte uint64, packageFeeSat int64, packageVsize int64) uint64 {
+	childFeeSat := int64(satPerVbyte)*(packageVsize+cpfpChildVsize) - packageFeeSat
+	childSatPerVbyte := uint64(0)
+	if childFeeSat > 0 {
+		childSatPerVbyte = uint64((childFeeSat + cpfpChildVsize - 1) / cpfpChildVsize)
+	}
+	if childSatPerVbyte < satPerVbyte {
+		return satPerVbyte
+	}
+	return childSatPerVbyte
+}
@@ -0,0 +1,105 @@
+package cln
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/proto/cln"
+)
+
+func newTestTransaction(t *testing.T,
+	blockHeight uint32,
+	inputs []*wire.OutPoint,
+	outputValues ...int64) (*cln.ListtransactionsTransactions, *wire.MsgTx) {
+
+	tx := wire.NewMsgTx(2)
+	for _, input := range inputs {
+		tx.AddTxIn(wire.NewTxIn(input, nil, wire.TxWitness{make([]byte, 26)}))
+	}
+	for _, value := range outputValues {
+		tx.AddTxOut(wire.NewTxOut(value, make([]byte, 22)))
+	}
+	var rawTx bytes.Buffer
+	err := tx.Serialize(&rawTx)
+	if err != nil {
+		t.Fatalf("serializing the transaction: %v", err)
+	}
+	txHash := tx.TxHash()
+	hash, err := hex.DecodeString(txHash.String())
+	if err != nil {
+		t.Fatalf("decoding the transaction hash: %v", err)
+	}
+	transaction := &cln.ListtransactionsTransactions{Hash: hash, Rawtx: rawTx.Bytes(), Blockheight: blockHeight}
+	for _, input := range inputs {
+		txId, err := hex.DecodeString(input.Hash.String())
+		if err != nil {
+			t.Fatalf("decoding the input hash: %v", err)
+		}
+		transaction.Inputs = append(transaction.Inputs,
+			&cln.ListtransactionsTransactionsInputs{Txid: txId, Index: input.Index})
+	}
+	return transaction, tx
+}
+
+func TestBumpFeePackage(t *testing.T) {
+	funding, fundingTx := newTestTransaction(t, 100, []*wire.OutPoint{{Hash: chainhash.Hash{1}}}, 100_000)
+	fundingHash := fundingTx.TxHash()
+	send, sendTx := newTestTransaction(t, 0, []*wire.OutPoint{{Hash: fundingHash}}, 60_000, 39_000)
+	sendHash := sendTx.TxHash()
+	child, childTx := newTestTransaction(t, 0, []*wire.OutPoint{{Hash: sendHash, Index: 1}}, 38_500)
+	transactions := []*cln.ListtransactionsTransactions{funding, send, child}
+
+	chain := getUnconfirmedChain(sendHash.String(), transactions)
+	expected := []string{sendHash.String(), childTx.TxHash().String()}
+	if !slices.Equal(chain, expected) {
+		t.Fatalf("expected the chain %v, got %v", expected, chain)
+	}
+	chain = getUnconfirmedChain(fundingHash.String(), transactions)
+	if len(chain) != 3 {
+		t.Errorf("expected the funding transaction with its 2 unconfirmed descendants, got %v", chain)
+	}
+
+	packageFeeSat, packageVsize := getPackageFeeAndVsize(expected, transactions)
+	if packageFeeSat != 1_000+500 {
+		t.Errorf("expected a package fee of 1500 sat, got %v", packageFeeSat)
+	}
+	vsize := func(tx *wire.MsgTx) int64 {
+		return int64((tx.SerializeSizeStripped()*3 + tx.SerializeSize() + 3) / 4)
+	}
+	if packageVsize != vsize(sendTx)+vsize(childTx) {
+		t.Errorf("expected a package size of %v, got %v", vsize(sendTx)+vsize(childTx), packageVsize)
+	}
+
+	// The fee of the funding transaction is unknown without its parent
+	packageFeeSat, _ = getPackageFeeAndVsize([]string{fundingHash.String()}, transactions)
+	if packageFeeSat != 0 {
+		t.Errorf("expected an unknown fee to count as 0, got %v", packageFeeSat)
+	}
+}
+
+func TestGetChildSatPerVbyte(t *testing.T) {
+	tests := []struct {
+		satPerVbyte   uint64
+		packageFeeSat int64
+		packageVsize  int64
+		expected      uint64
+	}{
+		// (10 * (200 + 110) - 200) / 110 rounded up
+		{10, 200, 200, 27},
+		// The parent already pays more than the requested rate
+		{10, 5_000, 200, 10},
+		{5, 0, 0, 5},
+	}
+	for _, test := range tests {
+		childSatPerVbyte := getChildSatPerVbyte(test.satPerVbyte, test.packageFeeSat, test.packageVsize)
+		if childSatPerVbyte != test.expected {
+			t.Errorf("getChildSatPerVbyte(%v, %v, %v) = %v, want %v",
+				test.satPerVbyte, test.packageFeeSat, test.packageVsize, childSatPerVbyte, test.expected)
+		}
+	}
+}
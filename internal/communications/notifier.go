@@ -217,7 +217,8 @@ func HandleNotification(db *sqlx.DB, notifierEvent core.NotifierEvent) {
 	var err error
 	var communications []Communication
 	switch notifierEvent.NotificationType {
-	case core.NodeDetails:
+	case core.NodeDetails, core.PeerReliability, core.PeerReconnect, core.DeferredOperation, core.ForceClose,
+		core.InterruptedOperation:
 		communications, err = GetCommunicationsForNodeDetails(db,
 			notifierEvent.NodeId,
 			CommunicationTelegramHighPriority, CommunicationTelegramLowPriority, CommunicationSlack)
@@ -97,8 +97,9 @@ const (
 	ImportInvoices
 	ImportForwards
 	ImportHistoricForwards
+	ImportGraphSnapshots
 )
-const NodeConnectionDetailCustomSettingsMax = int(ImportHistoricForwards)*2 - 1
+const NodeConnectionDetailCustomSettingsMax = int(ImportGraphSnapshots)*2 - 1
 
 const (
 	MEMPOOL string = "https://mempool.space/lightning/channel/"
@@ -118,6 +119,11 @@ type NotificationType int
 
 const (
 	NodeDetails NotificationType = iota
+	PeerReliability
+	PeerReconnect
+	DeferredOperation
+	ForceClose
+	InterruptedOperation
 )
 
 type NodeConnectionSetting int
@@ -271,6 +271,7 @@ func GetNodeConnectionDetailCustomSettings() []NodeConnectionDetailCustomSetting
 		ImportInvoices,
 		ImportForwards,
 		ImportHistoricForwards,
+		ImportGraphSnapshots,
 	}
 }
 
@@ -3,6 +3,9 @@ package database
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/jmoiron/sqlx"
@@ -10,49 +13,140 @@ import (
 	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/jmoiron/sqlx/splunksqlx"
 )
 
-func PgConnect(dbName string, user string, password string, host string, port string) (*sqlx.DB, error) {
-	db, err := splunksqlx.Connect("postgres",
-		fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-			host, port, user, password, dbName))
+// ConnectionSettings configure the connection (pool) to the Torq database.
+type ConnectionSettings struct {
+	Name     string
+	User     string
+	Password string
+	Host     string
+	Port     string
+	// SslMode is one of disable, require, verify-ca or verify-full
+	SslMode     string
+	SslRootCert string
+	SslCert     string
+	SslKey      string
+	// ConnectTimeout, StatementTimeout, ConnMaxLifetime and ConnMaxIdleTime are disabled when 0
+	ConnectTimeout   time.Duration
+	StatementTimeout time.Duration
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+}
+
+func PgConnect(settings ConnectionSettings) (*sqlx.DB, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	db, err := splunksqlx.Connect("postgres", settings.dsn(settings.User, settings.Name))
 	if err != nil {
-		defaultDB, err := splunksqlx.Connect("postgres",
-			fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-				host, port, "postgres", password, "postgres"))
+		defaultDB, err := splunksqlx.Connect("postgres", settings.dsn("postgres", "postgres"))
 		if err != nil {
 			return nil, errors.Wrapf(err, "default database connect")
 		}
 		defer defaultDB.Close()
 
-		userExists, err := checkUserExists(defaultDB, user)
+		userExists, err := checkUserExists(defaultDB, settings.User)
 		if err != nil {
 			return nil, errors.Wrap(err, "pg connect")
 		}
 		if !userExists {
 			log.Println("Creating database user")
-			if err := createUser(defaultDB, user, password); err != nil {
+			if err := createUser(defaultDB, settings.User, settings.Password); err != nil {
 				return nil, errors.Wrap(err, "pg connect")
 			}
 		}
-		dbExists, err := checkDatabaseExists(defaultDB, dbName)
+		dbExists, err := checkDatabaseExists(defaultDB, settings.Name)
 		if err != nil {
 			return nil, errors.Wrap(err, "pg connect")
 		}
 		if !dbExists {
 			log.Println("Creating new database")
-			if err := createDb(defaultDB, user, dbName); err != nil {
+			if err := createDb(defaultDB, settings.User, settings.Name); err != nil {
 				return nil, errors.Wrap(err, "pg connect")
 			}
 		}
-		db, err = splunksqlx.Connect("postgres",
-			fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-				host, port, user, password, dbName))
+		db, err = splunksqlx.Connect("postgres", settings.dsn(settings.User, settings.Name))
 		if err != nil {
 			return nil, errors.Wrap(err, "database connect")
 		}
 	}
+	if settings.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(settings.MaxOpenConns)
+	}
+	if settings.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(settings.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(settings.ConnMaxIdleTime)
 	return db, nil
 }
 
+func (settings ConnectionSettings) validate() error {
+	switch settings.SslMode {
+	case "", "disable", "require", "verify-ca", "verify-full":
+	default:
+		return errors.Newf("Unsupported database sslmode %v (use disable, require, verify-ca or verify-full)",
+			settings.SslMode)
+	}
+	if (settings.SslCert == "") != (settings.SslKey == "") {
+		return errors.New("The database client certificate and key must be configured together")
+	}
+	if (settings.SslMode == "verify-ca" || settings.SslMode == "verify-full") && settings.SslRootCert == "" {
+		return errors.Newf("Database sslmode %v requires the CA certificate (db.tls.ca-path)", settings.SslMode)
+	}
+	return nil
+}
+
+// dsn builds a key/value connection string, statement_timeout is passed to Postgres as a run-time parameter.
+func (settings ConnectionSettings) dsn(user string, dbName string) string {
+	sslMode := settings.SslMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	parameters := map[string]string{
+		"host":     settings.Host,
+		"port":     settings.Port,
+		"user":     user,
+		"password": settings.Password,
+		"dbname":   dbName,
+		"sslmode":  sslMode,
+	}
+	if sslMode != "disable" {
+		if settings.SslRootCert != "" {
+			parameters["sslrootcert"] = settings.SslRootCert
+		}
+		if settings.SslCert != "" {
+			parameters["sslcert"] = settings.SslCert
+			parameters["sslkey"] = settings.SslKey
+		}
+	}
+	if settings.ConnectTimeout > 0 {
+		parameters["connect_timeout"] = fmt.Sprintf("%d", int(settings.ConnectTimeout.Seconds()))
+	}
+	if settings.StatementTimeout > 0 {
+		parameters["statement_timeout"] = fmt.Sprintf("%d", settings.StatementTimeout.Milliseconds())
+	}
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	dsn := make([]string, 0, len(keys))
+	for _, key := range keys {
+		dsn = append(dsn, key+"="+quoteDsnValue(parameters[key]))
+	}
+	return strings.Join(dsn, " ")
+}
+
+// quoteDsnValue quotes values that are empty or contain spaces, quotes or backslashes (e.g. passwords).
+func quoteDsnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
 func checkUserExists(db *sqlx.DB, user string) (userExists bool, err error) {
 	err = db.QueryRow(`SELECT EXISTS(SELECT 1 FROM pg_roles WHERE rolname=$1);`, user).Scan(&userExists)
 	if err != nil {
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDsn(t *testing.T) {
+	settings := ConnectionSettings{
+		Name:     "torq",
+		User:     "torq",
+		Password: `it's a secret`,
+		Host:     "db.example.com",
+		Port:     "5432",
+	}
+	expected := `dbname=torq host=db.example.com password='it\'s a secret' port=5432 sslmode=disable user=torq`
+	if dsn := settings.dsn(settings.User, settings.Name); dsn != expected {
+		t.Errorf("expected %v, got %v", expected, dsn)
+	}
+
+	settings.Password = "password"
+	settings.SslMode = "verify-full"
+	settings.SslRootCert = "/certs/ca.pem"
+	settings.SslCert = "/certs/client.pem"
+	settings.SslKey = "/certs/client.key"
+	settings.ConnectTimeout = 10 * time.Second
+	settings.StatementTimeout = 30 * time.Second
+	expected = "connect_timeout=10 dbname=postgres host=db.example.com password=password port=5432 " +
+		"sslcert=/certs/client.pem sslkey=/certs/client.key sslmode=verify-full sslrootcert=/certs/ca.pem " +
+		"statement_timeout=30000 user=postgres"
+	if dsn := settings.dsn("postgres", "postgres"); dsn != expected {
+		t.Errorf("expected %v, got %v", expected, dsn)
+	}
+}
+
+func TestConnectionSettingsValidate(t *testing.T) {
+	if err := (ConnectionSettings{}).validate(); err != nil {
+		t.Errorf("expected the defaults to be valid, got %v", err)
+	}
+	if err := (ConnectionSettings{SslMode: "prefer"}).validate(); err == nil {
+		t.Error("expected an error for an unsupported sslmode")
+	}
+	if err := (ConnectionSettings{SslMode: "verify-full"}).validate(); err == nil {
+		t.Error("expected an error for verify-full without a CA certificate")
+	}
+	if err := (ConnectionSettings{SslMode: "require", SslCert: "/certs/client.pem"}).validate(); err == nil {
+		t.Error("expected an error for a client certificate without key")
+	}
+}
+
+func TestGetReconnectBackoff(t *testing.T) {
+	if backoff := getReconnectBackoff(1); backoff != time.Second {
+		t.Errorf("expected 1s, got %v", backoff)
+	}
+	if backoff := getReconnectBackoff(4); backoff != 8*time.Second {
+		t.Errorf("expected 8s, got %v", backoff)
+	}
+	if backoff := getReconnectBackoff(100); backoff != reconnectMaxBackoff {
+		t.Errorf("expected %v, got %v", reconnectMaxBackoff, backoff)
+	}
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+const healthCheckInterval = 10 * time.Second
+const healthCheckTimeout = 5 * time.Second
+const reconnectMaxBackoff = 2 * time.Minute
+
+// MonitorConnection pings the database until the context is cancelled. The pool replaces broken connections by itself
+// (lib/pq reports them as driver.ErrBadConn), when a ping fails the database is pinged again with an increasing
+// backoff until it's reachable again.
+// onHealthChange is called whenever the database becomes unreachable or reachable again.
+func MonitorConnection(ctx context.Context, db *sqlx.DB, onHealthChange func(healthy bool)) {
+	healthy := true
+	wait := healthCheckInterval
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		err := ping(ctx, db)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			if !healthy {
+				log.Info().Msgf("Database connection restored after %v failed attempt(s)", failures)
+				healthy = true
+				onHealthChange(true)
+			}
+			failures = 0
+			wait = healthCheckInterval
+			continue
+		}
+
+		failures++
+		if healthy {
+			log.Error().Err(err).Msg("Database connection lost, reconnecting")
+			healthy = false
+			onHealthChange(false)
+		} else {
+			log.Error().Err(err).Msgf("Database reconnect attempt %v failed", failures)
+		}
+		wait = getReconnectBackoff(failures)
+	}
+}
+
+func ping(ctx context.Context, db *sqlx.DB) error {
+	pingCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	return db.PingContext(pingCtx)
+}
+
+// getReconnectBackoff doubles the wait for every failed attempt starting at a second, up to reconnectMaxBackoff.
+func getReconnectBackoff(failures int) time.Duration {
+	if failures > 10 {
+		return reconnectMaxBackoff
+	}
+	backoff := time.Second << (failures - 1)
+	if backoff > reconnectMaxBackoff {
+		return reconnectMaxBackoff
+	}
+	return backoff
+}
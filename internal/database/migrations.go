@@ -67,3 +67,19 @@ func MigrateUp(db *sqlx.DB) error {
 
 	return nil
 }
+
+// MigrationVersion returns the current migration version of the database and whether that migration failed halfway.
+func MigrationVersion(db *sqlx.DB) (uint, bool, error) {
+	m, err := newMigrationInstance(db.DB)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "Creating new migration instance")
+	}
+	version, dirty, err := m.Version()
+	if err != nil {
+		if errors.Is(err, migrate.ErrNilVersion) {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrap(err, "Obtaining migration version")
+	}
+	return version, dirty, nil
+}
@@ -0,0 +1,114 @@
+package fee_bumps
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/lncapital/torq/internal/database"
+)
+
+type UnconfirmedTransaction struct {
+	NodeId               int     `json:"nodeId" db:"node_id"`
+	TxHash               string  `json:"txHash" db:"tx_hash"`
+	FirstSeenBlockHeight uint32  `json:"firstSeenBlockHeight" db:"first_seen_block_height"`
+	LastBumpBlockHeight  *uint32 `json:"lastBumpBlockHeight" db:"last_bump_block_height"`
+	// LastBumpSatPerVbyte is unknown when the last bump used a confirmation target
+	LastBumpSatPerVbyte *uint64 `json:"lastBumpSatPerVbyte" db:"last_bump_sat_per_vbyte"`
+	Bumps               int     `json:"bumps" db:"bumps"`
+	// BumpOutpoint is the output spent by the child (CPFP) or the input of the replaced transaction (RBF)
+	BumpOutpoint *string   `json:"bumpOutpoint" db:"bump_outpoint"`
+	BumpReplaced bool      `json:"bumpReplaced" db:"bump_replaced"`
+	CreatedOn    time.Time `json:"createdOn" db:"created_on"`
+	UpdatedOn    time.Time `json:"updatedOn" db:"updated_on"`
+}
+
+func getUnconfirmedTransactions(db *sqlx.DB, nodeId int) ([]UnconfirmedTransaction, error) {
+	var unconfirmedTransactions []UnconfirmedTransaction
+	err := db.Select(&unconfirmedTransactions, `
+		SELECT *
+		FROM unconfirmed_transaction
+		WHERE node_id = $1
+		ORDER BY first_seen_block_height, tx_hash;`, nodeId)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return unconfirmedTransactions, nil
+}
+
+// setUnconfirmedTransactions adds the transactions that are seen for the first time and removes the transactions
+// that are no longer unconfirmed.
+func setUnconfirmedTransactions(db *sqlx.DB, nodeId int, txHashes []string, blockHeight uint32) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, database.SqlBeginTransactionError)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	_, err = tx.Exec(`DELETE FROM unconfirmed_transaction WHERE node_id = $1 AND NOT tx_hash = ANY($2);`,
+		nodeId, pq.Array(txHashes))
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	now := time.Now().UTC()
+	for _, txHash := range txHashes {
+		_, err = tx.Exec(`
+			INSERT INTO unconfirmed_transaction (node_id, tx_hash, first_seen_block_height, created_on, updated_on)
+			VALUES ($1, $2, $3, $4, $4)
+			ON CONFLICT (node_id, tx_hash) DO NOTHING;`,
+			nodeId, txHash, blockHeight, now)
+		if err != nil {
+			return errors.Wrap(err, database.SqlExecutionError)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, database.SqlCommitTransactionError)
+	}
+	return nil
+}
+
+func setTransactionBumped(db *sqlx.DB,
+	nodeId int,
+	txHash string,
+	blockHeight uint32,
+	satPerVbyte *uint64,
+	bumpOutpoint string,
+	bumpReplaced bool) error {
+
+	_, err := db.Exec(`
+		INSERT INTO unconfirmed_transaction (node_id, tx_hash, first_seen_block_height, last_bump_block_height,
+		                                     last_bump_sat_per_vbyte, bumps, bump_outpoint, bump_replaced,
+		                                     created_on, updated_on)
+		VALUES ($1, $2, $3, $3, $4, 1, $5, $6, $7, $7)
+		ON CONFLICT (node_id, tx_hash) DO UPDATE
+		SET last_bump_block_height = EXCLUDED.last_bump_block_height,
+		    last_bump_sat_per_vbyte = EXCLUDED.last_bump_sat_per_vbyte,
+		    bumps = unconfirmed_transaction.bumps + 1,
+		    bump_outpoint = EXCLUDED.bump_outpoint,
+		    bump_replaced = EXCLUDED.bump_replaced,
+		    updated_on = EXCLUDED.updated_on;`,
+		nodeId, txHash, blockHeight, satPerVbyte, bumpOutpoint, bumpReplaced, time.Now().UTC())
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	return nil
+}
+
+// setTransactionReplaced continues the bumps of the replaced transaction on the transaction that replaced it.
+func setTransactionReplaced(db *sqlx.DB, nodeId int, txHash string, replaced UnconfirmedTransaction) error {
+	_, err := db.Exec(`
+		UPDATE unconfirmed_transaction
+		SET first_seen_block_height = $3, last_bump_block_height = $4, last_bump_sat_per_vbyte = $5, bumps = $6,
+		    bump_outpoint = $7, bump_replaced = $8, updated_on = $9
+		WHERE node_id = $1 AND tx_hash = $2;`,
+		nodeId, txHash, replaced.FirstSeenBlockHeight, replaced.LastBumpBlockHeight, replaced.LastBumpSatPerVbyte,
+		replaced.Bumps, replaced.BumpOutpoint, replaced.BumpReplaced, time.Now().UTC())
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	return nil
+}
@@ -0,0 +1,376 @@
+package fee_bumps
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+)
+
+// bumpEscalationPercent is how much the fee rate goes up for every earlier bump of a transaction
+const bumpEscalationPercent = 25
+
+// BumpFee bumps the fee of the unconfirmed transaction and remembers the block height of the bump.
+func BumpFee(ctx context.Context,
+	db *sqlx.DB,
+	request lightning_helpers.BumpFeeRequest) (lightning_helpers.BumpFeeResponse, error) {
+
+	response, err := lightning.BumpFee(ctx, request)
+	if err != nil {
+		return lightning_helpers.BumpFeeResponse{}, errors.Wrapf(err, "Bumping the fee of %v", request.TxId)
+	}
+	err = setTransactionBumped(db, request.NodeId, request.TxId, cache.GetBlockHeight(), request.SatPerVbyte,
+		response.Outpoint, response.Replaced)
+	if err != nil {
+		return response, errors.Wrapf(err, "Storing the fee bump of %v", request.TxId)
+	}
+	return response, nil
+}
+
+// RefreshUnconfirmedTransactions returns the unconfirmed transactions of the node with the block height they were
+// first seen. These are the transactions with an unconfirmed output of the node wallet, the funding and closing
+// transactions of the pending channels and the unconfirmed closing transactions of force closes.
+func RefreshUnconfirmedTransactions(ctx context.Context,
+	db *sqlx.DB,
+	nodeId int) ([]UnconfirmedTransaction, error) {
+
+	unconfirmedTransactions, _, _, err := refreshUnconfirmedTransactions(ctx, db, nodeId)
+	return unconfirmedTransactions, err
+}
+
+// refreshUnconfirmedTransactions also returns the unconfirmed wallet transactions and the hashes of the incoming
+// transactions.
+func refreshUnconfirmedTransactions(ctx context.Context,
+	db *sqlx.DB,
+	nodeId int) ([]UnconfirmedTransaction, []lightning_helpers.WalletTransaction, []string, error) {
+
+	previousTransactions, err := getUnconfirmedTransactions(db, nodeId)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "Obtaining unconfirmed transactions for nodeId: %v", nodeId)
+	}
+	utxos, err := lightning.ListUnspent(ctx, nodeId, 0)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "Listing unspent outputs for nodeId: %v", nodeId)
+	}
+	walletTransactions, err := lightning.UnconfirmedTransactions(ctx, nodeId)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "Listing unconfirmed transactions for nodeId: %v", nodeId)
+	}
+	forceClosingChannels, err := lightning.ForceClosingChannels(ctx, nodeId)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "Listing force closing channels for nodeId: %v", nodeId)
+	}
+	txHashes, incomingTxHashes := getUnconfirmedTxHashes(utxos, walletTransactions,
+		cache.GetChannelSettingsByNodeId(nodeId), forceClosingChannels)
+	replacements := getReplacements(previousTransactions, txHashes, walletTransactions)
+	for txHash := range replacements {
+		if !slices.Contains(txHashes, txHash) {
+			txHashes = append(txHashes, txHash)
+		}
+	}
+	err = setUnconfirmedTransactions(db, nodeId, txHashes, cache.GetBlockHeight())
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "Storing unconfirmed transactions for nodeId: %v", nodeId)
+	}
+	for txHash, replaced := range replacements {
+		err = setTransactionReplaced(db, nodeId, txHash, replaced)
+		if err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "Storing the replacement of %v for nodeId: %v", replaced.TxHash, nodeId)
+		}
+	}
+	unconfirmedTransactions, err := getUnconfirmedTransactions(db, nodeId)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "Obtaining unconfirmed transactions for nodeId: %v", nodeId)
+	}
+	return unconfirmedTransactions, walletTransactions, incomingTxHashes, nil
+}
+
+// GetPendingChannelTxHash returns the node and the unconfirmed funding or closing transaction of a pending channel. The
+// closing transaction of a force close that Torq didn't initiate is taken from the force closing channels of the node.
+func GetPendingChannelTxHash(ctx context.Context, channelId int) (int, string, error) {
+	channel := cache.GetChannelSettingByChannelId(channelId)
+	if channel.ChannelId == 0 {
+		return 0, "", errors.Newf("Unknown channelId %v", channelId)
+	}
+	nodeId := channel.FirstNodeId
+	if !slices.Contains(cache.GetAllTorqNodeIds(), nodeId) {
+		nodeId = channel.SecondNodeId
+	}
+	switch {
+	case channel.Status == core.Opening && channel.FundingTransactionHash != nil:
+		return nodeId, *channel.FundingTransactionHash, nil
+	case channel.Status == core.Closing && channel.ClosingTransactionHash != nil:
+		return nodeId, *channel.ClosingTransactionHash, nil
+	case channel.Status == core.Open && channel.FundingTransactionHash != nil && channel.FundingOutputIndex != nil:
+		forceClosingChannels, err := lightning.ForceClosingChannels(ctx, nodeId)
+		if err != nil {
+			return 0, "", errors.Wrapf(err, "Listing force closing channels for nodeId: %v", nodeId)
+		}
+		channelPoint := core.CreateChannelPoint(*channel.FundingTransactionHash, *channel.FundingOutputIndex)
+		for _, forceClosingChannel := range forceClosingChannels {
+			if forceClosingChannel.ChannelPoint == channelPoint && forceClosingChannel.WaitingClose &&
+				forceClosingChannel.ClosingTxId != "" {
+				return nodeId, forceClosingChannel.ClosingTxId, nil
+			}
+		}
+	}
+	return 0, "", errors.Newf("Channel %v has no unconfirmed funding or closing transaction", channelId)
+}
+
+// getUnconfirmedTxHashes also returns the incoming transactions: the transactions that only pay to the wallet of the
+// node and have no input of the node, or that are not known as wallet transaction. Channel transactions are never
+// incoming.
+func getUnconfirmedTxHashes(utxos []lightning_helpers.Utxo,
+	walletTransactions []lightning_helpers.WalletTransaction,
+	channels []cache.ChannelSettingsCache,
+	forceClosingChannels []lightning_helpers.ForceClosingChannel) ([]string, []string) {
+
+	txHashes := make([]string, 0)
+	var incomingTxHashes []string
+	for _, utxo := range utxos {
+		if utxo.Confirmations == 0 && !utxo.Leased && !slices.Contains(txHashes, utxo.TxId) {
+			txHashes = append(txHashes, utxo.TxId)
+			walletTransactionIndex := slices.IndexFunc(walletTransactions,
+				func(walletTransaction lightning_helpers.WalletTransaction) bool {
+					return walletTransaction.TxId == utxo.TxId
+				})
+			if walletTransactionIndex == -1 || walletTransactions[walletTransactionIndex].Incoming {
+				incomingTxHashes = append(incomingTxHashes, utxo.TxId)
+			}
+		}
+	}
+	for _, channel := range channels {
+		var txHash *string
+		switch channel.Status {
+		case core.Opening:
+			txHash = channel.FundingTransactionHash
+		case core.Closing:
+			txHash = channel.ClosingTransactionHash
+		}
+		if txHash == nil || *txHash == "" {
+			continue
+		}
+		incomingTxHashes = removeTxHash(incomingTxHashes, *txHash)
+		if !slices.Contains(txHashes, *txHash) {
+			txHashes = append(txHashes, *txHash)
+		}
+	}
+	// The anchor of an unconfirmed force close can be bumped, also when Torq didn't initiate the close
+	for _, forceClosingChannel := range forceClosingChannels {
+		if !forceClosingChannel.WaitingClose || forceClosingChannel.ClosingTxId == "" {
+			continue
+		}
+		incomingTxHashes = removeTxHash(incomingTxHashes, forceClosingChannel.ClosingTxId)
+		if !slices.Contains(txHashes, forceClosingChannel.ClosingTxId) {
+			txHashes = append(txHashes, forceClosingChannel.ClosingTxId)
+		}
+	}
+	return txHashes, incomingTxHashes
+}
+
+func removeTxHash(txHashes []string, txHash string) []string {
+	if index := slices.Index(txHashes, txHash); index != -1 {
+		return slices.Delete(txHashes, index, index+1)
+	}
+	return txHashes
+}
+
+// getReplacements returns the bumped transactions that are no longer unconfirmed by the hash of the unconfirmed
+// transaction that replaced them (RBF), the replacement spends the input that was bumped.
+func getReplacements(previousTransactions []UnconfirmedTransaction,
+	txHashes []string,
+	walletTransactions []lightning_helpers.WalletTransaction) map[string]UnconfirmedTransaction {
+
+	replacedByInput := make(map[string]UnconfirmedTransaction)
+	for _, previousTransaction := range previousTransactions {
+		if previousTransaction.BumpReplaced && previousTransaction.BumpOutpoint != nil &&
+			!slices.Contains(txHashes, previousTransaction.TxHash) {
+			replacedByInput[*previousTransaction.BumpOutpoint] = previousTransaction
+		}
+	}
+	replacements := make(map[string]UnconfirmedTransaction)
+	for _, walletTransaction := range walletTransactions {
+		for _, input := range walletTransaction.Inputs {
+			if replaced, exists := replacedByInput[input]; exists && replaced.TxHash != walletTransaction.TxId {
+				replacements[walletTransaction.TxId] = replaced
+			}
+		}
+	}
+	return replacements
+}
+
+// getBumpChildren returns the transactions that spend the output of a transaction that Torq bumped with a child
+// (CPFP). A child is bumped again through its parent, bumping it by itself would add yet another child.
+func getBumpChildren(unconfirmedTransactions []UnconfirmedTransaction,
+	walletTransactions []lightning_helpers.WalletTransaction) []string {
+
+	bumpOutpoints := make(map[string]bool)
+	for _, unconfirmedTransaction := range unconfirmedTransactions {
+		if !unconfirmedTransaction.BumpReplaced && unconfirmedTransaction.BumpOutpoint != nil {
+			bumpOutpoints[*unconfirmedTransaction.BumpOutpoint] = true
+		}
+	}
+	var children []string
+	for _, walletTransaction := range walletTransactions {
+		for _, input := range walletTransaction.Inputs {
+			if bumpOutpoints[input] && !slices.Contains(children, walletTransaction.TxId) {
+				children = append(children, walletTransaction.TxId)
+			}
+		}
+	}
+	return children
+}
+
+// getEscalatedFeeRate raises the fee rate with every earlier bump of the transaction, a bump at the same rate as the
+// previous one would not speed it up. The fee rate goes up by bumpEscalationPercent, at least 1 sat/vbyte, and the
+// confirmation target is halved per bump.
+func getEscalatedFeeRate(targetConf *int32, satPerVbyte *uint64, bumps int) (*int32, *uint64) {
+	if bumps <= 0 {
+		return targetConf, satPerVbyte
+	}
+	if satPerVbyte != nil {
+		increase := (*satPerVbyte*uint64(bumps)*bumpEscalationPercent + 99) / 100
+		if increase < uint64(bumps) {
+			increase = uint64(bumps)
+		}
+		escalated := *satPerVbyte + increase
+		return nil, &escalated
+	}
+	if targetConf != nil {
+		escalated := *targetConf
+		for i := 0; i < bumps && escalated > 1; i++ {
+			escalated /= 2
+		}
+		if escalated < 1 {
+			escalated = 1
+		}
+		return &escalated, nil
+	}
+	return nil, nil
+}
+
+// getCappedFeeRate limits the escalated fee rate to the maximum fee rate. The fee rate of a confirmation target is the
+// fee estimate of the node. When the escalated fee rate is above the maximum the transaction is bumped at the maximum,
+// unless the last bump already was, then it's not bumped again.
+func getCappedFeeRate(targetConf *int32,
+	satPerVbyte *uint64,
+	feeRate uint64,
+	lastBumpSatPerVbyte *uint64,
+	maxSatPerVbyte uint64) (*int32, *uint64, bool) {
+
+	if feeRate <= maxSatPerVbyte {
+		return targetConf, satPerVbyte, true
+	}
+	if lastBumpSatPerVbyte != nil && *lastBumpSatPerVbyte >= maxSatPerVbyte {
+		return nil, nil, false
+	}
+	return nil, &maxSatPerVbyte, true
+}
+
+// getTransactionsToBump returns the transactions that are unconfirmed for at least the number of blocks since they
+// were first seen or last bumped.
+func getTransactionsToBump(unconfirmedTransactions []UnconfirmedTransaction,
+	blockHeight uint32,
+	blocks uint32) []UnconfirmedTransaction {
+
+	var transactionsToBump []UnconfirmedTransaction
+	for _, unconfirmedTransaction := range unconfirmedTransactions {
+		since := unconfirmedTransaction.FirstSeenBlockHeight
+		if unconfirmedTransaction.LastBumpBlockHeight != nil {
+			since = *unconfirmedTransaction.LastBumpBlockHeight
+		}
+		if blockHeight >= since+blocks {
+			transactionsToBump = append(transactionsToBump, unconfirmedTransaction)
+		}
+	}
+	return transactionsToBump
+}
+
+type BumpResult struct {
+	TxHash      string  `json:"txHash"`
+	Outpoint    string  `json:"outpoint,omitempty"`
+	Replaced    bool    `json:"replaced,omitempty"`
+	TargetConf  *int32  `json:"targetConf,omitempty"`
+	SatPerVbyte *uint64 `json:"satPerVbyte,omitempty"`
+	// Skipped is set when the transaction was not bumped
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BumpUnconfirmedTransactions bumps the fee of every transaction of the node that is unconfirmed for at least the
+// number of blocks since it was first seen or last bumped. The fee rate escalates with the earlier bumps of the
+// transaction up to maxSatPerVbyte and the children of earlier bumps are skipped, they are bumped through their parent.
+// Incoming transactions, that someone else sent to the node, are only bumped with bumpIncoming.
+func BumpUnconfirmedTransactions(ctx context.Context,
+	db *sqlx.DB,
+	nodeId int,
+	blocks uint32,
+	targetConf *int32,
+	satPerVbyte *uint64,
+	maxSatPerVbyte uint64,
+	bumpIncoming bool,
+	force bool) ([]BumpResult, error) {
+
+	unconfirmedTransactions, walletTransactions, incomingTxHashes, err := refreshUnconfirmedTransactions(ctx, db, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	children := getBumpChildren(unconfirmedTransactions, walletTransactions)
+	var results []BumpResult
+	for _, unconfirmedTransaction := range getTransactionsToBump(unconfirmedTransactions, cache.GetBlockHeight(), blocks) {
+		if slices.Contains(children, unconfirmedTransaction.TxHash) {
+			continue
+		}
+		if !bumpIncoming && slices.Contains(incomingTxHashes, unconfirmedTransaction.TxHash) {
+			continue
+		}
+		result := BumpResult{TxHash: unconfirmedTransaction.TxHash}
+		escalatedTargetConf, escalatedSatPerVbyte := getEscalatedFeeRate(targetConf, satPerVbyte,
+			unconfirmedTransaction.Bumps)
+		var feeRate uint64
+		switch {
+		case escalatedSatPerVbyte != nil:
+			feeRate = *escalatedSatPerVbyte
+		case escalatedTargetConf != nil:
+			feeEstimate, err := lightning.GetFeeEstimate(ctx, nodeId, *escalatedTargetConf)
+			if err != nil {
+				result.TargetConf = escalatedTargetConf
+				result.Error = err.Error()
+				results = append(results, result)
+				continue
+			}
+			feeRate = uint64(math.Ceil(feeEstimate.SatPerVbyte))
+		}
+		var bump bool
+		result.TargetConf, result.SatPerVbyte, bump = getCappedFeeRate(escalatedTargetConf, escalatedSatPerVbyte,
+			feeRate, unconfirmedTransaction.LastBumpSatPerVbyte, maxSatPerVbyte)
+		if !bump {
+			result.Skipped = fmt.Sprintf("already bumped at the maximum fee rate of %v sat/vbyte", maxSatPerVbyte)
+			results = append(results, result)
+			continue
+		}
+		response, err := BumpFee(ctx, db, lightning_helpers.BumpFeeRequest{
+			CommunicationRequest: lightning_helpers.CommunicationRequest{NodeId: nodeId},
+			TxId:                 unconfirmedTransaction.TxHash,
+			TargetConf:           result.TargetConf,
+			SatPerVbyte:          result.SatPerVbyte,
+			Force:                force,
+		})
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Outpoint = response.Outpoint
+			result.Replaced = response.Replaced
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
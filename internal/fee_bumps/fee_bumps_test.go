@@ -0,0 +1,162 @@
+package fee_bumps
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+)
+
+func TestGetUnconfirmedTxHashes(t *testing.T) {
+	funding := "funding"
+	closing := "closing"
+	open := "open"
+	utxos := []lightning_helpers.Utxo{
+		{TxId: "send", Confirmations: 0},
+		{TxId: "send", Confirmations: 0},
+		{TxId: "confirmed", Confirmations: 3},
+		{TxId: "leased", Confirmations: 0, Leased: true},
+		{TxId: closing, Confirmations: 0},
+		{TxId: "receive", Confirmations: 0},
+		{TxId: "unknown", Confirmations: 0},
+	}
+	walletTransactions := []lightning_helpers.WalletTransaction{
+		{TxId: "send"},
+		{TxId: "receive", Incoming: true},
+		// the funding transaction of the peer closing the channel has no input of the node
+		{TxId: closing, Incoming: true},
+	}
+	channels := []cache.ChannelSettingsCache{
+		{Status: core.Opening, FundingTransactionHash: &funding},
+		{Status: core.Closing, FundingTransactionHash: &open, ClosingTransactionHash: &closing},
+		{Status: core.Open, FundingTransactionHash: &open},
+	}
+
+	forceClosingChannels := []lightning_helpers.ForceClosingChannel{
+		{ClosingTxId: "forceClose", WaitingClose: true},
+		{ClosingTxId: closing, WaitingClose: true},
+		{ClosingTxId: "confirmedForceClose"},
+	}
+
+	txHashes, incomingTxHashes := getUnconfirmedTxHashes(utxos, walletTransactions, channels, forceClosingChannels)
+	expected := []string{"send", closing, "receive", "unknown", funding, "forceClose"}
+	if !slices.Equal(txHashes, expected) {
+		t.Errorf("expected %v, got %v", expected, txHashes)
+	}
+	expectedIncoming := []string{"receive", "unknown"}
+	if !slices.Equal(incomingTxHashes, expectedIncoming) {
+		t.Errorf("expected incoming %v, got %v", expectedIncoming, incomingTxHashes)
+	}
+}
+
+func TestGetTransactionsToBump(t *testing.T) {
+	bumpedAt := uint32(105)
+	unconfirmedTransactions := []UnconfirmedTransaction{
+		{TxHash: "old", FirstSeenBlockHeight: 100},
+		{TxHash: "new", FirstSeenBlockHeight: 108},
+		{TxHash: "bumped", FirstSeenBlockHeight: 100, LastBumpBlockHeight: &bumpedAt},
+	}
+
+	var txHashes []string
+	for _, unconfirmedTransaction := range getTransactionsToBump(unconfirmedTransactions, 110, 6) {
+		txHashes = append(txHashes, unconfirmedTransaction.TxHash)
+	}
+	expected := []string{"old"}
+	if !slices.Equal(txHashes, expected) {
+		t.Errorf("expected %v, got %v", expected, txHashes)
+	}
+}
+
+func TestBumpChildrenAndReplacements(t *testing.T) {
+	childOutpoint := "parent:1"
+	replacedInput := "wallet:0"
+	bumpedAt := uint32(105)
+	unconfirmedTransactions := []UnconfirmedTransaction{
+		{TxHash: "parent", BumpOutpoint: &childOutpoint, Bumps: 1, LastBumpBlockHeight: &bumpedAt},
+		{TxHash: "replaced", FirstSeenBlockHeight: 100, BumpOutpoint: &replacedInput, BumpReplaced: true, Bumps: 2,
+			LastBumpBlockHeight: &bumpedAt},
+	}
+	walletTransactions := []lightning_helpers.WalletTransaction{
+		{TxId: "child", Inputs: []string{"parent:1"}},
+		{TxId: "spend", Inputs: []string{"parent:0"}},
+		{TxId: "replacement", Inputs: []string{"wallet:0", "wallet:3"}},
+	}
+
+	children := getBumpChildren(unconfirmedTransactions, walletTransactions)
+	if !slices.Equal(children, []string{"child"}) {
+		t.Errorf("expected only the child of the bump, got %v", children)
+	}
+
+	replacements := getReplacements(unconfirmedTransactions, []string{"parent", "replaced"}, walletTransactions)
+	if len(replacements) != 0 {
+		t.Errorf("expected no replacement while the bumped transaction is unconfirmed, got %v", replacements)
+	}
+	replacements = getReplacements(unconfirmedTransactions, []string{"parent"}, walletTransactions)
+	if len(replacements) != 1 || replacements["replacement"].Bumps != 2 ||
+		replacements["replacement"].FirstSeenBlockHeight != 100 {
+		t.Errorf("expected the replacement to continue the bumps of the replaced transaction, got %v", replacements)
+	}
+}
+
+func TestGetEscalatedFeeRate(t *testing.T) {
+	satPerVbyte := uint64(20)
+	targetConf := int32(12)
+	low := uint64(2)
+
+	tests := []struct {
+		targetConf          *int32
+		satPerVbyte         *uint64
+		bumps               int
+		expectedTargetConf  int32
+		expectedSatPerVbyte uint64
+	}{
+		{nil, &satPerVbyte, 0, 0, 20},
+		{nil, &satPerVbyte, 1, 0, 25},
+		{nil, &satPerVbyte, 3, 0, 35},
+		// At least 1 sat/vbyte more per bump
+		{nil, &low, 1, 0, 3},
+		{&targetConf, nil, 0, 12, 0},
+		{&targetConf, nil, 2, 3, 0},
+		{&targetConf, nil, 10, 1, 0},
+	}
+	for _, test := range tests {
+		escalatedTargetConf, escalatedSatPerVbyte := getEscalatedFeeRate(test.targetConf, test.satPerVbyte, test.bumps)
+		if test.satPerVbyte != nil && (escalatedSatPerVbyte == nil || *escalatedSatPerVbyte != test.expectedSatPerVbyte) {
+			t.Errorf("%v bumps: expected %v sat/vbyte, got %v", test.bumps, test.expectedSatPerVbyte, escalatedSatPerVbyte)
+		}
+		if test.targetConf != nil && (escalatedTargetConf == nil || *escalatedTargetConf != test.expectedTargetConf) {
+			t.Errorf("%v bumps: expected a target of %v, got %v", test.bumps, test.expectedTargetConf, escalatedTargetConf)
+		}
+	}
+	if satPerVbyte != 20 || targetConf != 12 {
+		t.Errorf("the configured fee rate must not change")
+	}
+}
+
+func TestGetCappedFeeRate(t *testing.T) {
+	targetConf := int32(2)
+	satPerVbyte := uint64(40)
+	atMaximum := uint64(50)
+	belowMaximum := uint64(45)
+
+	cappedTargetConf, cappedSatPerVbyte, bump := getCappedFeeRate(nil, &satPerVbyte, 40, nil, 50)
+	if !bump || cappedTargetConf != nil || cappedSatPerVbyte == nil || *cappedSatPerVbyte != 40 {
+		t.Errorf("expected a fee rate below the maximum to be used, got %v %v %v", cappedTargetConf, cappedSatPerVbyte, bump)
+	}
+	cappedTargetConf, cappedSatPerVbyte, bump = getCappedFeeRate(&targetConf, nil, 30, nil, 50)
+	if !bump || cappedTargetConf == nil || *cappedTargetConf != 2 || cappedSatPerVbyte != nil {
+		t.Errorf("expected a target with an estimate below the maximum to be used, got %v %v %v",
+			cappedTargetConf, cappedSatPerVbyte, bump)
+	}
+	cappedTargetConf, cappedSatPerVbyte, bump = getCappedFeeRate(&targetConf, nil, 80, &belowMaximum, 50)
+	if !bump || cappedTargetConf != nil || cappedSatPerVbyte == nil || *cappedSatPerVbyte != 50 {
+		t.Errorf("expected the maximum fee rate, got %v %v %v", cappedTargetConf, cappedSatPerVbyte, bump)
+	}
+	_, _, bump = getCappedFeeRate(nil, &satPerVbyte, 60, &atMaximum, 50)
+	if bump {
+		t.Errorf("expected no bump after a bump at the maximum fee rate")
+	}
+}
@@ -0,0 +1,113 @@
+package fee_bumps
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+	"github.com/lncapital/torq/pkg/server_errors"
+)
+
+type FeeBumpRequest struct {
+	// NodeId and TxId are used to bump a transaction, ChannelId to bump the transaction of a pending channel
+	NodeId      int     `json:"nodeId"`
+	TxId        string  `json:"txId"`
+	ChannelId   int     `json:"channelId"`
+	TargetConf  *int32  `json:"targetConf"`
+	SatPerVbyte *uint64 `json:"satPerVbyte"`
+	Force       bool    `json:"force"`
+}
+
+type UnconfirmedTransactionBody struct {
+	UnconfirmedTransaction
+	BlocksUnconfirmed uint32 `json:"blocksUnconfirmed"`
+}
+
+func getUnconfirmedTransactionsHandler(c *gin.Context, db *sqlx.DB) {
+	nodeId, err := strconv.Atoi(c.Param("nodeId"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Failed to find/parse nodeId in the request.")
+		return
+	}
+	if !slices.Contains(cache.GetAllTorqNodeIds(), nodeId) {
+		server_errors.SendBadRequest(c, "Unknown nodeId.")
+		return
+	}
+	unconfirmedTransactions, err := RefreshUnconfirmedTransactions(c.Request.Context(), db, nodeId)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting unconfirmed transactions")
+		return
+	}
+	blockHeight := cache.GetBlockHeight()
+	bodies := make([]UnconfirmedTransactionBody, 0, len(unconfirmedTransactions))
+	for _, unconfirmedTransaction := range unconfirmedTransactions {
+		body := UnconfirmedTransactionBody{UnconfirmedTransaction: unconfirmedTransaction}
+		if blockHeight > unconfirmedTransaction.FirstSeenBlockHeight {
+			body.BlocksUnconfirmed = blockHeight - unconfirmedTransaction.FirstSeenBlockHeight
+		}
+		bodies = append(bodies, body)
+	}
+	c.JSON(http.StatusOK, bodies)
+}
+
+func bumpTransactionFeeHandler(c *gin.Context, db *sqlx.DB) {
+	var request FeeBumpRequest
+	if err := c.BindJSON(&request); err != nil {
+		server_errors.SendBadRequestFromError(c, errors.Wrap(err, server_errors.JsonParseError))
+		return
+	}
+	if !slices.Contains(cache.GetAllTorqNodeIds(), request.NodeId) {
+		server_errors.SendBadRequest(c, "Unknown nodeId.")
+		return
+	}
+	if request.TxId == "" {
+		server_errors.SendBadRequest(c, "txId is required")
+		return
+	}
+	bumpFee(c, db, request)
+}
+
+func bumpPendingChannelFeeHandler(c *gin.Context, db *sqlx.DB) {
+	var request FeeBumpRequest
+	if err := c.BindJSON(&request); err != nil {
+		server_errors.SendBadRequestFromError(c, errors.Wrap(err, server_errors.JsonParseError))
+		return
+	}
+	nodeId, txHash, err := GetPendingChannelTxHash(c.Request.Context(), request.ChannelId)
+	if err != nil {
+		server_errors.SendBadRequestFromError(c, err)
+		return
+	}
+	request.NodeId = nodeId
+	request.TxId = txHash
+	bumpFee(c, db, request)
+}
+
+func bumpFee(c *gin.Context, db *sqlx.DB, request FeeBumpRequest) {
+	if (request.TargetConf == nil) == (request.SatPerVbyte == nil) {
+		server_errors.SendBadRequest(c, "Either targetConf or satPerVbyte is required")
+		return
+	}
+	if (request.TargetConf != nil && *request.TargetConf < 1) || (request.SatPerVbyte != nil && *request.SatPerVbyte < 1) {
+		server_errors.SendBadRequest(c, "targetConf and satPerVbyte must be at least 1")
+		return
+	}
+	response, err := BumpFee(c.Request.Context(), db, lightning_helpers.BumpFeeRequest{
+		CommunicationRequest: lightning_helpers.CommunicationRequest{NodeId: request.NodeId},
+		TxId:                 request.TxId,
+		TargetConf:           request.TargetConf,
+		SatPerVbyte:          request.SatPerVbyte,
+		Force:                request.Force,
+	})
+	if err != nil {
+		server_errors.SendBadRequestFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,12 @@
+package fee_bumps
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func RegisterFeeBumpRoutes(r *gin.RouterGroup, db *sqlx.DB) {
+	r.GET(":nodeId", func(c *gin.Context) { getUnconfirmedTransactionsHandler(c, db) })
+	r.POST("transaction", func(c *gin.Context) { bumpTransactionFeeHandler(c, db) })
+	r.POST("channel", func(c *gin.Context) { bumpPendingChannelFeeHandler(c, db) })
+}
@@ -0,0 +1,170 @@
+package fee_estimates
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/lncapital/torq/internal/database"
+)
+
+type FeeEstimate struct {
+	NodeId      int       `json:"nodeId" db:"node_id"`
+	TargetConf  int32     `json:"targetConf" db:"target_conf"`
+	SatPerVbyte float64   `json:"satPerVbyte" db:"sat_per_vbyte"`
+	CreatedOn   time.Time `json:"createdOn" db:"created_on"`
+}
+
+type DeferredOperation struct {
+	DeferredOperationId int                     `json:"deferredOperationId" db:"deferred_operation_id"`
+	NodeId              int                     `json:"nodeId" db:"node_id"`
+	OperationType       DeferredOperationType   `json:"operationType" db:"operation_type"`
+	Request             json.RawMessage         `json:"request" db:"request"`
+	MaxSatPerVbyte      float64                 `json:"maxSatPerVbyte" db:"max_sat_per_vbyte"`
+	TargetConf          int32                   `json:"targetConf" db:"target_conf"`
+	Deadline            time.Time               `json:"deadline" db:"deadline"`
+	ExecuteAtDeadline   bool                    `json:"executeAtDeadline" db:"execute_at_deadline"`
+	Status              DeferredOperationStatus `json:"status" db:"status"`
+	// SatPerVbyte is the fee rate the operation was executed with
+	SatPerVbyte *float64  `json:"satPerVbyte" db:"sat_per_vbyte"`
+	Result      *string   `json:"result" db:"result"`
+	Error       *string   `json:"error" db:"error"`
+	CreatedOn   time.Time `json:"createdOn" db:"created_on"`
+	UpdatedOn   time.Time `json:"updatedOn" db:"updated_on"`
+}
+
+func insertFeeEstimates(db *sqlx.DB, feeEstimates []FeeEstimate) error {
+	for _, feeEstimate := range feeEstimates {
+		_, err := db.Exec(`
+			INSERT INTO fee_estimate (node_id, target_conf, sat_per_vbyte, created_on)
+			VALUES ($1, $2, $3, $4);`,
+			feeEstimate.NodeId, feeEstimate.TargetConf, feeEstimate.SatPerVbyte, feeEstimate.CreatedOn)
+		if err != nil {
+			return errors.Wrap(err, database.SqlExecutionError)
+		}
+	}
+	return nil
+}
+
+func deleteFeeEstimates(db *sqlx.DB, before time.Time) error {
+	_, err := db.Exec(`DELETE FROM fee_estimate WHERE created_on < $1;`, before)
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	return nil
+}
+
+// getLatestFeeEstimates returns the most recent estimate of every confirmation target of the node.
+func getLatestFeeEstimates(db *sqlx.DB, nodeId int) ([]FeeEstimate, error) {
+	feeEstimates := make([]FeeEstimate, 0)
+	err := db.Select(&feeEstimates, `
+		SELECT DISTINCT ON (target_conf) node_id, target_conf, sat_per_vbyte, created_on
+		FROM fee_estimate
+		WHERE node_id=$1
+		ORDER BY target_conf, created_on DESC;`, nodeId)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return feeEstimates, nil
+}
+
+func getFeeEstimateHistory(db *sqlx.DB,
+	nodeId int,
+	targetConf int32,
+	from time.Time,
+	to time.Time) ([]FeeEstimate, error) {
+
+	feeEstimates := make([]FeeEstimate, 0)
+	err := db.Select(&feeEstimates, `
+		SELECT node_id, target_conf, sat_per_vbyte, created_on
+		FROM fee_estimate
+		WHERE node_id=$1 AND target_conf=$2 AND created_on >= $3 AND created_on <= $4
+		ORDER BY created_on;`, nodeId, targetConf, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return feeEstimates, nil
+}
+
+func insertDeferredOperation(db *sqlx.DB, deferredOperation *DeferredOperation) error {
+	err := db.QueryRowx(`
+		INSERT INTO deferred_operation (node_id, operation_type, request, max_sat_per_vbyte, target_conf, deadline,
+		                                execute_at_deadline, status, created_on, updated_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING deferred_operation_id;`,
+		deferredOperation.NodeId, deferredOperation.OperationType, []byte(deferredOperation.Request),
+		deferredOperation.MaxSatPerVbyte, deferredOperation.TargetConf, deferredOperation.Deadline,
+		deferredOperation.ExecuteAtDeadline, deferredOperation.Status,
+		deferredOperation.CreatedOn, deferredOperation.UpdatedOn).
+		Scan(&deferredOperation.DeferredOperationId)
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	return nil
+}
+
+func getDeferredOperations(db *sqlx.DB, nodeIds []int) ([]DeferredOperation, error) {
+	deferredOperations := make([]DeferredOperation, 0)
+	err := db.Select(&deferredOperations, `
+		SELECT * FROM deferred_operation
+		WHERE node_id = ANY($1)
+		ORDER BY created_on DESC;`, pq.Array(nodeIds))
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return deferredOperations, nil
+}
+
+func getDeferredOperationsByStatus(db *sqlx.DB, status DeferredOperationStatus) ([]DeferredOperation, error) {
+	deferredOperations := make([]DeferredOperation, 0)
+	err := db.Select(&deferredOperations, `
+		SELECT * FROM deferred_operation
+		WHERE status=$1
+		ORDER BY created_on;`, status)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return deferredOperations, nil
+}
+
+// updateDeferredOperation stores the new status of the operation when its status is still previousStatus, it returns
+// false when another process changed the status first.
+func updateDeferredOperation(db *sqlx.DB,
+	deferredOperation DeferredOperation,
+	previousStatus DeferredOperationStatus) (bool, error) {
+
+	result, err := db.Exec(`
+		UPDATE deferred_operation
+		SET status=$1, sat_per_vbyte=$2, result=$3, error=$4, updated_on=$5
+		WHERE deferred_operation_id=$6 AND status=$7;`,
+		deferredOperation.Status, deferredOperation.SatPerVbyte, deferredOperation.Result, deferredOperation.Error,
+		deferredOperation.UpdatedOn, deferredOperation.DeferredOperationId, previousStatus)
+	if err != nil {
+		return false, errors.Wrap(err, database.SqlExecutionError)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return rowsAffected == 1, nil
+}
+
+// cancelDeferredOperation cancels the operation when it's still pending, it returns false otherwise.
+func cancelDeferredOperation(db *sqlx.DB, deferredOperationId int) (bool, error) {
+	result, err := db.Exec(`
+		UPDATE deferred_operation
+		SET status=$1, updated_on=$2
+		WHERE deferred_operation_id=$3 AND status=$4;`,
+		DeferredOperationCancelled, time.Now().UTC(), deferredOperationId, DeferredOperationPending)
+	if err != nil {
+		return false, errors.Wrap(err, database.SqlExecutionError)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return rowsAffected == 1, nil
+}
@@ -0,0 +1,240 @@
+package fee_estimates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/lncapital/torq/internal/communications"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+)
+
+type DeferredOperationType string
+
+const (
+	DeferredOperationOpen  DeferredOperationType = "open"
+	DeferredOperationClose DeferredOperationType = "close"
+	DeferredOperationSend  DeferredOperationType = "send"
+)
+
+type DeferredOperationStatus string
+
+const (
+	DeferredOperationPending DeferredOperationStatus = "pending"
+	// DeferredOperationExecuting is stored before the operation is sent to the node, so an operation that was
+	// interrupted by a restart is never executed twice
+	DeferredOperationExecuting DeferredOperationStatus = "executing"
+	DeferredOperationExecuted  DeferredOperationStatus = "executed"
+	DeferredOperationFailed    DeferredOperationStatus = "failed"
+	DeferredOperationExpired   DeferredOperationStatus = "expired"
+	DeferredOperationCancelled DeferredOperationStatus = "cancelled"
+)
+
+// AddDeferredOperation queues the operation, it's executed by the FeeEstimateService once the fee estimate for its
+// confirmation target is at most its maximum fee rate.
+func AddDeferredOperation(db *sqlx.DB, deferredOperation DeferredOperation) (DeferredOperation, error) {
+	deferredOperation.Status = DeferredOperationPending
+	deferredOperation.CreatedOn = time.Now().UTC()
+	deferredOperation.UpdatedOn = deferredOperation.CreatedOn
+	err := insertDeferredOperation(db, &deferredOperation)
+	if err != nil {
+		return DeferredOperation{}, err
+	}
+	return deferredOperation, nil
+}
+
+type deferredOperationAction int
+
+const (
+	deferredOperationWait = deferredOperationAction(iota)
+	deferredOperationExecute
+	deferredOperationExpire
+)
+
+// getDeferredOperationAction decides what to do with a pending operation given the current estimate for its
+// confirmation target. It returns the fee rate to execute with, whole sat/vB rounded up from the estimate.
+func getDeferredOperationAction(deferredOperation DeferredOperation,
+	estimate float64,
+	estimateExists bool,
+	now time.Time) (deferredOperationAction, uint64) {
+
+	if !estimateExists {
+		if now.Before(deferredOperation.Deadline) {
+			return deferredOperationWait, 0
+		}
+		return deferredOperationExpire, 0
+	}
+	satPerVbyte := uint64(math.Max(1, math.Ceil(estimate)))
+	if float64(satPerVbyte) <= deferredOperation.MaxSatPerVbyte {
+		return deferredOperationExecute, satPerVbyte
+	}
+	if now.Before(deferredOperation.Deadline) {
+		return deferredOperationWait, 0
+	}
+	if deferredOperation.ExecuteAtDeadline {
+		return deferredOperationExecute, satPerVbyte
+	}
+	return deferredOperationExpire, 0
+}
+
+func processDeferredOperation(ctx context.Context,
+	db *sqlx.DB,
+	deferredOperation DeferredOperation,
+	estimate float64,
+	estimateExists bool,
+	now time.Time) {
+
+	action, satPerVbyte := getDeferredOperationAction(deferredOperation, estimate, estimateExists, now)
+	switch action {
+	case deferredOperationWait:
+		return
+	case deferredOperationExpire:
+		deferredOperation.Status = DeferredOperationExpired
+		deferredOperation.UpdatedOn = now
+		expired, err := updateDeferredOperation(db, deferredOperation, DeferredOperationPending)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to expire deferred operation: %v", deferredOperation.DeferredOperationId)
+			return
+		}
+		if expired {
+			notifyDeferredOperation(db, deferredOperation)
+		}
+		return
+	}
+
+	// Only the process that moves the operation from pending to executing executes it, it was cancelled (or picked up
+	// by another process) otherwise.
+	deferredOperation.Status = DeferredOperationExecuting
+	deferredOperation.UpdatedOn = now
+	started, err := updateDeferredOperation(db, deferredOperation, DeferredOperationPending)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to start deferred operation: %v", deferredOperation.DeferredOperationId)
+		return
+	}
+	if !started {
+		log.Debug().Msgf("Deferred operation %v is no longer pending", deferredOperation.DeferredOperationId)
+		return
+	}
+
+	executedSatPerVbyte := float64(satPerVbyte)
+	deferredOperation.SatPerVbyte = &executedSatPerVbyte
+	// An interrupted operation is failed by failInterruptedDeferredOperations instead of executed again
+	result, err := executeDeferredOperation(lightning.WithoutResume(ctx), db, deferredOperation, satPerVbyte)
+	if err != nil {
+		errorMessage := err.Error()
+		deferredOperation.Status = DeferredOperationFailed
+		deferredOperation.Error = &errorMessage
+	} else {
+		deferredOperation.Status = DeferredOperationExecuted
+		deferredOperation.Result = &result
+	}
+	deferredOperation.UpdatedOn = time.Now().UTC()
+	if _, err = updateDeferredOperation(db, deferredOperation, DeferredOperationExecuting); err != nil {
+		log.Error().Err(err).Msgf("Failed to store the result of deferred operation: %v",
+			deferredOperation.DeferredOperationId)
+	}
+	notifyDeferredOperation(db, deferredOperation)
+}
+
+// executeDeferredOperation executes the stored request with the fee rate and returns the channel point or the
+// transaction id.
+func executeDeferredOperation(ctx context.Context,
+	db *sqlx.DB,
+	deferredOperation DeferredOperation,
+	satPerVbyte uint64) (string, error) {
+
+	switch deferredOperation.OperationType {
+	case DeferredOperationOpen:
+		var request lightning_helpers.OpenChannelRequest
+		if err := json.Unmarshal(deferredOperation.Request, &request); err != nil {
+			return "", errors.Wrap(err, "Parsing the open channel request")
+		}
+		request.NodeId = deferredOperation.NodeId
+		request.SatPerVbyte = &satPerVbyte
+		request.TargetConf = nil
+		response, err := lightning.OpenChannel(ctx, request)
+		if err != nil {
+			return "", errors.Wrap(err, "Opening channel")
+		}
+		return response.ChannelPoint, nil
+	case DeferredOperationClose:
+		var request lightning_helpers.CloseChannelRequest
+		if err := json.Unmarshal(deferredOperation.Request, &request); err != nil {
+			return "", errors.Wrap(err, "Parsing the close channel request")
+		}
+		request.NodeId = deferredOperation.NodeId
+		request.Db = db
+		request.SatPerVbyte = &satPerVbyte
+		request.TargetConf = nil
+		response, err := lightning.CloseChannel(ctx, request)
+		if err != nil {
+			return "", errors.Wrap(err, "Closing channel")
+		}
+		return response.ClosingTransactionHash, nil
+	case DeferredOperationSend:
+		var request lightning_helpers.OnChainPaymentRequest
+		if err := json.Unmarshal(deferredOperation.Request, &request); err != nil {
+			return "", errors.Wrap(err, "Parsing the on-chain payment request")
+		}
+		request.NodeId = deferredOperation.NodeId
+		request.SatPerVbyte = &satPerVbyte
+		request.TargetConf = nil
+		txId, err := lightning.OnChainPayment(ctx, request)
+		if err != nil {
+			return "", errors.Wrap(err, "Sending on-chain payment")
+		}
+		return txId, nil
+	}
+	return "", errors.Newf("Unknown deferred operation type: %v", deferredOperation.OperationType)
+}
+
+// failInterruptedDeferredOperations fails the operations that were executing when Torq stopped, they might have
+// reached the node so they are not executed again.
+func failInterruptedDeferredOperations(db *sqlx.DB) {
+	deferredOperations, err := getDeferredOperationsByStatus(db, DeferredOperationExecuting)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to obtain the interrupted deferred operations.")
+		return
+	}
+	for _, deferredOperation := range deferredOperations {
+		errorMessage := "Torq stopped while executing the operation, check the node before adding it again"
+		deferredOperation.Status = DeferredOperationFailed
+		deferredOperation.Error = &errorMessage
+		deferredOperation.UpdatedOn = time.Now().UTC()
+		if _, err = updateDeferredOperation(db, deferredOperation, DeferredOperationExecuting); err != nil {
+			log.Error().Err(err).Msgf("Failed to fail interrupted deferred operation: %v",
+				deferredOperation.DeferredOperationId)
+		}
+	}
+}
+
+func notifyDeferredOperation(db *sqlx.DB, deferredOperation DeferredOperation) {
+	// TODO FIXME Language from user for translations
+	message := fmt.Sprintf("Deferred %v operation %v %v", deferredOperation.OperationType,
+		deferredOperation.DeferredOperationId, deferredOperation.Status)
+	switch deferredOperation.Status {
+	case DeferredOperationExecuted:
+		message = fmt.Sprintf("%v at %v sat/vB: %v", message, *deferredOperation.SatPerVbyte, *deferredOperation.Result)
+	case DeferredOperationFailed:
+		message = fmt.Sprintf("%v: %v", message, *deferredOperation.Error)
+	case DeferredOperationExpired:
+		message = fmt.Sprintf("%v, the fee estimate stayed above %v sat/vB until the deadline", message,
+			deferredOperation.MaxSatPerVbyte)
+	}
+	communications.HandleNotification(db, core.NotifierEvent{
+		EventData: core.EventData{
+			EventTime: time.Now().UTC(),
+			NodeId:    deferredOperation.NodeId,
+		},
+		Notification:     &message,
+		NotificationType: core.DeferredOperation,
+	})
+}
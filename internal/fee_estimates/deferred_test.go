@@ -0,0 +1,88 @@
+package fee_estimates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lncapital/torq/testutil"
+)
+
+func TestGetDeferredOperationAction(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	beforeDeadline := DeferredOperation{MaxSatPerVbyte: 10, Deadline: now.Add(time.Hour)}
+	afterDeadline := DeferredOperation{MaxSatPerVbyte: 10, Deadline: now.Add(-time.Hour)}
+	executeAtDeadline := DeferredOperation{MaxSatPerVbyte: 10, Deadline: now.Add(-time.Hour), ExecuteAtDeadline: true}
+
+	tests := []struct {
+		name                string
+		deferredOperation   DeferredOperation
+		estimate            float64
+		estimateExists      bool
+		expectedAction      deferredOperationAction
+		expectedSatPerVbyte uint64
+	}{
+		{"below the maximum", beforeDeadline, 8.2, true, deferredOperationExecute, 9},
+		{"at the maximum", beforeDeadline, 9.5, true, deferredOperationExecute, 10},
+		{"above the maximum", beforeDeadline, 10.1, true, deferredOperationWait, 0},
+		{"below one", beforeDeadline, 0.25, true, deferredOperationExecute, 1},
+		{"without estimate", beforeDeadline, 0, false, deferredOperationWait, 0},
+		{"expired", afterDeadline, 25, true, deferredOperationExpire, 0},
+		{"expired without estimate", executeAtDeadline, 0, false, deferredOperationExpire, 0},
+		{"below the maximum after the deadline", afterDeadline, 4, true, deferredOperationExecute, 4},
+		{"execute at the deadline", executeAtDeadline, 25, true, deferredOperationExecute, 25},
+	}
+	for _, test := range tests {
+		action, satPerVbyte := getDeferredOperationAction(test.deferredOperation, test.estimate, test.estimateExists, now)
+		if action != test.expectedAction || satPerVbyte != test.expectedSatPerVbyte {
+			t.Errorf("%v: expected action %v at %v sat/vB, got %v at %v sat/vB", test.name,
+				test.expectedAction, test.expectedSatPerVbyte, action, satPerVbyte)
+		}
+	}
+}
+
+func TestUpdateDeferredOperation(t *testing.T) {
+	srv, err := testutil.InitTestDBConn()
+	if err != nil {
+		panic(err)
+	}
+
+	db, cancel, err := srv.NewTestDatabase()
+	defer cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	nodeId, _ := testutil.Setup(db, cancel)
+	deferredOperation, err := AddDeferredOperation(db, DeferredOperation{
+		NodeId:         nodeId,
+		OperationType:  DeferredOperationSend,
+		Request:        []byte(`{}`),
+		MaxSatPerVbyte: 10,
+		TargetConf:     6,
+		Deadline:       time.Now().UTC().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("adding deferred operation: %v", err)
+	}
+
+	testutil.Given(t, "a deferred operation that was cancelled after it was read as pending")
+	cancelled, err := cancelDeferredOperation(db, deferredOperation.DeferredOperationId)
+	if err != nil || !cancelled {
+		testutil.Fatalf(t, "expected the pending operation to be cancelled, got %v: %v", cancelled, err)
+	}
+	deferredOperation.Status = DeferredOperationExecuting
+	deferredOperation.UpdatedOn = time.Now().UTC()
+	started, err := updateDeferredOperation(db, deferredOperation, DeferredOperationPending)
+	if err != nil {
+		testutil.Fatalf(t, "updating deferred operation: %v", err)
+	}
+	if started {
+		testutil.Errorf(t, "expected the cancelled operation not to move to executing")
+	}
+	deferredOperations, err := getDeferredOperationsByStatus(db, DeferredOperationCancelled)
+	if err != nil || len(deferredOperations) != 1 {
+		testutil.Fatalf(t, "expected the operation to stay cancelled, got %v: %v", deferredOperations, err)
+	}
+	testutil.Successf(t, "a cancelled operation is never executed")
+}
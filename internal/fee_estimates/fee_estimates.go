@@ -0,0 +1,98 @@
+package fee_estimates
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/lightning"
+)
+
+const feeEstimateTickerSeconds = 5 * 60
+const feeEstimateRetentionDays = 90
+const defaultTargetConf = 6
+
+// estimatedTargetConfs are estimated for every node, the targets of the pending deferred operations are added.
+var estimatedTargetConfs = []int32{2, 3, 6, 12, 24, 144} //nolint:gochecknoglobals
+
+type feeEstimateKey struct {
+	nodeId     int
+	targetConf int32
+}
+
+// FeeEstimateServiceStart stores the fee estimates of all active nodes and executes the deferred operations once the
+// estimate for their confirmation target drops to their maximum fee rate.
+func FeeEstimateServiceStart(ctx context.Context, db *sqlx.DB) {
+	ticker := time.NewTicker(feeEstimateTickerSeconds * time.Second)
+	defer ticker.Stop()
+
+	failInterruptedDeferredOperations(db)
+	refreshFeeEstimates(ctx, db)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refreshFeeEstimates(ctx, db)
+		}
+	}
+}
+
+func refreshFeeEstimates(ctx context.Context, db *sqlx.DB) {
+	deferredOperations, err := getDeferredOperationsByStatus(db, DeferredOperationPending)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to obtain the pending deferred operations.")
+	}
+
+	now := time.Now().UTC()
+	estimates := make(map[feeEstimateKey]float64)
+	var feeEstimates []FeeEstimate
+	for _, torqNodeSettings := range cache.GetActiveTorqNodeSettings() {
+		for _, targetConf := range getTargetConfs(torqNodeSettings.NodeId, deferredOperations) {
+			response, err := lightning.GetFeeEstimate(ctx, torqNodeSettings.NodeId, targetConf)
+			if err != nil {
+				if !errors.Is(err, lightning.ServiceInactiveError) {
+					log.Error().Err(err).Msgf("Failed to obtain the fee estimate for nodeId: %v and target: %v",
+						torqNodeSettings.NodeId, targetConf)
+				}
+				continue
+			}
+			estimates[feeEstimateKey{nodeId: torqNodeSettings.NodeId, targetConf: targetConf}] = response.SatPerVbyte
+			feeEstimates = append(feeEstimates, FeeEstimate{
+				NodeId:      torqNodeSettings.NodeId,
+				TargetConf:  targetConf,
+				SatPerVbyte: response.SatPerVbyte,
+				CreatedOn:   now,
+			})
+		}
+	}
+	if err = insertFeeEstimates(db, feeEstimates); err != nil {
+		log.Error().Err(err).Msg("Failed to store the fee estimates.")
+	}
+	if err = deleteFeeEstimates(db, now.AddDate(0, 0, -feeEstimateRetentionDays)); err != nil {
+		log.Error().Err(err).Msg("Failed to remove the expired fee estimates.")
+	}
+
+	for _, deferredOperation := range deferredOperations {
+		estimate, exists := estimates[feeEstimateKey{
+			nodeId:     deferredOperation.NodeId,
+			targetConf: deferredOperation.TargetConf,
+		}]
+		processDeferredOperation(ctx, db, deferredOperation, estimate, exists, now)
+	}
+}
+
+func getTargetConfs(nodeId int, deferredOperations []DeferredOperation) []int32 {
+	targetConfs := slices.Clone(estimatedTargetConfs)
+	for _, deferredOperation := range deferredOperations {
+		if deferredOperation.NodeId == nodeId && !slices.Contains(targetConfs, deferredOperation.TargetConf) {
+			targetConfs = append(targetConfs, deferredOperation.TargetConf)
+		}
+	}
+	return targetConfs
+}
@@ -0,0 +1,208 @@
+package fee_estimates
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+	"github.com/lncapital/torq/pkg/server_errors"
+)
+
+type DeferredOperationRequest struct {
+	NodeId         int                   `json:"nodeId"`
+	OperationType  DeferredOperationType `json:"operationType"`
+	MaxSatPerVbyte float64               `json:"maxSatPerVbyte"`
+	// TargetConf is the confirmation target of the estimate that is compared with MaxSatPerVbyte (default 6)
+	TargetConf        *int32    `json:"targetConf"`
+	Deadline          time.Time `json:"deadline"`
+	ExecuteAtDeadline bool      `json:"executeAtDeadline"`
+	// Request is the open channel, close channel or on-chain payment request, its fee rate is ignored
+	Request json.RawMessage `json:"request"`
+}
+
+// getFeeEstimatesHandler returns the most recent estimate of every confirmation target of the node.
+func getFeeEstimatesHandler(c *gin.Context, db *sqlx.DB) {
+	nodeId, ok := getTorqNodeId(c)
+	if !ok {
+		return
+	}
+	feeEstimates, err := getLatestFeeEstimates(db, nodeId)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting fee estimates")
+		return
+	}
+	c.JSON(http.StatusOK, feeEstimates)
+}
+
+func getFeeEstimateHistoryHandler(c *gin.Context, db *sqlx.DB) {
+	nodeId, ok := getTorqNodeId(c)
+	if !ok {
+		return
+	}
+	targetConf := int64(defaultTargetConf)
+	var err error
+	if c.Query("targetConf") != "" {
+		targetConf, err = strconv.ParseInt(c.Query("targetConf"), 10, 32)
+		if err != nil || targetConf < 1 {
+			server_errors.SendBadRequest(c, "Can't process targetConf")
+			return
+		}
+	}
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Invalid 'from' date.")
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Invalid 'to' date.")
+		return
+	}
+	// Include the whole 'to' day
+	to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	feeEstimates, err := getFeeEstimateHistory(db, nodeId, int32(targetConf), from, to)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting fee estimate history")
+		return
+	}
+	c.JSON(http.StatusOK, feeEstimates)
+}
+
+func getDeferredOperationsHandler(c *gin.Context, db *sqlx.DB) {
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+	deferredOperations, err := getDeferredOperations(db,
+		cache.GetAllTorqNodeIdsByNetwork(core.Bitcoin, core.Network(network)))
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting deferred operations")
+		return
+	}
+	c.JSON(http.StatusOK, deferredOperations)
+}
+
+// addDeferredOperationHandler queues an open, close or send until the fee estimate for its confirmation target is
+// at most maxSatPerVbyte.
+func addDeferredOperationHandler(c *gin.Context, db *sqlx.DB) {
+	var request DeferredOperationRequest
+	if err := c.BindJSON(&request); err != nil {
+		server_errors.SendBadRequestFromError(c, errors.Wrap(err, server_errors.JsonParseError))
+		return
+	}
+	if !slices.Contains(cache.GetAllTorqNodeIds(), request.NodeId) {
+		server_errors.SendBadRequest(c, "Unknown nodeId.")
+		return
+	}
+	if request.MaxSatPerVbyte <= 0 {
+		server_errors.SendBadRequest(c, "maxSatPerVbyte must be above 0")
+		return
+	}
+	targetConf := int32(defaultTargetConf)
+	if request.TargetConf != nil {
+		if *request.TargetConf < 1 {
+			server_errors.SendBadRequest(c, "targetConf must be at least 1")
+			return
+		}
+		targetConf = *request.TargetConf
+	}
+	if !request.Deadline.After(time.Now()) {
+		server_errors.SendBadRequest(c, "The deadline must be in the future")
+		return
+	}
+	if err := validateDeferredRequest(request.OperationType, request.Request); err != nil {
+		server_errors.SendBadRequestFromError(c, err)
+		return
+	}
+
+	deferredOperation, err := AddDeferredOperation(db, DeferredOperation{
+		NodeId:            request.NodeId,
+		OperationType:     request.OperationType,
+		Request:           request.Request,
+		MaxSatPerVbyte:    request.MaxSatPerVbyte,
+		TargetConf:        targetConf,
+		Deadline:          request.Deadline.UTC(),
+		ExecuteAtDeadline: request.ExecuteAtDeadline,
+	})
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Adding deferred operation")
+		return
+	}
+	c.JSON(http.StatusOK, deferredOperation)
+}
+
+func cancelDeferredOperationHandler(c *gin.Context, db *sqlx.DB) {
+	deferredOperationId, err := strconv.Atoi(c.Param("deferredOperationId"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Failed to find/parse deferredOperationId in the request.")
+		return
+	}
+	cancelled, err := cancelDeferredOperation(db, deferredOperationId)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Cancelling deferred operation")
+		return
+	}
+	if !cancelled {
+		server_errors.SendBadRequest(c, "Only pending deferred operations can be cancelled.")
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// validateDeferredRequest makes sure the request can be executed later, the fee rate fields are overwritten then.
+func validateDeferredRequest(operationType DeferredOperationType, request json.RawMessage) error {
+	switch operationType {
+	case DeferredOperationOpen:
+		var openChannelRequest lightning_helpers.OpenChannelRequest
+		if err := json.Unmarshal(request, &openChannelRequest); err != nil {
+			return errors.Wrap(err, "Parsing the open channel request")
+		}
+		if openChannelRequest.NodePubKey == "" || openChannelRequest.LocalFundingAmount <= 0 {
+			return errors.New("The open channel request needs a nodePubKey and a localFundingAmount")
+		}
+	case DeferredOperationClose:
+		var closeChannelRequest lightning_helpers.CloseChannelRequest
+		if err := json.Unmarshal(request, &closeChannelRequest); err != nil {
+			return errors.Wrap(err, "Parsing the close channel request")
+		}
+		if closeChannelRequest.ChannelId == 0 {
+			return errors.New("The close channel request needs a channelId")
+		}
+	case DeferredOperationSend:
+		var onChainPaymentRequest lightning_helpers.OnChainPaymentRequest
+		if err := json.Unmarshal(request, &onChainPaymentRequest); err != nil {
+			return errors.Wrap(err, "Parsing the on-chain payment request")
+		}
+		sendAll := onChainPaymentRequest.SendAll != nil && *onChainPaymentRequest.SendAll
+		if onChainPaymentRequest.Address == "" || (onChainPaymentRequest.AmountSat <= 0 && !sendAll) {
+			return errors.New("The on-chain payment request needs an address and an amountSat")
+		}
+	default:
+		return errors.Newf("Unknown operationType %v, use open, close or send", operationType)
+	}
+	return nil
+}
+
+func getTorqNodeId(c *gin.Context) (int, bool) {
+	nodeId, err := strconv.Atoi(c.Param("nodeId"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Failed to find/parse nodeId in the request.")
+		return 0, false
+	}
+	if !slices.Contains(cache.GetAllTorqNodeIds(), nodeId) {
+		server_errors.SendBadRequest(c, "Unknown nodeId.")
+		return 0, false
+	}
+	return nodeId, true
+}
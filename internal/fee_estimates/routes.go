@@ -0,0 +1,17 @@
+package fee_estimates
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func RegisterFeeEstimateRoutes(r *gin.RouterGroup, db *sqlx.DB) {
+	r.GET(":nodeId", func(c *gin.Context) { getFeeEstimatesHandler(c, db) })
+	r.GET(":nodeId/history", func(c *gin.Context) { getFeeEstimateHistoryHandler(c, db) })
+}
+
+func RegisterDeferredOperationRoutes(r *gin.RouterGroup, db *sqlx.DB) {
+	r.GET("", func(c *gin.Context) { getDeferredOperationsHandler(c, db) })
+	r.POST("", func(c *gin.Context) { addDeferredOperationHandler(c, db) })
+	r.DELETE(":deferredOperationId", func(c *gin.Context) { cancelDeferredOperationHandler(c, db) })
+}
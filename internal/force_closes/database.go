@@ -0,0 +1,35 @@
+package force_closes
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/lncapital/torq/internal/database"
+)
+
+// addNotification returns false when the event of the channel was notified before.
+func addNotification(db *sqlx.DB, nodeId int, channelPoint string, event string) (bool, error) {
+	result, err := db.Exec(`
+		INSERT INTO force_close_notification (node_id, channel_point, event, created_on)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (node_id, channel_point, event) DO NOTHING;`,
+		nodeId, channelPoint, event, time.Now().UTC())
+	if err != nil {
+		return false, errors.Wrap(err, database.SqlExecutionError)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, database.SqlAffectedRowsCheckError)
+	}
+	return rowsAffected == 1, nil
+}
+
+func deleteNotifications(db *sqlx.DB, before time.Time) error {
+	_, err := db.Exec(`DELETE FROM force_close_notification WHERE created_on < $1;`, before)
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	return nil
+}
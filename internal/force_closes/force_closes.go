@@ -0,0 +1,180 @@
+package force_closes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/communications"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+)
+
+const forceCloseTickerSeconds = 2 * 60
+const notificationRetentionDays = 90
+
+// htlcWarningBlocks is the number of blocks before a pending HTLC output matures or expires that a notification is sent
+const htlcWarningBlocks = 12
+
+type ForceClosingChannel struct {
+	lightning_helpers.ForceClosingChannel
+	NodeId     int    `json:"nodeId"`
+	NodeName   string `json:"nodeName"`
+	ChannelId  int    `json:"channelId"`
+	PeerNodeId int    `json:"peerNodeId"`
+	PeerAlias  string `json:"peerAlias"`
+	// PendingHtlcCount, PendingHtlcAmountSat and NextHtlcBlocksTilMaturity summarize the pending HTLC outputs
+	PendingHtlcCount          int    `json:"pendingHtlcCount"`
+	PendingHtlcAmountSat      int64  `json:"pendingHtlcAmountSat"`
+	NextHtlcBlocksTilMaturity *int32 `json:"nextHtlcBlocksTilMaturity"`
+}
+
+type forceCloseEvent struct {
+	event   string
+	message string
+}
+
+// GetForceClosingChannels returns the force closing channels of the active nodes, nodes that can't be reached are
+// skipped.
+func GetForceClosingChannels(ctx context.Context, nodeIds []int) []ForceClosingChannel {
+	forceClosingChannels := make([]ForceClosingChannel, 0)
+	for _, nodeId := range nodeIds {
+		channels, err := lightning.ForceClosingChannels(ctx, nodeId)
+		if err != nil {
+			if !errors.Is(err, lightning.ServiceInactiveError) {
+				log.Error().Err(err).Msgf("Failed to obtain the force closing channels for nodeId: %v", nodeId)
+			}
+			continue
+		}
+		nodeSettings := cache.GetNodeSettingsByNodeId(nodeId)
+		for _, channel := range channels {
+			forceClosingChannel := ForceClosingChannel{
+				ForceClosingChannel: channel,
+				NodeId:              nodeId,
+				NodeName:            cache.GetNodeAlias(nodeId),
+				ChannelId:           cache.GetChannelIdByChannelPoint(channel.ChannelPoint),
+				PeerNodeId: cache.GetPeerNodeIdByPublicKey(channel.RemotePublicKey,
+					nodeSettings.Chain, nodeSettings.Network),
+			}
+			if forceClosingChannel.PeerNodeId != 0 {
+				forceClosingChannel.PeerAlias = cache.GetNodeAlias(forceClosingChannel.PeerNodeId)
+			}
+			if forceClosingChannel.ClosingTxId == "" && forceClosingChannel.ChannelId != 0 {
+				closingTransactionHash := cache.GetChannelSettingByChannelId(forceClosingChannel.ChannelId).ClosingTransactionHash
+				if closingTransactionHash != nil {
+					forceClosingChannel.ClosingTxId = *closingTransactionHash
+				}
+			}
+			for _, htlc := range channel.PendingHtlcs {
+				forceClosingChannel.PendingHtlcCount++
+				forceClosingChannel.PendingHtlcAmountSat += htlc.AmountSat
+				if forceClosingChannel.NextHtlcBlocksTilMaturity == nil ||
+					htlc.BlocksTilMaturity < *forceClosingChannel.NextHtlcBlocksTilMaturity {
+					blocksTilMaturity := htlc.BlocksTilMaturity
+					forceClosingChannel.NextHtlcBlocksTilMaturity = &blocksTilMaturity
+				}
+			}
+			forceClosingChannels = append(forceClosingChannels, forceClosingChannel)
+		}
+	}
+	return forceClosingChannels
+}
+
+// ForceCloseMonitorServiceStart notifies when the funds of a force closed channel mature and when a pending HTLC
+// output is about to mature or expire.
+func ForceCloseMonitorServiceStart(ctx context.Context, db *sqlx.DB) {
+	ticker := time.NewTicker(forceCloseTickerSeconds * time.Second)
+	defer ticker.Stop()
+
+	monitorForceClosingChannels(ctx, db)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			monitorForceClosingChannels(ctx, db)
+		}
+	}
+}
+
+func monitorForceClosingChannels(ctx context.Context, db *sqlx.DB) {
+	var nodeIds []int
+	for _, torqNodeSettings := range cache.GetActiveTorqNodeSettings() {
+		nodeIds = append(nodeIds, torqNodeSettings.NodeId)
+	}
+	for _, forceClosingChannel := range GetForceClosingChannels(ctx, nodeIds) {
+		for _, event := range getForceCloseEvents(forceClosingChannel) {
+			added, err := addNotification(db, forceClosingChannel.NodeId, forceClosingChannel.ChannelPoint, event.event)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to store the force close notification for channel point: %v",
+					forceClosingChannel.ChannelPoint)
+				continue
+			}
+			if !added {
+				continue
+			}
+			message := event.message
+			communications.HandleNotification(db, core.NotifierEvent{
+				EventData: core.EventData{
+					EventTime: time.Now().UTC(),
+					NodeId:    forceClosingChannel.NodeId,
+				},
+				Notification:     &message,
+				NotificationType: core.ForceClose,
+			})
+		}
+	}
+	err := deleteNotifications(db, time.Now().AddDate(0, 0, -notificationRetentionDays))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete the old force close notifications.")
+	}
+}
+
+// getForceCloseEvents returns the events of the channel that deserve a notification, every event is notified once.
+func getForceCloseEvents(channel ForceClosingChannel) []forceCloseEvent {
+	// TODO FIXME Language from user for translations
+	description := fmt.Sprintf("force closed channel %v", channel.ChannelPoint)
+	if channel.PeerAlias != "" {
+		description = fmt.Sprintf("%v with %v", description, channel.PeerAlias)
+	}
+
+	var events []forceCloseEvent
+	if channel.BlocksTilMaturity != nil && *channel.BlocksTilMaturity <= 0 && channel.LimboBalanceSat > 0 {
+		events = append(events, forceCloseEvent{
+			event: "matured",
+			message: fmt.Sprintf("The funds of %v matured at height %v, %v sat is being swept to the wallet",
+				description, *channel.MaturityHeight, channel.LimboBalanceSat),
+		})
+	}
+	for _, htlc := range channel.PendingHtlcs {
+		direction := "Outgoing"
+		if htlc.Incoming {
+			direction = "Incoming"
+		}
+		htlcKey := htlc.Outpoint
+		if htlcKey == "" {
+			htlcKey = fmt.Sprintf("%v:%v:%v", direction, htlc.MaturityHeight, htlc.AmountSat)
+		}
+		switch {
+		case htlc.BlocksTilMaturity <= 0:
+			events = append(events, forceCloseEvent{
+				event: fmt.Sprintf("htlc:%v:%v:matured", htlcKey, htlc.Stage),
+				message: fmt.Sprintf("%v HTLC of %v sat on %v reached its height %v",
+					direction, htlc.AmountSat, description, htlc.MaturityHeight),
+			})
+		case htlc.BlocksTilMaturity <= htlcWarningBlocks:
+			events = append(events, forceCloseEvent{
+				event: fmt.Sprintf("htlc:%v:%v:approaching", htlcKey, htlc.Stage),
+				message: fmt.Sprintf("%v HTLC of %v sat on %v reaches its height %v in %v blocks",
+					direction, htlc.AmountSat, description, htlc.MaturityHeight, htlc.BlocksTilMaturity),
+			})
+		}
+	}
+	return events
+}
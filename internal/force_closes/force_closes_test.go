@@ -0,0 +1,49 @@
+package force_closes
+
+import (
+	"testing"
+
+	"github.com/lncapital/torq/internal/lightning_helpers"
+)
+
+func TestGetForceCloseEvents(t *testing.T) {
+	maturityHeight := uint32(800_000)
+	blocksTilMaturity := int32(0)
+	channel := ForceClosingChannel{
+		ForceClosingChannel: lightning_helpers.ForceClosingChannel{
+			ChannelPoint:      "a:0",
+			LimboBalanceSat:   1000,
+			MaturityHeight:    &maturityHeight,
+			BlocksTilMaturity: &blocksTilMaturity,
+			PendingHtlcs: []lightning_helpers.PendingHtlc{
+				{Outpoint: "b:1", AmountSat: 100, MaturityHeight: 800_005, BlocksTilMaturity: 5, Stage: 1},
+				{Outpoint: "b:2", AmountSat: 200, MaturityHeight: 799_999, BlocksTilMaturity: -1, Stage: 2},
+				{Outpoint: "b:3", AmountSat: 300, MaturityHeight: 800_100, BlocksTilMaturity: 100, Stage: 1},
+				{Incoming: true, AmountSat: 400, MaturityHeight: 800_002, BlocksTilMaturity: 2},
+			},
+		},
+		PeerAlias: "peer",
+	}
+
+	events := getForceCloseEvents(channel)
+	expected := []string{
+		"matured",
+		"htlc:b:1:1:approaching",
+		"htlc:b:2:2:matured",
+		"htlc:Incoming:800002:400:0:approaching",
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %v events, got %+v", len(expected), events)
+	}
+	for i, event := range events {
+		if event.event != expected[i] {
+			t.Errorf("expected event %v, got %v", expected[i], event.event)
+		}
+	}
+
+	blocksTilMaturity = 10
+	channel.PendingHtlcs = nil
+	if events := getForceCloseEvents(channel); len(events) != 0 {
+		t.Errorf("expected no events before maturity, got %+v", events)
+	}
+}
@@ -0,0 +1,22 @@
+package force_closes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/pkg/server_errors"
+)
+
+func getForceClosingChannelsHandler(c *gin.Context) {
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+	nodeIds := cache.GetAllTorqNodeIdsByNetwork(core.Bitcoin, core.Network(network))
+	c.JSON(http.StatusOK, GetForceClosingChannels(c.Request.Context(), nodeIds))
+}
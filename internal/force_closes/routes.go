@@ -0,0 +1,9 @@
+package force_closes
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterForceCloseRoutes(r *gin.RouterGroup) {
+	r.GET("", func(c *gin.Context) { getForceClosingChannelsHandler(c) })
+}
@@ -0,0 +1,105 @@
+package forecasts
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/database"
+)
+
+// defaultWindowDays is the amount of history used to calculate the flow rates
+const defaultWindowDays = 7
+const maximumWindowDays = 90
+const forecastTickerSeconds = 15 * 60
+
+func ForecastServiceStart(ctx context.Context, db *sqlx.DB) {
+	ticker := time.NewTicker(forecastTickerSeconds * time.Second)
+	defer ticker.Stop()
+
+	refreshChannelFlowRates(db)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refreshChannelFlowRates(db)
+		}
+	}
+}
+
+func refreshChannelFlowRates(db *sqlx.DB) {
+	flowRates, err := getChannelFlowRates(db, cache.GetAllTorqNodeIds(), defaultWindowDays, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to calculate the channel flow rates for the liquidity forecasts.")
+		return
+	}
+	cache.SetChannelFlowRates(flowRates)
+}
+
+// getChannelFlowRates calculates the average daily balance movement of every channel over the last windowDays.
+// Every forward, payment, rebalance and invoice that touched the channel moves the local balance.
+func getChannelFlowRates(db *sqlx.DB, nodeIds []int, windowDays int, now time.Time) ([]cache.ChannelFlowRate, error) {
+	var publicKeys []string
+	for _, nodeId := range nodeIds {
+		publicKeys = append(publicKeys, cache.GetNodeSettingsByNodeId(nodeId).PublicKey)
+	}
+
+	windowStart := now.AddDate(0, 0, -windowDays)
+	rows, err := db.Queryx(`
+		SELECT node_id, channel_id,
+			COALESCE(ROUND(SUM(outbound_msat) / 1000 / $3), 0)::bigint AS outbound,
+			COALESCE(ROUND(SUM(inbound_msat) / 1000 / $3), 0)::bigint AS inbound
+		FROM (
+			SELECT node_id, outgoing_channel_id AS channel_id, outgoing_amount_msat AS outbound_msat, 0 AS inbound_msat
+			FROM forward
+			WHERE node_id = ANY($1) AND time::timestamptz >= $2
+			UNION ALL
+			SELECT node_id, incoming_channel_id AS channel_id, 0 AS outbound_msat, incoming_amount_msat AS inbound_msat
+			FROM forward
+			WHERE node_id = ANY($1) AND time::timestamptz >= $2
+			UNION ALL
+			SELECT node_id, outgoing_channel_id AS channel_id, value_msat + fee_msat AS outbound_msat, 0 AS inbound_msat
+			FROM payment
+			WHERE status = 'SUCCEEDED' AND node_id = ANY($1) AND creation_timestamp >= $2
+			UNION ALL
+			-- rebalances are payments of which the last hop is one of our nodes, they arrive on the incoming channel
+			SELECT node_id, incoming_channel_id AS channel_id, 0 AS outbound_msat,
+				COALESCE(rebalance_amount_msat, value_msat) AS inbound_msat
+			FROM payment
+			WHERE status = 'SUCCEEDED' AND node_id = ANY($1) AND creation_timestamp >= $2
+				AND htlcs->-1->'route'->'hops'->-1->>'pub_key' = ANY($4)
+			UNION ALL
+			-- the invoices paid by our own rebalances are already counted as the rebalance inbound
+			SELECT i.node_id, i.channel_id, 0 AS outbound_msat, i.amt_paid_msat AS inbound_msat
+			FROM invoice i
+			WHERE i.invoice_state = 'SETTLED' AND i.node_id = ANY($1) AND i.settle_date >= $2
+				AND NOT EXISTS (
+					SELECT 1
+					FROM payment p
+					WHERE p.payment_hash = i.r_hash AND p.status = 'SUCCEEDED' AND p.node_id = ANY($1)
+				)
+		) AS flows
+		WHERE channel_id IS NOT NULL
+		GROUP BY node_id, channel_id;`, pq.Array(nodeIds), windowStart, windowDays, pq.Array(publicKeys))
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	defer rows.Close()
+
+	var flowRates []cache.ChannelFlowRate
+	for rows.Next() {
+		flowRate := cache.ChannelFlowRate{WindowStart: windowStart, CalculatedOn: now}
+		err = rows.Scan(&flowRate.NodeId, &flowRate.ChannelId, &flowRate.Outbound, &flowRate.Inbound)
+		if err != nil {
+			return nil, errors.Wrap(err, "SQL row scan for channel flow rates")
+		}
+		flowRates = append(flowRates, flowRate)
+	}
+	return flowRates, errors.Wrap(rows.Err(), "Iterating channel flow rates")
+}
@@ -0,0 +1,97 @@
+package forecasts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/testutil"
+)
+
+func getChannelIdByLndShortChannelId(t *testing.T, db *sqlx.DB, lndShortChannelId uint64) int {
+	var channelId int
+	err := db.Get(&channelId, "SELECT channel_id FROM channel WHERE lnd_short_channel_id=$1;", lndShortChannelId)
+	if err != nil {
+		t.Fatalf("obtaining channel id: %v", err)
+	}
+	return channelId
+}
+
+func TestGetChannelFlowRates(t *testing.T) {
+	srv, err := testutil.InitTestDBConn()
+	if err != nil {
+		panic(err)
+	}
+
+	db, cancel, err := srv.NewTestDatabase()
+	defer cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	nodeId, _ := testutil.Setup(db, cancel)
+	channel1 := getChannelIdByLndShortChannelId(t, db, 1111)
+	channel2 := getChannelIdByLndShortChannelId(t, db, 2222)
+	channel3 := getChannelIdByLndShortChannelId(t, db, 3333)
+	channel4 := getChannelIdByLndShortChannelId(t, db, 4444)
+	now := time.Now().UTC()
+	eventTime := now.AddDate(0, 0, -1)
+	// 7_000_000 sat over the 7 day window is 1_000_000 sat per day
+	amountMsat := int64(7_000_000_000)
+
+	addPayment := func(paymentIndex int, paymentHash string, outgoingChannelId int, incomingChannelId int, hops string) {
+		_, err := db.Exec(`INSERT INTO payment (payment_hash, creation_timestamp, payment_preimage, value_msat,
+				payment_request, status, fee_msat, creation_time_ns, htlcs, payment_index, failure_reason,
+				incoming_channel_id, outgoing_channel_id, node_id, created_on)
+			VALUES ($1, $2, '', $3, '', 'SUCCEEDED', 0, 0, $4, $8, '',
+				$5, $6, $7, $2);`,
+			paymentHash, eventTime, amountMsat, `[{"route":{"hops":`+hops+`}}]`,
+			incomingChannelId, outgoingChannelId, nodeId, paymentIndex)
+		if err != nil {
+			t.Fatalf("inserting payment: %v", err)
+		}
+	}
+	addInvoice := func(rHash string, channelId int) {
+		_, err := db.Exec(`INSERT INTO invoice (r_hash, value_msat, amt_paid_msat, invoice_state, settle_date,
+				channel_id, node_id, created_on)
+			VALUES ($1, $2, $2, 'SETTLED', $3, $4, $5, $3);`, rHash, amountMsat, eventTime, channelId, nodeId)
+		if err != nil {
+			t.Fatalf("inserting invoice: %v", err)
+		}
+	}
+
+	// A rebalance from channel 2 to channel 1 pays our own invoice
+	addPayment(1, "rebalance", channel2, channel1,
+		`[{"chan_id":"2222","pub_key":"`+testutil.TestPublicKey2+`"},`+
+			`{"chan_id":"1111","pub_key":"`+testutil.TestPublicKey1+`"}]`)
+	addInvoice("rebalance", channel1)
+	// A direct payment to the peer has the same first and last hop channel
+	addPayment(2, "direct", channel3, channel3, `[{"chan_id":"3333","pub_key":"`+testutil.TestPublicKey2+`"}]`)
+	addInvoice("received", channel4)
+
+	flowRates, err := getChannelFlowRates(db, []int{nodeId}, 7, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flowRatesByChannel := make(map[int]cache.ChannelFlowRate)
+	for _, flowRate := range flowRates {
+		flowRatesByChannel[flowRate.ChannelId] = flowRate
+	}
+
+	expected := map[int][2]int64{
+		channel1: {0, 1_000_000},
+		channel2: {1_000_000, 0},
+		channel3: {1_000_000, 0},
+		channel4: {0, 1_000_000},
+	}
+	for channelId, want := range expected {
+		flowRate := flowRatesByChannel[channelId]
+		if flowRate.Outbound != want[0] || flowRate.Inbound != want[1] {
+			testutil.Errorf(t, "channel %v: got outbound %v inbound %v, want %v", channelId,
+				flowRate.Outbound, flowRate.Inbound, want)
+		}
+	}
+}
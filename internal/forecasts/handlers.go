@@ -0,0 +1,141 @@
+package forecasts
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/channels"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/pkg/server_errors"
+)
+
+type ChannelLiquidityForecast struct {
+	NodeId         int    `json:"nodeId"`
+	ChannelId      int    `json:"channelId"`
+	ShortChannelId string `json:"shortChannelId"`
+	PeerAlias      string `json:"peerAlias"`
+	Capacity       int64  `json:"capacity"`
+	LocalBalance   int64  `json:"localBalance"`
+	RemoteBalance  int64  `json:"remoteBalance"`
+	cache.ChannelFlowRate
+	cache.ChannelForecast
+}
+
+// getForecastsHandler returns the forecast of every open channel ordered by the first expected depletion.
+// The cached flow rates are used unless lookbackDays is provided.
+func getForecastsHandler(c *gin.Context, db *sqlx.DB) {
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+
+	channelBodies, err := channels.GetChannelsByNetwork(core.Network(network))
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting channels")
+		return
+	}
+
+	flowRates := make(map[int]cache.ChannelFlowRate)
+	if c.Query("lookbackDays") != "" {
+		lookbackDays, err := strconv.Atoi(c.Query("lookbackDays"))
+		if err != nil || lookbackDays < 1 || lookbackDays > maximumWindowDays {
+			server_errors.SendBadRequest(c, "lookbackDays must be a number between 1 and 90")
+			return
+		}
+		rates, err := getChannelFlowRates(db,
+			cache.GetAllTorqNodeIdsByNetwork(core.Bitcoin, core.Network(network)), lookbackDays, time.Now())
+		if err != nil {
+			server_errors.WrapLogAndSendServerError(c, err, "Calculating channel flow rates")
+			return
+		}
+		for _, rate := range rates {
+			flowRates[rate.ChannelId] = rate
+		}
+	} else {
+		for _, rate := range cache.GetChannelFlowRates() {
+			flowRates[rate.ChannelId] = rate
+		}
+	}
+
+	now := time.Now()
+	forecasts := make([]ChannelLiquidityForecast, 0, len(channelBodies))
+	for _, channelBody := range channelBodies {
+		flowRate, exists := flowRates[channelBody.ChannelId]
+		if !exists {
+			flowRate = cache.ChannelFlowRate{NodeId: channelBody.NodeId, ChannelId: channelBody.ChannelId}
+		}
+		forecasts = append(forecasts, ChannelLiquidityForecast{
+			NodeId:          channelBody.NodeId,
+			ChannelId:       channelBody.ChannelId,
+			ShortChannelId:  channelBody.ShortChannelId,
+			PeerAlias:       channelBody.PeerAlias,
+			Capacity:        channelBody.Capacity,
+			LocalBalance:    channelBody.LocalBalance,
+			RemoteBalance:   channelBody.RemoteBalance,
+			ChannelFlowRate: flowRate,
+			ChannelForecast: flowRate.Forecast(channelBody.LocalBalance, channelBody.RemoteBalance, now),
+		})
+	}
+	sort.SliceStable(forecasts, func(i, j int) bool {
+		return firstEvent(forecasts[i].ChannelForecast) < firstEvent(forecasts[j].ChannelForecast)
+	})
+	c.JSON(http.StatusOK, forecasts)
+}
+
+func getChannelForecastHandler(c *gin.Context) {
+	channelId, err := strconv.Atoi(c.Param("channelId"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Failed to find/parse channelId in the request.")
+		return
+	}
+	channelSettings := cache.GetChannelSettingByChannelId(channelId)
+	if channelSettings.ChannelId == 0 {
+		server_errors.SendBadRequest(c, "Unknown channelId.")
+		return
+	}
+	nodeId := channelSettings.FirstNodeId
+	if !slices.Contains(cache.GetAllTorqNodeIds(), nodeId) {
+		nodeId = channelSettings.SecondNodeId
+	}
+	channelBodies, err := channels.GetChannelsByIds(nodeId, []int{channelId})
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting channel")
+		return
+	}
+	if len(channelBodies) == 0 {
+		server_errors.SendBadRequest(c, "Channel is not open.")
+		return
+	}
+	channelBody := channelBodies[0]
+	flowRate := cache.GetChannelFlowRate(channelId)
+	c.JSON(http.StatusOK, ChannelLiquidityForecast{
+		NodeId:          channelBody.NodeId,
+		ChannelId:       channelBody.ChannelId,
+		ShortChannelId:  channelBody.ShortChannelId,
+		PeerAlias:       channelBody.PeerAlias,
+		Capacity:        channelBody.Capacity,
+		LocalBalance:    channelBody.LocalBalance,
+		RemoteBalance:   channelBody.RemoteBalance,
+		ChannelFlowRate: flowRate,
+		ChannelForecast: flowRate.Forecast(channelBody.LocalBalance, channelBody.RemoteBalance, time.Now()),
+	})
+}
+
+// firstEvent is used for sorting: channels without an expected depletion or saturation go last
+func firstEvent(forecast cache.ChannelForecast) uint64 {
+	switch {
+	case forecast.DepletionSecondsDelta != nil:
+		return *forecast.DepletionSecondsDelta
+	case forecast.SaturationSecondsDelta != nil:
+		return *forecast.SaturationSecondsDelta
+	}
+	return ^uint64(0)
+}
@@ -0,0 +1,11 @@
+package forecasts
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func RegisterForecastRoutes(r *gin.RouterGroup, db *sqlx.DB) {
+	r.GET("", func(c *gin.Context) { getForecastsHandler(c, db) })
+	r.GET(":channelId", func(c *gin.Context) { getChannelForecastHandler(c) })
+}
@@ -5,15 +5,19 @@ import (
 	"strconv"
 	"time"
 
+	ah "github.com/lncapital/torq/internal/api_helpers"
 	"github.com/lncapital/torq/internal/cache"
 	"github.com/lncapital/torq/internal/core"
+	qp "github.com/lncapital/torq/internal/query_parser"
 	"github.com/lncapital/torq/internal/tags"
 
 	"github.com/lib/pq"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/cockroachdb/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
 	"gopkg.in/guregu/null.v4"
 
 	"github.com/lncapital/torq/pkg/server_errors"
@@ -264,3 +268,131 @@ func getForwardsTableData(db *sqlx.DB, nodeIds []int,
 
 	return r, nil
 }
+
+func getForwardsExportHandler(c *gin.Context, db *sqlx.DB) {
+	format, err := ah.GetExportFormat(c.Query("format"))
+	if err != nil {
+		server_errors.SendBadRequest(c, err.Error())
+		return
+	}
+
+	// Filter parser with whitelisted columns
+	var filter sq.Sqlizer
+	filterParam := c.Query("filter")
+	if filterParam != "" {
+		filter, err = qp.ParseFilterParam(filterParam, []string{
+			"date",
+			"torq_node_id",
+			"torq_node_name",
+			"incoming_channel_id",
+			"incoming_short_channel_id",
+			"incoming_alias",
+			"outgoing_channel_id",
+			"outgoing_short_channel_id",
+			"outgoing_alias",
+			"incoming_amount",
+			"outgoing_amount",
+			"fee",
+		})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	var sort []string
+	sortParam := c.Query("order")
+	if sortParam != "" {
+		// Order parser with whitelisted columns
+		sort, err = qp.ParseOrderParams(
+			sortParam,
+			[]string{
+				"date",
+				"torq_node_name",
+				"incoming_alias",
+				"outgoing_alias",
+				"incoming_amount",
+				"outgoing_amount",
+				"fee",
+			})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+
+	chain := core.Bitcoin
+
+	rows, err := queryForwards(db, cache.GetAllTorqNodeIdsByNetwork(chain, core.Network(network)), filter, sort)
+	if err != nil {
+		server_errors.LogAndSendServerError(c, err)
+		return
+	}
+	defer rows.Close()
+
+	exporter := ah.NewExporter(c, format, "forwards")
+	err = exporter.WriteRows(rows)
+	if err != nil {
+		log.Error().Err(err).Msg("Exporting forwards")
+	}
+	exporter.Flush()
+}
+
+// queryForwards returns the individual forwards (not aggregated per channel like the forwards table)
+func queryForwards(db *sqlx.DB, nodeIds []int, filter sq.Sqlizer, order []string) (*sqlx.Rows, error) {
+	//language=PostgreSQL
+	qb := sq.Select("*").
+		PlaceholderFormat(sq.Dollar).
+		FromSelect(
+			sq.Select(`
+				f.time as date,
+				f.node_id as torq_node_id,
+				coalesce(ncd.name, '') as torq_node_name,
+				f.incoming_channel_id,
+				ic.short_channel_id as incoming_short_channel_id,
+				ia.alias as incoming_alias,
+				f.outgoing_channel_id,
+				oc.short_channel_id as outgoing_short_channel_id,
+				oa.alias as outgoing_alias,
+				f.incoming_amount_msat/1000 as incoming_amount,
+				f.outgoing_amount_msat/1000 as outgoing_amount,
+				f.fee_msat/1000 as fee,
+				f.fee_msat
+			`).
+				PlaceholderFormat(sq.Dollar).
+				From("forward f").
+				LeftJoin("node_connection_details ncd ON ncd.node_id = f.node_id").
+				LeftJoin("channel ic ON ic.channel_id = f.incoming_channel_id").
+				LeftJoin("channel oc ON oc.channel_id = f.outgoing_channel_id").
+				LeftJoin(`(
+					SELECT event_node_id, last(alias, timestamp) as alias
+					FROM node_event
+					GROUP BY event_node_id
+				) ia ON ia.event_node_id = CASE WHEN ic.first_node_id = f.node_id THEN ic.second_node_id ELSE ic.first_node_id END`).
+				LeftJoin(`(
+					SELECT event_node_id, last(alias, timestamp) as alias
+					FROM node_event
+					GROUP BY event_node_id
+				) oa ON oa.event_node_id = CASE WHEN oc.first_node_id = f.node_id THEN oc.second_node_id ELSE oc.first_node_id END`).
+				Where(sq.Eq{"f.node_id": nodeIds}),
+			"subquery").
+		Where(filter).
+		OrderBy(order...)
+
+	qs, args, err := qb.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "SQL compile statement")
+	}
+
+	rows, err := db.Queryx(qs, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Run SQL Query")
+	}
+	return rows, nil
+}
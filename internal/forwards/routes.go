@@ -7,4 +7,5 @@ import (
 
 func RegisterForwardsRoutes(r *gin.RouterGroup, db *sqlx.DB) {
 	r.GET("", func(c *gin.Context) { getForwardsTableHandler(c, db) })
+	r.GET("export", func(c *gin.Context) { getForwardsExportHandler(c, db) })
 }
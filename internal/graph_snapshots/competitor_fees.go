@@ -0,0 +1,127 @@
+package graph_snapshots
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lncapital/torq/internal/cache"
+)
+
+// cheapestCompetitorCount is the number of cheapest competitors kept for every peer
+const cheapestCompetitorCount = 5
+
+// competitorFees calculates for every peer of our node the distribution of the fees the other nodes charge to route
+// into that peer. Only enabled policies count and a competitor with multiple channels to the peer counts once with
+// its cheapest channel.
+func (graph *Graph) competitorFees(ownPublicKey string,
+	peerPublicKeys []string,
+	now time.Time) map[string]cache.CompetitorFees {
+
+	ownIndex, exists := graph.nodeIndexes[ownPublicKey]
+	if !exists {
+		ownIndex = -1
+	}
+	peers := make(map[int]bool)
+	for _, channel := range graph.Channels {
+		if channel.Node1 == ownIndex {
+			peers[channel.Node2] = true
+		}
+		if channel.Node2 == ownIndex {
+			peers[channel.Node1] = true
+		}
+	}
+	// Private channels are not part of the public graph
+	for _, publicKey := range peerPublicKeys {
+		if peerIndex, exists := graph.nodeIndexes[publicKey]; exists {
+			peers[peerIndex] = true
+		}
+	}
+	delete(peers, ownIndex)
+
+	competitorsByPeer := make(map[int]map[int]cache.Competitor)
+	addCompetitor := func(peer int, competitor int, capacity int64, policy *Policy) {
+		if !peers[peer] || competitor == ownIndex || competitor == peer || policy == nil || policy.Disabled {
+			return
+		}
+		if competitorsByPeer[peer] == nil {
+			competitorsByPeer[peer] = make(map[int]cache.Competitor)
+		}
+		existing, exists := competitorsByPeer[peer][competitor]
+		if exists && (existing.FeeRateMilliMsat < policy.FeeRateMilliMsat ||
+			(existing.FeeRateMilliMsat == policy.FeeRateMilliMsat && existing.FeeBaseMsat <= policy.FeeBaseMsat)) {
+			return
+		}
+		competitorsByPeer[peer][competitor] = cache.Competitor{
+			PublicKey:        graph.PublicKeys[competitor],
+			Alias:            graph.aliases[competitor],
+			FeeRateMilliMsat: policy.FeeRateMilliMsat,
+			FeeBaseMsat:      policy.FeeBaseMsat,
+			Capacity:         capacity,
+		}
+	}
+	for _, channel := range graph.Channels {
+		// The policy of a node applies to the payments it forwards over the channel to the other node
+		addCompetitor(channel.Node2, channel.Node1, channel.Capacity, channel.Node1Policy)
+		addCompetitor(channel.Node1, channel.Node2, channel.Capacity, channel.Node2Policy)
+	}
+
+	competitorFees := make(map[string]cache.CompetitorFees, len(competitorsByPeer))
+	for peer, competitorsByIndex := range competitorsByPeer {
+		competitors := make([]cache.Competitor, 0, len(competitorsByIndex))
+		for _, competitor := range competitorsByIndex {
+			competitors = append(competitors, competitor)
+		}
+		competitorFees[graph.PublicKeys[peer]] = summarizeCompetitors(competitors, now)
+	}
+	return competitorFees
+}
+
+func summarizeCompetitors(competitors []cache.Competitor, now time.Time) cache.CompetitorFees {
+	sort.Slice(competitors, func(i, j int) bool {
+		if competitors[i].FeeRateMilliMsat != competitors[j].FeeRateMilliMsat {
+			return competitors[i].FeeRateMilliMsat < competitors[j].FeeRateMilliMsat
+		}
+		if competitors[i].FeeBaseMsat != competitors[j].FeeBaseMsat {
+			return competitors[i].FeeBaseMsat < competitors[j].FeeBaseMsat
+		}
+		return competitors[i].PublicKey < competitors[j].PublicKey
+	})
+	feeRates := make([]int64, len(competitors))
+	feeBases := make([]int64, len(competitors))
+	for ix, competitor := range competitors {
+		feeRates[ix] = competitor.FeeRateMilliMsat
+		feeBases[ix] = competitor.FeeBaseMsat
+	}
+	cheapest := competitors
+	if len(cheapest) > cheapestCompetitorCount {
+		cheapest = cheapest[:cheapestCompetitorCount]
+	}
+	return cache.CompetitorFees{
+		Count:          len(competitors),
+		FeeRateMin:     feeRates[0],
+		FeeRateP10:     percentile(feeRates, 10),
+		FeeRateP20:     percentile(feeRates, 20),
+		FeeRateP30:     percentile(feeRates, 30),
+		FeeRateP40:     percentile(feeRates, 40),
+		FeeRateMedian:  percentile(feeRates, 50),
+		FeeRateP60:     percentile(feeRates, 60),
+		FeeRateP70:     percentile(feeRates, 70),
+		FeeRateP80:     percentile(feeRates, 80),
+		FeeRateP90:     percentile(feeRates, 90),
+		FeeRateMax:     feeRates[len(feeRates)-1],
+		FeeBaseMedian:  median(feeBases),
+		Cheapest:       append([]cache.Competitor{}, cheapest...),
+		SortedFeeRates: feeRates,
+		CalculatedOn:   now.UTC(),
+	}
+}
+
+// percentile returns the nearest-rank percentile of the sorted values.
+func percentile(sorted []int64, percent int) int64 {
+	rank := int(math.Ceil(float64(percent)/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,50 @@
+package graph_snapshots
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCompetitorFees(t *testing.T) {
+	graph := NewGraph()
+	channelId := 0
+	addChannel := func(source string, destination string, sourcePolicy *Policy, destinationPolicy *Policy) {
+		channelId++
+		graph.AddChannelPolicy(strconv.Itoa(channelId), source, destination, 1_000_000, sourcePolicy)
+		graph.AddChannelPolicy(strconv.Itoa(channelId), destination, source, 1_000_000, destinationPolicy)
+	}
+	addChannel("us", "peer", &Policy{FeeRateMilliMsat: 250}, &Policy{FeeRateMilliMsat: 10})
+	for ix := 1; ix <= 4; ix++ {
+		// The peer's policy is for the payments out of the peer so it doesn't count
+		addChannel("x"+strconv.Itoa(ix), "peer",
+			&Policy{FeeRateMilliMsat: int64(ix * 100), FeeBaseMsat: 1000}, &Policy{FeeRateMilliMsat: 1})
+	}
+	// A competitor counts once with its cheapest channel
+	addChannel("x1", "peer", &Policy{FeeRateMilliMsat: 50}, nil)
+	addChannel("x5", "peer", &Policy{FeeRateMilliMsat: 1, Disabled: true}, nil)
+	addChannel("x6", "other", &Policy{FeeRateMilliMsat: 1}, nil)
+	graph.finalize()
+
+	competitorFees := graph.competitorFees("us", nil, time.Now())
+	if len(competitorFees) != 1 {
+		t.Fatalf("expected competitor fees for 1 peer, got %v", len(competitorFees))
+	}
+	peer := competitorFees["peer"]
+	if peer.Count != 4 {
+		t.Errorf("expected 4 competitors, got %v", peer.Count)
+	}
+	if peer.FeeRateMin != 50 || peer.FeeRateMax != 400 {
+		t.Errorf("expected fee rates from 50 to 400, got %v to %v", peer.FeeRateMin, peer.FeeRateMax)
+	}
+	if peer.FeeRateP40 != 200 || peer.FeeRateMedian != 200 || peer.FeeRateP60 != 300 {
+		t.Errorf("expected percentiles 200, 200 and 300, got %v, %v and %v",
+			peer.FeeRateP40, peer.FeeRateMedian, peer.FeeRateP60)
+	}
+	if peer.Cheapest[0].PublicKey != "x1" || peer.Cheapest[0].FeeBaseMsat != 0 {
+		t.Errorf("expected the cheapest channel of x1 first, got %+v", peer.Cheapest[0])
+	}
+	if percentile := peer.FeeRatePercentile(250); percentile != 50 {
+		t.Errorf("expected half of the competitors to be cheaper, got %v", percentile)
+	}
+}
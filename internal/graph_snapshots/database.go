@@ -0,0 +1,179 @@
+package graph_snapshots
+
+import (
+	"database/sql"
+	"math/rand"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/database"
+)
+
+// snapshotRetentionDays is how long snapshots are kept, the uptime of a node is calculated over this period.
+const snapshotRetentionDays = 30
+
+type Snapshot struct {
+	GraphSnapshotId int       `json:"graphSnapshotId" db:"graph_snapshot_id"`
+	NodeId          int       `json:"nodeId" db:"node_id"`
+	NodeCount       int       `json:"nodeCount" db:"node_count"`
+	ChannelCount    int       `json:"channelCount" db:"channel_count"`
+	CreatedOn       time.Time `json:"createdOn" db:"created_on"`
+}
+
+// StoreSnapshot stores the graph with the statistics of every node, refreshes the competitor fees of the node's
+// peers and removes the expired snapshots.
+func StoreSnapshot(db *sqlx.DB, nodeId int, graph *Graph, now time.Time) (Snapshot, error) {
+	graph.finalize()
+	statistics := graph.nodeStatistics(rand.New(rand.NewSource(now.UnixNano()))) //nolint:gosec
+	cache.SetCompetitorFees(nodeId,
+		graph.competitorFees(cache.GetNodeSettingsByNodeId(nodeId).PublicKey, getPeerPublicKeys(nodeId), now))
+	channels, err := encodeChannels(graph)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot := Snapshot{
+		NodeId:       nodeId,
+		NodeCount:    len(graph.PublicKeys),
+		ChannelCount: len(graph.Channels),
+		CreatedOn:    now.UTC(),
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return Snapshot{}, errors.Wrap(err, "Starting transaction")
+	}
+	err = insertSnapshot(tx, &snapshot, channels, statistics)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return Snapshot{}, errors.Wrap(rollbackErr, "Rolling back graph snapshot")
+		}
+		return Snapshot{}, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return Snapshot{}, errors.Wrap(err, "Committing graph snapshot")
+	}
+
+	_, err = db.Exec(`DELETE FROM graph_snapshot WHERE node_id=$1 AND created_on < $2;`,
+		nodeId, now.AddDate(0, 0, -snapshotRetentionDays))
+	if err != nil {
+		return snapshot, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return snapshot, nil
+}
+
+func insertSnapshot(tx *sqlx.Tx, snapshot *Snapshot, channels []byte, statistics []NodeStatistics) error {
+	err := tx.QueryRowx(`
+		INSERT INTO graph_snapshot (node_id, node_count, channel_count, channels, created_on)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING graph_snapshot_id;`,
+		snapshot.NodeId, snapshot.NodeCount, snapshot.ChannelCount, channels, snapshot.CreatedOn).
+		Scan(&snapshot.GraphSnapshotId)
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+
+	// The network has tens of thousands of nodes so they are copied instead of inserted one by one
+	statement, err := tx.Prepare(pq.CopyIn("graph_snapshot_node",
+		"graph_snapshot_id", "public_key", "alias", "channel_count", "enabled_channel_count", "capacity",
+		"median_fee_rate_mill_msat", "median_fee_base_msat", "betweenness"))
+	if err != nil {
+		return errors.Wrap(err, "Preparing graph snapshot node copy")
+	}
+	for _, node := range statistics {
+		_, err = statement.Exec(snapshot.GraphSnapshotId, node.PublicKey, node.Alias, node.ChannelCount,
+			node.EnabledChannelCount, node.Capacity, node.MedianFeeRateMilliMsat, node.MedianFeeBaseMsat,
+			node.Betweenness)
+		if err != nil {
+			return errors.Wrap(err, "Copying graph snapshot node")
+		}
+	}
+	if _, err = statement.Exec(); err != nil {
+		return errors.Wrap(err, "Flushing graph snapshot nodes")
+	}
+	if err = statement.Close(); err != nil {
+		return errors.Wrap(err, "Closing graph snapshot node copy")
+	}
+	return nil
+}
+
+func getSnapshots(db *sqlx.DB, nodeId int) ([]Snapshot, error) {
+	snapshots := []Snapshot{}
+	err := db.Select(&snapshots, `
+		SELECT graph_snapshot_id, node_id, node_count, channel_count, created_on
+		FROM graph_snapshot
+		WHERE node_id=$1
+		ORDER BY created_on DESC;`, nodeId)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return snapshots, nil
+}
+
+// getLatestSnapshot returns false when the node doesn't have a snapshot yet.
+func getLatestSnapshot(db *sqlx.DB, nodeId int) (Snapshot, *Graph, bool, error) {
+	var snapshot Snapshot
+	var channels []byte
+	err := db.QueryRowx(`
+		SELECT graph_snapshot_id, node_id, node_count, channel_count, created_on, channels
+		FROM graph_snapshot
+		WHERE node_id=$1
+		ORDER BY created_on DESC
+		LIMIT 1;`, nodeId).
+		Scan(&snapshot.GraphSnapshotId, &snapshot.NodeId, &snapshot.NodeCount, &snapshot.ChannelCount,
+			&snapshot.CreatedOn, &channels)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Snapshot{}, nil, false, nil
+		}
+		return Snapshot{}, nil, false, errors.Wrap(err, database.SqlExecutionError)
+	}
+	graph, err := decodeChannels(channels)
+	if err != nil {
+		return Snapshot{}, nil, false, err
+	}
+	return snapshot, graph, true, nil
+}
+
+func getSnapshotNodes(db *sqlx.DB, graphSnapshotId int) ([]NodeStatistics, error) {
+	var nodes []NodeStatistics
+	err := db.Select(&nodes, `
+		SELECT public_key, alias, channel_count, enabled_channel_count, capacity,
+		       median_fee_rate_mill_msat, median_fee_base_msat, betweenness
+		FROM graph_snapshot_node
+		WHERE graph_snapshot_id=$1;`, graphSnapshotId)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return nodes, nil
+}
+
+// getUptimes returns the share of the node's snapshots in which every public key had at least one enabled channel.
+func getUptimes(db *sqlx.DB, nodeId int) (map[string]float64, error) {
+	rows, err := db.Queryx(`
+		SELECT gsn.public_key,
+		       COUNT(*) FILTER (WHERE gsn.enabled_channel_count > 0)::float /
+		           (SELECT COUNT(*) FROM graph_snapshot WHERE node_id=$1) AS uptime
+		FROM graph_snapshot_node gsn
+		JOIN graph_snapshot gs ON gs.graph_snapshot_id = gsn.graph_snapshot_id
+		WHERE gs.node_id=$1
+		GROUP BY gsn.public_key;`, nodeId)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	defer rows.Close()
+	uptimes := make(map[string]float64)
+	for rows.Next() {
+		var publicKey string
+		var uptime float64
+		if err = rows.Scan(&publicKey, &uptime); err != nil {
+			return nil, errors.Wrap(err, "SQL row scan for graph uptimes")
+		}
+		uptimes[publicKey] = uptime
+	}
+	return uptimes, errors.Wrap(rows.Err(), "Iterating graph uptimes")
+}
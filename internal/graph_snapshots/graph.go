@@ -0,0 +1,286 @@
+package graph_snapshots
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+)
+
+// betweennessSampleSize is the number of source nodes used to estimate the betweenness centrality.
+// The exact calculation visits every node of the network for every node of the network which is too slow.
+const betweennessSampleSize = 128
+
+type Policy struct {
+	FeeRateMilliMsat int64
+	FeeBaseMsat      int64
+	Disabled         bool
+}
+
+type Channel struct {
+	Node1    int   `json:"n1"`
+	Node2    int   `json:"n2"`
+	Capacity int64 `json:"c"`
+	// Node1Policy and Node2Policy are the policies announced by the nodes, they are not stored in the snapshot's
+	// channels, the node statistics are calculated from them.
+	Node1Policy *Policy `json:"-"`
+	Node2Policy *Policy `json:"-"`
+	// Disabled when both nodes disabled the channel (or never announced a policy)
+	Disabled bool `json:"d,omitempty"`
+}
+
+// Graph is the public network graph as seen by one of our nodes.
+type Graph struct {
+	PublicKeys []string  `json:"nodes"`
+	Channels   []Channel `json:"channels"`
+
+	aliases      []string
+	nodeIndexes  map[string]int
+	channelIndex map[string]int
+}
+
+func NewGraph() *Graph {
+	return &Graph{nodeIndexes: make(map[string]int), channelIndex: make(map[string]int)}
+}
+
+// AddNode registers a node with its alias, nodes without an announcement are added by AddChannelPolicy.
+func (graph *Graph) AddNode(publicKey string, alias string) {
+	index := graph.getNodeIndex(publicKey)
+	graph.aliases[index] = alias
+}
+
+// AddChannelPolicy adds the policy of source for the channel identified by channelKey.
+// LND reports both policies of a channel at once while CLN reports every direction separately, so the channel is
+// created by the first policy and completed by the second. A nil policy only adds the channel.
+func (graph *Graph) AddChannelPolicy(channelKey string,
+	source string,
+	destination string,
+	capacity int64,
+	policy *Policy) {
+
+	sourceIndex := graph.getNodeIndex(source)
+	destinationIndex := graph.getNodeIndex(destination)
+	index, exists := graph.channelIndex[channelKey]
+	if !exists {
+		index = len(graph.Channels)
+		graph.channelIndex[channelKey] = index
+		graph.Channels = append(graph.Channels, Channel{Node1: sourceIndex, Node2: destinationIndex, Capacity: capacity})
+	}
+	channel := &graph.Channels[index]
+	if policy == nil {
+		return
+	}
+	if channel.Node1 == sourceIndex {
+		channel.Node1Policy = policy
+	} else {
+		channel.Node2Policy = policy
+	}
+}
+
+func (graph *Graph) getNodeIndex(publicKey string) int {
+	index, exists := graph.nodeIndexes[publicKey]
+	if !exists {
+		index = len(graph.PublicKeys)
+		graph.nodeIndexes[publicKey] = index
+		graph.PublicKeys = append(graph.PublicKeys, publicKey)
+		graph.aliases = append(graph.aliases, "")
+	}
+	return index
+}
+
+// finalize flags the channels that can't be used in either direction.
+func (graph *Graph) finalize() {
+	for ix := range graph.Channels {
+		channel := &graph.Channels[ix]
+		channel.Disabled = (channel.Node1Policy == nil || channel.Node1Policy.Disabled) &&
+			(channel.Node2Policy == nil || channel.Node2Policy.Disabled)
+	}
+}
+
+// adjacency returns the neighbours of every node using the channels that are not disabled.
+// Multiple channels between the same nodes result in a single neighbour.
+func (graph *Graph) adjacency() [][]int {
+	neighbours := make([][]int, len(graph.PublicKeys))
+	seen := make(map[[2]int]bool)
+	for _, channel := range graph.Channels {
+		if channel.Disabled || channel.Node1 == channel.Node2 {
+			continue
+		}
+		key := [2]int{channel.Node1, channel.Node2}
+		if channel.Node1 > channel.Node2 {
+			key = [2]int{channel.Node2, channel.Node1}
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		neighbours[channel.Node1] = append(neighbours[channel.Node1], channel.Node2)
+		neighbours[channel.Node2] = append(neighbours[channel.Node2], channel.Node1)
+	}
+	return neighbours
+}
+
+type NodeStatistics struct {
+	PublicKey              string  `json:"publicKey" db:"public_key"`
+	Alias                  string  `json:"alias" db:"alias"`
+	ChannelCount           int     `json:"channelCount" db:"channel_count"`
+	EnabledChannelCount    int     `json:"enabledChannelCount" db:"enabled_channel_count"`
+	Capacity               int64   `json:"capacity" db:"capacity"`
+	MedianFeeRateMilliMsat int64   `json:"medianFeeRateMilliMsat" db:"median_fee_rate_mill_msat"`
+	MedianFeeBaseMsat      int64   `json:"medianFeeBaseMsat" db:"median_fee_base_msat"`
+	Betweenness            float64 `json:"betweenness" db:"betweenness"`
+}
+
+// nodeStatistics calculates the statistics of every node, fees are the medians of the node's own policies.
+func (graph *Graph) nodeStatistics(random *rand.Rand) []NodeStatistics {
+	statistics := make([]NodeStatistics, len(graph.PublicKeys))
+	feeRates := make([][]int64, len(graph.PublicKeys))
+	feeBases := make([][]int64, len(graph.PublicKeys))
+	addPolicy := func(nodeIndex int, policy *Policy) {
+		if policy == nil || policy.Disabled {
+			return
+		}
+		statistics[nodeIndex].EnabledChannelCount++
+		feeRates[nodeIndex] = append(feeRates[nodeIndex], policy.FeeRateMilliMsat)
+		feeBases[nodeIndex] = append(feeBases[nodeIndex], policy.FeeBaseMsat)
+	}
+	for _, channel := range graph.Channels {
+		for _, nodeIndex := range []int{channel.Node1, channel.Node2} {
+			statistics[nodeIndex].ChannelCount++
+			statistics[nodeIndex].Capacity += channel.Capacity
+		}
+		addPolicy(channel.Node1, channel.Node1Policy)
+		addPolicy(channel.Node2, channel.Node2Policy)
+	}
+	betweenness := estimateBetweenness(graph.adjacency(), betweennessSampleSize, random)
+	for ix := range statistics {
+		statistics[ix].PublicKey = graph.PublicKeys[ix]
+		statistics[ix].Alias = graph.aliases[ix]
+		statistics[ix].MedianFeeRateMilliMsat = median(feeRates[ix])
+		statistics[ix].MedianFeeBaseMsat = median(feeBases[ix])
+		statistics[ix].Betweenness = betweenness[ix]
+	}
+	return statistics
+}
+
+func median(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values[len(values)/2]
+}
+
+// estimateBetweenness estimates the normalised betweenness centrality (0 to 1) of every node with Brandes'
+// algorithm from a random sample of source nodes.
+func estimateBetweenness(neighbours [][]int, sampleSize int, random *rand.Rand) []float64 {
+	nodeCount := len(neighbours)
+	betweenness := make([]float64, nodeCount)
+	if nodeCount < 3 {
+		return betweenness
+	}
+	sources := random.Perm(nodeCount)
+	if sampleSize < nodeCount {
+		sources = sources[:sampleSize]
+	}
+
+	distance := make([]int, nodeCount)
+	paths := make([]float64, nodeCount)
+	dependency := make([]float64, nodeCount)
+	order := make([]int, 0, nodeCount)
+	for _, source := range sources {
+		for ix := range distance {
+			distance[ix] = -1
+			paths[ix] = 0
+			dependency[ix] = 0
+		}
+		order = order[:0]
+		distance[source] = 0
+		paths[source] = 1
+		order = append(order, source)
+		for head := 0; head < len(order); head++ {
+			current := order[head]
+			for _, neighbour := range neighbours[current] {
+				if distance[neighbour] < 0 {
+					distance[neighbour] = distance[current] + 1
+					order = append(order, neighbour)
+				}
+				if distance[neighbour] == distance[current]+1 {
+					paths[neighbour] += paths[current]
+				}
+			}
+		}
+		for ix := len(order) - 1; ix > 0; ix-- {
+			current := order[ix]
+			for _, neighbour := range neighbours[current] {
+				if distance[neighbour] == distance[current]-1 {
+					dependency[neighbour] += paths[neighbour] / paths[current] * (1 + dependency[current])
+				}
+			}
+			betweenness[current] += dependency[current]
+		}
+	}
+	// Every source counts the pairs it is part of, scale to the full network and the number of ordered pairs.
+	scale := float64(nodeCount) / float64(len(sources)) / float64((nodeCount-1)*(nodeCount-2))
+	for ix := range betweenness {
+		betweenness[ix] *= scale
+	}
+	return betweenness
+}
+
+// distances returns the number of hops from source to every node, -1 when the node can't be reached.
+func distances(neighbours [][]int, source int) []int {
+	distance := make([]int, len(neighbours))
+	for ix := range distance {
+		distance[ix] = -1
+	}
+	distance[source] = 0
+	queue := []int{source}
+	for head := 0; head < len(queue); head++ {
+		current := queue[head]
+		for _, neighbour := range neighbours[current] {
+			if distance[neighbour] < 0 {
+				distance[neighbour] = distance[current] + 1
+				queue = append(queue, neighbour)
+			}
+		}
+	}
+	return distance
+}
+
+func encodeChannels(graph *Graph) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if err := json.NewEncoder(writer).Encode(graph); err != nil {
+		return nil, errors.Wrap(err, "Encoding graph channels")
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.Wrap(err, "Compressing graph channels")
+	}
+	return buffer.Bytes(), nil
+}
+
+func decodeChannels(data []byte) (*Graph, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "Decompressing graph channels")
+	}
+	defer reader.Close()
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "Decompressing graph channels")
+	}
+	graph := NewGraph()
+	if err = json.Unmarshal(decoded, graph); err != nil {
+		return nil, errors.Wrap(err, "Decoding graph channels")
+	}
+	for ix, publicKey := range graph.PublicKeys {
+		graph.nodeIndexes[publicKey] = ix
+	}
+	graph.aliases = make([]string, len(graph.PublicKeys))
+	return graph, nil
+}
@@ -0,0 +1,127 @@
+package graph_snapshots
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/pkg/server_errors"
+)
+
+type RecommendationsResponse struct {
+	Snapshot        Snapshot             `json:"snapshot"`
+	Recommendations []PeerRecommendation `json:"recommendations"`
+}
+
+func getSnapshotsHandler(c *gin.Context, db *sqlx.DB) {
+	nodeId, ok := getTorqNodeId(c)
+	if !ok {
+		return
+	}
+	snapshots, err := getSnapshots(db, nodeId)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting graph snapshots")
+		return
+	}
+	c.JSON(http.StatusOK, snapshots)
+}
+
+// getRecommendationsHandler ranks the potential new peers of the node based on its latest graph snapshot.
+func getRecommendationsHandler(c *gin.Context, db *sqlx.DB) {
+	nodeId, ok := getTorqNodeId(c)
+	if !ok {
+		return
+	}
+	filter := RecommendationFilter{Limit: defaultRecommendationLimit, MinChannels: defaultMinimumChannels}
+	var err error
+	if c.Query("limit") != "" {
+		filter.Limit, err = strconv.Atoi(c.Query("limit"))
+		if err != nil || filter.Limit < 1 || filter.Limit > maximumRecommendationLimit {
+			server_errors.SendBadRequest(c, "limit must be a number between 1 and 250")
+			return
+		}
+	}
+	if c.Query("minChannels") != "" {
+		filter.MinChannels, err = strconv.Atoi(c.Query("minChannels"))
+		if err != nil || filter.MinChannels < 0 {
+			server_errors.SendBadRequest(c, "Can't process minChannels")
+			return
+		}
+	}
+	if c.Query("minCapacity") != "" {
+		filter.MinCapacity, err = strconv.ParseInt(c.Query("minCapacity"), 10, 64)
+		if err != nil || filter.MinCapacity < 0 {
+			server_errors.SendBadRequest(c, "Can't process minCapacity")
+			return
+		}
+	}
+
+	snapshot, graph, exists, err := getLatestSnapshot(db, nodeId)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting latest graph snapshot")
+		return
+	}
+	if !exists {
+		server_errors.SendBadRequest(c, "No graph snapshot available, enable graph snapshots for this node first.")
+		return
+	}
+	nodes, err := getSnapshotNodes(db, snapshot.GraphSnapshotId)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting graph snapshot nodes")
+		return
+	}
+	uptimes, err := getUptimes(db, nodeId)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting graph uptimes")
+		return
+	}
+
+	c.JSON(http.StatusOK, RecommendationsResponse{
+		Snapshot: snapshot,
+		Recommendations: recommendPeers(recommendationRequest{
+			graph:          graph,
+			nodes:          nodes,
+			uptimes:        uptimes,
+			ownPublicKey:   cache.GetNodeSettingsByNodeId(nodeId).PublicKey,
+			peerPublicKeys: getPeerPublicKeys(nodeId),
+			filter:         filter,
+			// The same snapshot always results in the same recommendations
+			random: rand.New(rand.NewSource(int64(snapshot.GraphSnapshotId))), //nolint:gosec
+		}),
+	})
+}
+
+func getTorqNodeId(c *gin.Context) (int, bool) {
+	nodeId, err := strconv.Atoi(c.Param("nodeId"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Failed to find/parse nodeId in the request.")
+		return 0, false
+	}
+	if !slices.Contains(cache.GetAllTorqNodeIds(), nodeId) {
+		server_errors.SendBadRequest(c, "Unknown nodeId.")
+		return 0, false
+	}
+	return nodeId, true
+}
+
+// getPeerPublicKeys returns the peers with an open (or opening) channel, including the private channels.
+func getPeerPublicKeys(nodeId int) []string {
+	var publicKeys []string
+	for _, channel := range cache.GetChannelSettingsByNodeId(nodeId) {
+		if channel.Status >= core.CooperativeClosed {
+			continue
+		}
+		remoteNodeId := channel.FirstNodeId
+		if remoteNodeId == nodeId {
+			remoteNodeId = channel.SecondNodeId
+		}
+		publicKeys = append(publicKeys, cache.GetNodeSettingsByNodeId(remoteNodeId).PublicKey)
+	}
+	return publicKeys
+}
@@ -0,0 +1,267 @@
+package graph_snapshots
+
+import (
+	"math/rand"
+	"sort"
+)
+
+const defaultRecommendationLimit = 25
+const maximumRecommendationLimit = 250
+const defaultMinimumChannels = 10
+
+// candidatePoolSize is the number of most central nodes for which the betweenness gain is calculated
+const candidatePoolSize = 200
+
+// The betweenness gain is estimated on the shortest paths between a random sample of nodes
+const gainSampleSources = 64
+const gainSampleTargets = 256
+
+// The weights of the score components, they add up to 1
+const (
+	betweennessGainWeight = 0.30
+	centralityWeight      = 0.20
+	capacityWeight        = 0.15
+	uptimeWeight          = 0.15
+	feeWeight             = 0.10
+	overlapWeight         = 0.10
+)
+
+type RecommendationFilter struct {
+	Limit       int
+	MinChannels int
+	MinCapacity int64
+}
+
+// RecommendationScores are the components of the score, every component is between 0 and 1 and higher is better.
+type RecommendationScores struct {
+	BetweennessGain float64 `json:"betweennessGain"`
+	Centrality      float64 `json:"centrality"`
+	Capacity        float64 `json:"capacity"`
+	Uptime          float64 `json:"uptime"`
+	// Fee ranks lower fees higher, they make the routes through our node cheaper
+	Fee float64 `json:"fee"`
+	// Overlap ranks candidates that share fewer peers with our node higher
+	Overlap float64 `json:"overlap"`
+}
+
+type PeerRecommendation struct {
+	NodeStatistics
+	// Uptime is the share of the snapshots in which the node had at least one enabled channel
+	Uptime float64 `json:"uptime"`
+	// BetweennessGain is the share of the sampled shortest paths that would use the new channel
+	BetweennessGain float64 `json:"betweennessGain"`
+	// SharedPeers are the peers of our node the candidate already has a channel with
+	SharedPeers int                  `json:"sharedPeers"`
+	Score       float64              `json:"score"`
+	Scores      RecommendationScores `json:"scores"`
+}
+
+type recommendationRequest struct {
+	graph          *Graph
+	nodes          []NodeStatistics
+	uptimes        map[string]float64
+	ownPublicKey   string
+	peerPublicKeys []string
+	filter         RecommendationFilter
+	random         *rand.Rand
+}
+
+// recommendPeers ranks the nodes we don't have a channel with yet.
+func recommendPeers(request recommendationRequest) []PeerRecommendation {
+	graph := request.graph
+	neighbours := graph.adjacency()
+	ownIndex, exists := graph.nodeIndexes[request.ownPublicKey]
+	if !exists {
+		// Private nodes are not part of the public graph
+		ownIndex = len(neighbours)
+		neighbours = append(neighbours, nil)
+	}
+
+	// Private channels are not part of the public graph either
+	peers := make(map[int]bool)
+	for _, neighbour := range neighbours[ownIndex] {
+		peers[neighbour] = true
+	}
+	for _, publicKey := range request.peerPublicKeys {
+		peerIndex, exists := graph.nodeIndexes[publicKey]
+		if !exists || peers[peerIndex] || peerIndex == ownIndex {
+			continue
+		}
+		peers[peerIndex] = true
+		neighbours[ownIndex] = append(neighbours[ownIndex], peerIndex)
+		neighbours[peerIndex] = append(neighbours[peerIndex], ownIndex)
+	}
+
+	statistics := make(map[int]NodeStatistics, len(request.nodes))
+	for _, node := range request.nodes {
+		if index, exists := graph.nodeIndexes[node.PublicKey]; exists {
+			statistics[index] = node
+		}
+	}
+
+	var candidates []int
+	for index, node := range statistics {
+		if index == ownIndex || peers[index] ||
+			node.EnabledChannelCount == 0 ||
+			node.ChannelCount < request.filter.MinChannels ||
+			node.Capacity < request.filter.MinCapacity {
+			continue
+		}
+		candidates = append(candidates, index)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if statistics[candidates[i]].Betweenness != statistics[candidates[j]].Betweenness {
+			return statistics[candidates[i]].Betweenness > statistics[candidates[j]].Betweenness
+		}
+		return candidates[i] < candidates[j]
+	})
+	if len(candidates) > candidatePoolSize {
+		candidates = candidates[:candidatePoolSize]
+	}
+	if len(candidates) == 0 {
+		return []PeerRecommendation{}
+	}
+
+	gains := estimateBetweennessGains(neighbours, ownIndex, candidates, request.random)
+	centralities := make([]float64, len(candidates))
+	capacities := make([]float64, len(candidates))
+	fees := make([]float64, len(candidates))
+	maximumGain := 0.0
+	for ix, candidate := range candidates {
+		centralities[ix] = statistics[candidate].Betweenness
+		capacities[ix] = float64(statistics[candidate].Capacity)
+		fees[ix] = -float64(statistics[candidate].MedianFeeRateMilliMsat)
+		if gains[ix] > maximumGain {
+			maximumGain = gains[ix]
+		}
+	}
+	centralityRanks := percentileRanks(centralities)
+	capacityRanks := percentileRanks(capacities)
+	feeRanks := percentileRanks(fees)
+
+	recommendations := make([]PeerRecommendation, 0, len(candidates))
+	for ix, candidate := range candidates {
+		recommendation := PeerRecommendation{
+			NodeStatistics:  statistics[candidate],
+			Uptime:          request.uptimes[statistics[candidate].PublicKey],
+			BetweennessGain: gains[ix],
+		}
+		for _, neighbour := range neighbours[candidate] {
+			if peers[neighbour] {
+				recommendation.SharedPeers++
+			}
+		}
+		recommendation.Scores = RecommendationScores{
+			Centrality: centralityRanks[ix],
+			Capacity:   capacityRanks[ix],
+			Uptime:     recommendation.Uptime,
+			Fee:        feeRanks[ix],
+			Overlap:    1,
+		}
+		if maximumGain > 0 {
+			recommendation.Scores.BetweennessGain = gains[ix] / maximumGain
+		}
+		if len(peers) != 0 {
+			recommendation.Scores.Overlap = 1 - float64(recommendation.SharedPeers)/float64(len(peers))
+		}
+		recommendation.Score = betweennessGainWeight*recommendation.Scores.BetweennessGain +
+			centralityWeight*recommendation.Scores.Centrality +
+			capacityWeight*recommendation.Scores.Capacity +
+			uptimeWeight*recommendation.Scores.Uptime +
+			feeWeight*recommendation.Scores.Fee +
+			overlapWeight*recommendation.Scores.Overlap
+		recommendations = append(recommendations, recommendation)
+	}
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+	if len(recommendations) > request.filter.Limit {
+		recommendations = recommendations[:request.filter.Limit]
+	}
+	return recommendations
+}
+
+// estimateBetweennessGains estimates for every candidate the share of the sampled shortest paths that would use a
+// new channel between our node and the candidate. A path that becomes shorter counts fully, a path that becomes
+// one of the shortest paths counts half.
+func estimateBetweennessGains(neighbours [][]int, ownIndex int, candidates []int, random *rand.Rand) []float64 {
+	nodeCount := len(neighbours)
+	sources := random.Perm(nodeCount)
+	if len(sources) > gainSampleSources {
+		sources = sources[:gainSampleSources]
+	}
+	targets := random.Perm(nodeCount)
+	if len(targets) > gainSampleTargets {
+		targets = targets[:gainSampleTargets]
+	}
+	sourceDistances := make([][]int, len(sources))
+	for ix, source := range sources {
+		sourceDistances[ix] = distances(neighbours, source)
+	}
+	ownDistances := distances(neighbours, ownIndex)
+
+	gains := make([]float64, len(candidates))
+	for ix, candidate := range candidates {
+		candidateDistances := distances(neighbours, candidate)
+		var credit float64
+		var pairs int
+		for sx, source := range sources {
+			for _, target := range targets {
+				if source == target {
+					continue
+				}
+				pairs++
+				via := shortest(
+					pathLength(sourceDistances[sx][ownIndex], candidateDistances[target]),
+					pathLength(sourceDistances[sx][candidate], ownDistances[target]))
+				if via < 0 {
+					continue
+				}
+				current := sourceDistances[sx][target]
+				if current < 0 || via < current {
+					credit++
+				} else if via == current {
+					credit += 0.5
+				}
+			}
+		}
+		if pairs != 0 {
+			gains[ix] = credit / float64(pairs)
+		}
+	}
+	return gains
+}
+
+// pathLength is the length of a path over the new channel, -1 when one of the ends can't be reached.
+func pathLength(toChannel int, fromChannel int) int {
+	if toChannel < 0 || fromChannel < 0 {
+		return -1
+	}
+	return toChannel + 1 + fromChannel
+}
+
+func shortest(a int, b int) int {
+	if a < 0 || (b >= 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// percentileRanks returns the share of the values that are lower than every value (equal values count half).
+func percentileRanks(values []float64) []float64 {
+	ranks := make([]float64, len(values))
+	if len(values) < 2 {
+		for ix := range ranks {
+			ranks[ix] = 1
+		}
+		return ranks
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	for ix, value := range values {
+		lower := sort.SearchFloat64s(sorted, value)
+		upper := sort.Search(len(sorted), func(i int) bool { return sorted[i] > value })
+		ranks[ix] = (float64(lower) + float64(upper-lower-1)/2) / float64(len(values)-1)
+	}
+	return ranks
+}
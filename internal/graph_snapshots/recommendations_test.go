@@ -0,0 +1,73 @@
+package graph_snapshots
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestEstimateBetweenness(t *testing.T) {
+	graph := NewGraph()
+	policy := &Policy{FeeRateMilliMsat: 100}
+	graph.AddChannelPolicy("1", "a", "b", 1_000_000, policy)
+	graph.AddChannelPolicy("2", "b", "c", 1_000_000, policy)
+	graph.finalize()
+
+	betweenness := estimateBetweenness(graph.adjacency(), 10, rand.New(rand.NewSource(1)))
+	expected := []float64{0, 1, 0}
+	for ix := range expected {
+		if math.Abs(betweenness[ix]-expected[ix]) > 1e-9 {
+			t.Errorf("expected betweenness %v for %v, got %v", expected[ix], graph.PublicKeys[ix], betweenness[ix])
+		}
+	}
+}
+
+func TestRecommendPeers(t *testing.T) {
+	graph := NewGraph()
+	policy := &Policy{FeeRateMilliMsat: 100, FeeBaseMsat: 1000}
+	channelId := 0
+	addChannel := func(node1 string, node2 string) {
+		channelId++
+		graph.AddChannelPolicy(strconv.Itoa(channelId), node1, node2, 1_000_000, policy)
+		graph.AddChannelPolicy(strconv.Itoa(channelId), node2, node1, 1_000_000, policy)
+	}
+	// Two fully connected clusters with a single channel between them
+	for _, cluster := range []string{"a", "b"} {
+		for i := 1; i <= 4; i++ {
+			for j := i + 1; j <= 4; j++ {
+				addChannel(cluster+strconv.Itoa(i), cluster+strconv.Itoa(j))
+			}
+		}
+	}
+	addChannel("a1", "b1")
+	addChannel("us", "a2")
+	graph.finalize()
+
+	recommendations := recommendPeers(recommendationRequest{
+		graph:        graph,
+		nodes:        graph.nodeStatistics(rand.New(rand.NewSource(1))),
+		uptimes:      map[string]float64{"b2": 1},
+		ownPublicKey: "us",
+		filter:       RecommendationFilter{Limit: 10},
+		random:       rand.New(rand.NewSource(1)),
+	})
+
+	gains := make(map[string]float64)
+	for _, recommendation := range recommendations {
+		if recommendation.PublicKey == "us" || recommendation.PublicKey == "a2" {
+			t.Errorf("expected %v not to be recommended", recommendation.PublicKey)
+		}
+		gains[recommendation.PublicKey] = recommendation.BetweennessGain
+	}
+	if len(recommendations) != 7 {
+		t.Errorf("expected 7 recommendations, got %v", len(recommendations))
+	}
+	if gains["b2"] <= gains["a3"] {
+		t.Errorf("expected a channel to the other cluster to gain more than one within our cluster, got %v and %v",
+			gains["b2"], gains["a3"])
+	}
+	if recommendations[0].PublicKey[0] != 'b' {
+		t.Errorf("expected a node of the other cluster to be recommended first, got %v", recommendations[0].PublicKey)
+	}
+}
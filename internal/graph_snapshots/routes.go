@@ -0,0 +1,11 @@
+package graph_snapshots
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func RegisterGraphSnapshotRoutes(r *gin.RouterGroup, db *sqlx.DB) {
+	r.GET(":nodeId/snapshots", func(c *gin.Context) { getSnapshotsHandler(c, db) })
+	r.GET(":nodeId/recommendations", func(c *gin.Context) { getRecommendationsHandler(c, db) })
+}
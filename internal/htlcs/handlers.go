@@ -0,0 +1,210 @@
+package htlcs
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+
+	ah "github.com/lncapital/torq/internal/api_helpers"
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	qp "github.com/lncapital/torq/internal/query_parser"
+	"github.com/lncapital/torq/pkg/server_errors"
+)
+
+var timeBuckets = map[string]string{ //nolint:gochecknoglobals
+	"hour": "1 hour",
+	"day":  "1 day",
+	"week": "1 week",
+}
+
+func getHtlcsHandler(c *gin.Context, db *sqlx.DB) {
+
+	// Filter parser with whitelisted columns
+	var filter sq.Sqlizer
+	filterParam := c.Query("filter")
+	var err error
+	if filterParam != "" {
+		filter, err = qp.ParseFilterParam(filterParam, []string{
+			"date",
+			"node_id",
+			"event_type",
+			"event_origin",
+			"incoming_channel_id",
+			"incoming_short_channel_id",
+			"outgoing_channel_id",
+			"outgoing_short_channel_id",
+			"incoming_amount",
+			"outgoing_amount",
+			"bolt_failure_code",
+			"lnd_failure_detail",
+			"failure_category",
+		})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	var sort []string
+	sortParam := c.Query("order")
+	if sortParam != "" {
+		// Order parser with whitelisted columns
+		sort, err = qp.ParseOrderParams(
+			sortParam,
+			[]string{
+				"date",
+				"event_type",
+				"incoming_amount",
+				"outgoing_amount",
+				"bolt_failure_code",
+				"lnd_failure_detail",
+				"failure_category",
+			})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	var limit uint64
+	if c.Query("limit") != "" {
+		limit, err = strconv.ParseUint(c.Query("limit"), 10, 64)
+		switch err.(type) {
+		case nil:
+			break
+		case *strconv.NumError:
+			c.JSON(http.StatusBadRequest, gin.H{"Error": "Limit must be a positive number"})
+			return
+		default:
+			server_errors.LogAndSendServerError(c, err)
+		}
+		if limit == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": "Limit must be a at least 1"})
+			return
+		}
+	}
+
+	var offset uint64
+	if c.Query("offset") != "" {
+		offset, err = strconv.ParseUint(c.Query("offset"), 10, 64)
+		switch err.(type) {
+		case nil:
+			break
+		case *strconv.NumError:
+			c.JSON(http.StatusBadRequest, gin.H{"Error": "Offset must be a positive number"})
+			return
+		default:
+			server_errors.LogAndSendServerError(c, err)
+		}
+	}
+
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+
+	chain := core.Bitcoin
+
+	r, total, err := getHtlcs(db, cache.GetAllTorqNodeIdsByNetwork(chain, core.Network(network)), filter, sort, limit, offset)
+	if err != nil {
+		server_errors.LogAndSendServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ah.ApiResponse{
+		Data: r, Pagination: ah.Pagination{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		}})
+}
+
+func getHtlcFailureSummaryHandler(c *gin.Context, db *sqlx.DB) {
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Invalid 'from' date.")
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Invalid 'to' date.")
+		return
+	}
+	// Include the whole 'to' day
+	to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	bucket := timeBuckets["day"]
+	if c.Query("bucket") != "" {
+		var exists bool
+		bucket, exists = timeBuckets[c.Query("bucket")]
+		if !exists {
+			server_errors.SendBadRequest(c, "Bucket must be one of: hour, day, week")
+			return
+		}
+	}
+
+	var filter sq.Sqlizer
+	filterParam := c.Query("filter")
+	if filterParam != "" {
+		filter, err = qp.ParseFilterParam(filterParam, []string{
+			"date",
+			"incoming_channel_id",
+			"incoming_short_channel_id",
+			"incoming_alias",
+			"outgoing_channel_id",
+			"outgoing_short_channel_id",
+			"outgoing_alias",
+			"failure_code",
+			"failure_category",
+			"count",
+			"amount",
+		})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	sort := []string{"date asc", "count desc"}
+	sortParam := c.Query("order")
+	if sortParam != "" {
+		sort, err = qp.ParseOrderParams(
+			sortParam,
+			[]string{
+				"date",
+				"incoming_alias",
+				"outgoing_alias",
+				"failure_code",
+				"failure_category",
+				"count",
+				"amount",
+			})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+
+	chain := core.Bitcoin
+
+	r, err := getHtlcFailureSummary(db, cache.GetAllTorqNodeIdsByNetwork(chain, core.Network(network)),
+		bucket, from, to, filter, sort)
+	if err != nil {
+		server_errors.LogAndSendServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, r)
+}
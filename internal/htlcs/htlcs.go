@@ -0,0 +1,220 @@
+package htlcs
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/lncapital/torq/internal/cache"
+)
+
+type Htlc struct {
+	Date                   time.Time `json:"date" db:"date"`
+	NodeId                 int       `json:"nodeId" db:"node_id"`
+	EventType              *string   `json:"eventType" db:"event_type"`
+	EventOrigin            *string   `json:"eventOrigin" db:"event_origin"`
+	IncomingChannelId      *int      `json:"incomingChannelId" db:"incoming_channel_id"`
+	IncomingShortChannelId *string   `json:"incomingShortChannelId" db:"incoming_short_channel_id"`
+	OutgoingChannelId      *int      `json:"outgoingChannelId" db:"outgoing_channel_id"`
+	OutgoingShortChannelId *string   `json:"outgoingShortChannelId" db:"outgoing_short_channel_id"`
+	IncomingAmount         *uint64   `json:"incomingAmount" db:"incoming_amount"`
+	OutgoingAmount         *uint64   `json:"outgoingAmount" db:"outgoing_amount"`
+	IncomingTimelock       *uint32   `json:"incomingTimelock" db:"incoming_timelock"`
+	OutgoingTimelock       *uint32   `json:"outgoingTimelock" db:"outgoing_timelock"`
+	BoltFailureCode        *string   `json:"boltFailureCode" db:"bolt_failure_code"`
+	BoltFailureString      *string   `json:"boltFailureString" db:"bolt_failure_string"`
+	LndFailureDetail       *string   `json:"lndFailureDetail" db:"lnd_failure_detail"`
+	ClnForwardStatusId     *int      `json:"clnForwardStatusId" db:"cln_forward_status_id"`
+	FailureCategory        string    `json:"failureCategory" db:"failure_category"`
+}
+
+type HtlcFailureSummary struct {
+	Date                   time.Time `json:"date" db:"date"`
+	IncomingChannelId      *int      `json:"incomingChannelId" db:"incoming_channel_id"`
+	IncomingShortChannelId *string   `json:"incomingShortChannelId" db:"incoming_short_channel_id"`
+	IncomingAlias          *string   `json:"incomingAlias" db:"incoming_alias"`
+	OutgoingChannelId      *int      `json:"outgoingChannelId" db:"outgoing_channel_id"`
+	OutgoingShortChannelId *string   `json:"outgoingShortChannelId" db:"outgoing_short_channel_id"`
+	OutgoingAlias          *string   `json:"outgoingAlias" db:"outgoing_alias"`
+	FailureCode            string    `json:"failureCode" db:"failure_code"`
+	FailureCategory        string    `json:"failureCategory" db:"failure_category"`
+	Count                  uint64    `json:"count" db:"count"`
+	Amount                 uint64    `json:"amount" db:"amount"`
+}
+
+// htlcColumns is shared between the HTLC list and the total count so the filters match up
+const htlcColumns = `
+	he.time as date,
+	he.node_id,
+	he.event_type,
+	he.event_origin,
+	he.incoming_channel_id,
+	ic.short_channel_id as incoming_short_channel_id,
+	he.outgoing_channel_id,
+	oc.short_channel_id as outgoing_short_channel_id,
+	he.incoming_amt_msat/1000 as incoming_amount,
+	he.outgoing_amt_msat/1000 as outgoing_amount,
+	he.incoming_timelock,
+	he.outgoing_timelock,
+	he.bolt_failure_code,
+	he.bolt_failure_string,
+	he.lnd_failure_detail,
+	he.cln_forward_status_id,
+` + failureCategorySql + ` as failure_category`
+
+// failureCategorySql groups the many LND/BOLT/CLN failure codes into the few reasons that matter when deciding
+// to rebalance (liquidity) or to change the routing policy (fee, timelock).
+const failureCategorySql = `
+	CASE
+		WHEN he.lnd_failure_detail = 'INSUFFICIENT_BALANCE'
+			OR (he.lnd_failure_detail IS NULL AND he.bolt_failure_code = 'TEMPORARY_CHANNEL_FAILURE') THEN 'liquidity'
+		WHEN he.bolt_failure_code IN ('FEE_INSUFFICIENT', 'AMOUNT_BELOW_MINIMUM') THEN 'fee'
+		WHEN he.bolt_failure_code IN ('INCORRECT_CLTV_EXPIRY', 'EXPIRY_TOO_SOON', 'EXPIRY_TOO_FAR') THEN 'timelock'
+		WHEN he.lnd_failure_detail IN ('LINK_NOT_ELIGIBLE', 'FORWARDS_DISABLED')
+			OR he.bolt_failure_code IN ('CHANNEL_DISABLED', 'UNKNOWN_NEXT_PEER') THEN 'unavailable'
+		WHEN he.event_type IN ('LinkFailEvent', 'ForwardFailEvent') THEN 'other'
+		ELSE ''
+	END`
+
+func getHtlcs(db *sqlx.DB,
+	nodeIds []int,
+	filter sq.Sqlizer,
+	order []string,
+	limit uint64,
+	offset uint64) (r []*Htlc, total uint64, err error) {
+
+	//language=PostgreSQL
+	qb := sq.Select("*").
+		PlaceholderFormat(sq.Dollar).
+		FromSelect(
+			sq.Select(htlcColumns).
+				PlaceholderFormat(sq.Dollar).
+				From("htlc_event he").
+				LeftJoin("channel ic ON ic.channel_id = he.incoming_channel_id").
+				LeftJoin("channel oc ON oc.channel_id = he.outgoing_channel_id").
+				Where(sq.Eq{"he.node_id": nodeIds}),
+			"subquery").
+		Where(filter).
+		OrderBy(order...)
+
+	if limit > 0 {
+		qb = qb.Limit(limit).Offset(offset)
+	}
+
+	qs, args, err := qb.ToSql()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "SQL compile statement")
+	}
+
+	rows, err := db.Queryx(qs, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Run SQL Query")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var htlc Htlc
+		err = rows.StructScan(&htlc)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "SQL struct scan")
+		}
+		r = append(r, &htlc)
+	}
+
+	totalQb := sq.Select("count(*) as total").
+		PlaceholderFormat(sq.Dollar).
+		FromSelect(
+			sq.Select(htlcColumns).
+				PlaceholderFormat(sq.Dollar).
+				From("htlc_event he").
+				LeftJoin("channel ic ON ic.channel_id = he.incoming_channel_id").
+				LeftJoin("channel oc ON oc.channel_id = he.outgoing_channel_id").
+				Where(sq.Eq{"he.node_id": nodeIds}),
+			"subquery").
+		Where(filter)
+
+	totalQs, args, err := totalQb.ToSql()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "SQL compile query")
+	}
+
+	err = db.QueryRowx(totalQs, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "SQL run query")
+	}
+
+	return r, total, nil
+}
+
+// getHtlcFailureSummary groups the failed HTLCs by time bucket, channel pair and failure code.
+func getHtlcFailureSummary(db *sqlx.DB,
+	nodeIds []int,
+	bucket string,
+	from time.Time,
+	to time.Time,
+	filter sq.Sqlizer,
+	order []string) (r []*HtlcFailureSummary, err error) {
+
+	//language=PostgreSQL
+	qb := sq.Select("*").
+		PlaceholderFormat(sq.Dollar).
+		FromSelect(
+			sq.Select().Column(`
+				time_bucket(?::interval, he.time::timestamp AT TIME ZONE ?) as date,
+				he.incoming_channel_id,
+				ic.short_channel_id as incoming_short_channel_id,
+				ia.alias as incoming_alias,
+				he.outgoing_channel_id,
+				oc.short_channel_id as outgoing_short_channel_id,
+				oa.alias as outgoing_alias,
+				coalesce(he.lnd_failure_detail, he.bolt_failure_code,
+					CASE he.cln_forward_status_id WHEN 1 THEN 'REMOTE_FAILED' WHEN 2 THEN 'LOCAL_FAILED' END,
+					'UNKNOWN') as failure_code,
+				`+failureCategorySql+` as failure_category,
+				count(*) as count,
+				coalesce(floor(sum(coalesce(he.outgoing_amt_msat, he.incoming_amt_msat))/1000), 0) as amount
+			`, bucket, cache.GetSettings().PreferredTimeZone).
+				PlaceholderFormat(sq.Dollar).
+				From("htlc_event he").
+				LeftJoin("channel ic ON ic.channel_id = he.incoming_channel_id").
+				LeftJoin("channel oc ON oc.channel_id = he.outgoing_channel_id").
+				LeftJoin(`(
+					SELECT event_node_id, last(alias, timestamp) as alias
+					FROM node_event
+					GROUP BY event_node_id
+				) ia ON ia.event_node_id = CASE WHEN ic.first_node_id = he.node_id THEN ic.second_node_id ELSE ic.first_node_id END`).
+				LeftJoin(`(
+					SELECT event_node_id, last(alias, timestamp) as alias
+					FROM node_event
+					GROUP BY event_node_id
+				) oa ON oa.event_node_id = CASE WHEN oc.first_node_id = he.node_id THEN oc.second_node_id ELSE oc.first_node_id END`).
+				Where(sq.Eq{"he.node_id": nodeIds}).
+				Where(sq.Eq{"he.event_type": []string{"LinkFailEvent", "ForwardFailEvent"}}).
+				Where("he.time::timestamp AT TIME ZONE ? >= ?::timestamp", cache.GetSettings().PreferredTimeZone, from).
+				Where("he.time::timestamp AT TIME ZONE ? <= ?::timestamp", cache.GetSettings().PreferredTimeZone, to).
+				GroupBy("1, 2, 3, 4, 5, 6, 7, 8, 9"),
+			"subquery").
+		Where(filter).
+		OrderBy(order...)
+
+	qs, args, err := qb.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "SQL compile statement")
+	}
+
+	rows, err := db.Queryx(qs, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Run SQL Query")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var summary HtlcFailureSummary
+		err = rows.StructScan(&summary)
+		if err != nil {
+			return nil, errors.Wrap(err, "SQL struct scan")
+		}
+		r = append(r, &summary)
+	}
+	return r, nil
+}
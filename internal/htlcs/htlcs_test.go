@@ -0,0 +1,161 @@
+package htlcs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+
+	ah "github.com/lncapital/torq/internal/api_helpers"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/testutil"
+)
+
+type testHtlcEvent struct {
+	eventType        string
+	boltFailureCode  *string
+	lndFailureDetail *string
+	expectedCategory string
+}
+
+func addHtlcEvent(t *testing.T, db *sqlx.DB, nodeId int, incomingChannelId int, outgoingChannelId int,
+	eventTime time.Time, event testHtlcEvent) {
+
+	_, err := db.Exec(`INSERT INTO htlc_event
+			(time, data, event_type, incoming_channel_id, outgoing_channel_id, outgoing_amt_msat,
+			 bolt_failure_code, lnd_failure_detail, node_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`,
+		eventTime, "{}", event.eventType, incomingChannelId, outgoingChannelId, 1_000_000,
+		event.boltFailureCode, event.lndFailureDetail, nodeId)
+	if err != nil {
+		t.Fatalf("inserting htlc event: %v", err)
+	}
+}
+
+func getTestChannelId(t *testing.T, db *sqlx.DB, lndShortChannelId uint64) int {
+	var channelId int
+	err := db.Get(&channelId, "SELECT channel_id FROM channel WHERE lnd_short_channel_id=$1;", lndShortChannelId)
+	if err != nil {
+		t.Fatalf("obtaining channel id: %v", err)
+	}
+	return channelId
+}
+
+func TestHtlcFailureCategories(t *testing.T) {
+	srv, err := testutil.InitTestDBConn()
+	if err != nil {
+		panic(err)
+	}
+
+	db, cancel, err := srv.NewTestDatabase()
+	defer cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	nodeId, _ := testutil.Setup(db, cancel)
+	incomingChannelId := getTestChannelId(t, db, 1111)
+	outgoingChannelId := getTestChannelId(t, db, 2222)
+
+	code := func(s string) *string { return &s }
+	events := []testHtlcEvent{
+		{"LinkFailEvent", code("TEMPORARY_CHANNEL_FAILURE"), code("INSUFFICIENT_BALANCE"), "liquidity"},
+		{"ForwardFailEvent", code("TEMPORARY_CHANNEL_FAILURE"), nil, "liquidity"},
+		{"LinkFailEvent", code("FEE_INSUFFICIENT"), code("NO_DETAIL"), "fee"},
+		{"LinkFailEvent", code("EXPIRY_TOO_SOON"), code("NO_DETAIL"), "timelock"},
+		{"LinkFailEvent", code("UNKNOWN_FAILURE"), code("FORWARDS_DISABLED"), "unavailable"},
+		{"LinkFailEvent", code("UNKNOWN_FAILURE"), code("NO_DETAIL"), "other"},
+		{"SettleEvent", nil, nil, ""},
+	}
+	// noon keeps every event on the day that the summary is queried for
+	now := time.Now().UTC()
+	eventTime := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+	for i, event := range events {
+		addHtlcEvent(t, db, nodeId, incomingChannelId, outgoingChannelId,
+			eventTime.Add(time.Duration(i)*time.Second), event)
+	}
+
+	testutil.Given(t, "htlc events with each failure category")
+	htlcs, total, err := getHtlcs(db, []int{nodeId}, nil, []string{"date asc"}, 0, 0)
+	if err != nil {
+		testutil.Fatalf(t, "getHtlcs: %v", err)
+	}
+	if total != uint64(len(events)) || len(htlcs) != len(events) {
+		testutil.Fatalf(t, "expected %v htlcs, got %v (total %v)", len(events), len(htlcs), total)
+	}
+	for i, htlc := range htlcs {
+		if htlc.FailureCategory != events[i].expectedCategory {
+			testutil.Errorf(t, "event %v: expected failure category %q, got %q",
+				i, events[i].expectedCategory, htlc.FailureCategory)
+		}
+	}
+	testutil.Successf(t, "every htlc is categorized")
+
+	testutil.Given(t, "the failure summary endpoint")
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterHtlcsRoutes(r.Group("/api/htlcs"), db)
+
+	today := eventTime.Format("2006-01-02")
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet,
+		"/api/htlcs/failures?bucket=week&from="+today+"&to="+today+
+			"&network="+strconv.Itoa(int(core.SigNet)), nil))
+	if recorder.Code != http.StatusOK {
+		testutil.Fatalf(t, "expected status %v, got %v: %v", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+	var summaries []HtlcFailureSummary
+	err = json.Unmarshal(recorder.Body.Bytes(), &summaries)
+	if err != nil {
+		testutil.Fatalf(t, "unmarshal failure summary: %v", err)
+	}
+	counts := make(map[string]uint64)
+	for _, summary := range summaries {
+		counts[summary.FailureCategory] += summary.Count
+	}
+	expectedCounts := map[string]uint64{"liquidity": 2, "fee": 1, "timelock": 1, "unavailable": 1, "other": 1}
+	for category, expected := range expectedCounts {
+		if counts[category] != expected {
+			testutil.Errorf(t, "expected %v failures in category %v, got %v", expected, category, counts[category])
+		}
+	}
+	if _, exists := counts[""]; exists {
+		testutil.Errorf(t, "settled htlcs should not be part of the failure summary")
+	}
+	testutil.Successf(t, "failures are grouped by category")
+
+	testutil.Given(t, "an htlc list filtered on the failure category")
+	recorder = httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet,
+		"/api/htlcs?network="+strconv.Itoa(int(core.SigNet))+
+			"&filter="+url.QueryEscape(`{"$filter":{"funcName":"eq","key":"failureCategory","parameter":"liquidity"}}`),
+		nil))
+	if recorder.Code != http.StatusOK {
+		testutil.Fatalf(t, "expected status %v, got %v: %v", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+	var response ah.ApiResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	if err != nil {
+		testutil.Fatalf(t, "unmarshal htlcs: %v", err)
+	}
+	if response.Pagination.Total != 2 {
+		testutil.Errorf(t, "expected 2 liquidity failures, got %v", response.Pagination.Total)
+	}
+	testutil.Successf(t, "htlcs can be filtered on the failure category")
+
+	testutil.Given(t, "an invalid bucket")
+	recorder = httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet,
+		"/api/htlcs/failures?bucket=month&from="+today+"&to="+today+"&network=0", nil))
+	if recorder.Code != http.StatusBadRequest {
+		testutil.Errorf(t, "expected status %v, got %v", http.StatusBadRequest, recorder.Code)
+	}
+	testutil.Successf(t, "invalid buckets are rejected")
+}
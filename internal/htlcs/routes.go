@@ -0,0 +1,11 @@
+package htlcs
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func RegisterHtlcsRoutes(r *gin.RouterGroup, db *sqlx.DB) {
+	r.GET("", func(c *gin.Context) { getHtlcsHandler(c, db) })
+	r.GET("failures", func(c *gin.Context) { getHtlcFailureSummaryHandler(c, db) })
+}
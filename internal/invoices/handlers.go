@@ -7,6 +7,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
 
 	ah "github.com/lncapital/torq/internal/api_helpers"
 	"github.com/lncapital/torq/internal/cache"
@@ -17,66 +18,10 @@ import (
 
 func getInvoicesHandler(c *gin.Context, db *sqlx.DB) {
 
-	// Filter parser with whitelisted columns
-	var filter sq.Sqlizer
-	filterParam := c.Query("filter")
-	var err error
-	if filterParam != "" {
-		filter, err = qp.ParseFilterParam(filterParam, []string{
-			"add_index",
-			"creation_date",
-			"settle_date",
-			"settle_index",
-			"payment_request",
-			"destination_pub_key",
-			"r_hash",
-			"r_preimage",
-			"memo",
-			"value",
-			"amt_paid",
-			"invoice_state",
-			"is_rebalance",
-			"is_keysend",
-			"is_amp",
-			"payment_addr",
-			"fallback_addr",
-			"updated_on",
-			"expiry",
-			"cltv_expiry",
-			"private",
-		})
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
-			return
-		}
-	}
-
-	var sort []string
-	sortParam := c.Query("order")
-	if sortParam != "" {
-		// Order parser with whitelisted columns
-		sort, err = qp.ParseOrderParams(
-			sortParam,
-			[]string{
-				"creation_date",
-				"settle_date",
-				"add_index",
-				"settle_index",
-				"memo",
-				"value",
-				"amt_paid",
-				"invoice_state",
-				"is_rebalance",
-				"is_keysend",
-				"is_amp",
-				"updated_on",
-				"expiry",
-				"private",
-			})
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
-			return
-		}
+	filter, sort, err := parseInvoicesFilterAndSort(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
 	}
 
 	var limit uint64
@@ -158,3 +103,99 @@ func getInvoiceHandler(c *gin.Context, db *sqlx.DB) {
 
 	c.JSON(http.StatusOK, r)
 }
+
+func getInvoicesExportHandler(c *gin.Context, db *sqlx.DB) {
+	format, err := ah.GetExportFormat(c.Query("format"))
+	if err != nil {
+		server_errors.SendBadRequest(c, err.Error())
+		return
+	}
+
+	filter, sort, err := parseInvoicesFilterAndSort(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+
+	chain := core.Bitcoin
+
+	rows, err := queryInvoices(db, cache.GetAllTorqNodeIdsByNetwork(chain, core.Network(network)), filter, sort, 0, 0)
+	if err != nil {
+		server_errors.LogAndSendServerError(c, err)
+		return
+	}
+	defer rows.Close()
+
+	exporter := ah.NewExporter(c, format, "invoices")
+	err = exporter.WriteRows(rows)
+	if err != nil {
+		log.Error().Err(err).Msg("Exporting invoices")
+	}
+	exporter.Flush()
+}
+
+func parseInvoicesFilterAndSort(c *gin.Context) (filter sq.Sqlizer, sort []string, err error) {
+	// Filter parser with whitelisted columns
+	filterParam := c.Query("filter")
+	if filterParam != "" {
+		filter, err = qp.ParseFilterParam(filterParam, []string{
+			"add_index",
+			"creation_date",
+			"settle_date",
+			"settle_index",
+			"payment_request",
+			"destination_pub_key",
+			"r_hash",
+			"r_preimage",
+			"memo",
+			"value",
+			"amt_paid",
+			"invoice_state",
+			"is_rebalance",
+			"is_keysend",
+			"is_amp",
+			"payment_addr",
+			"fallback_addr",
+			"updated_on",
+			"expiry",
+			"cltv_expiry",
+			"private",
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	sortParam := c.Query("order")
+	if sortParam != "" {
+		// Order parser with whitelisted columns
+		sort, err = qp.ParseOrderParams(
+			sortParam,
+			[]string{
+				"creation_date",
+				"settle_date",
+				"add_index",
+				"settle_index",
+				"memo",
+				"value",
+				"amt_paid",
+				"invoice_state",
+				"is_rebalance",
+				"is_keysend",
+				"is_amp",
+				"updated_on",
+				"expiry",
+				"private",
+			})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return filter, sort, nil
+}
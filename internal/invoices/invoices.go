@@ -37,47 +37,9 @@ type Invoice struct {
 func getInvoices(db *sqlx.DB, nodeIds []int, filter sq.Sqlizer, order []string, limit uint64, offset uint64) (r []*Invoice,
 	total uint64, err error) {
 
-	qb := sq.Select("*").FromSelect(sq.Select(`
-				add_index,
-				creation_date,
-				settle_date,
-				settle_index,
-				invoice.payment_request,
-				invoice.destination_pub_key,
-				r_hash,
-				r_preimage,
-				memo,
-				(invoice.value_msat/1000) as value,
-				(invoice.amt_paid_msat/1000) as amt_paid,
-				invoice_state,
-    			coalesce(invoice.r_hash = p.payment_hash, false) as is_rebalance,
-				is_keysend,
-				is_amp,
-				payment_addr,
-				fallback_addr,
-				invoice.updated_on,
-				expiry,
-				cltv_expiry,
-				private
-			`).From("invoice").
-		Where(sq.Eq{"invoice.node_id": nodeIds}).
-		LeftJoin("payment p on (invoice.r_hash = p.payment_hash)"), "subq").
-		PlaceholderFormat(sq.Dollar).
-		Where(filter).
-		OrderBy(order...)
-
-	if limit > 0 {
-		qb = qb.Limit(limit).Offset(offset)
-	}
-
-	qs, args, err := qb.ToSql()
-	if err != nil {
-		return nil, 0, errors.Wrap(err, "Compiling SQL")
-	}
-
-	rows, err := db.Queryx(qs, args...)
+	rows, err := queryInvoices(db, nodeIds, filter, order, limit, offset)
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "Running SQL Query")
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -159,6 +121,55 @@ func getInvoices(db *sqlx.DB, nodeIds []int, filter sq.Sqlizer, order []string,
 
 }
 
+// queryInvoices returns the open result set so it can be streamed (export) or scanned (table)
+func queryInvoices(db *sqlx.DB, nodeIds []int, filter sq.Sqlizer, order []string, limit uint64,
+	offset uint64) (*sqlx.Rows, error) {
+
+	qb := sq.Select("*").FromSelect(sq.Select(`
+				add_index,
+				creation_date,
+				settle_date,
+				settle_index,
+				invoice.payment_request,
+				invoice.destination_pub_key,
+				r_hash,
+				r_preimage,
+				memo,
+				(invoice.value_msat/1000) as value,
+				(invoice.amt_paid_msat/1000) as amt_paid,
+				invoice_state,
+    			coalesce(invoice.r_hash = p.payment_hash, false) as is_rebalance,
+				is_keysend,
+				is_amp,
+				payment_addr,
+				fallback_addr,
+				invoice.updated_on,
+				expiry,
+				cltv_expiry,
+				private
+			`).From("invoice").
+		Where(sq.Eq{"invoice.node_id": nodeIds}).
+		LeftJoin("payment p on (invoice.r_hash = p.payment_hash)"), "subq").
+		PlaceholderFormat(sq.Dollar).
+		Where(filter).
+		OrderBy(order...)
+
+	if limit > 0 {
+		qb = qb.Limit(limit).Offset(offset)
+	}
+
+	qs, args, err := qb.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "Compiling SQL")
+	}
+
+	rows, err := db.Queryx(qs, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Running SQL Query")
+	}
+	return rows, nil
+}
+
 type Htlc struct {
 	State             *uint64 `json:"state" db:"state"`
 	LNDShortChannelId *uint64 `json:"lndShortChannelId" db:"lnd_short_channel_id"`
@@ -7,5 +7,6 @@ import (
 
 func RegisterInvoicesRoutes(r *gin.RouterGroup, db *sqlx.DB) {
 	r.GET("", func(c *gin.Context) { getInvoicesHandler(c, db) })
+	r.GET("export", func(c *gin.Context) { getInvoicesExportHandler(c, db) })
 	r.GET(":identifier", func(c *gin.Context) { getInvoiceHandler(c, db) })
 }
@@ -4,6 +4,10 @@ import (
 	"context"
 
 	"github.com/cockroachdb/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/lncapital/torq/internal/cache"
 	"github.com/lncapital/torq/internal/cln"
@@ -15,7 +19,17 @@ import (
 var ServiceInactiveError = errors.New("service is not active")         //nolint:gochecknoglobals
 var UnsupportedOperationError = errors.New("request is not supported") //nolint:gochecknoglobals
 
+const name = "lightning"
+
+// startSpan traces a request to a node, the gRPC calls of the request become children of the span.
+func startSpan(ctx context.Context, operation string, nodeId int) (context.Context, trace.Span) {
+	return otel.Tracer(name).Start(ctx, operation, trace.WithAttributes(attribute.Int("nodeId", nodeId)))
+}
+
 func GetInformation(ctx context.Context, nodeId int) (lightning_helpers.InformationResponse, error) {
+	ctx, span := startSpan(ctx, "GetInformation", nodeId)
+	defer span.End()
+
 	request := lightning_helpers.InformationRequest{
 		CommunicationRequest: lightning_helpers.CommunicationRequest{
 			NodeId: nodeId,
@@ -35,7 +49,7 @@ func GetInformation(ctx context.Context, nodeId int) (lightning_helpers.Informat
 		if !cache.IsLndServiceActive(nodeId) {
 			return lightning_helpers.InformationResponse{}, ServiceInactiveError
 		}
-		response = lnd.Information(request)
+		response = lnd.Information(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(nodeId) {
 			return lightning_helpers.InformationResponse{}, ServiceInactiveError
@@ -43,12 +57,16 @@ func GetInformation(ctx context.Context, nodeId int) (lightning_helpers.Informat
 		response = cln.Information(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return lightning_helpers.InformationResponse{}, errors.New(response.Error)
 	}
 	return response, nil
 }
 
 func SignMessage(ctx context.Context, nodeId int, message string, singleHash *bool) (string, error) {
+	ctx, span := startSpan(ctx, "SignMessage", nodeId)
+	defer span.End()
+
 	request := lightning_helpers.SignMessageRequest{
 		CommunicationRequest: lightning_helpers.CommunicationRequest{
 			NodeId: nodeId,
@@ -70,7 +88,7 @@ func SignMessage(ctx context.Context, nodeId int, message string, singleHash *bo
 		if !cache.IsLndServiceActive(nodeId) {
 			return "", ServiceInactiveError
 		}
-		response = lnd.SignMessage(request)
+		response = lnd.SignMessage(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(nodeId) {
 			return "", ServiceInactiveError
@@ -78,12 +96,16 @@ func SignMessage(ctx context.Context, nodeId int, message string, singleHash *bo
 		response = cln.SignMessage(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return "", errors.New(response.Error)
 	}
 	return response.Signature, nil
 }
 
 func SignatureVerification(ctx context.Context, nodeId int, message string, signature string) (string, bool, error) {
+	ctx, span := startSpan(ctx, "SignatureVerification", nodeId)
+	defer span.End()
+
 	request := lightning_helpers.SignatureVerificationRequest{
 		CommunicationRequest: lightning_helpers.CommunicationRequest{
 			NodeId: nodeId,
@@ -105,7 +127,7 @@ func SignatureVerification(ctx context.Context, nodeId int, message string, sign
 		if !cache.IsLndServiceActive(nodeId) {
 			return "", false, ServiceInactiveError
 		}
-		response = lnd.SignatureVerification(request)
+		response = lnd.SignatureVerification(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(nodeId) {
 			return "", false, ServiceInactiveError
@@ -113,6 +135,7 @@ func SignatureVerification(ctx context.Context, nodeId int, message string, sign
 		response = cln.SignatureVerification(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return "", false, errors.New(response.Error)
 	}
 	return response.PublicKey, response.Valid, nil
@@ -120,6 +143,9 @@ func SignatureVerification(ctx context.Context, nodeId int, message string, sign
 
 func SetRoutingPolicy(ctx context.Context,
 	request lightning_helpers.RoutingPolicyUpdateRequest) (lightning_helpers.RoutingPolicyUpdateResponse, error) {
+	ctx, span := startSpan(ctx, "SetRoutingPolicy", request.NodeId)
+	defer span.End()
+	defer startInFlightOperation(ctx, request.NodeId, RoutingPolicyOperation, request)()
 
 	response := lightning_helpers.RoutingPolicyUpdateResponse{
 		Request: request,
@@ -134,7 +160,7 @@ func SetRoutingPolicy(ctx context.Context,
 		if !cache.IsLndServiceActive(request.NodeId) {
 			return lightning_helpers.RoutingPolicyUpdateResponse{}, ServiceInactiveError
 		}
-		response = lnd.RoutingPolicyUpdate(request)
+		response = lnd.RoutingPolicyUpdate(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(request.NodeId) {
 			return lightning_helpers.RoutingPolicyUpdateResponse{}, ServiceInactiveError
@@ -142,12 +168,16 @@ func SetRoutingPolicy(ctx context.Context,
 		response = cln.RoutingPolicyUpdate(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return lightning_helpers.RoutingPolicyUpdateResponse{}, errors.New(response.Error)
 	}
 	return response, nil
 }
 
 func ConnectPeer(ctx context.Context, nodeId int, publicKey string, host string) (bool, error) {
+	ctx, span := startSpan(ctx, "ConnectPeer", nodeId)
+	defer span.End()
+
 	request := lightning_helpers.ConnectPeerRequest{
 		CommunicationRequest: lightning_helpers.CommunicationRequest{
 			NodeId: nodeId,
@@ -169,7 +199,7 @@ func ConnectPeer(ctx context.Context, nodeId int, publicKey string, host string)
 		if !cache.IsLndServiceActive(nodeId) {
 			return false, ServiceInactiveError
 		}
-		response = lnd.ConnectPeer(request)
+		response = lnd.ConnectPeer(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(nodeId) {
 			return false, ServiceInactiveError
@@ -177,12 +207,16 @@ func ConnectPeer(ctx context.Context, nodeId int, publicKey string, host string)
 		response = cln.ConnectPeer(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return false, errors.New(response.Error)
 	}
 	return response.RequestFailCurrentlyConnected, nil
 }
 
 func DisconnectPeer(ctx context.Context, nodeId int, peerNodeId int) (bool, error) {
+	ctx, span := startSpan(ctx, "DisconnectPeer", nodeId)
+	defer span.End()
+
 	request := lightning_helpers.DisconnectPeerRequest{
 		CommunicationRequest: lightning_helpers.CommunicationRequest{
 			NodeId: nodeId,
@@ -203,7 +237,7 @@ func DisconnectPeer(ctx context.Context, nodeId int, peerNodeId int) (bool, erro
 		if !cache.IsLndServiceActive(nodeId) {
 			return false, ServiceInactiveError
 		}
-		response = lnd.DisconnectPeer(request)
+		response = lnd.DisconnectPeer(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(nodeId) {
 			return false, ServiceInactiveError
@@ -211,12 +245,16 @@ func DisconnectPeer(ctx context.Context, nodeId int, peerNodeId int) (bool, erro
 		response = cln.DisconnectPeer(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return false, errors.New(response.Error)
 	}
 	return response.RequestFailedCurrentlyDisconnected, nil
 }
 
 func GetWalletBalance(ctx context.Context, nodeId int) (lightning_helpers.WalletBalanceResponse, error) {
+	ctx, span := startSpan(ctx, "GetWalletBalance", nodeId)
+	defer span.End()
+
 	request := lightning_helpers.WalletBalanceRequest{
 		CommunicationRequest: lightning_helpers.CommunicationRequest{
 			NodeId: nodeId,
@@ -236,7 +274,7 @@ func GetWalletBalance(ctx context.Context, nodeId int) (lightning_helpers.Wallet
 		if !cache.IsLndServiceActive(nodeId) {
 			return lightning_helpers.WalletBalanceResponse{}, ServiceInactiveError
 		}
-		response = lnd.WalletBalance(request)
+		response = lnd.WalletBalance(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(nodeId) {
 			return lightning_helpers.WalletBalanceResponse{}, ServiceInactiveError
@@ -244,12 +282,270 @@ func GetWalletBalance(ctx context.Context, nodeId int) (lightning_helpers.Wallet
 		response = cln.WalletBalance(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return lightning_helpers.WalletBalanceResponse{}, errors.New(response.Error)
 	}
 	return response, nil
 }
 
+// GetFeeEstimate returns the on-chain fee rate the node expects to confirm a transaction within targetConf blocks.
+func GetFeeEstimate(ctx context.Context, nodeId int, targetConf int32) (lightning_helpers.FeeEstimateResponse, error) {
+	ctx, span := startSpan(ctx, "GetFeeEstimate", nodeId)
+	defer span.End()
+
+	request := lightning_helpers.FeeEstimateRequest{
+		CommunicationRequest: lightning_helpers.CommunicationRequest{
+			NodeId: nodeId,
+		},
+		TargetConf: targetConf,
+	}
+
+	response := lightning_helpers.FeeEstimateResponse{
+		Request: request,
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+	}
+
+	nodeConnectionDetails := cache.GetNodeConnectionDetails(nodeId)
+	switch nodeConnectionDetails.Implementation {
+	case core.LND:
+		if !cache.IsLndServiceActive(nodeId) {
+			return lightning_helpers.FeeEstimateResponse{}, ServiceInactiveError
+		}
+		response = lnd.FeeEstimate(ctx, request)
+	case core.CLN:
+		if !cache.IsClnServiceActive(nodeId) {
+			return lightning_helpers.FeeEstimateResponse{}, ServiceInactiveError
+		}
+		response = cln.FeeEstimate(ctx, request)
+	}
+	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
+		return lightning_helpers.FeeEstimateResponse{}, errors.New(response.Error)
+	}
+	return response, nil
+}
+
+// ListUnspent returns the unspent outputs of the wallet with at least minConfs confirmations, including the leased
+// (or reserved) outputs.
+func ListUnspent(ctx context.Context, nodeId int, minConfs int32) ([]lightning_helpers.Utxo, error) {
+	ctx, span := startSpan(ctx, "ListUnspent", nodeId)
+	defer span.End()
+
+	request := lightning_helpers.ListUnspentRequest{
+		CommunicationRequest: lightning_helpers.CommunicationRequest{
+			NodeId: nodeId,
+		},
+		MinConfs: minConfs,
+	}
+
+	response := lightning_helpers.ListUnspentResponse{
+		Request: request,
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+	}
+
+	nodeConnectionDetails := cache.GetNodeConnectionDetails(nodeId)
+	switch nodeConnectionDetails.Implementation {
+	case core.LND:
+		if !cache.IsLndServiceActive(nodeId) {
+			return nil, ServiceInactiveError
+		}
+		response = lnd.ListUnspent(ctx, request)
+	case core.CLN:
+		if !cache.IsClnServiceActive(nodeId) {
+			return nil, ServiceInactiveError
+		}
+		response = cln.ListUnspent(ctx, request)
+	}
+	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
+		return nil, errors.New(response.Error)
+	}
+	return response.Utxos, nil
+}
+
+// UnconfirmedTransactions returns the unconfirmed transactions of the wallet with the outpoints they spend.
+func UnconfirmedTransactions(ctx context.Context, nodeId int) ([]lightning_helpers.WalletTransaction, error) {
+	ctx, span := startSpan(ctx, "UnconfirmedTransactions", nodeId)
+	defer span.End()
+
+	request := lightning_helpers.UnconfirmedTransactionsRequest{
+		CommunicationRequest: lightning_helpers.CommunicationRequest{
+			NodeId: nodeId,
+		},
+	}
+
+	response := lightning_helpers.UnconfirmedTransactionsResponse{
+		Request: request,
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+	}
+
+	nodeConnectionDetails := cache.GetNodeConnectionDetails(nodeId)
+	switch nodeConnectionDetails.Implementation {
+	case core.LND:
+		if !cache.IsLndServiceActive(nodeId) {
+			return nil, ServiceInactiveError
+		}
+		response = lnd.UnconfirmedTransactions(ctx, request)
+	case core.CLN:
+		if !cache.IsClnServiceActive(nodeId) {
+			return nil, ServiceInactiveError
+		}
+		response = cln.UnconfirmedTransactions(ctx, request)
+	}
+	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
+		return nil, errors.New(response.Error)
+	}
+	return response.Transactions, nil
+}
+
+func LeaseOutput(ctx context.Context,
+	request lightning_helpers.LeaseOutputRequest) (lightning_helpers.LeaseOutputResponse, error) {
+	ctx, span := startSpan(ctx, "LeaseOutput", request.NodeId)
+	defer span.End()
+
+	response := lightning_helpers.LeaseOutputResponse{
+		Request: request,
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+	}
+
+	nodeConnectionDetails := cache.GetNodeConnectionDetails(request.NodeId)
+	switch nodeConnectionDetails.Implementation {
+	case core.LND:
+		if !cache.IsLndServiceActive(request.NodeId) {
+			return lightning_helpers.LeaseOutputResponse{}, ServiceInactiveError
+		}
+		response = lnd.LeaseOutput(ctx, request)
+	case core.CLN:
+		if !cache.IsClnServiceActive(request.NodeId) {
+			return lightning_helpers.LeaseOutputResponse{}, ServiceInactiveError
+		}
+		response = cln.LeaseOutput(ctx, request)
+	}
+	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
+		return lightning_helpers.LeaseOutputResponse{}, errors.New(response.Error)
+	}
+	return response, nil
+}
+
+// BumpFee speeds up an unconfirmed transaction by spending one of its outputs that belongs to the node with a child
+// transaction at a higher fee rate (CPFP). For LND the output can also be the anchor of a force close and LND 0.18 and
+// later replace the transaction at a higher fee rate (RBF) when one of its inputs belongs to the node.
+func BumpFee(ctx context.Context,
+	request lightning_helpers.BumpFeeRequest) (lightning_helpers.BumpFeeResponse, error) {
+	ctx, span := startSpan(ctx, "BumpFee", request.NodeId)
+	defer span.End()
+
+	response := lightning_helpers.BumpFeeResponse{
+		Request: request,
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+	}
+
+	nodeConnectionDetails := cache.GetNodeConnectionDetails(request.NodeId)
+	switch nodeConnectionDetails.Implementation {
+	case core.LND:
+		if !cache.IsLndServiceActive(request.NodeId) {
+			return lightning_helpers.BumpFeeResponse{}, ServiceInactiveError
+		}
+		response = lnd.BumpFee(ctx, request)
+	case core.CLN:
+		if !cache.IsClnServiceActive(request.NodeId) {
+			return lightning_helpers.BumpFeeResponse{}, ServiceInactiveError
+		}
+		response = cln.BumpFee(ctx, request)
+	}
+	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
+		return lightning_helpers.BumpFeeResponse{}, errors.New(response.Error)
+	}
+	return response, nil
+}
+
+// ForceClosingChannels returns the closed channels of the node with funds that are not swept yet, with the blocks
+// until our funds and the pending HTLC outputs mature.
+func ForceClosingChannels(ctx context.Context, nodeId int) ([]lightning_helpers.ForceClosingChannel, error) {
+	ctx, span := startSpan(ctx, "ForceClosingChannels", nodeId)
+	defer span.End()
+
+	request := lightning_helpers.ForceClosingChannelsRequest{
+		CommunicationRequest: lightning_helpers.CommunicationRequest{NodeId: nodeId},
+	}
+	response := lightning_helpers.ForceClosingChannelsResponse{
+		Request: request,
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+	}
+
+	nodeConnectionDetails := cache.GetNodeConnectionDetails(nodeId)
+	switch nodeConnectionDetails.Implementation {
+	case core.LND:
+		if !cache.IsLndServiceActive(nodeId) {
+			return nil, ServiceInactiveError
+		}
+		response = lnd.ForceClosingChannels(ctx, request)
+	case core.CLN:
+		if !cache.IsClnServiceActive(nodeId) {
+			return nil, ServiceInactiveError
+		}
+		response = cln.ForceClosingChannels(ctx, request)
+	}
+	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
+		return nil, errors.New(response.Error)
+	}
+	return response.Channels, nil
+}
+
+// ReleaseOutput releases an output leased by LeaseOutput. CLN can't release reserved outputs through gRPC, the
+// reservation expires instead.
+func ReleaseOutput(ctx context.Context,
+	request lightning_helpers.ReleaseOutputRequest) (lightning_helpers.ReleaseOutputResponse, error) {
+	ctx, span := startSpan(ctx, "ReleaseOutput", request.NodeId)
+	defer span.End()
+
+	response := lightning_helpers.ReleaseOutputResponse{
+		Request: request,
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+	}
+
+	nodeConnectionDetails := cache.GetNodeConnectionDetails(request.NodeId)
+	switch nodeConnectionDetails.Implementation {
+	case core.LND:
+		if !cache.IsLndServiceActive(request.NodeId) {
+			return lightning_helpers.ReleaseOutputResponse{}, ServiceInactiveError
+		}
+		response = lnd.ReleaseOutput(ctx, request)
+	case core.CLN:
+		if !cache.IsClnServiceActive(request.NodeId) {
+			return lightning_helpers.ReleaseOutputResponse{}, ServiceInactiveError
+		}
+		return lightning_helpers.ReleaseOutputResponse{}, UnsupportedOperationError
+	}
+	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
+		return lightning_helpers.ReleaseOutputResponse{}, errors.New(response.Error)
+	}
+	return response, nil
+}
+
 func ListPeers(ctx context.Context, nodeId int, latestError bool) (map[string]lightning_helpers.Peer, error) {
+	ctx, span := startSpan(ctx, "ListPeers", nodeId)
+	defer span.End()
+
 	request := lightning_helpers.ListPeersRequest{
 		CommunicationRequest: lightning_helpers.CommunicationRequest{
 			NodeId: nodeId,
@@ -270,7 +566,7 @@ func ListPeers(ctx context.Context, nodeId int, latestError bool) (map[string]li
 		if !cache.IsLndServiceActive(nodeId) {
 			return nil, ServiceInactiveError
 		}
-		response = lnd.ListPeers(request)
+		response = lnd.ListPeers(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(nodeId) {
 			return nil, ServiceInactiveError
@@ -278,6 +574,7 @@ func ListPeers(ctx context.Context, nodeId int, latestError bool) (map[string]li
 		response = cln.ListPeers(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return nil, errors.New(response.Error)
 	}
 	return response.Peers, nil
@@ -285,6 +582,9 @@ func ListPeers(ctx context.Context, nodeId int, latestError bool) (map[string]li
 
 func NewAddress(ctx context.Context,
 	request lightning_helpers.NewAddressRequest) (string, error) {
+	ctx, span := startSpan(ctx, "NewAddress", request.NodeId)
+	defer span.End()
+
 	response := lightning_helpers.NewAddressResponse{
 		Request: request,
 		CommunicationResponse: lightning_helpers.CommunicationResponse{
@@ -298,7 +598,7 @@ func NewAddress(ctx context.Context,
 		if !cache.IsLndServiceActive(request.NodeId) {
 			return "", ServiceInactiveError
 		}
-		response = lnd.NewAddress(request)
+		response = lnd.NewAddress(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(request.NodeId) {
 			return "", ServiceInactiveError
@@ -306,6 +606,7 @@ func NewAddress(ctx context.Context,
 		response = cln.NewAddress(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return "", errors.New(response.Error)
 	}
 	return response.Address, nil
@@ -313,6 +614,10 @@ func NewAddress(ctx context.Context,
 
 func OpenChannel(ctx context.Context,
 	request lightning_helpers.OpenChannelRequest) (lightning_helpers.OpenChannelResponse, error) {
+	ctx, span := startSpan(ctx, "OpenChannel", request.NodeId)
+	defer span.End()
+	defer startInFlightOperation(ctx, request.NodeId, OpenChannelOperation, request)()
+
 	response := lightning_helpers.OpenChannelResponse{
 		Request: request,
 		CommunicationResponse: lightning_helpers.CommunicationResponse{
@@ -326,7 +631,7 @@ func OpenChannel(ctx context.Context,
 		if !cache.IsLndServiceActive(request.NodeId) {
 			return lightning_helpers.OpenChannelResponse{}, ServiceInactiveError
 		}
-		response = lnd.OpenChannel(request)
+		response = lnd.OpenChannel(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(request.NodeId) {
 			return lightning_helpers.OpenChannelResponse{}, ServiceInactiveError
@@ -334,6 +639,7 @@ func OpenChannel(ctx context.Context,
 		response = cln.OpenChannel(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return lightning_helpers.OpenChannelResponse{}, errors.New(response.Error)
 	}
 	return response, nil
@@ -341,6 +647,9 @@ func OpenChannel(ctx context.Context,
 
 func BatchOpenChannel(ctx context.Context,
 	request lightning_helpers.BatchOpenChannelRequest) (lightning_helpers.BatchOpenChannelResponse, error) {
+	ctx, span := startSpan(ctx, "BatchOpenChannel", request.NodeId)
+	defer span.End()
+	defer startInFlightOperation(ctx, request.NodeId, BatchOpenChannelOperation, request)()
 
 	response := lightning_helpers.BatchOpenChannelResponse{
 		Request: request,
@@ -355,7 +664,7 @@ func BatchOpenChannel(ctx context.Context,
 		if !cache.IsLndServiceActive(request.NodeId) {
 			return lightning_helpers.BatchOpenChannelResponse{}, ServiceInactiveError
 		}
-		response = lnd.BatchOpenChannel(request)
+		response = lnd.BatchOpenChannel(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(request.NodeId) {
 			return lightning_helpers.BatchOpenChannelResponse{}, ServiceInactiveError
@@ -363,6 +672,7 @@ func BatchOpenChannel(ctx context.Context,
 		return lightning_helpers.BatchOpenChannelResponse{}, UnsupportedOperationError
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return lightning_helpers.BatchOpenChannelResponse{}, errors.New(response.Error)
 	}
 	return response, nil
@@ -370,6 +680,9 @@ func BatchOpenChannel(ctx context.Context,
 
 func CloseChannel(ctx context.Context,
 	request lightning_helpers.CloseChannelRequest) (lightning_helpers.CloseChannelResponse, error) {
+	ctx, span := startSpan(ctx, "CloseChannel", request.NodeId)
+	defer span.End()
+
 	response := lightning_helpers.CloseChannelResponse{
 		Request: request,
 		CommunicationResponse: lightning_helpers.CommunicationResponse{
@@ -383,7 +696,7 @@ func CloseChannel(ctx context.Context,
 		if !cache.IsLndServiceActive(request.NodeId) {
 			return lightning_helpers.CloseChannelResponse{}, ServiceInactiveError
 		}
-		response = lnd.CloseChannel(request)
+		response = lnd.CloseChannel(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(request.NodeId) {
 			return lightning_helpers.CloseChannelResponse{}, ServiceInactiveError
@@ -391,6 +704,7 @@ func CloseChannel(ctx context.Context,
 		response = cln.CloseChannel(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return lightning_helpers.CloseChannelResponse{}, errors.New(response.Error)
 	}
 	return response, nil
@@ -398,6 +712,9 @@ func CloseChannel(ctx context.Context,
 
 func NewInvoice(ctx context.Context,
 	request lightning_helpers.NewInvoiceRequest) (lightning_helpers.NewInvoiceResponse, error) {
+	ctx, span := startSpan(ctx, "NewInvoice", request.NodeId)
+	defer span.End()
+
 	response := lightning_helpers.NewInvoiceResponse{
 		Request: request,
 		CommunicationResponse: lightning_helpers.CommunicationResponse{
@@ -411,7 +728,7 @@ func NewInvoice(ctx context.Context,
 		if !cache.IsLndServiceActive(request.NodeId) {
 			return lightning_helpers.NewInvoiceResponse{}, ServiceInactiveError
 		}
-		response = lnd.NewInvoice(request)
+		response = lnd.NewInvoice(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(request.NodeId) {
 			return lightning_helpers.NewInvoiceResponse{}, ServiceInactiveError
@@ -419,6 +736,7 @@ func NewInvoice(ctx context.Context,
 		response = cln.NewInvoice(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return lightning_helpers.NewInvoiceResponse{}, errors.New(response.Error)
 	}
 	return response, nil
@@ -426,6 +744,9 @@ func NewInvoice(ctx context.Context,
 
 func OnChainPayment(ctx context.Context,
 	request lightning_helpers.OnChainPaymentRequest) (string, error) {
+	ctx, span := startSpan(ctx, "OnChainPayment", request.NodeId)
+	defer span.End()
+
 	response := lightning_helpers.OnChainPaymentResponse{
 		Request: request,
 		CommunicationResponse: lightning_helpers.CommunicationResponse{
@@ -439,7 +760,7 @@ func OnChainPayment(ctx context.Context,
 		if !cache.IsLndServiceActive(request.NodeId) {
 			return "", ServiceInactiveError
 		}
-		response = lnd.OnChainPayment(request)
+		response = lnd.OnChainPayment(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(request.NodeId) {
 			return "", ServiceInactiveError
@@ -447,6 +768,7 @@ func OnChainPayment(ctx context.Context,
 		response = cln.OnChainPayment(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return "", errors.New(response.Error)
 	}
 	return response.TxId, nil
@@ -454,6 +776,9 @@ func OnChainPayment(ctx context.Context,
 
 func NewPayment(ctx context.Context,
 	request lightning_helpers.NewPaymentRequest) (lightning_helpers.NewPaymentResponse, error) {
+	ctx, span := startSpan(ctx, "NewPayment", request.NodeId)
+	defer span.End()
+
 	response := lightning_helpers.NewPaymentResponse{
 		Request: request,
 		CommunicationResponse: lightning_helpers.CommunicationResponse{
@@ -467,7 +792,7 @@ func NewPayment(ctx context.Context,
 		if !cache.IsLndServiceActive(request.NodeId) {
 			return lightning_helpers.NewPaymentResponse{}, ServiceInactiveError
 		}
-		response = lnd.NewPayment(request)
+		response = lnd.NewPayment(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(request.NodeId) {
 			return lightning_helpers.NewPaymentResponse{}, ServiceInactiveError
@@ -475,13 +800,56 @@ func NewPayment(ctx context.Context,
 		response = cln.NewPayment(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return lightning_helpers.NewPaymentResponse{}, errors.New(response.Error)
 	}
 	return response, nil
 }
 
+// PaymentStatus returns the status of the payment for the hash that was sent by the node.
+func PaymentStatus(ctx context.Context, nodeId int, paymentHash string) (string, error) {
+	ctx, span := startSpan(ctx, "PaymentStatus", nodeId)
+	defer span.End()
+
+	request := lightning_helpers.PaymentStatusRequest{
+		CommunicationRequest: lightning_helpers.CommunicationRequest{
+			NodeId: nodeId,
+		},
+		PaymentHash: paymentHash,
+	}
+
+	response := lightning_helpers.PaymentStatusResponse{
+		Request: request,
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+	}
+
+	nodeConnectionDetails := cache.GetNodeConnectionDetails(nodeId)
+	switch nodeConnectionDetails.Implementation {
+	case core.LND:
+		if !cache.IsLndServiceActive(nodeId) {
+			return "", ServiceInactiveError
+		}
+		response = lnd.PaymentStatus(ctx, request)
+	case core.CLN:
+		if !cache.IsClnServiceActive(nodeId) {
+			return "", ServiceInactiveError
+		}
+		response = cln.PaymentStatus(ctx, request)
+	}
+	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
+		return "", errors.New(response.Error)
+	}
+	return response.PaymentStatus, nil
+}
+
 func DecodeInvoice(ctx context.Context,
 	request lightning_helpers.DecodeInvoiceRequest) (lightning_helpers.DecodeInvoiceResponse, error) {
+	ctx, span := startSpan(ctx, "DecodeInvoice", request.NodeId)
+	defer span.End()
+
 	response := lightning_helpers.DecodeInvoiceResponse{
 		Request: request,
 		CommunicationResponse: lightning_helpers.CommunicationResponse{
@@ -495,7 +863,7 @@ func DecodeInvoice(ctx context.Context,
 		if !cache.IsLndServiceActive(request.NodeId) {
 			return lightning_helpers.DecodeInvoiceResponse{}, ServiceInactiveError
 		}
-		response = lnd.DecodeInvoice(request)
+		response = lnd.DecodeInvoice(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(request.NodeId) {
 			return lightning_helpers.DecodeInvoiceResponse{}, ServiceInactiveError
@@ -503,12 +871,16 @@ func DecodeInvoice(ctx context.Context,
 		response = cln.DecodeInvoice(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return lightning_helpers.DecodeInvoiceResponse{}, errors.New(response.Error)
 	}
 	return response, nil
 }
 
 func ChannelStatusUpdate(ctx context.Context, request lightning_helpers.ChannelStatusUpdateRequest) error {
+	ctx, span := startSpan(ctx, "ChannelStatusUpdate", request.NodeId)
+	defer span.End()
+
 	response := lightning_helpers.ChannelStatusUpdateResponse{
 		Request: request,
 		CommunicationResponse: lightning_helpers.CommunicationResponse{
@@ -522,7 +894,7 @@ func ChannelStatusUpdate(ctx context.Context, request lightning_helpers.ChannelS
 		if !cache.IsLndServiceActive(request.NodeId) {
 			return ServiceInactiveError
 		}
-		response = lnd.ChannelStatusUpdate(request)
+		response = lnd.ChannelStatusUpdate(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(request.NodeId) {
 			return ServiceInactiveError
@@ -530,6 +902,7 @@ func ChannelStatusUpdate(ctx context.Context, request lightning_helpers.ChannelS
 		return UnsupportedOperationError
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return errors.New(response.Error)
 	}
 	return nil
@@ -537,6 +910,9 @@ func ChannelStatusUpdate(ctx context.Context, request lightning_helpers.ChannelS
 
 func MoveFundsOffChain(ctx context.Context,
 	request lightning_helpers.MoveFundsOffChainRequest) (lightning_helpers.MoveFundsOffChainResponse, error) {
+	ctx, span := startSpan(ctx, "MoveFundsOffChain", request.NodeId)
+	defer span.End()
+
 	response := lightning_helpers.MoveFundsOffChainResponse{
 		Request: request,
 		CommunicationResponse: lightning_helpers.CommunicationResponse{
@@ -550,7 +926,7 @@ func MoveFundsOffChain(ctx context.Context,
 		if !cache.IsLndServiceActive(request.NodeId) {
 			return lightning_helpers.MoveFundsOffChainResponse{}, ServiceInactiveError
 		}
-		response = lnd.MoveFundsOffChain(request)
+		response = lnd.MoveFundsOffChain(ctx, request)
 	case core.CLN:
 		if !cache.IsClnServiceActive(request.NodeId) {
 			return lightning_helpers.MoveFundsOffChainResponse{}, ServiceInactiveError
@@ -558,6 +934,7 @@ func MoveFundsOffChain(ctx context.Context,
 		response = cln.MoveFundsOffChain(ctx, request)
 	}
 	if response.Error != "" {
+		span.SetStatus(codes.Error, response.Error)
 		return lightning_helpers.MoveFundsOffChainResponse{}, errors.New(response.Error)
 	}
 	return response, nil
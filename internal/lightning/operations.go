@@ -0,0 +1,87 @@
+package lightning
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type OperationType string
+
+const (
+	RebalanceOperation        OperationType = "rebalance"
+	RoutingPolicyOperation    OperationType = "routingPolicy"
+	OpenChannelOperation      OperationType = "openChannel"
+	BatchOpenChannelOperation OperationType = "batchOpenChannel"
+)
+
+// InFlightOperation is a request that was sent to a node and didn't return yet.
+type InFlightOperation struct {
+	NodeId        int
+	OperationType OperationType
+	Request       interface{}
+	StartedOn     time.Time
+}
+
+const inFlightPollMilliseconds = 100
+
+var inFlightOperations = make(map[uint64]InFlightOperation) //nolint:gochecknoglobals
+var inFlightOperationSequence uint64                        //nolint:gochecknoglobals
+var inFlightOperationsMutex sync.Mutex                      //nolint:gochecknoglobals
+
+type withoutResumeKey struct{}
+
+// WithoutResume is used by callers that handle their own interrupted operations, the operations executed with the
+// context are not executed again on the next start.
+func WithoutResume(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutResumeKey{}, true)
+}
+
+// startInFlightOperation registers the request until the returned function is called, operations that are still
+// registered when Torq shuts down are stored and executed again on the next start.
+func startInFlightOperation(ctx context.Context,
+	nodeId int,
+	operationType OperationType,
+	request interface{}) func() {
+
+	if withoutResume, ok := ctx.Value(withoutResumeKey{}).(bool); ok && withoutResume {
+		return func() {}
+	}
+	inFlightOperationsMutex.Lock()
+	defer inFlightOperationsMutex.Unlock()
+	inFlightOperationSequence++
+	sequence := inFlightOperationSequence
+	inFlightOperations[sequence] = InFlightOperation{
+		NodeId:        nodeId,
+		OperationType: operationType,
+		Request:       request,
+		StartedOn:     time.Now().UTC(),
+	}
+	return func() {
+		inFlightOperationsMutex.Lock()
+		defer inFlightOperationsMutex.Unlock()
+		delete(inFlightOperations, sequence)
+	}
+}
+
+func getInFlightOperations() []InFlightOperation {
+	inFlightOperationsMutex.Lock()
+	defer inFlightOperationsMutex.Unlock()
+	var operations []InFlightOperation
+	for _, operation := range inFlightOperations {
+		operations = append(operations, operation)
+	}
+	return operations
+}
+
+// WaitForInFlightOperations waits until every in-flight operation returned or the deadline passed and returns the
+// operations that are still in flight.
+func WaitForInFlightOperations(deadline time.Time) []InFlightOperation {
+	for {
+		operations := getInFlightOperations()
+		if len(operations) == 0 || time.Now().After(deadline) {
+			return operations
+		}
+		time.Sleep(inFlightPollMilliseconds * time.Millisecond)
+	}
+}
@@ -17,6 +17,16 @@ const (
 	RebalanceManual
 )
 
+func (ro RebalanceOrigin) String() string {
+	switch ro {
+	case RebalanceWorkflowNode:
+		return "WorkflowNode"
+	case RebalanceManual:
+		return "Manual"
+	}
+	return "Unknown"
+}
+
 type PeerSyncType int32
 
 const (
@@ -197,6 +207,48 @@ type WalletBalanceRequest struct {
 	CommunicationRequest
 }
 
+type FeeEstimateRequest struct {
+	CommunicationRequest
+	// TargetConf is the number of blocks the transaction should confirm within
+	TargetConf int32 `json:"targetConf"`
+}
+
+type ListUnspentRequest struct {
+	CommunicationRequest
+	// MinConfs of 0 includes the unconfirmed outputs
+	MinConfs int32 `json:"minConfs"`
+}
+
+type UnconfirmedTransactionsRequest struct {
+	CommunicationRequest
+}
+
+type LeaseOutputRequest struct {
+	CommunicationRequest
+	// Outpoint is the txid:index of the output
+	Outpoint          string `json:"outpoint"`
+	ExpirationSeconds uint64 `json:"expirationSeconds"`
+}
+
+type BumpFeeRequest struct {
+	CommunicationRequest
+	// TxId is the unconfirmed transaction, a child transaction spends one of its outputs that belongs to the node
+	TxId        string  `json:"txId"`
+	TargetConf  *int32  `json:"targetConf"`
+	SatPerVbyte *uint64 `json:"satPerVbyte"`
+	// Force sweeps the output even when the fee is higher than its value
+	Force bool `json:"force"`
+}
+
+type ReleaseOutputRequest struct {
+	CommunicationRequest
+	Outpoint string `json:"outpoint"`
+}
+
+type ForceClosingChannelsRequest struct {
+	CommunicationRequest
+}
+
 type ListPeersRequest struct {
 	CommunicationRequest
 	LatestError bool `json:"latestError"`
@@ -223,6 +275,8 @@ type OpenChannelRequest struct {
 	MinConfs           *int32  `json:"minConfs"`
 	SpendUnconfirmed   *bool   `json:"spendUnconfirmed"`
 	CloseAddress       *string `json:"closeAddress"`
+	// Outpoints (txid:index) restricts the funding of the channel to these outputs
+	Outpoints []string `json:"outpoints"`
 }
 
 type BatchOpenChannel struct {
@@ -277,6 +331,8 @@ type OnChainPaymentRequest struct {
 	Label            *string `json:"label"`
 	MinConfs         *int32  `json:"minConfs"`
 	SpendUnconfirmed *bool   `json:"spendUnconfirmed"`
+	// Outpoints (txid:index) restricts the inputs of the transaction to these outputs
+	Outpoints []string `json:"outpoints"`
 }
 
 type NewPaymentRequest struct {
@@ -290,6 +346,11 @@ type NewPaymentRequest struct {
 	AllowSelfPayment      *bool              `json:"allowSelfPayment"`
 }
 
+type PaymentStatusRequest struct {
+	CommunicationRequest
+	PaymentHash string `json:"paymentHash"`
+}
+
 type DecodeInvoiceRequest struct {
 	CommunicationRequest
 	Invoice string `json:"invoice"`
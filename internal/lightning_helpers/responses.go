@@ -91,6 +91,106 @@ type WalletBalanceResponse struct {
 	ReservedBalanceAnchorChan int64 `json:"reservedBalanceAnchorChan"`
 }
 
+type FeeEstimateResponse struct {
+	Request FeeEstimateRequest `json:"request"`
+	CommunicationResponse
+	SatPerVbyte float64 `json:"satPerVbyte"`
+}
+
+type Utxo struct {
+	Outpoint      string `json:"outpoint"`
+	TxId          string `json:"txId"`
+	OutputIndex   uint32 `json:"outputIndex"`
+	AmountSat     int64  `json:"amountSat"`
+	Address       string `json:"address"`
+	AddressType   string `json:"addressType"`
+	Confirmations int64  `json:"confirmations"`
+	// Leased is true when the output is locked (LND) or reserved (CLN) and can't be spent by the wallet
+	Leased bool `json:"leased"`
+	// LeasedUntil is only known for LND, CLN reserves outputs for a number of blocks
+	LeasedUntil *time.Time `json:"leasedUntil"`
+}
+
+type ForceClosingChannel struct {
+	ChannelPoint    string `json:"channelPoint"`
+	RemotePublicKey string `json:"remotePublicKey"`
+	// ClosingTxId is empty for CLN, CLN doesn't report the closing transaction of a channel
+	ClosingTxId string `json:"closingTxId"`
+	// WaitingClose is true while the closing transaction is unconfirmed
+	WaitingClose        bool  `json:"waitingClose"`
+	LimboBalanceSat     int64 `json:"limboBalanceSat"`
+	RecoveredBalanceSat int64 `json:"recoveredBalanceSat"`
+	// MaturityHeight is the height at which our output becomes spendable (CSV), it's unknown for CLN
+	MaturityHeight    *uint32 `json:"maturityHeight"`
+	BlocksTilMaturity *int32  `json:"blocksTilMaturity"`
+	// AnchorState is limbo, recovered or lost for LND and unknown for CLN
+	AnchorState  string        `json:"anchorState"`
+	PendingHtlcs []PendingHtlc `json:"pendingHtlcs"`
+	// Status is the status description of CLN
+	Status []string `json:"status"`
+}
+
+type PendingHtlc struct {
+	Incoming  bool  `json:"incoming"`
+	AmountSat int64 `json:"amountSat"`
+	// Outpoint is unknown for CLN
+	Outpoint string `json:"outpoint"`
+	// MaturityHeight is the height at which the HTLC output can be swept (LND) or the HTLC expires (CLN)
+	MaturityHeight    uint32 `json:"maturityHeight"`
+	BlocksTilMaturity int32  `json:"blocksTilMaturity"`
+	// Stage is 1 while the HTLC output is on the commitment transaction and 2 on the second level transaction (LND)
+	Stage uint32 `json:"stage"`
+}
+
+type ForceClosingChannelsResponse struct {
+	Request ForceClosingChannelsRequest `json:"request"`
+	CommunicationResponse
+	Channels []ForceClosingChannel `json:"channels"`
+}
+
+type WalletTransaction struct {
+	TxId string `json:"txId"`
+	// Inputs are the outpoints (txid:index) spent by the transaction
+	Inputs []string `json:"inputs"`
+	// Incoming is true when none of the inputs belong to the wallet of the node, someone else sent the transaction
+	Incoming bool `json:"incoming"`
+}
+
+type UnconfirmedTransactionsResponse struct {
+	Request UnconfirmedTransactionsRequest `json:"request"`
+	CommunicationResponse
+	Transactions []WalletTransaction `json:"transactions"`
+}
+
+type ListUnspentResponse struct {
+	Request ListUnspentRequest `json:"request"`
+	CommunicationResponse
+	Utxos []Utxo `json:"utxos"`
+}
+
+type LeaseOutputResponse struct {
+	Request LeaseOutputRequest `json:"request"`
+	CommunicationResponse
+	Expiration time.Time `json:"expiration"`
+}
+
+type BumpFeeResponse struct {
+	Request BumpFeeRequest `json:"request"`
+	CommunicationResponse
+	// Outpoint is the output that is spent by the child transaction
+	Outpoint string `json:"outpoint"`
+	// ChildTxId is only known when the child transaction was published directly (CLN)
+	ChildTxId string `json:"childTxId"`
+	// Replaced is true when the transaction is replaced at a higher fee rate (RBF) instead of bumped by a child, the
+	// outpoint is then the input of the transaction that was bumped (LND 0.18 and later)
+	Replaced bool `json:"replaced"`
+}
+
+type ReleaseOutputResponse struct {
+	Request ReleaseOutputRequest `json:"request"`
+	CommunicationResponse
+}
+
 type ListPeersResponse struct {
 	Request ListPeersRequest `json:"request"`
 	CommunicationResponse
@@ -196,6 +296,21 @@ type NewPaymentResponse struct {
 	Attempt        Attempt   `json:"path"`
 }
 
+// PaymentStatus values of the PaymentStatusResponse, they match the LND payment statuses.
+const (
+	PaymentStatusUnknown   = "UNKNOWN"
+	PaymentStatusInFlight  = "IN_FLIGHT"
+	PaymentStatusSucceeded = "SUCCEEDED"
+	PaymentStatusFailed    = "FAILED"
+)
+
+type PaymentStatusResponse struct {
+	Request PaymentStatusRequest `json:"request"`
+	CommunicationResponse
+	// PaymentStatus is UNKNOWN when the node never sent a payment for the hash
+	PaymentStatus string `json:"paymentStatus"`
+}
+
 type FeatureMap map[uint32]Feature
 
 type HopHint struct {
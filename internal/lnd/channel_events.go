@@ -18,6 +18,7 @@ import (
 	"github.com/lncapital/torq/internal/channels"
 	"github.com/lncapital/torq/internal/core"
 	"github.com/lncapital/torq/internal/nodes"
+	"github.com/lncapital/torq/internal/tracing"
 	"github.com/lncapital/torq/internal/vector"
 
 	"github.com/rs/zerolog/log"
@@ -390,11 +391,14 @@ func SubscribeAndStoreChannelEvents(ctx context.Context,
 			return
 		}
 
-		err = storeChannelEvent(ctx, db, chanEvent, nodeSettings)
+		eventCtx, span := startStreamEventSpan(ctx, "channelEvent", nodeSettings.NodeId)
+		err = storeChannelEvent(eventCtx, db, chanEvent, nodeSettings)
 		if err != nil {
 			// TODO FIXME STORE THIS SOMEWHERE??? CHANNELEVENT IS NOW IGNORED???
-			log.Error().Err(err).Msgf("Storing channel event failed for nodeId: %v", nodeSettings.NodeId)
+			tracing.Log(eventCtx).Error().Err(err).Msgf("Storing channel event failed for nodeId: %v",
+				nodeSettings.NodeId)
 		}
+		tracing.End(span, err)
 	}
 }
 
@@ -22,6 +22,7 @@ import (
 	"github.com/lncapital/torq/internal/core"
 	"github.com/lncapital/torq/internal/graph_events"
 	"github.com/lncapital/torq/internal/nodes"
+	"github.com/lncapital/torq/internal/tracing"
 )
 
 type subscribeChannelGraphClient interface {
@@ -74,17 +75,22 @@ func SubscribeAndStoreChannelGraph(ctx context.Context,
 			return
 		}
 
+		eventCtx, span := startStreamEventSpan(ctx, "channelGraphUpdate", nodeSettings.NodeId)
 		err = processNodeUpdates(gpu.NodeUpdates, db, nodeSettings)
 		if err != nil {
 			// TODO FIXME STORE THIS SOMEWHERE??? NODE UPDATES ARE NOW IGNORED???
-			log.Error().Err(err).Msgf("Failed to store node update events for nodeId: %v", nodeSettings.NodeId)
+			span.RecordError(err)
+			tracing.Log(eventCtx).Error().Err(err).Msgf("Failed to store node update events for nodeId: %v",
+				nodeSettings.NodeId)
 		}
 
 		err = processChannelUpdates(gpu.ChannelUpdates, db, nodeSettings)
 		if err != nil {
 			// TODO FIXME STORE THIS SOMEWHERE??? CHANNEL UPDATES ARE NOW IGNORED???
-			log.Error().Err(err).Msgf("Failed to store channel update events for nodeId: %v", nodeSettings.NodeId)
+			tracing.Log(eventCtx).Error().Err(err).Msgf("Failed to store channel update events for nodeId: %v",
+				nodeSettings.NodeId)
 		}
+		tracing.End(span, err)
 	}
 }
 
@@ -31,6 +31,7 @@ import (
 	"github.com/lncapital/torq/internal/core"
 	"github.com/lncapital/torq/internal/graph_events"
 	"github.com/lncapital/torq/internal/settings"
+	"github.com/lncapital/torq/internal/tracing"
 	"github.com/lncapital/torq/pkg/lnd_connect"
 )
 
@@ -98,9 +99,9 @@ type lightningService struct {
 	limit chan struct{}
 }
 
-func MoveFundsOffChain(request lightning_helpers.MoveFundsOffChainRequest) lightning_helpers.MoveFundsOffChainResponse {
+func MoveFundsOffChain(ctx context.Context, request lightning_helpers.MoveFundsOffChainRequest) lightning_helpers.MoveFundsOffChainResponse {
 	responseChan := make(chan any)
-	processConcurrent(context.Background(), 20, request, responseChan)
+	processConcurrent(tracing.Detach(ctx), 20, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.MoveFundsOffChainResponse); ok {
 		return res
@@ -108,9 +109,9 @@ func MoveFundsOffChain(request lightning_helpers.MoveFundsOffChainRequest) light
 	return lightning_helpers.MoveFundsOffChainResponse{}
 }
 
-func Information(request lightning_helpers.InformationRequest) lightning_helpers.InformationResponse {
+func Information(ctx context.Context, request lightning_helpers.InformationRequest) lightning_helpers.InformationResponse {
 	responseChan := make(chan any)
-	processSequential(context.Background(), 2, request, responseChan)
+	processSequential(tracing.Detach(ctx), 2, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.InformationResponse); ok {
 		return res
@@ -118,9 +119,9 @@ func Information(request lightning_helpers.InformationRequest) lightning_helpers
 	return lightning_helpers.InformationResponse{}
 }
 
-func SignMessage(request lightning_helpers.SignMessageRequest) lightning_helpers.SignMessageResponse {
+func SignMessage(ctx context.Context, request lightning_helpers.SignMessageRequest) lightning_helpers.SignMessageResponse {
 	responseChan := make(chan any)
-	processSequential(context.Background(), 2, request, responseChan)
+	processSequential(tracing.Detach(ctx), 2, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.SignMessageResponse); ok {
 		return res
@@ -128,9 +129,9 @@ func SignMessage(request lightning_helpers.SignMessageRequest) lightning_helpers
 	return lightning_helpers.SignMessageResponse{}
 }
 
-func SignatureVerification(request lightning_helpers.SignatureVerificationRequest) lightning_helpers.SignatureVerificationResponse {
+func SignatureVerification(ctx context.Context, request lightning_helpers.SignatureVerificationRequest) lightning_helpers.SignatureVerificationResponse {
 	responseChan := make(chan any)
-	processSequential(context.Background(), 2, request, responseChan)
+	processSequential(tracing.Detach(ctx), 2, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.SignatureVerificationResponse); ok {
 		return res
@@ -138,9 +139,9 @@ func SignatureVerification(request lightning_helpers.SignatureVerificationReques
 	return lightning_helpers.SignatureVerificationResponse{}
 }
 
-func RoutingPolicyUpdate(request lightning_helpers.RoutingPolicyUpdateRequest) lightning_helpers.RoutingPolicyUpdateResponse {
+func RoutingPolicyUpdate(ctx context.Context, request lightning_helpers.RoutingPolicyUpdateRequest) lightning_helpers.RoutingPolicyUpdateResponse {
 	responseChan := make(chan any)
-	processSequential(context.Background(), 2, request, responseChan)
+	processSequential(tracing.Detach(ctx), 2, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.RoutingPolicyUpdateResponse); ok {
 		return res
@@ -148,9 +149,9 @@ func RoutingPolicyUpdate(request lightning_helpers.RoutingPolicyUpdateRequest) l
 	return lightning_helpers.RoutingPolicyUpdateResponse{}
 }
 
-func ConnectPeer(request lightning_helpers.ConnectPeerRequest) lightning_helpers.ConnectPeerResponse {
+func ConnectPeer(ctx context.Context, request lightning_helpers.ConnectPeerRequest) lightning_helpers.ConnectPeerResponse {
 	responseChan := make(chan any)
-	processConcurrent(context.Background(), 60, request, responseChan)
+	processConcurrent(tracing.Detach(ctx), 60, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.ConnectPeerResponse); ok {
 		return res
@@ -158,9 +159,9 @@ func ConnectPeer(request lightning_helpers.ConnectPeerRequest) lightning_helpers
 	return lightning_helpers.ConnectPeerResponse{}
 }
 
-func DisconnectPeer(request lightning_helpers.DisconnectPeerRequest) lightning_helpers.DisconnectPeerResponse {
+func DisconnectPeer(ctx context.Context, request lightning_helpers.DisconnectPeerRequest) lightning_helpers.DisconnectPeerResponse {
 	responseChan := make(chan any)
-	processConcurrent(context.Background(), disconnectPeerTimeoutInSeconds, request, responseChan)
+	processConcurrent(tracing.Detach(ctx), disconnectPeerTimeoutInSeconds, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.DisconnectPeerResponse); ok {
 		return res
@@ -168,9 +169,9 @@ func DisconnectPeer(request lightning_helpers.DisconnectPeerRequest) lightning_h
 	return lightning_helpers.DisconnectPeerResponse{}
 }
 
-func WalletBalance(request lightning_helpers.WalletBalanceRequest) lightning_helpers.WalletBalanceResponse {
+func WalletBalance(ctx context.Context, request lightning_helpers.WalletBalanceRequest) lightning_helpers.WalletBalanceResponse {
 	responseChan := make(chan any)
-	processSequential(context.Background(), 2, request, responseChan)
+	processSequential(tracing.Detach(ctx), 2, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.WalletBalanceResponse); ok {
 		return res
@@ -178,9 +179,83 @@ func WalletBalance(request lightning_helpers.WalletBalanceRequest) lightning_hel
 	return lightning_helpers.WalletBalanceResponse{}
 }
 
-func ListPeers(request lightning_helpers.ListPeersRequest) lightning_helpers.ListPeersResponse {
+func FeeEstimate(ctx context.Context, request lightning_helpers.FeeEstimateRequest) lightning_helpers.FeeEstimateResponse {
 	responseChan := make(chan any)
-	processConcurrent(context.Background(), 60, request, responseChan)
+	processConcurrent(tracing.Detach(ctx), 10, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.FeeEstimateResponse); ok {
+		return res
+	}
+	return lightning_helpers.FeeEstimateResponse{}
+}
+
+func ListUnspent(ctx context.Context, request lightning_helpers.ListUnspentRequest) lightning_helpers.ListUnspentResponse {
+	responseChan := make(chan any)
+	processConcurrent(tracing.Detach(ctx), 30, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.ListUnspentResponse); ok {
+		return res
+	}
+	return lightning_helpers.ListUnspentResponse{}
+}
+
+func UnconfirmedTransactions(ctx context.Context,
+	request lightning_helpers.UnconfirmedTransactionsRequest) lightning_helpers.UnconfirmedTransactionsResponse {
+
+	responseChan := make(chan any)
+	processConcurrent(tracing.Detach(ctx), 30, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.UnconfirmedTransactionsResponse); ok {
+		return res
+	}
+	return lightning_helpers.UnconfirmedTransactionsResponse{}
+}
+
+func LeaseOutput(ctx context.Context, request lightning_helpers.LeaseOutputRequest) lightning_helpers.LeaseOutputResponse {
+	responseChan := make(chan any)
+	processSequential(tracing.Detach(ctx), 10, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.LeaseOutputResponse); ok {
+		return res
+	}
+	return lightning_helpers.LeaseOutputResponse{}
+}
+
+func ReleaseOutput(ctx context.Context, request lightning_helpers.ReleaseOutputRequest) lightning_helpers.ReleaseOutputResponse {
+	responseChan := make(chan any)
+	processSequential(tracing.Detach(ctx), 10, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.ReleaseOutputResponse); ok {
+		return res
+	}
+	return lightning_helpers.ReleaseOutputResponse{}
+}
+
+func BumpFee(ctx context.Context, request lightning_helpers.BumpFeeRequest) lightning_helpers.BumpFeeResponse {
+	responseChan := make(chan any)
+	processSequential(tracing.Detach(ctx), 30, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.BumpFeeResponse); ok {
+		return res
+	}
+	return lightning_helpers.BumpFeeResponse{}
+}
+
+func ForceClosingChannels(ctx context.Context,
+	request lightning_helpers.ForceClosingChannelsRequest) lightning_helpers.ForceClosingChannelsResponse {
+
+	responseChan := make(chan any)
+	processConcurrent(tracing.Detach(ctx), 30, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.ForceClosingChannelsResponse); ok {
+		return res
+	}
+	return lightning_helpers.ForceClosingChannelsResponse{}
+}
+
+func ListPeers(ctx context.Context, request lightning_helpers.ListPeersRequest) lightning_helpers.ListPeersResponse {
+	responseChan := make(chan any)
+	processConcurrent(tracing.Detach(ctx), 60, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.ListPeersResponse); ok {
 		return res
@@ -188,9 +263,9 @@ func ListPeers(request lightning_helpers.ListPeersRequest) lightning_helpers.Lis
 	return lightning_helpers.ListPeersResponse{}
 }
 
-func NewAddress(request lightning_helpers.NewAddressRequest) lightning_helpers.NewAddressResponse {
+func NewAddress(ctx context.Context, request lightning_helpers.NewAddressRequest) lightning_helpers.NewAddressResponse {
 	responseChan := make(chan any)
-	processSequential(context.Background(), 2, request, responseChan)
+	processSequential(tracing.Detach(ctx), 2, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.NewAddressResponse); ok {
 		return res
@@ -198,9 +273,9 @@ func NewAddress(request lightning_helpers.NewAddressRequest) lightning_helpers.N
 	return lightning_helpers.NewAddressResponse{}
 }
 
-func OpenChannel(request lightning_helpers.OpenChannelRequest) lightning_helpers.OpenChannelResponse {
+func OpenChannel(ctx context.Context, request lightning_helpers.OpenChannelRequest) lightning_helpers.OpenChannelResponse {
 	responseChan := make(chan any)
-	processConcurrent(context.Background(), 300, request, responseChan)
+	processConcurrent(tracing.Detach(ctx), 300, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.OpenChannelResponse); ok {
 		return res
@@ -208,9 +283,9 @@ func OpenChannel(request lightning_helpers.OpenChannelRequest) lightning_helpers
 	return lightning_helpers.OpenChannelResponse{}
 }
 
-func BatchOpenChannel(request lightning_helpers.BatchOpenChannelRequest) lightning_helpers.BatchOpenChannelResponse {
+func BatchOpenChannel(ctx context.Context, request lightning_helpers.BatchOpenChannelRequest) lightning_helpers.BatchOpenChannelResponse {
 	responseChan := make(chan any)
-	processConcurrent(context.Background(), 300, request, responseChan)
+	processConcurrent(tracing.Detach(ctx), 300, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.BatchOpenChannelResponse); ok {
 		return res
@@ -218,9 +293,9 @@ func BatchOpenChannel(request lightning_helpers.BatchOpenChannelRequest) lightni
 	return lightning_helpers.BatchOpenChannelResponse{}
 }
 
-func CloseChannel(request lightning_helpers.CloseChannelRequest) lightning_helpers.CloseChannelResponse {
+func CloseChannel(ctx context.Context, request lightning_helpers.CloseChannelRequest) lightning_helpers.CloseChannelResponse {
 	responseChan := make(chan any)
-	processConcurrent(context.Background(), 300, request, responseChan)
+	processConcurrent(tracing.Detach(ctx), 300, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.CloseChannelResponse); ok {
 		return res
@@ -228,9 +303,9 @@ func CloseChannel(request lightning_helpers.CloseChannelRequest) lightning_helpe
 	return lightning_helpers.CloseChannelResponse{}
 }
 
-func NewInvoice(request lightning_helpers.NewInvoiceRequest) lightning_helpers.NewInvoiceResponse {
+func NewInvoice(ctx context.Context, request lightning_helpers.NewInvoiceRequest) lightning_helpers.NewInvoiceResponse {
 	responseChan := make(chan any)
-	processSequential(context.Background(), 2, request, responseChan)
+	processSequential(tracing.Detach(ctx), 2, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.NewInvoiceResponse); ok {
 		return res
@@ -238,9 +313,10 @@ func NewInvoice(request lightning_helpers.NewInvoiceRequest) lightning_helpers.N
 	return lightning_helpers.NewInvoiceResponse{}
 }
 
-func OnChainPayment(request lightning_helpers.OnChainPaymentRequest) lightning_helpers.OnChainPaymentResponse {
+func OnChainPayment(ctx context.Context, request lightning_helpers.OnChainPaymentRequest) lightning_helpers.OnChainPaymentResponse {
 	responseChan := make(chan any)
-	processSequential(context.Background(), 2, request, responseChan)
+	// Selected outputs are funded, finalized and published as a PSBT, that's three requests instead of one
+	processSequential(tracing.Detach(ctx), 10, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.OnChainPaymentResponse); ok {
 		return res
@@ -255,9 +331,9 @@ func OnChainPayment(request lightning_helpers.OnChainPaymentRequest) lightning_h
 // amt and amt_msat are mutually exclusive
 // payments hash - the hash to use within the payment's HTLC
 // timeout seconds is mandatory
-func NewPayment(request lightning_helpers.NewPaymentRequest) lightning_helpers.NewPaymentResponse {
+func NewPayment(ctx context.Context, request lightning_helpers.NewPaymentRequest) lightning_helpers.NewPaymentResponse {
 	responseChan := make(chan any)
-	processConcurrent(context.Background(), 120, request, responseChan)
+	processConcurrent(tracing.Detach(ctx), 120, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.NewPaymentResponse); ok {
 		return res
@@ -265,9 +341,19 @@ func NewPayment(request lightning_helpers.NewPaymentRequest) lightning_helpers.N
 	return lightning_helpers.NewPaymentResponse{}
 }
 
-func DecodeInvoice(request lightning_helpers.DecodeInvoiceRequest) lightning_helpers.DecodeInvoiceResponse {
+func PaymentStatus(ctx context.Context, request lightning_helpers.PaymentStatusRequest) lightning_helpers.PaymentStatusResponse {
+	responseChan := make(chan any)
+	processConcurrent(tracing.Detach(ctx), 30, request, responseChan)
+	response := <-responseChan
+	if res, ok := response.(lightning_helpers.PaymentStatusResponse); ok {
+		return res
+	}
+	return lightning_helpers.PaymentStatusResponse{}
+}
+
+func DecodeInvoice(ctx context.Context, request lightning_helpers.DecodeInvoiceRequest) lightning_helpers.DecodeInvoiceResponse {
 	responseChan := make(chan any)
-	processSequential(context.Background(), 2, request, responseChan)
+	processSequential(tracing.Detach(ctx), 2, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.DecodeInvoiceResponse); ok {
 		return res
@@ -275,11 +361,11 @@ func DecodeInvoice(request lightning_helpers.DecodeInvoiceRequest) lightning_hel
 	return lightning_helpers.DecodeInvoiceResponse{}
 }
 
-func ChannelStatusUpdate(
+func ChannelStatusUpdate(ctx context.Context,
 	request lightning_helpers.ChannelStatusUpdateRequest) lightning_helpers.ChannelStatusUpdateResponse {
 
 	responseChan := make(chan any)
-	processSequential(context.Background(), 2, request, responseChan)
+	processSequential(tracing.Detach(ctx), 2, request, responseChan)
 	response := <-responseChan
 	if res, ok := response.(lightning_helpers.ChannelStatusUpdateResponse); ok {
 		return res
@@ -459,6 +545,48 @@ func processRequestByType(ctx context.Context, req any, responseChan chan<- any)
 					CommunicationResponse: communicationResponse,
 				}
 				return
+			case lightning_helpers.FeeEstimateRequest:
+				responseChan <- lightning_helpers.FeeEstimateResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
+			case lightning_helpers.ListUnspentRequest:
+				responseChan <- lightning_helpers.ListUnspentResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
+			case lightning_helpers.UnconfirmedTransactionsRequest:
+				responseChan <- lightning_helpers.UnconfirmedTransactionsResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
+			case lightning_helpers.LeaseOutputRequest:
+				responseChan <- lightning_helpers.LeaseOutputResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
+			case lightning_helpers.BumpFeeRequest:
+				responseChan <- lightning_helpers.BumpFeeResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
+			case lightning_helpers.ForceClosingChannelsRequest:
+				responseChan <- lightning_helpers.ForceClosingChannelsResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
+			case lightning_helpers.ReleaseOutputRequest:
+				responseChan <- lightning_helpers.ReleaseOutputResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
 			case lightning_helpers.ListPeersRequest:
 				responseChan <- lightning_helpers.ListPeersResponse{
 					Request:               r,
@@ -495,6 +623,12 @@ func processRequestByType(ctx context.Context, req any, responseChan chan<- any)
 					CommunicationResponse: communicationResponse,
 				}
 				return
+			case lightning_helpers.PaymentStatusRequest:
+				responseChan <- lightning_helpers.PaymentStatusResponse{
+					Request:               r,
+					CommunicationResponse: communicationResponse,
+				}
+				return
 			case lightning_helpers.NewPaymentRequest:
 				responseChan <- lightning_helpers.NewPaymentResponse{
 					Request:               r,
@@ -568,6 +702,27 @@ func processRequestByType(ctx context.Context, req any, responseChan chan<- any)
 	case lightning_helpers.WalletBalanceRequest:
 		responseChan <- processWalletBalanceRequest(ctx, r)
 		return
+	case lightning_helpers.FeeEstimateRequest:
+		responseChan <- processFeeEstimateRequest(ctx, r)
+		return
+	case lightning_helpers.ListUnspentRequest:
+		responseChan <- processListUnspentRequest(ctx, r)
+		return
+	case lightning_helpers.UnconfirmedTransactionsRequest:
+		responseChan <- processUnconfirmedTransactionsRequest(ctx, r)
+		return
+	case lightning_helpers.LeaseOutputRequest:
+		responseChan <- processLeaseOutputRequest(ctx, r)
+		return
+	case lightning_helpers.BumpFeeRequest:
+		responseChan <- processBumpFeeRequest(ctx, r)
+		return
+	case lightning_helpers.ForceClosingChannelsRequest:
+		responseChan <- processForceClosingChannelsRequest(ctx, r)
+		return
+	case lightning_helpers.ReleaseOutputRequest:
+		responseChan <- processReleaseOutputRequest(ctx, r)
+		return
 	case lightning_helpers.ListPeersRequest:
 		responseChan <- processListPeersRequest(ctx, r)
 		return
@@ -589,6 +744,9 @@ func processRequestByType(ctx context.Context, req any, responseChan chan<- any)
 	case lightning_helpers.OnChainPaymentRequest:
 		responseChan <- processOnChainPaymentRequest(ctx, r)
 		return
+	case lightning_helpers.PaymentStatusRequest:
+		responseChan <- processPaymentStatusRequest(ctx, r)
+		return
 	case lightning_helpers.NewPaymentRequest:
 		responseChan <- processNewPaymentRequest(ctx, r)
 		return
@@ -786,14 +944,24 @@ func processOpenChannelRequest(ctx context.Context,
 
 	//If host provided - check if node is connected to peer and if not, connect peer
 	if request.NodePubKey != "" && request.Host != nil {
-		if err := checkConnectPeer(request.NodeId, request.NodePubKey, *request.Host); err != nil {
+		if err := checkConnectPeer(ctx, request.NodeId, request.NodePubKey, *request.Host); err != nil {
 			response.Error = "could not connect to peer"
 			return response
 		}
 	}
 
+	var psbtFunding *psbtChannelFunding
+	if len(request.Outpoints) != 0 {
+		psbtFunding, err = newPsbtChannelFunding(walletrpc.NewWalletKitClient(connection), request)
+		if err != nil {
+			response.Error = err.Error()
+			return response
+		}
+		openChanReq.FundingShim = psbtFunding.getFundingShim()
+	}
+
 	// Send open channel request
-	response, err = openChannelProcess(ctx, client, openChanReq, request)
+	response, err = openChannelProcess(ctx, client, openChanReq, request, psbtFunding)
 	if err != nil {
 		response.Error = err.Error()
 		return response
@@ -864,8 +1032,8 @@ func prepareOpenRequest(request lightning_helpers.OpenChannelRequest) (r *lnrpc.
 	return openChanReq, nil
 }
 
-func checkConnectPeer(nodeId int, remotePublicKey string, host string) error {
-	peerList := ListPeers(lightning_helpers.ListPeersRequest{
+func checkConnectPeer(ctx context.Context, nodeId int, remotePublicKey string, host string) error {
+	peerList := ListPeers(ctx, lightning_helpers.ListPeersRequest{
 		CommunicationRequest: lightning_helpers.CommunicationRequest{NodeId: nodeId},
 		LatestError:          false,
 	})
@@ -887,7 +1055,7 @@ func checkConnectPeer(nodeId int, remotePublicKey string, host string) error {
 		Host:                 host,
 	}
 
-	res := ConnectPeer(req)
+	res := ConnectPeer(ctx, req)
 	if res.Error != "" {
 		return errors.Wrap(errors.New(res.Error), "Connect peer")
 	}
@@ -898,7 +1066,8 @@ func checkConnectPeer(nodeId int, remotePublicKey string, host string) error {
 func openChannelProcess(ctx context.Context,
 	client lnrpc.LightningClient,
 	openChannelReq *lnrpc.OpenChannelRequest,
-	request lightning_helpers.OpenChannelRequest) (lightning_helpers.OpenChannelResponse, error) {
+	request lightning_helpers.OpenChannelRequest,
+	psbtFunding *psbtChannelFunding) (lightning_helpers.OpenChannelResponse, error) {
 
 	// Create a context with a timeout.
 	timeoutCtx, cancel := context.WithTimeout(ctx, openChannelTimeoutInSeconds*time.Second)
@@ -937,6 +1106,14 @@ func openChannelProcess(ctx context.Context,
 		}
 
 		switch resp.GetUpdate().(type) {
+		case *lnrpc.OpenStatusUpdate_PsbtFund:
+			if psbtFunding == nil {
+				return lightning_helpers.OpenChannelResponse{}, errors.New("LND requested PSBT funding without a funding shim")
+			}
+			err = psbtFunding.fund(timeoutCtx, client, resp.GetPsbtFund())
+			if err != nil {
+				return lightning_helpers.OpenChannelResponse{}, err
+			}
 		case *lnrpc.OpenStatusUpdate_ChanPending:
 			r.ChannelStatus = core.Opening
 			ch, err := chainhash.NewHash(resp.GetChanPending().Txid)
@@ -1310,6 +1487,16 @@ func processOnChainPaymentRequest(ctx context.Context,
 		return response
 	}
 
+	if len(request.Outpoints) != 0 {
+		response.TxId, err = sendCoinsFromOutpoints(ctx, walletrpc.NewWalletKitClient(connection), request)
+		if err != nil {
+			response.Error = err.Error()
+			return response
+		}
+		response.Status = lightning_helpers.Active
+		return response
+	}
+
 	resp, err := lnrpc.NewLightningClient(connection).SendCoins(ctx, sendCoinsReq)
 	if err != nil {
 		response.Error = err.Error()
@@ -1334,7 +1521,8 @@ func processSendRequest(req lightning_helpers.OnChainPaymentRequest) (*lnrpc.Sen
 		return &lnrpc.SendCoinsRequest{}, errors.New("Address must be provided")
 	}
 
-	if req.AmountSat <= 0 {
+	// LND sends the whole wallet balance (or the selected outpoints) when the amount is 0 with SendAll
+	if req.AmountSat <= 0 && (req.SendAll == nil || !*req.SendAll) {
 		log.Error().Msgf("Invalid amount")
 		return &lnrpc.SendCoinsRequest{}, errors.New("Invalid amount")
 	}
@@ -1394,6 +1582,52 @@ func processNewPaymentRequest(ctx context.Context,
 	return sendPayment(ctx, routerrpc.NewRouterClient(connection), request, response)
 }
 
+func processPaymentStatusRequest(ctx context.Context,
+	request lightning_helpers.PaymentStatusRequest) lightning_helpers.PaymentStatusResponse {
+
+	response := lightning_helpers.PaymentStatusResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	paymentHash, err := hex.DecodeString(request.PaymentHash)
+	if err != nil {
+		response.Error = "Invalid payment hash"
+		return response
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+
+	trackCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Without NoInflightUpdates the first update is the current state of the payment
+	stream, err := routerrpc.NewRouterClient(connection).TrackPaymentV2(trackCtx,
+		&routerrpc.TrackPaymentRequest{PaymentHash: paymentHash})
+	if err == nil {
+		var payment *lnrpc.Payment
+		payment, err = stream.Recv()
+		if err == nil {
+			response.PaymentStatus = payment.Status.String()
+			response.Status = lightning_helpers.Active
+			return response
+		}
+	}
+	if status.Code(err) == codes.NotFound || strings.Contains(err.Error(), "payment isn't initiated") {
+		response.PaymentStatus = lightning_helpers.PaymentStatusUnknown
+		response.Status = lightning_helpers.Active
+		return response
+	}
+	response.Error = err.Error()
+	return response
+}
+
 func newSendPaymentRequest(npReq lightning_helpers.NewPaymentRequest) (*routerrpc.SendPaymentRequest, error) {
 	newPayReq := &routerrpc.SendPaymentRequest{
 		TimeoutSeconds: npReq.TimeOutSecs,
@@ -2171,6 +2405,37 @@ func processWalletBalanceRequest(ctx context.Context,
 	return response
 }
 
+func processFeeEstimateRequest(ctx context.Context,
+	request lightning_helpers.FeeEstimateRequest) lightning_helpers.FeeEstimateResponse {
+
+	response := lightning_helpers.FeeEstimateResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+
+	rsp, err := walletrpc.NewWalletKitClient(connection).EstimateFee(ctx,
+		&walletrpc.EstimateFeeRequest{ConfTarget: request.TargetConf})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	response.Status = lightning_helpers.Active
+	// A virtual byte weighs 4 weight units
+	response.SatPerVbyte = float64(rsp.SatPerKw) * 4 / 1_000
+
+	return response
+}
+
 // processMoveFundsOffChain
 func processMoveFundsOffChain(ctx context.Context,
 	request lightning_helpers.MoveFundsOffChainRequest) lightning_helpers.MoveFundsOffChainResponse {
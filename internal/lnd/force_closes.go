@@ -0,0 +1,99 @@
+package lnd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/lncapital/torq/internal/lightning_helpers"
+	"github.com/lncapital/torq/proto/lnrpc"
+)
+
+func processForceClosingChannelsRequest(ctx context.Context,
+	request lightning_helpers.ForceClosingChannelsRequest) lightning_helpers.ForceClosingChannelsResponse {
+
+	response := lightning_helpers.ForceClosingChannelsResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+
+	rsp, err := lnrpc.NewLightningClient(connection).PendingChannels(ctx, &lnrpc.PendingChannelsRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	response.Channels = make([]lightning_helpers.ForceClosingChannel, 0,
+		len(rsp.WaitingCloseChannels)+len(rsp.PendingForceClosingChannels))
+	for _, channel := range rsp.WaitingCloseChannels {
+		if !isForceCloseTransaction(channel.ClosingTxid, channel.Commitments) {
+			continue
+		}
+		response.Channels = append(response.Channels, lightning_helpers.ForceClosingChannel{
+			ChannelPoint:    channel.Channel.GetChannelPoint(),
+			RemotePublicKey: channel.Channel.GetRemoteNodePub(),
+			ClosingTxId:     channel.ClosingTxid,
+			WaitingClose:    true,
+			LimboBalanceSat: channel.LimboBalance,
+			AnchorState:     "limbo",
+		})
+	}
+	for _, channel := range rsp.PendingForceClosingChannels {
+		response.Channels = append(response.Channels, getForceClosingChannel(channel))
+	}
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+// isForceCloseTransaction returns true when the closing transaction is one of the commitment transactions, a
+// cooperative close has its own closing transaction.
+func isForceCloseTransaction(closingTxId string, commitments *lnrpc.PendingChannelsResponse_Commitments) bool {
+	if closingTxId == "" || commitments == nil {
+		return false
+	}
+	return closingTxId == commitments.LocalTxid ||
+		closingTxId == commitments.RemoteTxid ||
+		closingTxId == commitments.RemotePendingTxid
+}
+
+func getForceClosingChannel(
+	channel *lnrpc.PendingChannelsResponse_ForceClosedChannel) lightning_helpers.ForceClosingChannel {
+
+	forceClosingChannel := lightning_helpers.ForceClosingChannel{
+		ChannelPoint:        channel.Channel.GetChannelPoint(),
+		RemotePublicKey:     channel.Channel.GetRemoteNodePub(),
+		ClosingTxId:         channel.ClosingTxid,
+		LimboBalanceSat:     channel.LimboBalance,
+		RecoveredBalanceSat: channel.RecoveredBalance,
+		AnchorState:         strings.ToLower(channel.Anchor.String()),
+		PendingHtlcs:        make([]lightning_helpers.PendingHtlc, 0, len(channel.PendingHtlcs)),
+	}
+	// Without a time locked output of our own the maturity height is 0
+	if channel.MaturityHeight != 0 {
+		maturityHeight := channel.MaturityHeight
+		blocksTilMaturity := channel.BlocksTilMaturity
+		forceClosingChannel.MaturityHeight = &maturityHeight
+		forceClosingChannel.BlocksTilMaturity = &blocksTilMaturity
+	}
+	for _, htlc := range channel.PendingHtlcs {
+		forceClosingChannel.PendingHtlcs = append(forceClosingChannel.PendingHtlcs, lightning_helpers.PendingHtlc{
+			Incoming:          htlc.Incoming,
+			AmountSat:         htlc.Amount,
+			Outpoint:          htlc.Outpoint,
+			MaturityHeight:    htlc.MaturityHeight,
+			BlocksTilMaturity: htlc.BlocksTilMaturity,
+			Stage:             htlc.Stage,
+		})
+	}
+	return forceClosingChannel
+}
@@ -0,0 +1,59 @@
+package lnd
+
+import (
+	"testing"
+
+	"github.com/lncapital/torq/proto/lnrpc"
+)
+
+func TestIsForceCloseTransaction(t *testing.T) {
+	commitments := &lnrpc.PendingChannelsResponse_Commitments{LocalTxid: "local", RemoteTxid: "remote"}
+
+	if !isForceCloseTransaction("local", commitments) {
+		t.Error("expected the local commitment to be a force close")
+	}
+	if !isForceCloseTransaction("remote", commitments) {
+		t.Error("expected the remote commitment to be a force close")
+	}
+	if isForceCloseTransaction("cooperative", commitments) {
+		t.Error("expected a cooperative close not to be a force close")
+	}
+	if isForceCloseTransaction("", &lnrpc.PendingChannelsResponse_Commitments{}) {
+		t.Error("expected an unknown closing transaction not to be a force close")
+	}
+}
+
+func TestGetForceClosingChannel(t *testing.T) {
+	channel := &lnrpc.PendingChannelsResponse_ForceClosedChannel{
+		Channel:           &lnrpc.PendingChannelsResponse_PendingChannel{ChannelPoint: "a:0", RemoteNodePub: "pub"},
+		ClosingTxid:       "closing",
+		LimboBalance:      1000,
+		MaturityHeight:    800_144,
+		BlocksTilMaturity: 144,
+		Anchor:            lnrpc.PendingChannelsResponse_ForceClosedChannel_LIMBO,
+		PendingHtlcs: []*lnrpc.PendingHTLC{
+			{Incoming: true, Amount: 500, Outpoint: "closing:2", MaturityHeight: 800_010, BlocksTilMaturity: 10, Stage: 1},
+		},
+	}
+
+	forceClosingChannel := getForceClosingChannel(channel)
+	if forceClosingChannel.ChannelPoint != "a:0" || forceClosingChannel.ClosingTxId != "closing" {
+		t.Fatalf("unexpected channel %+v", forceClosingChannel)
+	}
+	if forceClosingChannel.AnchorState != "limbo" {
+		t.Errorf("expected anchor state limbo, got %v", forceClosingChannel.AnchorState)
+	}
+	if forceClosingChannel.MaturityHeight == nil || *forceClosingChannel.MaturityHeight != 800_144 ||
+		*forceClosingChannel.BlocksTilMaturity != 144 {
+		t.Errorf("unexpected maturity %+v", forceClosingChannel)
+	}
+	if len(forceClosingChannel.PendingHtlcs) != 1 || forceClosingChannel.PendingHtlcs[0].Outpoint != "closing:2" {
+		t.Errorf("unexpected pending htlcs %+v", forceClosingChannel.PendingHtlcs)
+	}
+
+	channel.MaturityHeight = 0
+	channel.BlocksTilMaturity = 0
+	if getForceClosingChannel(channel).MaturityHeight != nil {
+		t.Error("expected no maturity without a time locked output")
+	}
+}
@@ -77,6 +77,9 @@ func storeForwardingHistory(db *sqlx.DB, fwh []*lnrpc.ForwardingEvent, nodeId in
 		if !bootStrapping {
 			for _, forwardEvent := range forwardEvents {
 				ProcessForwardEvent(forwardEvent)
+				services_helpers.GetMetrics().AddForward(nodeId,
+					forwardEvent.IncomingChannelId, forwardEvent.OutgoingChannelId,
+					forwardEvent.AmountInMsat, forwardEvent.AmountOutMsat, forwardEvent.FeeMsat)
 			}
 		}
 	}
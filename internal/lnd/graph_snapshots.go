@@ -0,0 +1,112 @@
+package lnd
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/graph_snapshots"
+	"github.com/lncapital/torq/internal/services_helpers"
+	"github.com/lncapital/torq/pkg/grpc_helpers"
+	"github.com/lncapital/torq/proto/lnrpc"
+)
+
+const graphSnapshotTickerSeconds = 6 * 60 * 60
+
+type lightningClientDescribeGraph interface {
+	DescribeGraph(ctx context.Context, in *lnrpc.ChannelGraphRequest,
+		opts ...grpc.CallOption) (*lnrpc.ChannelGraph, error)
+}
+
+// SubscribeAndStoreGraphSnapshots stores a snapshot of the full network graph every 6 hours.
+func SubscribeAndStoreGraphSnapshots(ctx context.Context,
+	client lightningClientDescribeGraph,
+	db *sqlx.DB,
+	nodeSettings cache.NodeSettingsCache) {
+
+	serviceType := services_helpers.LndServiceGraphSnapshotService
+
+	cache.SetInitializingNodeServiceState(serviceType, nodeSettings.NodeId)
+
+	ticker := time.NewTicker(graphSnapshotTickerSeconds * time.Second)
+	defer ticker.Stop()
+
+	bootStrapping := true
+	for {
+		err := storeGraphSnapshot(ctx, client, db, nodeSettings)
+		if err != nil {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				cache.SetInactiveNodeServiceState(serviceType, nodeSettings.NodeId)
+				return
+			}
+			log.Error().Err(err).Msgf("Failed to store graph snapshot for nodeId: %v", nodeSettings.NodeId)
+			cache.SetFailedNodeServiceState(serviceType, nodeSettings.NodeId)
+			return
+		}
+		if bootStrapping {
+			cache.SetActiveNodeServiceState(serviceType, nodeSettings.NodeId)
+			bootStrapping = false
+		}
+
+		select {
+		case <-ctx.Done():
+			cache.SetInactiveNodeServiceState(serviceType, nodeSettings.NodeId)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func storeGraphSnapshot(ctx context.Context,
+	client lightningClientDescribeGraph,
+	db *sqlx.DB,
+	nodeSettings cache.NodeSettingsCache) error {
+
+	ctx, span := otel.Tracer(name).Start(ctx, "storeGraphSnapshot")
+	defer span.End()
+
+	channelGraph, err := client.DescribeGraph(ctx, &lnrpc.ChannelGraphRequest{},
+		grpc.MaxCallRecvMsgSize(grpc_helpers.GraphRecvMsgSize))
+	if err != nil {
+		return errors.Wrapf(err, "describing the graph for nodeId: %v", nodeSettings.NodeId)
+	}
+
+	graph := graph_snapshots.NewGraph()
+	for _, node := range channelGraph.Nodes {
+		graph.AddNode(node.PubKey, node.Alias)
+	}
+	for _, edge := range channelGraph.Edges {
+		channelKey := strconv.FormatUint(edge.ChannelId, 10)
+		graph.AddChannelPolicy(channelKey, edge.Node1Pub, edge.Node2Pub, edge.Capacity, getGraphPolicy(edge.Node1Policy))
+		graph.AddChannelPolicy(channelKey, edge.Node2Pub, edge.Node1Pub, edge.Capacity, getGraphPolicy(edge.Node2Policy))
+	}
+
+	snapshot, err := graph_snapshots.StoreSnapshot(db, nodeSettings.NodeId, graph, time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "storing the graph snapshot for nodeId: %v", nodeSettings.NodeId)
+	}
+	span.SetAttributes(attribute.Int("nodeCount", snapshot.NodeCount),
+		attribute.Int("channelCount", snapshot.ChannelCount))
+	log.Debug().Msgf("Stored graph snapshot with %v nodes and %v channels for nodeId: %v",
+		snapshot.NodeCount, snapshot.ChannelCount, nodeSettings.NodeId)
+	return nil
+}
+
+func getGraphPolicy(policy *lnrpc.RoutingPolicy) *graph_snapshots.Policy {
+	if policy == nil {
+		return nil
+	}
+	return &graph_snapshots.Policy{
+		FeeRateMilliMsat: policy.FeeRateMilliMsat,
+		FeeBaseMsat:      policy.FeeBaseMsat,
+		Disabled:         policy.Disabled,
+	}
+}
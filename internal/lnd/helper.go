@@ -1,9 +1,22 @@
 package lnd
 
 import (
+	"context"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const name = "lnd"
+
 func convertMicro(ns int64) time.Time {
 	return time.Unix(0, ns).Round(time.Microsecond).UTC()
 }
+
+// startStreamEventSpan starts the span for the processing of a single event received from a node stream.
+func startStreamEventSpan(ctx context.Context, eventType string, nodeId int) (context.Context, trace.Span) {
+	return otel.Tracer(name).Start(ctx, eventType, trace.WithNewRoot(), trace.WithAttributes(
+		attribute.Int("nodeId", nodeId)))
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/lncapital/torq/internal/cache"
 	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/tracing"
 
 	"github.com/rs/zerolog/log"
 )
@@ -95,6 +96,13 @@ func storeFullEvent(db *sqlx.DB, h *routerrpc.HtlcEvent, nodeId int, eventType s
 	if err != nil {
 		return HtlcEvent{}, errors.Wrapf(err, "Storing HTLC Event (%v)", eventType)
 	}
+	if eventType == "LinkFailEvent" || eventType == "ForwardFailEvent" {
+		var failureCode string
+		if htlcEvent.BoltFailureCode != nil {
+			failureCode = *htlcEvent.BoltFailureCode
+		}
+		services_helpers.GetMetrics().AddHtlcFailure(nodeId, eventType, failureCode)
+	}
 	return htlcEvent, nil
 }
 
@@ -194,12 +202,13 @@ func SubscribeAndStoreHtlcEvents(ctx context.Context,
 			return
 		}
 
+		eventCtx, span := startStreamEventSpan(ctx, "htlcEvent", nodeSettings.NodeId)
 		switch htlcEvent.Event.(type) {
 		case *routerrpc.HtlcEvent_ForwardEvent:
 			_, err = storeForwardEvent(db, htlcEvent, nodeSettings.NodeId)
 			if err != nil {
 				// TODO FIXME STORE THIS SOMEWHERE??? TRANSACTION IS NOW IGNORED???
-				log.Error().Err(err).Msgf(
+				tracing.Log(eventCtx).Error().Err(err).Msgf(
 					"Failed to store forward event of type HtlcEvent_ForwardEvent for nodeId: %v",
 					nodeSettings.NodeId)
 			}
@@ -207,7 +216,7 @@ func SubscribeAndStoreHtlcEvents(ctx context.Context,
 			_, err = storeForwardFailEvent(db, htlcEvent, nodeSettings.NodeId)
 			if err != nil {
 				// TODO FIXME STORE THIS SOMEWHERE??? TRANSACTION IS NOW IGNORED???
-				log.Error().Err(err).Msgf(
+				tracing.Log(eventCtx).Error().Err(err).Msgf(
 					"Failed to store forward event of type HtlcEvent_ForwardFailEvent for nodeId: %v",
 					nodeSettings.NodeId)
 			}
@@ -215,7 +224,7 @@ func SubscribeAndStoreHtlcEvents(ctx context.Context,
 			_, err = storeLinkFailEvent(db, htlcEvent, nodeSettings.NodeId)
 			if err != nil {
 				// TODO FIXME STORE THIS SOMEWHERE??? TRANSACTION IS NOW IGNORED???
-				log.Error().Err(err).Msgf(
+				tracing.Log(eventCtx).Error().Err(err).Msgf(
 					"Failed to store forward event of type HtlcEvent_LinkFailEvent for nodeId: %v",
 					nodeSettings.NodeId)
 			}
@@ -223,10 +232,11 @@ func SubscribeAndStoreHtlcEvents(ctx context.Context,
 			_, err = storeSettleEvent(db, htlcEvent, nodeSettings.NodeId)
 			if err != nil {
 				// TODO FIXME STORE THIS SOMEWHERE??? TRANSACTION IS NOW IGNORED???
-				log.Error().Err(err).Msgf(
+				tracing.Log(eventCtx).Error().Err(err).Msgf(
 					"Failed to store forward event of type HtlcEvent_SettleEvent for nodeId: %v",
 					nodeSettings.NodeId)
 			}
 		}
+		tracing.End(span, err)
 	}
 }
@@ -303,7 +303,9 @@ func SubscribeAndStoreInvoices(ctx context.Context, client invoicesClient, db *s
 			cache.SetFailedNodeServiceState(serviceType, nodeSettings.NodeId)
 			return
 		}
+		_, span := startStreamEventSpan(ctx, "invoice", nodeSettings.NodeId)
 		processInvoice(invoice, nodeSettings, db, bootStrapping)
+		span.End()
 		cancel()
 	}
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/lncapital/torq/internal/core"
 	"github.com/lncapital/torq/internal/nodes"
 	"github.com/lncapital/torq/internal/settings"
+	"github.com/lncapital/torq/internal/tracing"
 )
 
 type peerEventsClient interface {
@@ -69,11 +70,12 @@ func SubscribePeerEvents(ctx context.Context,
 			return
 		}
 
+		eventCtx, span := startStreamEventSpan(ctx, "peerEvent", nodeSettings.NodeId)
 		eventNodeId := cache.GetPeerNodeIdByPublicKey(peerEvent.PubKey, nodeSettings.Chain, nodeSettings.Network)
 		if eventNodeId != 0 {
 			err = setNodeConnectionHistory(db, peerEvent.Type, eventNodeId, nodeSettings.NodeId)
 			if err != nil {
-				log.Error().Err(err).Msgf(
+				tracing.Log(eventCtx).Error().Err(err).Msgf(
 					"Adding node connection history entry failed for nodeId: %v (eventNodeId: %v)",
 					nodeSettings.NodeId, eventNodeId)
 			}
@@ -87,6 +89,7 @@ func SubscribePeerEvents(ctx context.Context,
 				EventNodeId: eventNodeId,
 			})
 		}
+		tracing.End(span, err)
 	}
 }
 
@@ -8,6 +8,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/lncapital/torq/internal/core"
 	"github.com/lncapital/torq/internal/services_helpers"
@@ -16,6 +17,7 @@ import (
 	"github.com/lncapital/torq/proto/lnrpc"
 
 	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/tracing"
 )
 
 type Tx struct {
@@ -127,11 +129,14 @@ func SubscribeAndStoreTransactions(ctx context.Context,
 			return
 		}
 
+		eventCtx, span := startStreamEventSpan(ctx, "blockEpoch", nodeSettings.NodeId)
+		span.SetAttributes(attribute.Int64("blockHeight", int64(blockEpoch.Height)))
 		for _, transaction := range transactionDetails.Transactions {
 			storedTx, err = storeTransaction(db, transaction, nodeSettings.NodeId)
 			if err != nil {
 				// TODO FIXME This transaction is now missing
-				log.Error().Err(err).Msg("Failed to store the transaction (transaction is now missing and can only be recovered by emptying the transactions table)")
+				span.RecordError(err)
+				tracing.Log(eventCtx).Error().Err(err).Msg("Failed to store the transaction (transaction is now missing and can only be recovered by emptying the transactions table)")
 			}
 			//if !bootStrapping {
 			//	commons.TransactionEvent{
@@ -154,6 +159,7 @@ func SubscribeAndStoreTransactions(ctx context.Context,
 				transactionHeight = *storedTx.BlockHeight
 			}
 		}
+		span.End()
 		if bootStrapping {
 			bootStrapping = false
 			cache.SetActiveNodeServiceState(serviceType, nodeSettings.NodeId)
@@ -0,0 +1,620 @@
+package lnd
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/cockroachdb/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+	"github.com/lncapital/torq/proto/lnrpc"
+	"github.com/lncapital/torq/proto/lnrpc/walletrpc"
+)
+
+// defaultFundingTargetConf is the confirmation target of a transaction funded from selected outpoints when neither
+// the fee rate nor the target is set, like SendCoins and OpenChannel of LND.
+const defaultFundingTargetConf = 6
+
+// sendAllFundingAmountSat is the output amount used to fund a send all from the selected outpoints, the change output
+// that LND adds is merged into it afterwards.
+const sendAllFundingAmountSat = 1_000
+
+// getLeaseId returns the 32 byte id of the leases taken through the API, only these leases can be released again.
+func getLeaseId() []byte {
+	id := sha256.Sum256([]byte("torq"))
+	return id[:]
+}
+
+func processListUnspentRequest(ctx context.Context,
+	request lightning_helpers.ListUnspentRequest) lightning_helpers.ListUnspentResponse {
+
+	response := lightning_helpers.ListUnspentResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+	client := walletrpc.NewWalletKitClient(connection)
+
+	// The leased outputs are not part of the unspent outputs
+	unspent, err := client.ListUnspent(ctx, &walletrpc.ListUnspentRequest{
+		MinConfs: request.MinConfs,
+		MaxConfs: math.MaxInt32,
+	})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	leases, err := client.ListLeases(ctx, &walletrpc.ListLeasesRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	response.Utxos = make([]lightning_helpers.Utxo, 0, len(unspent.Utxos)+len(leases.LockedUtxos))
+	for _, utxo := range unspent.Utxos {
+		response.Utxos = append(response.Utxos, lightning_helpers.Utxo{
+			Outpoint:      getOutpoint(utxo.Outpoint),
+			TxId:          utxo.Outpoint.GetTxidStr(),
+			OutputIndex:   utxo.Outpoint.GetOutputIndex(),
+			AmountSat:     utxo.AmountSat,
+			Address:       utxo.Address,
+			AddressType:   getAddressType(utxo.AddressType),
+			Confirmations: utxo.Confirmations,
+		})
+	}
+	for _, lease := range leases.LockedUtxos {
+		leasedUntil := time.Unix(int64(lease.Expiration), 0).UTC()
+		response.Utxos = append(response.Utxos, lightning_helpers.Utxo{
+			Outpoint:    getOutpoint(lease.Outpoint),
+			TxId:        lease.Outpoint.GetTxidStr(),
+			OutputIndex: lease.Outpoint.GetOutputIndex(),
+			AmountSat:   int64(lease.Value),
+			Leased:      true,
+			LeasedUntil: &leasedUntil,
+		})
+	}
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+func processLeaseOutputRequest(ctx context.Context,
+	request lightning_helpers.LeaseOutputRequest) lightning_helpers.LeaseOutputResponse {
+
+	response := lightning_helpers.LeaseOutputResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	outpoint, err := parseOutpoint(request.Outpoint)
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+
+	rsp, err := walletrpc.NewWalletKitClient(connection).LeaseOutput(ctx, &walletrpc.LeaseOutputRequest{
+		Id:                getLeaseId(),
+		Outpoint:          outpoint,
+		ExpirationSeconds: request.ExpirationSeconds,
+	})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	response.Expiration = time.Unix(int64(rsp.Expiration), 0).UTC()
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+func processReleaseOutputRequest(ctx context.Context,
+	request lightning_helpers.ReleaseOutputRequest) lightning_helpers.ReleaseOutputResponse {
+
+	response := lightning_helpers.ReleaseOutputResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	outpoint, err := parseOutpoint(request.Outpoint)
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+
+	_, err = walletrpc.NewWalletKitClient(connection).ReleaseOutput(ctx, &walletrpc.ReleaseOutputRequest{
+		Id:       getLeaseId(),
+		Outpoint: outpoint,
+	})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+// sendCoinsFromOutpoints funds the transaction from the selected outpoints only. FundPsbt locks just these
+// outpoints, so other transactions of the node can still use the rest of the wallet in the meantime.
+func sendCoinsFromOutpoints(ctx context.Context,
+	client walletrpc.WalletKitClient,
+	request lightning_helpers.OnChainPaymentRequest) (string, error) {
+
+	inputs, err := parseOutpoints(request.Outpoints)
+	if err != nil {
+		return "", err
+	}
+	sendAll := request.SendAll != nil && *request.SendAll
+	amount := uint64(request.AmountSat)
+	if sendAll {
+		amount = sendAllFundingAmountSat
+	}
+	funded, err := client.FundPsbt(ctx, newFundPsbtRequest(inputs, map[string]uint64{request.Address: amount},
+		request.SatPerVbyte, request.TargetConf, request.MinConfs, request.SpendUnconfirmed))
+	if err != nil {
+		return "", errors.Wrap(err, "Funding the transaction from the selected outpoints")
+	}
+	fundedPsbt := funded.FundedPsbt
+	if sendAll {
+		fundedPsbt, err = mergeChangeOutput(fundedPsbt, funded.ChangeOutputIndex)
+		if err != nil {
+			releasePsbtInputs(ctx, client, funded.LockedUtxos)
+			return "", err
+		}
+	}
+	label := ""
+	if request.Label != nil {
+		label = *request.Label
+	}
+	txId, err := publishPsbt(ctx, client, fundedPsbt, label)
+	if err != nil {
+		releasePsbtInputs(ctx, client, funded.LockedUtxos)
+		return "", err
+	}
+	return txId, nil
+}
+
+// psbtChannelFunding funds a channel open from the selected outpoints through a PSBT funding shim.
+type psbtChannelFunding struct {
+	walletKitClient walletrpc.WalletKitClient
+	pendingChanId   []byte
+	inputs          []*lnrpc.OutPoint
+	request         lightning_helpers.OpenChannelRequest
+}
+
+func newPsbtChannelFunding(client walletrpc.WalletKitClient,
+	request lightning_helpers.OpenChannelRequest) (*psbtChannelFunding, error) {
+
+	inputs, err := parseOutpoints(request.Outpoints)
+	if err != nil {
+		return nil, err
+	}
+	pendingChanId := make([]byte, sha256.Size)
+	_, err = rand.Read(pendingChanId)
+	if err != nil {
+		return nil, errors.Wrap(err, "Generating the pending channel id")
+	}
+	return &psbtChannelFunding{
+		walletKitClient: client,
+		pendingChanId:   pendingChanId,
+		inputs:          inputs,
+		request:         request,
+	}, nil
+}
+
+func (funding *psbtChannelFunding) getFundingShim() *lnrpc.FundingShim {
+	return &lnrpc.FundingShim{
+		Shim: &lnrpc.FundingShim_PsbtShim{
+			PsbtShim: &lnrpc.PsbtShim{PendingChanId: funding.pendingChanId},
+		},
+	}
+}
+
+// fund funds the channel output that LND requested from the selected outpoints and hands the signed transaction back
+// to LND, which publishes it. The funding flow is canceled when a step fails.
+func (funding *psbtChannelFunding) fund(ctx context.Context,
+	client lnrpc.LightningClient,
+	psbtFund *lnrpc.ReadyForPsbtFunding) error {
+
+	funded, err := funding.walletKitClient.FundPsbt(ctx, newFundPsbtRequest(funding.inputs,
+		map[string]uint64{psbtFund.FundingAddress: uint64(psbtFund.FundingAmount)},
+		funding.request.SatPerVbyte, funding.request.TargetConf, funding.request.MinConfs,
+		funding.request.SpendUnconfirmed))
+	if err != nil {
+		funding.cancel(ctx, client, nil)
+		return errors.Wrap(err, "Funding the channel from the selected outpoints")
+	}
+	_, err = client.FundingStateStep(ctx, &lnrpc.FundingTransitionMsg{
+		Trigger: &lnrpc.FundingTransitionMsg_PsbtVerify{
+			PsbtVerify: &lnrpc.FundingPsbtVerify{FundedPsbt: funded.FundedPsbt, PendingChanId: funding.pendingChanId},
+		},
+	})
+	if err != nil {
+		funding.cancel(ctx, client, funded.LockedUtxos)
+		return errors.Wrap(err, "Verifying the funded channel transaction")
+	}
+	finalized, err := funding.walletKitClient.FinalizePsbt(ctx, &walletrpc.FinalizePsbtRequest{
+		FundedPsbt: funded.FundedPsbt,
+	})
+	if err != nil {
+		funding.cancel(ctx, client, funded.LockedUtxos)
+		return errors.Wrap(err, "Signing the funded channel transaction")
+	}
+	_, err = client.FundingStateStep(ctx, &lnrpc.FundingTransitionMsg{
+		Trigger: &lnrpc.FundingTransitionMsg_PsbtFinalize{
+			PsbtFinalize: &lnrpc.FundingPsbtFinalize{SignedPsbt: finalized.SignedPsbt, PendingChanId: funding.pendingChanId},
+		},
+	})
+	if err != nil {
+		funding.cancel(ctx, client, funded.LockedUtxos)
+		return errors.Wrap(err, "Finalizing the funded channel transaction")
+	}
+	return nil
+}
+
+func (funding *psbtChannelFunding) cancel(ctx context.Context,
+	client lnrpc.LightningClient,
+	lockedUtxos []*walletrpc.UtxoLease) {
+
+	_, err := client.FundingStateStep(ctx, &lnrpc.FundingTransitionMsg{
+		Trigger: &lnrpc.FundingTransitionMsg_ShimCancel{
+			ShimCancel: &lnrpc.FundingShimCancel{PendingChanId: funding.pendingChanId},
+		},
+	})
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to cancel the PSBT funding of the channel to %v", funding.request.NodePubKey)
+	}
+	releasePsbtInputs(ctx, funding.walletKitClient, lockedUtxos)
+}
+
+func newFundPsbtRequest(inputs []*lnrpc.OutPoint,
+	outputs map[string]uint64,
+	satPerVbyte *uint64,
+	targetConf *int32,
+	minConfs *int32,
+	spendUnconfirmed *bool) *walletrpc.FundPsbtRequest {
+
+	fundPsbtRequest := &walletrpc.FundPsbtRequest{
+		Template: &walletrpc.FundPsbtRequest_Raw{
+			Raw: &walletrpc.TxTemplate{Inputs: inputs, Outputs: outputs},
+		},
+		Fees: &walletrpc.FundPsbtRequest_TargetConf{TargetConf: defaultFundingTargetConf},
+	}
+	if satPerVbyte != nil {
+		fundPsbtRequest.Fees = &walletrpc.FundPsbtRequest_SatPerVbyte{SatPerVbyte: *satPerVbyte}
+	} else if targetConf != nil {
+		fundPsbtRequest.Fees = &walletrpc.FundPsbtRequest_TargetConf{TargetConf: uint32(*targetConf)}
+	}
+	if minConfs != nil {
+		fundPsbtRequest.MinConfs = *minConfs
+	}
+	if spendUnconfirmed != nil {
+		fundPsbtRequest.SpendUnconfirmed = *spendUnconfirmed
+	}
+	return fundPsbtRequest
+}
+
+// mergeChangeOutput adds the change output to the only other output, so a send all spends the selected outpoints
+// completely. The fee stays the same, which makes the fee rate slightly higher without the change output.
+func mergeChangeOutput(fundedPsbt []byte, changeOutputIndex int32) ([]byte, error) {
+	if changeOutputIndex < 0 {
+		return fundedPsbt, nil
+	}
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(fundedPsbt), false)
+	if err != nil {
+		return nil, errors.Wrap(err, "Parsing the funded transaction")
+	}
+	if len(packet.UnsignedTx.TxOut) != 2 || int(changeOutputIndex) >= len(packet.UnsignedTx.TxOut) {
+		return nil, errors.Newf("Expected a destination and a change output, got %v outputs",
+			len(packet.UnsignedTx.TxOut))
+	}
+	change := packet.UnsignedTx.TxOut[changeOutputIndex]
+	packet.UnsignedTx.TxOut = append(packet.UnsignedTx.TxOut[:changeOutputIndex],
+		packet.UnsignedTx.TxOut[changeOutputIndex+1:]...)
+	packet.Outputs = append(packet.Outputs[:changeOutputIndex], packet.Outputs[changeOutputIndex+1:]...)
+	packet.UnsignedTx.TxOut[0].Value += change.Value
+	var merged bytes.Buffer
+	err = packet.Serialize(&merged)
+	if err != nil {
+		return nil, errors.Wrap(err, "Serializing the funded transaction")
+	}
+	return merged.Bytes(), nil
+}
+
+// publishPsbt signs the funded transaction and publishes it.
+func publishPsbt(ctx context.Context,
+	client walletrpc.WalletKitClient,
+	fundedPsbt []byte,
+	label string) (string, error) {
+
+	finalized, err := client.FinalizePsbt(ctx, &walletrpc.FinalizePsbtRequest{FundedPsbt: fundedPsbt})
+	if err != nil {
+		return "", errors.Wrap(err, "Signing the funded transaction")
+	}
+	var tx wire.MsgTx
+	err = tx.Deserialize(bytes.NewReader(finalized.RawFinalTx))
+	if err != nil {
+		return "", errors.Wrap(err, "Parsing the signed transaction")
+	}
+	published, err := client.PublishTransaction(ctx, &walletrpc.Transaction{
+		TxHex: finalized.RawFinalTx,
+		Label: label,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "Publishing the transaction")
+	}
+	if published.PublishError != "" {
+		return "", errors.Newf("Publishing the transaction: %v", published.PublishError)
+	}
+	return tx.TxHash().String(), nil
+}
+
+// releasePsbtInputs releases the outpoints that FundPsbt locked for a transaction that isn't published.
+func releasePsbtInputs(ctx context.Context, client walletrpc.WalletKitClient, lockedUtxos []*walletrpc.UtxoLease) {
+	for _, lockedUtxo := range lockedUtxos {
+		_, err := client.ReleaseOutput(ctx, &walletrpc.ReleaseOutputRequest{
+			Id:       lockedUtxo.Id,
+			Outpoint: lockedUtxo.Outpoint,
+		})
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to release output %v, it will be released when the lock expires",
+				getOutpoint(lockedUtxo.Outpoint))
+		}
+	}
+}
+
+func parseOutpoints(outpoints []string) ([]*lnrpc.OutPoint, error) {
+	inputs := make([]*lnrpc.OutPoint, 0, len(outpoints))
+	for _, outpoint := range outpoints {
+		input, err := parseOutpoint(outpoint)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+func parseOutpoint(outpoint string) (*lnrpc.OutPoint, error) {
+	txId, outputIndex := core.ParseChannelPoint(outpoint)
+	if txId == nil || outputIndex == nil || *outputIndex < 0 {
+		return nil, errors.Newf("Invalid outpoint %v, use txid:index", outpoint)
+	}
+	return &lnrpc.OutPoint{TxidStr: *txId, OutputIndex: uint32(*outputIndex)}, nil
+}
+
+func getOutpoint(outpoint *lnrpc.OutPoint) string {
+	return core.CreateChannelPoint(outpoint.GetTxidStr(), int(outpoint.GetOutputIndex()))
+}
+
+func getAddressType(addressType lnrpc.AddressType) string {
+	switch addressType {
+	case lnrpc.AddressType_WITNESS_PUBKEY_HASH, lnrpc.AddressType_UNUSED_WITNESS_PUBKEY_HASH:
+		return "p2wkh"
+	case lnrpc.AddressType_NESTED_PUBKEY_HASH, lnrpc.AddressType_UNUSED_NESTED_PUBKEY_HASH:
+		return "np2wkh"
+	case lnrpc.AddressType_TAPROOT_PUBKEY, lnrpc.AddressType_UNUSED_TAPROOT_PUBKEY:
+		return "p2tr"
+	}
+	return core.UnknownEnumString
+}
+
+func processUnconfirmedTransactionsRequest(ctx context.Context,
+	request lightning_helpers.UnconfirmedTransactionsRequest) lightning_helpers.UnconfirmedTransactionsResponse {
+
+	response := lightning_helpers.UnconfirmedTransactionsResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+
+	transactions, err := getUnconfirmedTransactions(ctx, lnrpc.NewLightningClient(connection))
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	response.Transactions = make([]lightning_helpers.WalletTransaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		walletTransaction := lightning_helpers.WalletTransaction{
+			TxId:     transaction.TxHash,
+			Inputs:   make([]string, 0, len(transaction.PreviousOutpoints)),
+			Incoming: true,
+		}
+		for _, previousOutpoint := range transaction.PreviousOutpoints {
+			walletTransaction.Inputs = append(walletTransaction.Inputs, previousOutpoint.Outpoint)
+			if previousOutpoint.IsOurOutput {
+				walletTransaction.Incoming = false
+			}
+		}
+		response.Transactions = append(response.Transactions, walletTransaction)
+	}
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+// getUnconfirmedTransactions only reads the transactions after the current block, with an end height of -1 LND adds
+// the unconfirmed transactions.
+func getUnconfirmedTransactions(ctx context.Context, client lnrpc.LightningClient) ([]*lnrpc.Transaction, error) {
+	transactions, err := client.GetTransactions(ctx, &lnrpc.GetTransactionsRequest{
+		StartHeight: int32(cache.GetBlockHeight()) + 1,
+		EndHeight:   -1,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Listing unconfirmed transactions")
+	}
+	var unconfirmed []*lnrpc.Transaction
+	for _, transaction := range transactions.Transactions {
+		if transaction.NumConfirmations == 0 {
+			unconfirmed = append(unconfirmed, transaction)
+		}
+	}
+	return unconfirmed, nil
+}
+
+// processBumpFeeRequest bumps the fee through the sweeper of LND. An output of the transaction that is already being
+// swept, like the anchor of a force close, is preferred. LND 0.18 and later replace the transaction (RBF) when one of
+// its inputs belongs to the node, otherwise an unconfirmed wallet output is spent by a child transaction (CPFP).
+func processBumpFeeRequest(ctx context.Context,
+	request lightning_helpers.BumpFeeRequest) lightning_helpers.BumpFeeResponse {
+
+	response := lightning_helpers.BumpFeeResponse{
+		CommunicationResponse: lightning_helpers.CommunicationResponse{
+			Status: lightning_helpers.Inactive,
+		},
+		Request: request,
+	}
+
+	if (request.TargetConf == nil) == (request.SatPerVbyte == nil) {
+		response.Error = "either targetConf or satPerVbyte is required"
+		return response
+	}
+
+	connection, err := getConnection(request.NodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain a GRPC connection.")
+		response.Error = err.Error()
+		return response
+	}
+	client := walletrpc.NewWalletKitClient(connection)
+	lightningClient := lnrpc.NewLightningClient(connection)
+
+	sweeps, err := client.PendingSweeps(ctx, &walletrpc.PendingSweepsRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	info, err := lightningClient.GetInfo(ctx, &lnrpc.GetInfoRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	var transactions []*lnrpc.Transaction
+	if supportsReplaceByFee(info.Version) {
+		transactions, err = getUnconfirmedTransactions(ctx, lightningClient)
+		if err != nil {
+			response.Error = err.Error()
+			return response
+		}
+	}
+	// Without confirmations only the unconfirmed outputs are listed
+	unspent, err := client.ListUnspent(ctx, &walletrpc.ListUnspentRequest{})
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	outpoint, replaced := getBumpFeeOutpoint(request.TxId, sweeps.PendingSweeps, transactions, unspent.Utxos)
+	if outpoint == nil {
+		response.Error = "the transaction has no unconfirmed output or input of the node to bump the fee with"
+		return response
+	}
+
+	bumpFeeRequest := &walletrpc.BumpFeeRequest{
+		Outpoint: outpoint,
+		Force:    request.Force,
+	}
+	if request.TargetConf != nil {
+		bumpFeeRequest.TargetConf = uint32(*request.TargetConf)
+	}
+	if request.SatPerVbyte != nil {
+		bumpFeeRequest.SatPerVbyte = *request.SatPerVbyte
+	}
+	_, err = client.BumpFee(ctx, bumpFeeRequest)
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	response.Outpoint = getOutpoint(outpoint)
+	response.Replaced = replaced
+	response.Status = lightning_helpers.Active
+	return response
+}
+
+// getBumpFeeOutpoint returns the outpoint to bump the transaction with and whether the transaction is replaced. An
+// output that is already being swept comes first, then an input of the node when the transactions of a node that
+// supports replace by fee are given and last an unconfirmed wallet output.
+func getBumpFeeOutpoint(txId string,
+	sweeps []*walletrpc.PendingSweep,
+	transactions []*lnrpc.Transaction,
+	utxos []*lnrpc.Utxo) (*lnrpc.OutPoint, bool) {
+
+	for _, sweep := range sweeps {
+		if sweep.Outpoint.GetTxidStr() == txId {
+			return sweep.Outpoint, false
+		}
+	}
+	for _, transaction := range transactions {
+		if transaction.TxHash != txId {
+			continue
+		}
+		for _, previousOutpoint := range transaction.PreviousOutpoints {
+			if !previousOutpoint.IsOurOutput {
+				continue
+			}
+			outpoint, err := parseOutpoint(previousOutpoint.Outpoint)
+			if err == nil {
+				return outpoint, true
+			}
+		}
+	}
+	for _, utxo := range utxos {
+		if utxo.Outpoint.GetTxidStr() == txId && utxo.Confirmations == 0 {
+			return utxo.Outpoint, false
+		}
+	}
+	return nil, false
+}
+
+// supportsReplaceByFee is true from LND 0.18, its sweeper replaces the transaction when BumpFee is called with one of
+// the inputs of the transaction.
+func supportsReplaceByFee(version string) bool {
+	var major, minor int
+	_, err := fmt.Sscanf(version, "%d.%d", &major, &minor)
+	if err != nil {
+		return false
+	}
+	return major > 0 || minor >= 18
+}
@@ -0,0 +1,136 @@
+package lnd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/lncapital/torq/proto/lnrpc"
+	"github.com/lncapital/torq/proto/lnrpc/walletrpc"
+)
+
+func TestNewFundPsbtRequest(t *testing.T) {
+	inputs := []*lnrpc.OutPoint{{TxidStr: "a", OutputIndex: 1}}
+	outputs := map[string]uint64{"address": 10_000}
+
+	request := newFundPsbtRequest(inputs, outputs, nil, nil, nil, nil)
+	if request.GetRaw().GetInputs()[0] != inputs[0] || request.GetRaw().GetOutputs()["address"] != 10_000 {
+		t.Errorf("expected the selected inputs and the outputs in the template, got %v", request.GetRaw())
+	}
+	if request.GetTargetConf() != defaultFundingTargetConf {
+		t.Errorf("expected the default target of %v blocks, got %v", defaultFundingTargetConf, request.GetTargetConf())
+	}
+
+	satPerVbyte := uint64(12)
+	targetConf := int32(3)
+	request = newFundPsbtRequest(inputs, outputs, &satPerVbyte, &targetConf, nil, nil)
+	if request.GetSatPerVbyte() != satPerVbyte || request.GetTargetConf() != 0 {
+		t.Errorf("expected the fee rate to take precedence, got %v", request.GetFees())
+	}
+
+	minConfs := int32(0)
+	spendUnconfirmed := true
+	request = newFundPsbtRequest(inputs, outputs, nil, &targetConf, &minConfs, &spendUnconfirmed)
+	if request.GetTargetConf() != 3 || request.MinConfs != 0 || !request.SpendUnconfirmed {
+		t.Errorf("unexpected fund request %v", request)
+	}
+}
+
+func TestMergeChangeOutput(t *testing.T) {
+	packet, err := psbt.New([]*wire.OutPoint{{Index: 0}, {Index: 1}},
+		[]*wire.TxOut{{Value: 25_000, PkScript: []byte{0x51}}, {Value: sendAllFundingAmountSat, PkScript: []byte{0x52}}},
+		2, 0, []uint32{wire.MaxTxInSequenceNum, wire.MaxTxInSequenceNum})
+	if err != nil {
+		t.Fatalf("creating the psbt: %v", err)
+	}
+	var funded bytes.Buffer
+	err = packet.Serialize(&funded)
+	if err != nil {
+		t.Fatalf("serializing the psbt: %v", err)
+	}
+
+	merged, err := mergeChangeOutput(funded.Bytes(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	mergedPacket, err := psbt.NewFromRawBytes(bytes.NewReader(merged), false)
+	if err != nil {
+		t.Fatalf("parsing the merged psbt: %v", err)
+	}
+	if len(mergedPacket.UnsignedTx.TxOut) != 1 || len(mergedPacket.Outputs) != 1 {
+		t.Fatalf("expected only the destination output, got %v", mergedPacket.UnsignedTx.TxOut)
+	}
+	output := mergedPacket.UnsignedTx.TxOut[0]
+	if output.Value != 25_000+sendAllFundingAmountSat || !bytes.Equal(output.PkScript, []byte{0x52}) {
+		t.Errorf("expected the change merged into the destination, got %v sat to %x", output.Value, output.PkScript)
+	}
+
+	unchanged, err := mergeChangeOutput(funded.Bytes(), -1)
+	if err != nil || !bytes.Equal(unchanged, funded.Bytes()) {
+		t.Errorf("expected the psbt without change output to stay the same")
+	}
+}
+
+func TestGetBumpFeeOutpoint(t *testing.T) {
+	sweeps := []*walletrpc.PendingSweep{
+		{Outpoint: &lnrpc.OutPoint{TxidStr: "close", OutputIndex: 3}},
+	}
+	transactions := []*lnrpc.Transaction{
+		{TxHash: "send", PreviousOutpoints: []*lnrpc.PreviousOutPoint{
+			{Outpoint: "funding:0", IsOurOutput: false},
+			{Outpoint: "wallet:2", IsOurOutput: true},
+		}},
+		{TxHash: "remote", PreviousOutpoints: []*lnrpc.PreviousOutPoint{{Outpoint: "funding:1"}}},
+	}
+	utxos := []*lnrpc.Utxo{
+		{Outpoint: &lnrpc.OutPoint{TxidStr: "close", OutputIndex: 1}},
+		{Outpoint: &lnrpc.OutPoint{TxidStr: "send", OutputIndex: 1}},
+		{Outpoint: &lnrpc.OutPoint{TxidStr: "remote", OutputIndex: 0}},
+		{Outpoint: &lnrpc.OutPoint{TxidStr: "confirmed", OutputIndex: 0}, Confirmations: 2},
+	}
+
+	tests := []struct {
+		txId         string
+		transactions []*lnrpc.Transaction
+		expected     string
+		replaced     bool
+	}{
+		{"close", transactions, "close:3", false},
+		{"send", nil, "send:1", false},
+		{"send", transactions, "wallet:2", true},
+		{"remote", transactions, "remote:0", false},
+		{"confirmed", transactions, "", false},
+		{"unknown", transactions, "", false},
+	}
+	for _, test := range tests {
+		outpoint, replaced := getBumpFeeOutpoint(test.txId, sweeps, test.transactions, utxos)
+		if outpoint == nil {
+			if test.expected != "" {
+				t.Errorf("%v: expected %v, got no outpoint", test.txId, test.expected)
+			}
+			continue
+		}
+		if getOutpoint(outpoint) != test.expected || replaced != test.replaced {
+			t.Errorf("%v: expected %v (replaced %v), got %v (replaced %v)",
+				test.txId, test.expected, test.replaced, getOutpoint(outpoint), replaced)
+		}
+	}
+}
+
+func TestSupportsReplaceByFee(t *testing.T) {
+	versions := map[string]bool{
+		"0.17.4-beta commit=v0.17.4-beta":   false,
+		"0.18.0-beta commit=v0.18.0-beta":   true,
+		"0.18.3-beta.rc1 commit=v0.18.3-rc": true,
+		"0.19.1-beta":                       true,
+		"1.0.0":                             true,
+		"":                                  false,
+	}
+	for version, expected := range versions {
+		if supportsReplaceByFee(version) != expected {
+			t.Errorf("supportsReplaceByFee(%q) = %v, want %v", version, !expected, expected)
+		}
+	}
+}
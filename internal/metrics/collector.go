@@ -0,0 +1,208 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+	"github.com/lncapital/torq/internal/services_helpers"
+)
+
+const walletBalanceTimeoutSeconds = 5
+
+type walletBalanceResult struct {
+	nodeId        int
+	walletBalance lightning_helpers.WalletBalanceResponse
+	err           error
+}
+
+// Collector exposes the state of the nodes from the cache every time Prometheus scrapes.
+type Collector struct {
+	channelLabels services_helpers.ChannelLabels
+
+	channelLocalBalance     *prometheus.Desc
+	channelRemoteBalance    *prometheus.Desc
+	channelCapacity         *prometheus.Desc
+	channelFeeRate          *prometheus.Desc
+	channelFeeBase          *prometheus.Desc
+	channelMinHtlc          *prometheus.Desc
+	channelMaxHtlc          *prometheus.Desc
+	channelTimeLockDelta    *prometheus.Desc
+	channelDisabled         *prometheus.Desc
+	channelPendingHtlcCount *prometheus.Desc
+	pendingChannels         *prometheus.Desc
+	walletBalance           *prometheus.Desc
+}
+
+func NewCollector(channelLabels services_helpers.ChannelLabels) *Collector {
+	metrics := services_helpers.Metrics{ChannelLabels: channelLabels}
+	channelLabelNames := metrics.ChannelLabelNames()
+	channelDesc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("torq", "channel", name), help, channelLabelNames, nil)
+	}
+	return &Collector{
+		channelLabels:           channelLabels,
+		channelLocalBalance:     channelDesc("local_balance_sat", "Local balance of the open channels"),
+		channelRemoteBalance:    channelDesc("remote_balance_sat", "Remote balance of the open channels"),
+		channelCapacity:         channelDesc("capacity_sat", "Capacity of the open channels"),
+		channelFeeRate:          channelDesc("fee_rate_ppm", "Local fee rate of the channel"),
+		channelFeeBase:          channelDesc("fee_base_msat", "Local base fee of the channel"),
+		channelMinHtlc:          channelDesc("min_htlc_msat", "Local minimum HTLC of the channel"),
+		channelMaxHtlc:          channelDesc("max_htlc_msat", "Local maximum HTLC of the channel"),
+		channelTimeLockDelta:    channelDesc("time_lock_delta", "Local time lock delta of the channel"),
+		channelDisabled:         channelDesc("disabled", "1 when the channel is disabled locally"),
+		channelPendingHtlcCount: channelDesc("pending_htlcs", "Pending HTLCs of the open channels"),
+		pendingChannels: prometheus.NewDesc("torq_pending_channels", "Channels that are opening or closing",
+			[]string{"nodeId", "status"}, nil),
+		walletBalance: prometheus.NewDesc("torq_wallet_balance_sat", "On-chain wallet balance",
+			[]string{"nodeId", "type"}, nil),
+	}
+}
+
+func (collector *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.channelLocalBalance
+	ch <- collector.channelRemoteBalance
+	ch <- collector.channelCapacity
+	ch <- collector.channelPendingHtlcCount
+	if collector.channelLabels != services_helpers.ChannelLabelsNone {
+		ch <- collector.channelFeeRate
+		ch <- collector.channelFeeBase
+		ch <- collector.channelMinHtlc
+		ch <- collector.channelMaxHtlc
+		ch <- collector.channelTimeLockDelta
+		ch <- collector.channelDisabled
+	}
+	ch <- collector.pendingChannels
+	ch <- collector.walletBalance
+}
+
+func (collector *Collector) Collect(ch chan<- prometheus.Metric) {
+	metrics := services_helpers.Metrics{ChannelLabels: collector.channelLabels}
+	metrics.SetChannelLabelResolver(ResolveChannelLabels)
+
+	// The wallet balances are requested from all nodes at once with one timeout for the whole scrape,
+	// so a slow node can't push the scrape past the Prometheus scrape timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), walletBalanceTimeoutSeconds*time.Second)
+	defer cancel()
+	torqNodes := cache.GetActiveTorqNodeSettings()
+	walletBalances := make(chan walletBalanceResult, len(torqNodes))
+	for _, torqNode := range torqNodes {
+		go func(nodeId int) {
+			walletBalance, err := lightning.GetWalletBalance(ctx, nodeId)
+			walletBalances <- walletBalanceResult{nodeId: nodeId, walletBalance: walletBalance, err: err}
+		}(torqNode.NodeId)
+	}
+
+	for _, torqNode := range torqNodes {
+		collector.collectChannels(ch, &metrics, torqNode.NodeId)
+		collector.collectPendingChannels(ch, torqNode.NodeId)
+	}
+
+	for range torqNodes {
+		select {
+		case <-ctx.Done():
+			log.Debug().Msg("Skipping the wallet balance metrics of the nodes that did not respond in time")
+			return
+		case result := <-walletBalances:
+			collector.collectWalletBalance(ch, result)
+		}
+	}
+}
+
+func (collector *Collector) collectChannels(ch chan<- prometheus.Metric, metrics *services_helpers.Metrics, nodeId int) {
+	// Force the response because a scrape should not wait for the channel balance stream
+	channelStates := cache.GetChannelStates(nodeId, true)
+	if collector.channelLabels == services_helpers.ChannelLabelsNone {
+		var local, remote, capacity, pendingHtlcs float64
+		for _, channelState := range channelStates {
+			local += float64(channelState.LocalBalance)
+			remote += float64(channelState.RemoteBalance)
+			capacity += float64(cache.GetChannelSettingByChannelId(channelState.ChannelId).Capacity)
+			pendingHtlcs += float64(channelState.PendingIncomingHtlcCount + channelState.PendingOutgoingHtlcCount)
+		}
+		labels := metrics.ChannelLabelValues(nodeId, 0)
+		ch <- prometheus.MustNewConstMetric(collector.channelLocalBalance, prometheus.GaugeValue, local, labels...)
+		ch <- prometheus.MustNewConstMetric(collector.channelRemoteBalance, prometheus.GaugeValue, remote, labels...)
+		ch <- prometheus.MustNewConstMetric(collector.channelCapacity, prometheus.GaugeValue, capacity, labels...)
+		ch <- prometheus.MustNewConstMetric(collector.channelPendingHtlcCount, prometheus.GaugeValue, pendingHtlcs, labels...)
+		return
+	}
+
+	for _, channelState := range channelStates {
+		labels := metrics.ChannelLabelValues(nodeId, channelState.ChannelId)
+		capacity := cache.GetChannelSettingByChannelId(channelState.ChannelId).Capacity
+		pendingHtlcs := channelState.PendingIncomingHtlcCount + channelState.PendingOutgoingHtlcCount
+		disabled := 0.0
+		if channelState.LocalDisabled {
+			disabled = 1
+		}
+		for desc, value := range map[*prometheus.Desc]float64{
+			collector.channelLocalBalance:     float64(channelState.LocalBalance),
+			collector.channelRemoteBalance:    float64(channelState.RemoteBalance),
+			collector.channelCapacity:         float64(capacity),
+			collector.channelPendingHtlcCount: float64(pendingHtlcs),
+			collector.channelFeeRate:          float64(channelState.LocalFeeRateMilliMsat),
+			collector.channelFeeBase:          float64(channelState.LocalFeeBaseMsat),
+			collector.channelMinHtlc:          float64(channelState.LocalMinHtlcMsat),
+			collector.channelMaxHtlc:          float64(channelState.LocalMaxHtlcMsat),
+			collector.channelTimeLockDelta:    float64(channelState.LocalTimeLockDelta),
+			collector.channelDisabled:         disabled,
+		} {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+		}
+	}
+}
+
+func (collector *Collector) collectPendingChannels(ch chan<- prometheus.Metric, nodeId int) {
+	var opening, closing float64
+	for _, channelSettings := range cache.GetChannelSettingsByNodeId(nodeId) {
+		switch channelSettings.Status {
+		case core.Opening:
+			opening++
+		case core.Closing:
+			closing++
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(collector.pendingChannels, prometheus.GaugeValue, opening,
+		strconv.Itoa(nodeId), "opening")
+	ch <- prometheus.MustNewConstMetric(collector.pendingChannels, prometheus.GaugeValue, closing,
+		strconv.Itoa(nodeId), "closing")
+}
+
+func (collector *Collector) collectWalletBalance(ch chan<- prometheus.Metric, result walletBalanceResult) {
+	if result.err != nil {
+		log.Debug().Err(result.err).Msgf("Skipping the wallet balance metrics for nodeId: %v", result.nodeId)
+		return
+	}
+	walletBalance := result.walletBalance
+	for balanceType, value := range map[string]int64{
+		"confirmed":   walletBalance.ConfirmedBalance,
+		"unconfirmed": walletBalance.UnconfirmedBalance,
+		"locked":      walletBalance.LockedBalance,
+		"reserved":    walletBalance.ReservedBalanceAnchorChan,
+	} {
+		ch <- prometheus.MustNewConstMetric(collector.walletBalance, prometheus.GaugeValue, float64(value),
+			strconv.Itoa(result.nodeId), balanceType)
+	}
+}
+
+// ResolveChannelLabels is the services_helpers.ChannelLabelResolver backed by the cache
+func ResolveChannelLabels(nodeId int, channelId int) (string, string) {
+	channelSettings := cache.GetChannelSettingByChannelId(channelId)
+	var shortChannelId string
+	if channelSettings.ShortChannelId != nil {
+		shortChannelId = *channelSettings.ShortChannelId
+	}
+	peerNodeId := channelSettings.FirstNodeId
+	if peerNodeId == nodeId {
+		peerNodeId = channelSettings.SecondNodeId
+	}
+	return shortChannelId, cache.GetNodeAlias(peerNodeId)
+}
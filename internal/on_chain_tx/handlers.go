@@ -7,6 +7,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
 
 	ah "github.com/lncapital/torq/internal/api_helpers"
 	"github.com/lncapital/torq/internal/cache"
@@ -17,47 +18,10 @@ import (
 
 func getOnChainTxsHandler(c *gin.Context, db *sqlx.DB) {
 
-	// Filter parser with whitelisted columns
-	var filter sq.Sqlizer
-	filterParam := c.Query("filter")
-	var err error
-	if filterParam != "" {
-		filter, err = qp.ParseFilterParam(filterParam, []string{
-			"date",
-			"dest_addresses",
-			"dest_addresses_count",
-			"amount",
-			"total_fees",
-			"label",
-			"lnd_tx_type_label",
-			"lnd_short_chan_id",
-		})
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
-			return
-		}
-	}
-
-	var sort []string
-	sortParam := c.Query("order")
-	if sortParam != "" {
-		// Order parser with whitelisted columns
-		sort, err = qp.ParseOrderParams(
-			sortParam,
-			[]string{
-				"date",
-				"dest_addresses",
-				"dest_addresses_count",
-				"amount",
-				"total_fees",
-				"label",
-				"lnd_tx_type_label",
-				"lnd_short_chan_id",
-			})
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
-			return
-		}
+	filter, sort, err := parseOnChainTxsFilterAndSort(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
 	}
 
 	var limit uint64
@@ -109,3 +73,97 @@ func getOnChainTxsHandler(c *gin.Context, db *sqlx.DB) {
 			Offset: offset,
 		}})
 }
+
+func getOnChainTxsExportHandler(c *gin.Context, db *sqlx.DB) {
+	format, err := ah.GetExportFormat(c.Query("format"))
+	if err != nil {
+		server_errors.SendBadRequest(c, err.Error())
+		return
+	}
+
+	filter, sort, err := parseOnChainTxsFilterAndSort(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+
+	chain := core.Bitcoin
+
+	nodeIds := cache.GetAllTorqNodeIdsByNetwork(chain, core.Network(network))
+
+	rows, err := queryOnChainTxs(db, nodeIds, filter, sort, 0, 0, true)
+	if err != nil {
+		server_errors.LogAndSendServerError(c, err)
+		return
+	}
+	defer rows.Close()
+
+	exporter := ah.NewExporter(c, format, "on-chain-transactions")
+	err = exporter.WriteRows(rows)
+	if err != nil {
+		log.Error().Err(err).Msg("Exporting on-chain transactions")
+		exporter.Flush()
+		return
+	}
+
+	rows, err = queryOnChainTxs(db, nodeIds, filter, sort, 0, 0, false)
+	if err != nil {
+		log.Error().Err(err).Msg("Exporting on-chain transactions")
+		exporter.Flush()
+		return
+	}
+	defer rows.Close()
+
+	err = exporter.WriteRows(rows)
+	if err != nil {
+		log.Error().Err(err).Msg("Exporting on-chain transactions")
+	}
+	exporter.Flush()
+}
+
+func parseOnChainTxsFilterAndSort(c *gin.Context) (filter sq.Sqlizer, sort []string, err error) {
+	// Filter parser with whitelisted columns
+	filterParam := c.Query("filter")
+	if filterParam != "" {
+		filter, err = qp.ParseFilterParam(filterParam, []string{
+			"date",
+			"dest_addresses",
+			"dest_addresses_count",
+			"amount",
+			"total_fees",
+			"label",
+			"lnd_tx_type_label",
+			"lnd_short_chan_id",
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	sortParam := c.Query("order")
+	if sortParam != "" {
+		// Order parser with whitelisted columns
+		sort, err = qp.ParseOrderParams(
+			sortParam,
+			[]string{
+				"date",
+				"dest_addresses",
+				"dest_addresses_count",
+				"amount",
+				"total_fees",
+				"label",
+				"lnd_tx_type_label",
+				"lnd_short_chan_id",
+			})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return filter, sort, nil
+}
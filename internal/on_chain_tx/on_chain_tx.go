@@ -65,16 +65,9 @@ func getOnChainTxs(db *sqlx.DB,
 }
 
 func runQuery(db *sqlx.DB, nodeIds []int, filter sq.Sqlizer, order []string, limit uint64, offset uint64, r []*Transaction, withLabel bool) ([]*Transaction, error) {
-	qb := getQuery(nodeIds, filter, order, limit, offset, withLabel)
-	// Compile the query
-	qs, args, err := qb.ToSql()
+	rows, err := queryOnChainTxs(db, nodeIds, filter, order, limit, offset, withLabel)
 	if err != nil {
-		return nil, errors.Wrap(err, "SQL compile statement")
-	}
-
-	rows, err := db.Queryx(qs, args...)
-	if err != nil {
-		return nil, errors.Wrap(err, "Run SQL Query")
+		return nil, err
 	}
 	defer rows.Close()
 	for rows.Next() {
@@ -100,6 +93,22 @@ func runQuery(db *sqlx.DB, nodeIds []int, filter sq.Sqlizer, order []string, lim
 	return r, nil
 }
 
+// queryOnChainTxs returns the open result set so it can be streamed (export) or scanned (table)
+func queryOnChainTxs(db *sqlx.DB, nodeIds []int, filter sq.Sqlizer, order []string, limit uint64, offset uint64, withLabel bool) (*sqlx.Rows, error) {
+	qb := getQuery(nodeIds, filter, order, limit, offset, withLabel)
+	// Compile the query
+	qs, args, err := qb.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "SQL compile statement")
+	}
+
+	rows, err := db.Queryx(qs, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Run SQL Query")
+	}
+	return rows, nil
+}
+
 func getQuery(nodeIds []int, filter sq.Sqlizer, order []string, limit uint64, offset uint64, withLabel bool) sq.SelectBuilder {
 	//language=PostgreSQL
 	var qb sq.SelectBuilder
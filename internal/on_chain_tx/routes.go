@@ -7,4 +7,5 @@ import (
 
 func RegisterOnChainTxsRoutes(r *gin.RouterGroup, db *sqlx.DB) {
 	r.GET("", func(c *gin.Context) { getOnChainTxsHandler(c, db) })
+	r.GET("export", func(c *gin.Context) { getOnChainTxsExportHandler(c, db) })
 }
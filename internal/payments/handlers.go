@@ -7,6 +7,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
 
 	ah "github.com/lncapital/torq/internal/api_helpers"
 	"github.com/lncapital/torq/internal/cache"
@@ -17,54 +18,10 @@ import (
 
 func getPaymentsHandler(c *gin.Context, db *sqlx.DB) {
 
-	// Filter parser with whitelisted columns
-	var filter sq.Sqlizer
-	filterParam := c.Query("filter")
-	var err error
-	if filterParam != "" {
-		filter, err = qp.ParseFilterParam(filterParam, []string{
-			"date",
-			"destination_pub_key",
-			"status",
-			"value",
-			"fee",
-			"ppm",
-			"failure_reason",
-			"is_rebalance",
-			"is_mpp",
-			"count_successful_attempts",
-			"count_failed_attempts",
-			"seconds_in_flight",
-			"payment_hash",
-			"payment_preimage",
-		})
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
-			return
-		}
-	}
-
-	var sort []string
-	sortParam := c.Query("order")
-	if sortParam != "" {
-		// Order parser with whitelisted columns
-		sort, err = qp.ParseOrderParams(
-			sortParam,
-			[]string{
-				"date",
-				"status",
-				"value",
-				"fee",
-				"ppm",
-				"failure_reason",
-				"count_successful_attempts",
-				"count_failed_attempts",
-				"seconds_in_flight",
-			})
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
-			return
-		}
+	filter, sort, err := parsePaymentsFilterAndSort(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
 	}
 
 	var limit uint64
@@ -144,3 +101,87 @@ func getPaymentHandler(c *gin.Context, db *sqlx.DB) {
 
 	c.JSON(http.StatusOK, r)
 }
+
+func getPaymentsExportHandler(c *gin.Context, db *sqlx.DB) {
+	format, err := ah.GetExportFormat(c.Query("format"))
+	if err != nil {
+		server_errors.SendBadRequest(c, err.Error())
+		return
+	}
+
+	filter, sort, err := parsePaymentsFilterAndSort(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+
+	chain := core.Bitcoin
+
+	rows, err := queryPayments(db, cache.GetAllTorqNodeIdsByNetwork(chain, core.Network(network)), filter, sort, 0, 0)
+	if err != nil {
+		server_errors.LogAndSendServerError(c, err)
+		return
+	}
+	defer rows.Close()
+
+	exporter := ah.NewExporter(c, format, "payments")
+	err = exporter.WriteRows(rows)
+	if err != nil {
+		log.Error().Err(err).Msg("Exporting payments")
+	}
+	exporter.Flush()
+}
+
+func parsePaymentsFilterAndSort(c *gin.Context) (filter sq.Sqlizer, sort []string, err error) {
+	// Filter parser with whitelisted columns
+	filterParam := c.Query("filter")
+	if filterParam != "" {
+		filter, err = qp.ParseFilterParam(filterParam, []string{
+			"date",
+			"destination_pub_key",
+			"status",
+			"value",
+			"fee",
+			"ppm",
+			"failure_reason",
+			"is_rebalance",
+			"is_mpp",
+			"count_successful_attempts",
+			"count_failed_attempts",
+			"seconds_in_flight",
+			"payment_hash",
+			"payment_preimage",
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	sortParam := c.Query("order")
+	if sortParam != "" {
+		// Order parser with whitelisted columns
+		sort, err = qp.ParseOrderParams(
+			sortParam,
+			[]string{
+				"date",
+				"status",
+				"value",
+				"fee",
+				"ppm",
+				"failure_reason",
+				"count_successful_attempts",
+				"count_failed_attempts",
+				"seconds_in_flight",
+			})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return filter, sort, nil
+}
@@ -71,49 +71,9 @@ func getPayments(db *sqlx.DB, nodeIds []int, filter sq.Sqlizer, order []string,
 		publicKeys = append(publicKeys, cache.GetNodeSettingsByNodeId(nodeId).PublicKey)
 	}
 
-	//language=PostgreSQL
-	qb := sq.Select("*").
-		FromSelect(
-			sq.Select(`
-				payment_index,
-				creation_timestamp as date,
-				destination_pub_key,
-				status,
-				(value_msat / 1000) as value,
-				(fee_msat/1000) as fee,
-				coalesce(fee_msat/(NULLIF(value_msat, 0)/1000000), 0) as ppm,
-				failure_reason,
-				payment_hash,
-				payment_preimage,
-				payment_request,
-				destination_pub_key = ANY(ARRAY[(table pub_keys)]) as is_rebalance,
-				is_mpp,
-				count_successful_attempts,
-				count_failed_attempts,
-				extract(epoch from (to_timestamp(coalesce(NULLIF(resolved_ns, 0)/1000000000, 0))-creation_timestamp))::numeric as seconds_in_flight
-			`).
-				From("payment").
-				Where(sq.Eq{"node_id": nodeIds}).
-				Prefix(`WITH pub_keys as (select ?::text[])`, pq.Array(publicKeys)),
-			"subquery").
-		PlaceholderFormat(sq.Dollar).
-		Where(filter).
-		OrderBy(order...)
-
-	if limit > 0 {
-		qb = qb.Limit(limit).Offset(offset)
-	}
-
-	// Compile the query
-	qs, args, err := qb.ToSql()
-
-	if err != nil {
-		return nil, total, errors.Wrap(err, "Compiling query to sql")
-	}
-
-	rows, err := db.Queryx(qs, args...)
+	rows, err := queryPayments(db, nodeIds, filter, order, limit, offset)
 	if err != nil {
-		return nil, total, errors.Wrap(err, "Running query")
+		return nil, total, err
 	}
 	defer rows.Close()
 
@@ -187,6 +147,62 @@ func getPayments(db *sqlx.DB, nodeIds []int, filter sq.Sqlizer, order []string,
 	return r, total, nil
 }
 
+// queryPayments returns the open result set so it can be streamed (export) or scanned (table)
+func queryPayments(db *sqlx.DB, nodeIds []int, filter sq.Sqlizer, order []string,
+	limit uint64, offset uint64) (*sqlx.Rows, error) {
+
+	var publicKeys []string
+	for _, nodeId := range nodeIds {
+		publicKeys = append(publicKeys, cache.GetNodeSettingsByNodeId(nodeId).PublicKey)
+	}
+
+	//language=PostgreSQL
+	qb := sq.Select("*").
+		FromSelect(
+			sq.Select(`
+				payment_index,
+				creation_timestamp as date,
+				destination_pub_key,
+				status,
+				(value_msat / 1000) as value,
+				(fee_msat/1000) as fee,
+				coalesce(fee_msat/(NULLIF(value_msat, 0)/1000000), 0) as ppm,
+				failure_reason,
+				payment_hash,
+				payment_preimage,
+				payment_request,
+				destination_pub_key = ANY(ARRAY[(table pub_keys)]) as is_rebalance,
+				is_mpp,
+				count_successful_attempts,
+				count_failed_attempts,
+				extract(epoch from (to_timestamp(coalesce(NULLIF(resolved_ns, 0)/1000000000, 0))-creation_timestamp))::numeric as seconds_in_flight
+			`).
+				From("payment").
+				Where(sq.Eq{"node_id": nodeIds}).
+				Prefix(`WITH pub_keys as (select ?::text[])`, pq.Array(publicKeys)),
+			"subquery").
+		PlaceholderFormat(sq.Dollar).
+		Where(filter).
+		OrderBy(order...)
+
+	if limit > 0 {
+		qb = qb.Limit(limit).Offset(offset)
+	}
+
+	// Compile the query
+	qs, args, err := qb.ToSql()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "Compiling query to sql")
+	}
+
+	rows, err := db.Queryx(qs, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Running query")
+	}
+	return rows, nil
+}
+
 type ErrPaymentNotFound struct {
 	Identifier string
 }
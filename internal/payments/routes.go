@@ -8,5 +8,6 @@ import (
 
 func RegisterPaymentsRoutes(r *gin.RouterGroup, db *sqlx.DB) {
 	r.GET("", func(c *gin.Context) { getPaymentsHandler(c, db) })
+	r.GET("export", func(c *gin.Context) { getPaymentsExportHandler(c, db) })
 	r.GET(":identifier", func(c *gin.Context) { getPaymentHandler(c, db) })
 }
@@ -0,0 +1,89 @@
+package peer_reliability
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/database"
+	"github.com/lncapital/torq/proto/lnrpc"
+)
+
+type historyKey struct {
+	nodeId int
+	// remoteId is the peer's node id for the connection history and the channel id for the channel events
+	remoteId int
+}
+
+// getConnectionChanges returns the connection status changes of every peer within the window, the last status
+// before the window is returned as a change at windowStart.
+func getConnectionChanges(db *sqlx.DB, nodeIds []int, windowStart time.Time) (map[historyKey][]statusChange, error) {
+	rows, err := db.Queryx(`
+		SELECT torq_node_id, node_id, $2::timestamptz AS created_on,
+		       LAST(connection_status, created_on) AS connection_status
+		FROM node_connection_history
+		WHERE torq_node_id = ANY($1) AND created_on < $2 AND connection_status IS NOT NULL
+		GROUP BY torq_node_id, node_id
+		UNION ALL
+		SELECT torq_node_id, node_id, created_on, connection_status
+		FROM node_connection_history
+		WHERE torq_node_id = ANY($1) AND created_on >= $2 AND connection_status IS NOT NULL
+		ORDER BY torq_node_id, node_id, created_on;`, pq.Array(nodeIds), windowStart)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	defer rows.Close()
+
+	changes := make(map[historyKey][]statusChange)
+	for rows.Next() {
+		var key historyKey
+		var change statusChange
+		var connectionStatus core.NodeConnectionStatus
+		if err = rows.Scan(&key.nodeId, &key.remoteId, &change.time, &connectionStatus); err != nil {
+			return nil, errors.Wrap(err, "SQL row scan for connection history")
+		}
+		change.up = connectionStatus == core.NodeConnectionStatusConnected
+		changes[key] = append(changes[key], change)
+	}
+	return changes, errors.Wrap(rows.Err(), "Iterating connection history")
+}
+
+// getChannelActiveChanges returns the active and inactive channel events of every channel within the window, the
+// last event before the window is returned as a change at windowStart.
+func getChannelActiveChanges(db *sqlx.DB,
+	nodeIds []int,
+	windowStart time.Time) (map[historyKey][]statusChange, error) {
+
+	rows, err := db.Queryx(`
+		SELECT node_id, channel_id, $2::timestamptz AS time, LAST(event_type, time) AS event_type
+		FROM channel_event
+		WHERE node_id = ANY($1) AND time < $2 AND event_type = ANY($3) AND channel_id IS NOT NULL
+		GROUP BY node_id, channel_id
+		UNION ALL
+		SELECT node_id, channel_id, time, event_type
+		FROM channel_event
+		WHERE node_id = ANY($1) AND time >= $2 AND event_type = ANY($3) AND channel_id IS NOT NULL
+		ORDER BY node_id, channel_id, time;`,
+		pq.Array(nodeIds), windowStart,
+		pq.Array([]int{int(lnrpc.ChannelEventUpdate_ACTIVE_CHANNEL), int(lnrpc.ChannelEventUpdate_INACTIVE_CHANNEL)}))
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	defer rows.Close()
+
+	changes := make(map[historyKey][]statusChange)
+	for rows.Next() {
+		var key historyKey
+		var change statusChange
+		var eventType int
+		if err = rows.Scan(&key.nodeId, &key.remoteId, &change.time, &eventType); err != nil {
+			return nil, errors.Wrap(err, "SQL row scan for channel events")
+		}
+		change.up = eventType == int(lnrpc.ChannelEventUpdate_ACTIVE_CHANNEL)
+		changes[key] = append(changes[key], change)
+	}
+	return changes, errors.Wrap(rows.Err(), "Iterating channel events")
+}
@@ -0,0 +1,70 @@
+package peer_reliability
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/pkg/server_errors"
+)
+
+type PeerReliabilityResponse struct {
+	cache.PeerReliability
+	PeerAlias string `json:"peerAlias"`
+	PublicKey string `json:"pubKey"`
+}
+
+// getPeerReliabilitiesHandler returns the reliability of every peer ordered from the least reliable.
+// The cached reliabilities are used unless windowDays is provided.
+func getPeerReliabilitiesHandler(c *gin.Context, db *sqlx.DB) {
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+	nodeIds := cache.GetAllTorqNodeIdsByNetwork(core.Bitcoin, core.Network(network))
+
+	var peerReliabilities []cache.PeerReliability
+	if c.Query("windowDays") != "" {
+		windowDays, err := strconv.Atoi(c.Query("windowDays"))
+		if err != nil || windowDays < 1 || windowDays > maximumWindowDays {
+			server_errors.SendBadRequest(c, "windowDays must be a number between 1 and 90")
+			return
+		}
+		peerReliabilities, err = getPeerReliabilities(db, nodeIds, windowDays, time.Now())
+		if err != nil {
+			server_errors.WrapLogAndSendServerError(c, err, "Calculating peer reliabilities")
+			return
+		}
+	} else {
+		for _, peerReliability := range cache.GetPeerReliabilities() {
+			for _, nodeId := range nodeIds {
+				if peerReliability.NodeId == nodeId {
+					peerReliabilities = append(peerReliabilities, peerReliability)
+				}
+			}
+		}
+	}
+
+	response := make([]PeerReliabilityResponse, 0, len(peerReliabilities))
+	for _, peerReliability := range peerReliabilities {
+		response = append(response, PeerReliabilityResponse{
+			PeerReliability: peerReliability,
+			PeerAlias:       cache.GetNodeAlias(peerReliability.PeerNodeId),
+			PublicKey:       cache.GetNodeSettingsByNodeId(peerReliability.PeerNodeId).PublicKey,
+		})
+	}
+	sort.SliceStable(response, func(i, j int) bool {
+		if response[i].Score != response[j].Score {
+			return response[i].Score < response[j].Score
+		}
+		return response[i].PeerNodeId < response[j].PeerNodeId
+	})
+	c.JSON(http.StatusOK, response)
+}
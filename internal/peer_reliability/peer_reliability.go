@@ -0,0 +1,143 @@
+package peer_reliability
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/communications"
+	"github.com/lncapital/torq/internal/core"
+)
+
+const defaultWindowDays = 7
+const maximumWindowDays = 90
+const peerReliabilityTickerSeconds = 10 * 60
+
+func PeerReliabilityServiceStart(ctx context.Context, db *sqlx.DB) {
+	ticker := time.NewTicker(peerReliabilityTickerSeconds * time.Second)
+	defer ticker.Stop()
+
+	// Peers that are already reported as unreliable, they are reported again once they recovered first
+	notified := make(map[historyKey]bool)
+	refreshPeerReliabilities(db, notified)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refreshPeerReliabilities(db, notified)
+		}
+	}
+}
+
+func refreshPeerReliabilities(db *sqlx.DB, notified map[historyKey]bool) {
+	settings := cache.GetSettings().PeerReliabilitySettings
+	windowDays := settings.WindowDays
+	if windowDays < 1 {
+		windowDays = defaultWindowDays
+	}
+	peerReliabilities, err := getPeerReliabilities(db, cache.GetAllTorqNodeIds(), windowDays, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to calculate the peer reliabilities.")
+		return
+	}
+	cache.SetPeerReliabilities(peerReliabilities)
+
+	if settings.UptimeThreshold <= 0 {
+		return
+	}
+	for _, peerReliability := range peerReliabilities {
+		key := historyKey{nodeId: peerReliability.NodeId, remoteId: peerReliability.PeerNodeId}
+		if peerReliability.UptimePercentage >= settings.UptimeThreshold {
+			delete(notified, key)
+			continue
+		}
+		if notified[key] || !hasOpenChannel(peerReliability.NodeId, peerReliability.PeerNodeId) {
+			continue
+		}
+		notified[key] = true
+		notifyUnreliablePeer(db, peerReliability)
+	}
+}
+
+func notifyUnreliablePeer(db *sqlx.DB, peerReliability cache.PeerReliability) {
+	peer := cache.GetNodeAlias(peerReliability.PeerNodeId)
+	if peer == "" {
+		peer = cache.GetNodeSettingsByNodeId(peerReliability.PeerNodeId).PublicKey
+	}
+	// TODO FIXME Language from user for translations
+	message := fmt.Sprintf("Peer %v was connected %.1f%% of the last %v days (%v disconnects, longest outage %v)",
+		peer, peerReliability.UptimePercentage, peerReliability.WindowDays, peerReliability.FlapCount,
+		time.Duration(peerReliability.LongestOutageSeconds)*time.Second)
+	communications.HandleNotification(db, core.NotifierEvent{
+		EventData: core.EventData{
+			EventTime: time.Now().UTC(),
+			NodeId:    peerReliability.NodeId,
+		},
+		Notification:     &message,
+		NotificationType: core.PeerReliability,
+	})
+}
+
+func hasOpenChannel(nodeId int, peerNodeId int) bool {
+	for _, channel := range cache.GetChannelSettingsByNodeId(nodeId) {
+		if channel.Status < core.CooperativeClosed &&
+			(channel.FirstNodeId == peerNodeId || channel.SecondNodeId == peerNodeId) {
+			return true
+		}
+	}
+	return false
+}
+
+// getPeerReliabilities calculates the reliability of every peer with connection history over the last windowDays.
+// Only the channels that are still open count for the channel active percentage.
+func getPeerReliabilities(db *sqlx.DB, nodeIds []int, windowDays int, now time.Time) ([]cache.PeerReliability, error) {
+	windowStart := now.AddDate(0, 0, -windowDays)
+	connectionChanges, err := getConnectionChanges(db, nodeIds, windowStart)
+	if err != nil {
+		return nil, err
+	}
+	channelChanges, err := getChannelActiveChanges(db, nodeIds, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	channelAvailabilities := make(map[historyKey][]availability)
+	for _, nodeId := range nodeIds {
+		for _, channel := range cache.GetChannelSettingsByNodeId(nodeId) {
+			if channel.Status >= core.CooperativeClosed {
+				continue
+			}
+			changes, exists := channelChanges[historyKey{nodeId: nodeId, remoteId: channel.ChannelId}]
+			if !exists {
+				continue
+			}
+			peerNodeId := channel.FirstNodeId
+			if peerNodeId == nodeId {
+				peerNodeId = channel.SecondNodeId
+			}
+			key := historyKey{nodeId: nodeId, remoteId: peerNodeId}
+			channelAvailabilities[key] = append(channelAvailabilities[key],
+				measureAvailability(changes, windowStart, now))
+		}
+	}
+
+	peerReliabilities := make([]cache.PeerReliability, 0, len(connectionChanges))
+	for key, changes := range connectionChanges {
+		connection := measureAvailability(changes, windowStart, now)
+		if connection.knownSeconds == 0 {
+			continue
+		}
+		peerReliability := calculatePeerReliability(connection, channelAvailabilities[key], windowDays)
+		peerReliability.NodeId = key.nodeId
+		peerReliability.PeerNodeId = key.remoteId
+		peerReliability.WindowStart = windowStart
+		peerReliability.CalculatedOn = now
+		peerReliabilities = append(peerReliabilities, peerReliability)
+	}
+	return peerReliabilities, nil
+}
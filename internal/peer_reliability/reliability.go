@@ -0,0 +1,126 @@
+package peer_reliability
+
+import (
+	"math"
+	"time"
+
+	"github.com/lncapital/torq/internal/cache"
+)
+
+// flapPenalty is the number of score points a peer loses for every disconnect per day
+const flapPenalty = 5
+
+// statusChange is a connection status (or channel active status) that started at the given time.
+type statusChange struct {
+	time time.Time
+	up   bool
+}
+
+type availability struct {
+	upSeconds          float64
+	knownSeconds       float64
+	downs              int
+	longestDownSeconds float64
+}
+
+func (a availability) uptimePercentage() float64 {
+	if a.knownSeconds == 0 {
+		return 0
+	}
+	return a.upSeconds / a.knownSeconds * 100
+}
+
+// measureAvailability walks the status changes (sorted by time) from windowStart until now. The status at
+// windowStart is the status of the last change before it, when there is none the time until the first change is
+// unknown and not counted.
+func measureAvailability(changes []statusChange, windowStart time.Time, now time.Time) availability {
+	var result availability
+	var up *bool
+	var downSince time.Time
+	at := windowStart
+	for _, change := range changes {
+		changeTime := change.time
+		if changeTime.Before(windowStart) {
+			changeTime = windowStart
+		}
+		if changeTime.After(now) {
+			changeTime = now
+		}
+		if up != nil {
+			seconds := changeTime.Sub(at).Seconds()
+			result.knownSeconds += seconds
+			if *up {
+				result.upSeconds += seconds
+			}
+		}
+		switch {
+		case up != nil && *up && !change.up:
+			// Only a known connected status can flap, the first status in the window might be a stale disconnect
+			result.downs++
+			downSince = changeTime
+		case (up == nil || *up) && !change.up:
+			downSince = changeTime
+		case up != nil && !*up && change.up:
+			result.longestDownSeconds = math.Max(result.longestDownSeconds, changeTime.Sub(downSince).Seconds())
+		}
+		status := change.up
+		up = &status
+		at = changeTime
+	}
+	if up != nil {
+		seconds := now.Sub(at).Seconds()
+		result.knownSeconds += seconds
+		if *up {
+			result.upSeconds += seconds
+		} else {
+			result.longestDownSeconds = math.Max(result.longestDownSeconds, now.Sub(downSince).Seconds())
+		}
+	}
+	return result
+}
+
+// calculatePeerReliability combines the connection availability of the peer with the active availability of the
+// channels with the peer.
+func calculatePeerReliability(connection availability,
+	channels []availability,
+	windowDays int) cache.PeerReliability {
+
+	peerReliability := cache.PeerReliability{
+		UptimePercentage:     connection.uptimePercentage(),
+		FlapCount:            connection.downs,
+		LongestOutageSeconds: int64(connection.longestDownSeconds),
+		WindowDays:           windowDays,
+	}
+	if connection.downs != 0 {
+		meanSecondsBetweenDisconnects := int64(connection.upSeconds / float64(connection.downs))
+		peerReliability.MeanSecondsBetweenDisconnects = &meanSecondsBetweenDisconnects
+	}
+
+	uptime := peerReliability.UptimePercentage
+	var activePercentageSum float64
+	var activeChannels int
+	for _, channel := range channels {
+		if channel.knownSeconds == 0 {
+			continue
+		}
+		activePercentageSum += channel.uptimePercentage()
+		activeChannels++
+		if channel.downs > peerReliability.ChannelFlapCount {
+			peerReliability.ChannelFlapCount = channel.downs
+		}
+	}
+	if activeChannels != 0 {
+		channelActivePercentage := activePercentageSum / float64(activeChannels)
+		peerReliability.ChannelActivePercentage = &channelActivePercentage
+		uptime = math.Min(uptime, channelActivePercentage)
+	}
+
+	// A disconnect usually flips the channels as well so the flaps are not added up
+	flaps := peerReliability.FlapCount
+	if peerReliability.ChannelFlapCount > flaps {
+		flaps = peerReliability.ChannelFlapCount
+	}
+	score := uptime - flapPenalty*float64(flaps)/float64(windowDays)
+	peerReliability.Score = math.Max(0, math.Min(100, score))
+	return peerReliability
+}
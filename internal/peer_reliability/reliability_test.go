@@ -0,0 +1,78 @@
+package peer_reliability
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMeasureAvailability(t *testing.T) {
+	windowStart := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := windowStart.Add(10 * time.Hour)
+	changes := []statusChange{
+		// The status before the window
+		{time: windowStart, up: true},
+		{time: windowStart.Add(2 * time.Hour), up: false},
+		{time: windowStart.Add(3 * time.Hour), up: true},
+		{time: windowStart.Add(5 * time.Hour), up: false},
+		// Repeated status without a reconnect
+		{time: windowStart.Add(6 * time.Hour), up: false},
+		{time: windowStart.Add(8 * time.Hour), up: true},
+	}
+
+	result := measureAvailability(changes, windowStart, now)
+	if result.downs != 2 {
+		t.Errorf("expected 2 disconnects, got %v", result.downs)
+	}
+	if math.Abs(result.uptimePercentage()-60) > 1e-9 {
+		t.Errorf("expected 60%% uptime, got %v", result.uptimePercentage())
+	}
+	if result.longestDownSeconds != 3*60*60 {
+		t.Errorf("expected the longest outage to be 3 hours, got %v seconds", result.longestDownSeconds)
+	}
+}
+
+func TestMeasureAvailabilityUnknownStart(t *testing.T) {
+	windowStart := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := windowStart.Add(10 * time.Hour)
+	changes := []statusChange{
+		{time: windowStart.Add(5 * time.Hour), up: false},
+		{time: windowStart.Add(9 * time.Hour), up: true},
+	}
+
+	result := measureAvailability(changes, windowStart, now)
+	if result.knownSeconds != 5*60*60 {
+		t.Errorf("expected the time before the first status to be unknown, got %v known seconds", result.knownSeconds)
+	}
+	if result.downs != 0 {
+		t.Errorf("expected a first disconnected status not to count as a disconnect, got %v", result.downs)
+	}
+	if math.Abs(result.uptimePercentage()-20) > 1e-9 {
+		t.Errorf("expected 20%% uptime, got %v", result.uptimePercentage())
+	}
+}
+
+func TestCalculatePeerReliability(t *testing.T) {
+	connection := availability{upSeconds: 95, knownSeconds: 100, downs: 2, longestDownSeconds: 4}
+	channels := []availability{
+		{upSeconds: 90, knownSeconds: 100, downs: 7},
+		{upSeconds: 80, knownSeconds: 100, downs: 1},
+		{},
+	}
+
+	peerReliability := calculatePeerReliability(connection, channels, 7)
+	if peerReliability.MeanSecondsBetweenDisconnects == nil || *peerReliability.MeanSecondsBetweenDisconnects != 47 {
+		t.Errorf("expected 47 seconds between disconnects, got %v", peerReliability.MeanSecondsBetweenDisconnects)
+	}
+	if peerReliability.ChannelActivePercentage == nil || *peerReliability.ChannelActivePercentage != 85 {
+		t.Errorf("expected the channels to be active 85%% of the time, got %v",
+			peerReliability.ChannelActivePercentage)
+	}
+	if peerReliability.ChannelFlapCount != 7 {
+		t.Errorf("expected 7 channel flaps, got %v", peerReliability.ChannelFlapCount)
+	}
+	// The lowest uptime minus 5 points for every flap per day
+	if math.Abs(peerReliability.Score-80) > 1e-9 {
+		t.Errorf("expected a score of 80, got %v", peerReliability.Score)
+	}
+}
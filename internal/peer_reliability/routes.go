@@ -0,0 +1,10 @@
+package peer_reliability
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func RegisterPeerReliabilityRoutes(r *gin.RouterGroup, db *sqlx.DB) {
+	r.GET("", func(c *gin.Context) { getPeerReliabilitiesHandler(c, db) })
+}
@@ -3,12 +3,14 @@ package peers
 import (
 	"context"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/cockroachdb/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"golang.org/x/exp/slices"
 
 	"github.com/lncapital/torq/internal/cache"
 	"github.com/lncapital/torq/internal/core"
@@ -253,6 +255,43 @@ func updatePeer(c *gin.Context, db *sqlx.DB) {
 
 }
 
+type PeerReconnectStateResponse struct {
+	cache.PeerReconnectState
+	PeerAlias string `json:"peerAlias"`
+	PublicKey string `json:"pubKey"`
+}
+
+// getReconnectStatesHandler returns the reconnection state of the channel peers, the offline peers first.
+func getReconnectStatesHandler(c *gin.Context) {
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+	nodeIds := cache.GetAllTorqNodeIdsByNetwork(core.Bitcoin, core.Network(network))
+	response := make([]PeerReconnectStateResponse, 0)
+	for _, state := range cache.GetPeerReconnectStates() {
+		if !slices.Contains(nodeIds, state.NodeId) {
+			continue
+		}
+		response = append(response, PeerReconnectStateResponse{
+			PeerReconnectState: state,
+			PeerAlias:          cache.GetNodeAlias(state.PeerNodeId),
+			PublicKey:          cache.GetNodeSettingsByNodeId(state.PeerNodeId).PublicKey,
+		})
+	}
+	sort.SliceStable(response, func(i, j int) bool {
+		if (response[i].Status == cache.PeerReconnectConnected) != (response[j].Status == cache.PeerReconnectConnected) {
+			return response[j].Status == cache.PeerReconnectConnected
+		}
+		if response[i].Failures != response[j].Failures {
+			return response[i].Failures > response[j].Failures
+		}
+		return response[i].PeerNodeId < response[j].PeerNodeId
+	})
+	c.JSON(http.StatusOK, response)
+}
+
 func getHostFromPeer(ctx context.Context, connectionDetailsNodeId int, nodeId int) (string, error) {
 	nodeSettings := cache.GetNodeSettingsByNodeId(nodeId)
 	peers, err := lightning.ListPeers(ctx, connectionDetailsNodeId, true)
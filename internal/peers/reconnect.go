@@ -0,0 +1,380 @@
+package peers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/communications"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/database"
+	"github.com/lncapital/torq/internal/lightning"
+	"github.com/lncapital/torq/internal/settings"
+)
+
+const peerReconnectTickerSeconds = 30
+
+// The wait after a failed attempt doubles from reconnectBackoffMinimum until reconnectBackoffMaximum
+const reconnectBackoffMinimum = 1 * time.Minute
+const reconnectBackoffMaximum = 1 * time.Hour
+
+// connectTimeout limits a single connection attempt, tor addresses can take a while
+const connectTimeout = 60 * time.Second
+
+// reconnectPeerTimeout limits all connection attempts of a peer during one run so a peer with many unreachable
+// addresses doesn't hold up the next run
+const reconnectPeerTimeout = 3 * time.Minute
+
+// reconnectConcurrency is the number of peers that are reconnected at the same time
+const reconnectConcurrency = 8
+
+// announcementHistory is the number of most recent node announcements the addresses are collected from
+const announcementHistory = 10
+
+const (
+	networkClearnet = "clearnet"
+	networkTor      = "tor"
+)
+
+type reconnectKey struct {
+	nodeId     int
+	peerNodeId int
+}
+
+type peerReconnect struct {
+	state     *cache.PeerReconnectState
+	publicKey string
+}
+
+// PeerReconnectServiceStart reconnects the offline channel peers of all active nodes. Every attempt tries all known
+// addresses, when they all fail the next attempt waits twice as long as the previous one.
+func PeerReconnectServiceStart(ctx context.Context, db *sqlx.DB) {
+	ticker := time.NewTicker(peerReconnectTickerSeconds * time.Second)
+	defer ticker.Stop()
+
+	states := make(map[reconnectKey]*cache.PeerReconnectState)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reconnectPeers(ctx, db, states)
+		}
+	}
+}
+
+func reconnectPeers(ctx context.Context, db *sqlx.DB, states map[reconnectKey]*cache.PeerReconnectState) {
+	reconnectSettings := cache.GetSettings().PeerReconnectSettings
+	seen := make(map[reconnectKey]bool)
+	var peerReconnects []peerReconnect
+	for _, torqNodeSettings := range cache.GetActiveTorqNodeSettings() {
+		connectedPeers, err := lightning.ListPeers(ctx, torqNodeSettings.NodeId, false)
+		if err != nil {
+			if !errors.Is(err, lightning.ServiceInactiveError) {
+				log.Error().Err(err).Msgf("Failed to obtain the peers to reconnect for nodeId: %v",
+					torqNodeSettings.NodeId)
+			}
+			// The states are kept until the node is reachable again
+			for key := range states {
+				if key.nodeId == torqNodeSettings.NodeId {
+					seen[key] = true
+				}
+			}
+			continue
+		}
+		for _, peerNodeId := range getOpenChannelPeerNodeIds(torqNodeSettings.NodeId) {
+			key := reconnectKey{nodeId: torqNodeSettings.NodeId, peerNodeId: peerNodeId}
+			seen[key] = true
+			state, exists := states[key]
+			if !exists {
+				state = &cache.PeerReconnectState{NodeId: key.nodeId, PeerNodeId: key.peerNodeId}
+				states[key] = state
+			}
+			publicKey := cache.GetNodeSettingsByNodeId(peerNodeId).PublicKey
+			if _, connected := connectedPeers[publicKey]; connected {
+				*state = cache.PeerReconnectState{
+					NodeId:      key.nodeId,
+					PeerNodeId:  key.peerNodeId,
+					Status:      cache.PeerReconnectConnected,
+					LastAddress: state.LastAddress,
+				}
+				continue
+			}
+			peerReconnects = append(peerReconnects, peerReconnect{state: state, publicKey: publicKey})
+		}
+	}
+
+	// Every peer has its own state so the peers are reconnected concurrently
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, reconnectConcurrency)
+	for _, peer := range peerReconnects {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(peer peerReconnect) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			peerCtx, cancel := context.WithTimeout(ctx, reconnectPeerTimeout)
+			defer cancel()
+			reconnectPeer(peerCtx, db, peer.state, peer.publicKey, reconnectSettings)
+		}(peer)
+	}
+	wg.Wait()
+
+	peerReconnectStates := make([]cache.PeerReconnectState, 0, len(states))
+	for key, state := range states {
+		if !seen[key] {
+			delete(states, key)
+			continue
+		}
+		peerReconnectStates = append(peerReconnectStates, *state)
+	}
+	cache.SetPeerReconnectStates(peerReconnectStates)
+}
+
+func reconnectPeer(ctx context.Context,
+	db *sqlx.DB,
+	state *cache.PeerReconnectState,
+	publicKey string,
+	reconnectSettings cache.PeerReconnectSettings) {
+
+	now := time.Now().UTC()
+	if state.OfflineSince == nil {
+		state.OfflineSince = &now
+	}
+	if state.NextAttempt != nil && now.Before(*state.NextAttempt) {
+		return
+	}
+
+	lastAddress, setting, _, err := settings.GetNodeConnectionHistoryWithDetail(db, state.NodeId, state.PeerNodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain the connection setting of peer nodeId: %v for nodeId: %v",
+			state.PeerNodeId, state.NodeId)
+		return
+	}
+	if setting != nil && *setting == core.NodeConnectionSettingDisableReconnect {
+		state.Status = cache.PeerReconnectDisabled
+		state.NextAttempt = nil
+		return
+	}
+
+	addresses, err := getNodeAddresses(db, state.PeerNodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain the addresses of peer nodeId: %v", state.PeerNodeId)
+		return
+	}
+	if lastAddress != nil && *lastAddress != "" {
+		addresses = append([]string{*lastAddress}, addresses...)
+	}
+	state.Addresses = orderAddresses(addresses, reconnectSettings.NetworkOrder)
+	if len(state.Addresses) == 0 {
+		state.Status = cache.PeerReconnectNoAddress
+		return
+	}
+
+	state.LastAttempt = &now
+	for _, address := range state.Addresses {
+		state.LastAddress = address
+		alreadyConnected, err := connectPeer(ctx, state.NodeId, publicKey, address)
+		if err == nil || alreadyConnected {
+			connected := core.NodeConnectionStatusConnected
+			err = settings.AddNodeConnectionHistory(db, state.NodeId, state.PeerNodeId, &address, setting, &connected)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to store the reconnection of peer nodeId: %v for nodeId: %v",
+					state.PeerNodeId, state.NodeId)
+			}
+			log.Info().Msgf("Reconnected peer nodeId: %v for nodeId: %v using %v after %v failed attempts",
+				state.PeerNodeId, state.NodeId, address, state.Failures)
+			*state = cache.PeerReconnectState{
+				NodeId:      state.NodeId,
+				PeerNodeId:  state.PeerNodeId,
+				Status:      cache.PeerReconnectConnected,
+				LastAddress: address,
+			}
+			return
+		}
+		state.LastError = err.Error()
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	// Stopped, a peer that ran out of time is handled as a failed attempt
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return
+	}
+
+	state.Failures++
+	state.Status = cache.PeerReconnectWaiting
+	nextAttempt := now.Add(reconnectBackoff(state.Failures))
+	state.NextAttempt = &nextAttempt
+	if reconnectSettings.MaxFailures > 0 && state.Failures >= reconnectSettings.MaxFailures && !state.Notified {
+		state.Notified = true
+		notifyReconnectFailures(db, *state)
+	}
+}
+
+// connectPeer returns when the attempt times out, the request to the node itself isn't cancelled by the context.
+func connectPeer(ctx context.Context, nodeId int, publicKey string, address string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	type connectPeerResult struct {
+		alreadyConnected bool
+		err              error
+	}
+	results := make(chan connectPeerResult, 1)
+	go func() {
+		alreadyConnected, err := lightning.ConnectPeer(ctx, nodeId, publicKey, address)
+		results <- connectPeerResult{alreadyConnected: alreadyConnected, err: err}
+	}()
+	select {
+	case <-ctx.Done():
+		return false, errors.Wrapf(ctx.Err(), "Connecting to %v", address)
+	case result := <-results:
+		return result.alreadyConnected, result.err
+	}
+}
+
+func notifyReconnectFailures(db *sqlx.DB, state cache.PeerReconnectState) {
+	peer := cache.GetNodeAlias(state.PeerNodeId)
+	if peer == "" {
+		peer = cache.GetNodeSettingsByNodeId(state.PeerNodeId).PublicKey
+	}
+	// TODO FIXME Language from user for translations
+	message := fmt.Sprintf("Could not reconnect to channel peer %v after %v attempts on %v addresses, offline since %v",
+		peer, state.Failures, len(state.Addresses), state.OfflineSince.Format(time.RFC3339))
+	communications.HandleNotification(db, core.NotifierEvent{
+		EventData: core.EventData{
+			EventTime: time.Now().UTC(),
+			NodeId:    state.NodeId,
+		},
+		Notification:     &message,
+		NotificationType: core.PeerReconnect,
+	})
+}
+
+// reconnectBackoff is the wait after the given number of failed attempts.
+func reconnectBackoff(failures int) time.Duration {
+	backoff := reconnectBackoffMinimum
+	for i := 1; i < failures && backoff < reconnectBackoffMaximum; i++ {
+		backoff *= 2
+	}
+	if backoff > reconnectBackoffMaximum {
+		return reconnectBackoffMaximum
+	}
+	return backoff
+}
+
+func getOpenChannelPeerNodeIds(nodeId int) []int {
+	var peerNodeIds []int
+	seen := make(map[int]bool)
+	for _, channel := range cache.GetChannelSettingsByNodeId(nodeId) {
+		if channel.Status != core.Open {
+			continue
+		}
+		peerNodeId := channel.FirstNodeId
+		if peerNodeId == nodeId {
+			peerNodeId = channel.SecondNodeId
+		}
+		if !seen[peerNodeId] {
+			seen[peerNodeId] = true
+			peerNodeIds = append(peerNodeIds, peerNodeId)
+		}
+	}
+	return peerNodeIds
+}
+
+// GetNodeAddresses returns the known addresses of the node grouped by network in the configured reconnect order.
+func GetNodeAddresses(db *sqlx.DB, nodeId int) ([]string, error) {
+	addresses, err := getNodeAddresses(db, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	return orderAddresses(addresses, cache.GetSettings().PeerReconnectSettings.NetworkOrder), nil
+}
+
+// getNodeAddresses returns the addresses of the most recent node announcements of the node, newest first.
+func getNodeAddresses(db *sqlx.DB, nodeId int) ([]string, error) {
+	var announcements []string
+	err := db.Select(&announcements, `
+		SELECT node_addresses::text
+		FROM node_event
+		WHERE event_node_id=$1 AND node_addresses IS NOT NULL
+		ORDER BY timestamp DESC
+		LIMIT $2;`, nodeId, announcementHistory)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	var addresses []string
+	for _, announcement := range announcements {
+		addresses = append(addresses, parseNodeAddresses(announcement)...)
+	}
+	return addresses, nil
+}
+
+// parseNodeAddresses reads the addresses of a node announcement as stored by LND ({"network", "addr"}) or CLN
+// ({"item_type", "address", "port"}).
+func parseNodeAddresses(announcement string) []string {
+	var nodeAddresses []struct {
+		Addr     string `json:"addr"`
+		Address  string `json:"address"`
+		Port     uint32 `json:"port"`
+		ItemType int    `json:"item_type"`
+	}
+	if err := json.Unmarshal([]byte(announcement), &nodeAddresses); err != nil {
+		return nil
+	}
+	var addresses []string
+	for _, nodeAddress := range nodeAddresses {
+		switch {
+		case nodeAddress.Addr != "":
+			addresses = append(addresses, nodeAddress.Addr)
+		// CLN websocket addresses (item type 5) can't be used to connect
+		case nodeAddress.Address != "" && nodeAddress.ItemType != 5:
+			addresses = append(addresses, net.JoinHostPort(nodeAddress.Address, strconv.Itoa(int(nodeAddress.Port))))
+		}
+	}
+	return addresses
+}
+
+// orderAddresses removes the duplicates and groups the addresses by network in the configured order while keeping the
+// order within a network. Addresses of a network that is not configured are left out.
+func orderAddresses(addresses []string, networkOrder []string) []string {
+	if len(networkOrder) == 0 {
+		networkOrder = []string{networkClearnet, networkTor}
+	}
+	ordered := make([]string, 0, len(addresses))
+	seen := make(map[string]bool)
+	for _, network := range networkOrder {
+		for _, address := range addresses {
+			if seen[address] || addressNetwork(address) != network {
+				continue
+			}
+			seen[address] = true
+			ordered = append(ordered, address)
+		}
+	}
+	return ordered
+}
+
+func addressNetwork(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	if strings.HasSuffix(strings.ToLower(host), ".onion") {
+		return networkTor
+	}
+	return networkClearnet
+}
@@ -0,0 +1,49 @@
+package peers
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestParseNodeAddresses(t *testing.T) {
+	lnd := `[{"network":"tcp","addr":"1.2.3.4:9735"},{"network":"tcp","addr":"abc.onion:9735"}]`
+	if addresses := parseNodeAddresses(lnd); !slices.Equal(addresses, []string{"1.2.3.4:9735", "abc.onion:9735"}) {
+		t.Errorf("unexpected LND addresses %v", addresses)
+	}
+	cln := `[{"item_type":2,"port":9735,"address":"2001:db8::1"},{"item_type":5,"port":80,"address":"ws.example.com"}]`
+	if addresses := parseNodeAddresses(cln); !slices.Equal(addresses, []string{"[2001:db8::1]:9735"}) {
+		t.Errorf("unexpected CLN addresses %v", addresses)
+	}
+	if addresses := parseNodeAddresses("null"); len(addresses) != 0 {
+		t.Errorf("expected no addresses, got %v", addresses)
+	}
+}
+
+func TestOrderAddresses(t *testing.T) {
+	addresses := []string{"abc.onion:9735", "1.2.3.4:9735", "def.onion:9735", "1.2.3.4:9735"}
+	ordered := orderAddresses(addresses, []string{"tor", "clearnet"})
+	expected := []string{"abc.onion:9735", "def.onion:9735", "1.2.3.4:9735"}
+	if !slices.Equal(ordered, expected) {
+		t.Errorf("expected %v, got %v", expected, ordered)
+	}
+	if ordered = orderAddresses(addresses, []string{"clearnet"}); !slices.Equal(ordered, []string{"1.2.3.4:9735"}) {
+		t.Errorf("expected only the clearnet address, got %v", ordered)
+	}
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	expected := map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		4:  8 * time.Minute,
+		7:  time.Hour,
+		50: time.Hour,
+	}
+	for failures, backoff := range expected {
+		if reconnectBackoff(failures) != backoff {
+			t.Errorf("expected a backoff of %v after %v failures, got %v", backoff, failures, reconnectBackoff(failures))
+		}
+	}
+}
@@ -10,5 +10,6 @@ func RegisterPeerRoutes(r *gin.RouterGroup, db *sqlx.DB) {
 	r.POST("/connect", func(c *gin.Context) { connectNewPeerHandler(c, db) })
 	r.PATCH("/disconnect", func(c *gin.Context) { disconnectPeerHandler(c, db) })
 	r.PATCH("/reconnect", func(c *gin.Context) { reconnectPeerHandler(c, db) })
+	r.GET("/reconnect", func(c *gin.Context) { getReconnectStatesHandler(c) })
 	r.PATCH("/update", func(c *gin.Context) { updatePeer(c, db) })
 }
@@ -86,3 +86,12 @@ func GetLatestResultByOrigin(db *sqlx.DB,
 	}
 	return rebalanceResult, nil
 }
+
+func SetRebalanceStatus(db *sqlx.DB, rebalanceId int, status core.Status, updateOn time.Time) error {
+	_, err := db.Exec(`UPDATE rebalance SET status=$1, updated_on=$2 WHERE rebalance_id=$3;`,
+		status, updateOn, rebalanceId)
+	if err != nil {
+		return errors.Wrapf(err, "Update status of rebalancer %v", rebalanceId)
+	}
+	return nil
+}
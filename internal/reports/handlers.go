@@ -0,0 +1,327 @@
+package reports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/exp/slices"
+
+	ah "github.com/lncapital/torq/internal/api_helpers"
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	qp "github.com/lncapital/torq/internal/query_parser"
+	"github.com/lncapital/torq/pkg/server_errors"
+)
+
+func getFiatCurrenciesHandler(c *gin.Context, db *sqlx.DB) {
+	currencies, err := getFiatCurrencies(db)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting fiat currencies")
+		return
+	}
+	c.JSON(http.StatusOK, currencies)
+}
+
+func getFiatPricesHandler(c *gin.Context, db *sqlx.DB) {
+	currency := c.Param("currency")
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Invalid 'from' date.")
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Invalid 'to' date.")
+		return
+	}
+	prices, err := getFiatPrices(db, currency, from, to)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting fiat prices")
+		return
+	}
+	c.JSON(http.StatusOK, prices)
+}
+
+type fiatPriceRequest struct {
+	Date  string  `json:"date"`
+	Price float64 `json:"price"`
+}
+
+func setFiatPricesHandler(c *gin.Context, db *sqlx.DB) {
+	currency := c.Param("currency")
+	var requests []fiatPriceRequest
+	if err := c.BindJSON(&requests); err != nil {
+		server_errors.SendBadRequestFromError(c, errors.Wrap(err, server_errors.JsonParseError))
+		return
+	}
+	var prices []FiatPrice
+	for _, request := range requests {
+		date, err := time.Parse("2006-01-02", request.Date)
+		if err != nil {
+			server_errors.SendBadRequest(c, fmt.Sprintf("Invalid date %v, expected YYYY-MM-DD.", request.Date))
+			return
+		}
+		if request.Price <= 0 {
+			server_errors.SendBadRequest(c, fmt.Sprintf("Price for %v must be positive.", request.Date))
+			return
+		}
+		prices = append(prices, FiatPrice{Currency: currency, Date: date, Price: request.Price})
+	}
+	err := setFiatPrices(db, prices)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Setting fiat prices")
+		return
+	}
+	c.JSON(http.StatusOK, map[string]interface{}{"message": "Successfully stored fiat prices.", "count": len(prices)})
+}
+
+// importFiatPricesHandler accepts a CSV file either as the request body or as the multipart form field "file"
+func importFiatPricesHandler(c *gin.Context, db *sqlx.DB) {
+	currency := c.Param("currency")
+	var prices []FiatPrice
+	var err error
+	fileHeader, formErr := c.FormFile("file")
+	if formErr == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			server_errors.SendBadRequest(c, "Could not open the uploaded file.")
+			return
+		}
+		defer file.Close()
+		prices, err = parseFiatPriceCsv(file, currency)
+		if err != nil {
+			server_errors.SendBadRequestFromError(c, err)
+			return
+		}
+	} else {
+		prices, err = parseFiatPriceCsv(c.Request.Body, currency)
+		if err != nil {
+			server_errors.SendBadRequestFromError(c, err)
+			return
+		}
+	}
+	err = setFiatPrices(db, prices)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Importing fiat prices")
+		return
+	}
+	c.JSON(http.StatusOK, map[string]interface{}{"message": "Successfully imported fiat prices.", "count": len(prices)})
+}
+
+func removeFiatPricesHandler(c *gin.Context, db *sqlx.DB) {
+	err := deleteFiatPrices(db, c.Param("currency"))
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Removing fiat prices")
+		return
+	}
+	c.JSON(http.StatusOK, map[string]interface{}{"message": "Successfully removed fiat prices."})
+}
+
+func getIncomeStatementHandler(c *gin.Context, db *sqlx.DB) {
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Invalid 'from' date.")
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Invalid 'to' date.")
+		return
+	}
+	if to.Before(from) {
+		server_errors.SendBadRequest(c, "'to' date must be after 'from' date.")
+		return
+	}
+
+	period := PeriodMonth
+	if c.Query("period") != "" {
+		period = Period(strings.ToLower(c.Query("period")))
+		if period != PeriodMonth && period != PeriodQuarter && period != PeriodYear {
+			server_errors.SendBadRequest(c, "Period must be one of: month, quarter, year")
+			return
+		}
+	}
+
+	format, err := ah.GetExportFormat(c.DefaultQuery("format", string(ah.ExportFormatJson)))
+	if err != nil {
+		server_errors.SendBadRequestFromError(c, err)
+		return
+	}
+
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+
+	nodeIds := cache.GetAllTorqNodeIdsByNetwork(core.Bitcoin, core.Network(network))
+	if c.Query("nodeId") != "" {
+		nodeId, err := strconv.Atoi(c.Query("nodeId"))
+		if err != nil {
+			server_errors.SendBadRequest(c, "Can't process nodeId")
+			return
+		}
+		if !slices.Contains(nodeIds, nodeId) {
+			server_errors.SendBadRequest(c, "Unknown nodeId for the selected network.")
+			return
+		}
+		nodeIds = []int{nodeId}
+	}
+
+	currency := strings.ToUpper(c.Query("currency"))
+	report, err := getIncomeStatementReport(db, nodeIds, from, to, period, currency)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting income statement")
+		return
+	}
+
+	if format == ah.ExportFormatJson {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition",
+		fmt.Sprintf("attachment; filename=income-statement-%v-%v.csv", from.Format("20060102"), to.Format("20060102")))
+	c.Status(http.StatusOK)
+	err = writeIncomeStatementCsv(csv.NewWriter(c.Writer), report)
+	if err != nil {
+		server_errors.LogAndSendServerError(c, err)
+	}
+}
+
+// writeIncomeStatementCsv writes one row per period, node and line followed by the income, expense and net income rows
+func writeIncomeStatementCsv(writer *csv.Writer, report IncomeStatementReport) error {
+	err := writer.Write([]string{"period", "period_start", "period_end", "node_id", "node_name",
+		"line", "type", "amount_msat", "amount_btc", "currency", "amount_fiat", "missing_prices"})
+	if err != nil {
+		return errors.Wrap(err, "Writing CSV header")
+	}
+	for _, statement := range report.Statements {
+		nodeId := ""
+		if statement.NodeId != nil {
+			nodeId = strconv.Itoa(*statement.NodeId)
+		}
+		writeLine := func(line string, lineType string, amountMsat int64, amountFiat *float64) error {
+			fiat := ""
+			if amountFiat != nil {
+				fiat = strconv.FormatFloat(*amountFiat, 'f', 2, 64)
+			}
+			return writer.Write([]string{
+				statement.Period,
+				statement.PeriodStart.Format("2006-01-02"),
+				statement.PeriodEnd.Format("2006-01-02"),
+				nodeId,
+				statement.NodeName,
+				line,
+				lineType,
+				strconv.FormatInt(amountMsat, 10),
+				strconv.FormatFloat(float64(amountMsat)/msatPerBtc, 'f', 11, 64),
+				report.Currency,
+				fiat,
+				strconv.FormatBool(statement.MissingPrices),
+			})
+		}
+		for _, line := range statement.Lines {
+			if err = writeLine(line.Line, string(line.Type), line.AmountMsat, line.AmountFiat); err != nil {
+				return errors.Wrap(err, "Writing CSV row")
+			}
+		}
+		if err = writeLine("total_income", "total", statement.IncomeMsat, statement.IncomeFiat); err != nil {
+			return errors.Wrap(err, "Writing CSV row")
+		}
+		if err = writeLine("total_expense", "total", statement.ExpenseMsat, statement.ExpenseFiat); err != nil {
+			return errors.Wrap(err, "Writing CSV row")
+		}
+		if err = writeLine("net_income", "total", statement.NetIncomeMsat, statement.NetIncomeFiat); err != nil {
+			return errors.Wrap(err, "Writing CSV row")
+		}
+	}
+	writer.Flush()
+	return errors.Wrap(writer.Error(), "Flushing CSV")
+}
+
+func getProfitAndLossHandler(c *gin.Context, db *sqlx.DB) {
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Invalid 'from' date.")
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Invalid 'to' date.")
+		return
+	}
+	// Include the whole 'to' day
+	to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	if to.Before(from) {
+		server_errors.SendBadRequest(c, "'to' date must be after 'from' date.")
+		return
+	}
+
+	groupBy := GroupByChannel
+	if c.Query("groupBy") != "" {
+		groupBy = GroupBy(strings.ToLower(c.Query("groupBy")))
+		if _, exists := groupSql[groupBy]; !exists {
+			server_errors.SendBadRequest(c, "groupBy must be one of: channel, peer, tag, category")
+			return
+		}
+	}
+
+	columns := []string{
+		"id",
+		"name",
+		"channel_count",
+		"capacity",
+		"revenue_msat",
+		"revenue_in_msat",
+		"rebalancing_cost_msat",
+		"on_chain_cost_msat",
+		"total_cost_msat",
+		"profit_msat",
+		"capital_deployed_sat_days",
+		"annualized_roi",
+	}
+
+	var filter sq.Sqlizer
+	filterParam := c.Query("filter")
+	if filterParam != "" {
+		filter, err = qp.ParseFilterParam(filterParam, columns)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	sort := []string{"profit_msat desc"}
+	sortParam := c.Query("order")
+	if sortParam != "" {
+		sort, err = qp.ParseOrderParams(sortParam, columns)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+
+	r, err := getProfitAndLoss(db, cache.GetAllTorqNodeIdsByNetwork(core.Bitcoin, core.Network(network)),
+		from, to, groupBy, filter, sort)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting profit and loss")
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
@@ -0,0 +1,122 @@
+package reports
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/lncapital/torq/internal/database"
+)
+
+type FiatPrice struct {
+	Currency string    `json:"currency" db:"currency"`
+	Date     time.Time `json:"date" db:"date"`
+	// Price of 1 BTC in the currency
+	Price     float64   `json:"price" db:"price"`
+	CreatedOn time.Time `json:"createdOn" db:"created_on"`
+	UpdateOn  time.Time `json:"updatedOn" db:"updated_on"`
+}
+
+func getFiatPrices(db *sqlx.DB, currency string, from time.Time, to time.Time) ([]FiatPrice, error) {
+	prices := []FiatPrice{}
+	err := db.Select(&prices, `
+		SELECT currency, date, price, created_on, updated_on
+		FROM fiat_price
+		WHERE currency=$1 AND date >= $2 AND date <= $3
+		ORDER BY date;`, strings.ToUpper(currency), from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return prices, nil
+}
+
+func getFiatCurrencies(db *sqlx.DB) ([]string, error) {
+	currencies := []string{}
+	err := db.Select(&currencies, `SELECT DISTINCT currency FROM fiat_price ORDER BY currency;`)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return currencies, nil
+}
+
+// setFiatPrices inserts or overwrites the daily prices in one transaction
+func setFiatPrices(db *sqlx.DB, prices []FiatPrice) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "Starting transaction")
+	}
+	now := time.Now().UTC()
+	for _, price := range prices {
+		_, err = tx.Exec(`
+			INSERT INTO fiat_price (currency, date, price, created_on, updated_on)
+			VALUES ($1, $2, $3, $4, $4)
+			ON CONFLICT (currency, date) DO UPDATE SET price=EXCLUDED.price, updated_on=EXCLUDED.updated_on;`,
+			strings.ToUpper(price.Currency), price.Date, price.Price, now)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return errors.Wrap(rollbackErr, "Rolling back fiat prices")
+			}
+			return errors.Wrap(err, database.SqlExecutionError)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "Committing fiat prices")
+	}
+	return nil
+}
+
+func deleteFiatPrices(db *sqlx.DB, currency string) error {
+	_, err := db.Exec(`DELETE FROM fiat_price WHERE currency=$1;`, strings.ToUpper(currency))
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	return nil
+}
+
+// parseFiatPriceCsv reads a CSV with a date (YYYY-MM-DD) and a price column.
+// A header row is optional, comma and semicolon separated files are both accepted.
+func parseFiatPriceCsv(reader io.Reader, currency string) ([]FiatPrice, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "Reading CSV")
+	}
+	csvReader := csv.NewReader(strings.NewReader(string(content)))
+	if strings.Count(string(content), ";") > strings.Count(string(content), ",") {
+		csvReader.Comma = ';'
+	}
+	csvReader.TrimLeadingSpace = true
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "Parsing CSV")
+	}
+
+	var prices []FiatPrice
+	for i, record := range records {
+		if len(record) < 2 {
+			return nil, errors.Newf("line %v: expected a date and a price column", i+1)
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		if err != nil {
+			if i == 0 {
+				// header
+				continue
+			}
+			return nil, errors.Wrapf(err, "line %v: parsing date", i+1)
+		}
+		price, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %v: parsing price", i+1)
+		}
+		if price <= 0 {
+			return nil, errors.Newf("line %v: price must be positive", i+1)
+		}
+		prices = append(prices, FiatPrice{Currency: strings.ToUpper(currency), Date: date, Price: price})
+	}
+	return prices, nil
+}
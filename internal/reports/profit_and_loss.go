@@ -0,0 +1,238 @@
+package reports
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/database"
+)
+
+type GroupBy string
+
+const (
+	GroupByChannel  = GroupBy("channel")
+	GroupByPeer     = GroupBy("peer")
+	GroupByTag      = GroupBy("tag")
+	GroupByCategory = GroupBy("category")
+)
+
+type ProfitAndLoss struct {
+	// Id is the channel, peer node, tag or category id depending on the grouping
+	Id int `json:"id" db:"id"`
+	// Name is the short channel id, peer alias, tag name or category name depending on the grouping
+	Name         *string `json:"name" db:"name"`
+	ChannelCount int     `json:"channelCount" db:"channel_count"`
+	Capacity     int64   `json:"capacity" db:"capacity"`
+
+	// Fees earned on forwards leaving through the channels
+	RevenueMsat int64 `json:"revenueMsat" db:"revenue_msat"`
+	// Fees earned on forwards entering through the channels (informational, not part of the profit)
+	RevenueInMsat int64 `json:"revenueInMsat" db:"revenue_in_msat"`
+	// Half of the rebalancing fee is assigned to each of the two channels involved
+	RebalancingCostMsat int64 `json:"rebalancingCostMsat" db:"rebalancing_cost_msat"`
+	// Open and close transaction fees spread over the lifetime of the channels, only the part
+	// that overlaps the requested date range is included
+	OnChainCostMsat int64 `json:"onChainCostMsat" db:"on_chain_cost_msat"`
+	TotalCostMsat   int64 `json:"totalCostMsat" db:"total_cost_msat"`
+	ProfitMsat      int64 `json:"profitMsat" db:"profit_msat"`
+
+	// Capacity multiplied by the days the channels were open within the date range
+	CapitalDeployedSatDays float64 `json:"capitalDeployedSatDays" db:"capital_deployed_sat_days"`
+	// Profit divided by the capital deployed extrapolated to a year (0.05 is 5%)
+	AnnualizedRoi *float64 `json:"annualizedRoi" db:"annualized_roi"`
+}
+
+// channelProfitAndLossSql defines the channel_profit_and_loss CTE with one row per channel of our nodes that was
+// open at any point in the date range. The parameters are the node ids, from, to and the public keys of our nodes.
+const channelProfitAndLossSql = `
+	WITH params AS (
+		SELECT ?::integer[] AS node_ids, ?::timestamptz AS from_time, ?::timestamptz AS to_time,
+			?::text[] AS public_keys
+	), channels AS (
+		SELECT c.channel_id, c.short_channel_id, c.capacity,
+			CASE WHEN c.first_node_id = ANY(p.node_ids) THEN c.first_node_id ELSE c.second_node_id END AS node_id,
+			CASE WHEN c.first_node_id = ANY(p.node_ids) THEN c.second_node_id ELSE c.first_node_id END AS peer_node_id,
+			COALESCE(c.funded_on, c.created_on) AS opened_on,
+			c.closed_on,
+			c.funding_transaction_hash,
+			c.closing_transaction_hash
+		FROM channel c, params p
+		WHERE (c.first_node_id = ANY(p.node_ids) OR c.second_node_id = ANY(p.node_ids))
+			AND COALESCE(c.funded_on, c.created_on) <= p.to_time
+			AND (c.closed_on IS NULL OR c.closed_on >= p.from_time)
+	), revenue_out AS (
+		SELECT f.outgoing_channel_id AS channel_id, SUM(f.fee_msat) AS revenue_msat
+		FROM forward f, params p
+		WHERE f.node_id = ANY(p.node_ids) AND f.time::timestamptz >= p.from_time AND f.time::timestamptz <= p.to_time
+		GROUP BY f.outgoing_channel_id
+	), revenue_in AS (
+		SELECT f.incoming_channel_id AS channel_id, SUM(f.fee_msat) AS revenue_msat
+		FROM forward f, params p
+		WHERE f.node_id = ANY(p.node_ids) AND f.time::timestamptz >= p.from_time AND f.time::timestamptz <= p.to_time
+		GROUP BY f.incoming_channel_id
+	), rebalances AS (
+		-- Like the channel history, a rebalance is a payment of which the last hop is one of our nodes
+		SELECT pm.outgoing_channel_id, pm.incoming_channel_id, pm.fee_msat
+		FROM payment pm, params p
+		WHERE pm.status = 'SUCCEEDED' AND pm.node_id = ANY(p.node_ids)
+			AND pm.htlcs->-1->'route'->'hops'->-1->>'pub_key' = ANY(p.public_keys)
+			AND pm.creation_timestamp >= p.from_time AND pm.creation_timestamp <= p.to_time
+	), rebalancing AS (
+		SELECT channel_id, SUM(fee_msat) / 2 AS cost_msat
+		FROM (
+			SELECT outgoing_channel_id AS channel_id, fee_msat
+			FROM rebalances
+			UNION ALL
+			SELECT incoming_channel_id AS channel_id, fee_msat
+			FROM rebalances
+		) AS rebalance_sides
+		GROUP BY channel_id
+	), on_chain AS (
+		-- Batch opens and closes share one transaction, the fee is split between the channels
+		SELECT ch.channel_id, SUM(t.total_fees / (
+				SELECT COUNT(*)
+				FROM channel c2
+				WHERE c2.funding_transaction_hash = t.tx_hash OR c2.closing_transaction_hash = t.tx_hash
+			)) AS cost_sat
+		FROM channels ch
+		JOIN tx t ON t.node_id = ch.node_id
+			AND t.tx_hash IN (ch.funding_transaction_hash, ch.closing_transaction_hash)
+			AND t.total_fees > 0
+		GROUP BY ch.channel_id
+	), channel_periods AS (
+		SELECT ch.*,
+			GREATEST(EXTRACT(EPOCH FROM (LEAST(COALESCE(ch.closed_on, now()), p.to_time) - GREATEST(ch.opened_on, p.from_time))), 0) AS active_seconds,
+			GREATEST(EXTRACT(EPOCH FROM (COALESCE(ch.closed_on, now()) - ch.opened_on)), 1) AS lifetime_seconds
+		FROM channels ch, params p
+	), channel_profit_and_loss AS (
+		SELECT cp.channel_id, cp.short_channel_id, cp.node_id, cp.peer_node_id, COALESCE(cp.capacity, 0) AS capacity,
+			COALESCE(ro.revenue_msat, 0) AS revenue_msat,
+			COALESCE(ri.revenue_msat, 0) AS revenue_in_msat,
+			COALESCE(r.cost_msat, 0) AS rebalancing_cost_msat,
+			COALESCE(oc.cost_sat, 0) * 1000 * cp.active_seconds / cp.lifetime_seconds AS on_chain_cost_msat,
+			COALESCE(cp.capacity, 0) * cp.active_seconds / 86400 AS capital_deployed_sat_days
+		FROM channel_periods cp
+		LEFT JOIN revenue_out ro ON ro.channel_id = cp.channel_id
+		LEFT JOIN revenue_in ri ON ri.channel_id = cp.channel_id
+		LEFT JOIN rebalancing r ON r.channel_id = cp.channel_id
+		LEFT JOIN on_chain oc ON oc.channel_id = cp.channel_id
+	)`
+
+// groupSql maps the channel rows (alias cpl) to the requested grouping, a channel can be part of several tags.
+// Node tags are only set on peers, so matching them on either side of the channel is safe.
+var groupSql = map[GroupBy]struct { //nolint:gochecknoglobals
+	columns string
+	joins   string
+	groupBy string
+}{
+	GroupByChannel: {
+		columns: "cpl.channel_id AS id, cpl.short_channel_id AS name",
+		groupBy: "cpl.channel_id, cpl.short_channel_id",
+	},
+	GroupByPeer: {
+		columns: "cpl.peer_node_id AS id, COALESCE(MAX(ne.alias), MAX(n.public_key)) AS name",
+		joins: `
+			JOIN node n ON n.node_id = cpl.peer_node_id
+			LEFT JOIN LATERAL (
+				SELECT alias
+				FROM node_event
+				WHERE event_node_id = cpl.peer_node_id
+				ORDER BY timestamp DESC
+				LIMIT 1
+			) ne ON true`,
+		groupBy: "cpl.peer_node_id",
+	},
+	GroupByTag: {
+		columns: "t.tag_id AS id, t.name AS name",
+		joins: `
+			JOIN (
+				SELECT DISTINCT te.tag_id, c.channel_id
+				FROM tagged_entity te
+				JOIN channel c ON c.channel_id = te.channel_id
+					OR te.node_id = c.first_node_id OR te.node_id = c.second_node_id
+			) tc ON tc.channel_id = cpl.channel_id
+			JOIN tag t ON t.tag_id = tc.tag_id`,
+		groupBy: "t.tag_id, t.name",
+	},
+	GroupByCategory: {
+		columns: "cat.category_id AS id, cat.name AS name",
+		joins: `
+			JOIN (
+				SELECT DISTINCT t.category_id, c.channel_id
+				FROM tagged_entity te
+				JOIN tag t ON t.tag_id = te.tag_id
+				JOIN channel c ON c.channel_id = te.channel_id
+					OR te.node_id = c.first_node_id OR te.node_id = c.second_node_id
+			) cc ON cc.channel_id = cpl.channel_id
+			JOIN category cat ON cat.category_id = cc.category_id`,
+		groupBy: "cat.category_id, cat.name",
+	},
+}
+
+func getProfitAndLoss(db *sqlx.DB, nodeIds []int, from time.Time, to time.Time, groupBy GroupBy,
+	filter sq.Sqlizer, order []string) ([]ProfitAndLoss, error) {
+
+	var publicKeys []string
+	for _, nodeId := range nodeIds {
+		publicKeys = append(publicKeys, cache.GetNodeSettingsByNodeId(nodeId).PublicKey)
+	}
+
+	query, args, err := getProfitAndLossQuery(nodeIds, publicKeys, from, to, groupBy, filter, order)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []ProfitAndLoss{}
+	err = db.Select(&result, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return result, nil
+}
+
+func getProfitAndLossQuery(nodeIds []int, publicKeys []string, from time.Time, to time.Time, groupBy GroupBy,
+	filter sq.Sqlizer, order []string) (string, []interface{}, error) {
+
+	grouping, exists := groupSql[groupBy]
+	if !exists {
+		return "", nil, errors.Newf("unknown grouping %v", groupBy)
+	}
+
+	grouped := sq.Select(grouping.columns,
+		"COUNT(DISTINCT cpl.channel_id) AS channel_count",
+		"SUM(cpl.capacity)::bigint AS capacity",
+		"ROUND(SUM(cpl.revenue_msat))::bigint AS revenue_msat",
+		"ROUND(SUM(cpl.revenue_in_msat))::bigint AS revenue_in_msat",
+		"ROUND(SUM(cpl.rebalancing_cost_msat))::bigint AS rebalancing_cost_msat",
+		"ROUND(SUM(cpl.on_chain_cost_msat))::bigint AS on_chain_cost_msat",
+		"ROUND(SUM(cpl.rebalancing_cost_msat + cpl.on_chain_cost_msat))::bigint AS total_cost_msat",
+		"ROUND(SUM(cpl.revenue_msat - cpl.rebalancing_cost_msat - cpl.on_chain_cost_msat))::bigint AS profit_msat",
+		"SUM(cpl.capital_deployed_sat_days)::float AS capital_deployed_sat_days",
+		// profit in sat per sat-day, times 365 days
+		`CASE WHEN SUM(cpl.capital_deployed_sat_days) > 0
+			THEN (SUM(cpl.revenue_msat - cpl.rebalancing_cost_msat - cpl.on_chain_cost_msat) / 1000
+				/ SUM(cpl.capital_deployed_sat_days) * 365)::float
+		END AS annualized_roi`).
+		From("channel_profit_and_loss cpl" + grouping.joins).
+		GroupBy(grouping.groupBy)
+
+	qb := sq.Select("*").
+		PlaceholderFormat(sq.Dollar).
+		Prefix(channelProfitAndLossSql, pq.Array(nodeIds), from, to, pq.Array(publicKeys)).
+		FromSelect(grouped, "subquery")
+	if filter != nil {
+		qb = qb.Where(filter)
+	}
+	qb = qb.OrderBy(order...)
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "SQL generation")
+	}
+	return query, args, nil
+}
@@ -0,0 +1,192 @@
+package reports
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/lncapital/torq/testutil"
+)
+
+func TestGetProfitAndLossQuery(t *testing.T) {
+	from := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, time.January, 11, 0, 0, 0, 0, time.UTC)
+	query, args, err := getProfitAndLossQuery([]int{1}, []string{"pub"}, from, to, GroupByPeer,
+		sq.Eq{"name": "alias"}, []string{"profit_msat desc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 5 || args[4] != "alias" {
+		t.Fatalf("unexpected args %v", args)
+	}
+	if strings.Contains(query, "?") || !strings.Contains(query, "$5") || strings.Contains(query, "$6") {
+		t.Errorf("expected the placeholders $1 to $5, got %v", query)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(query), "WITH params AS") {
+		t.Errorf("expected the channel CTEs before the grouping, got %v", query)
+	}
+
+	_, _, err = getProfitAndLossQuery([]int{1}, []string{"pub"}, from, to, GroupBy("node"), nil, nil)
+	if err == nil {
+		t.Error("expected an error for an unknown grouping")
+	}
+}
+
+func getChannelIdByLndShortChannelId(t *testing.T, db *sqlx.DB, lndShortChannelId uint64) int {
+	var channelId int
+	err := db.Get(&channelId, "SELECT channel_id FROM channel WHERE lnd_short_channel_id=$1;", lndShortChannelId)
+	if err != nil {
+		t.Fatalf("obtaining channel id: %v", err)
+	}
+	return channelId
+}
+
+func addPayment(t *testing.T, db *sqlx.DB, nodeId int, paymentIndex int, created time.Time, feeMsat int64,
+	outgoingChannelId int, incomingChannelId int, hops string) {
+
+	_, err := db.Exec(`INSERT INTO payment (payment_hash, creation_timestamp, payment_preimage, value_msat,
+			payment_request, status, fee_msat, creation_time_ns, htlcs, payment_index, failure_reason,
+			incoming_channel_id, outgoing_channel_id, node_id, created_on)
+		VALUES ($1, $2, '', 100000000, '', 'SUCCEEDED', $3, 0, $4, $5, '', $6, $7, $8, $2);`,
+		fmt.Sprintf("hash%v", paymentIndex), created, feeMsat,
+		`[{"route":{"hops":`+hops+`}}]`, paymentIndex, incomingChannelId, outgoingChannelId, nodeId)
+	if err != nil {
+		t.Fatalf("inserting payment: %v", err)
+	}
+}
+
+func assertAlmostEqual(t *testing.T, description string, got float64, want float64) {
+	if math.Abs(got-want) > 0.000001*math.Max(1, math.Abs(want)) {
+		testutil.Errorf(t, "%v: got %v, want %v", description, got, want)
+	}
+}
+
+func TestGetProfitAndLoss(t *testing.T) {
+	srv, err := testutil.InitTestDBConn()
+	if err != nil {
+		panic(err)
+	}
+
+	db, cancel, err := srv.NewTestDatabase()
+	defer cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	nodeId, _ := testutil.Setup(db, cancel)
+	from := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, time.January, 11, 0, 0, 0, 0, time.UTC)
+	// Every channel is open for the whole 10 days of the date range
+	_, err = db.Exec("UPDATE channel SET funded_on=$1, closed_on=NULL;", from.AddDate(0, -1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	channel1 := getChannelIdByLndShortChannelId(t, db, 1111)
+	channel2 := getChannelIdByLndShortChannelId(t, db, 2222)
+	channel3 := getChannelIdByLndShortChannelId(t, db, 3333)
+	channel4 := getChannelIdByLndShortChannelId(t, db, 4444)
+	eventTime := from.AddDate(0, 0, 4)
+
+	_, err = db.Exec(`INSERT INTO forward (time, fee_msat, incoming_amount_msat, incoming_channel_id,
+			outgoing_amount_msat, outgoing_channel_id, node_id)
+		VALUES ($1, 1000000, 100000000, $2, 99000000, $3, $4);`, eventTime, channel2, channel1, nodeId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A rebalance from channel 3 to channel 1 returns to our own node
+	addPayment(t, db, nodeId, 1, eventTime, 200_000, channel3, channel1,
+		`[{"chan_id":"3333","pub_key":"`+testutil.TestPublicKey2+`"},`+
+			`{"chan_id":"1111","pub_key":"`+testutil.TestPublicKey1+`"}]`)
+	// A direct payment to the peer is not a rebalance even though the first and last hop are our channel
+	addPayment(t, db, nodeId, 2, eventTime, 50_000, channel4, channel4,
+		`[{"chan_id":"4444","pub_key":"`+testutil.TestPublicKey2+`"}]`)
+
+	var categoryId int
+	err = db.Get(&categoryId, `INSERT INTO category (name, style, created_on, updated_on)
+		VALUES ('test', 'test', $1, $1) RETURNING category_id;`, time.Now().UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tagId int
+	err = db.Get(&tagId, `INSERT INTO tag (name, style, category_id, created_on, updated_on)
+		VALUES ('test', 'test', $1, $2, $2) RETURNING tag_id;`, categoryId, time.Now().UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, channelId := range []int{channel1, channel3} {
+		_, err = db.Exec(`INSERT INTO tagged_entity (tag_id, channel_id, created_on) VALUES ($1, $2, $3);`,
+			tagId, channelId, time.Now().UTC())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	getRows := func(groupBy GroupBy) map[int]ProfitAndLoss {
+		result, err := getProfitAndLoss(db, []int{nodeId}, from, to, groupBy, nil, []string{"id"})
+		if err != nil {
+			testutil.Fatalf(t, "getProfitAndLoss(%v): %v", groupBy, err)
+		}
+		rows := make(map[int]ProfitAndLoss)
+		for _, row := range result {
+			rows[row.Id] = row
+		}
+		return rows
+	}
+
+	testutil.Given(t, "the profit and loss per channel")
+	channels := getRows(GroupByChannel)
+	if len(channels) != 4 {
+		testutil.Fatalf(t, "expected 4 channels, got %v", len(channels))
+	}
+	if channels[channel1].RevenueMsat != 1_000_000 || channels[channel2].RevenueInMsat != 1_000_000 {
+		testutil.Errorf(t, "unexpected revenue %+v %+v", channels[channel1], channels[channel2])
+	}
+	if channels[channel1].RebalancingCostMsat != 100_000 || channels[channel3].RebalancingCostMsat != 100_000 {
+		testutil.Errorf(t, "expected the rebalancing cost split over both channels, got %v and %v",
+			channels[channel1].RebalancingCostMsat, channels[channel3].RebalancingCostMsat)
+	}
+	if channels[channel4].RebalancingCostMsat != 0 {
+		testutil.Errorf(t, "expected no rebalancing cost for a direct payment, got %v",
+			channels[channel4].RebalancingCostMsat)
+	}
+	if channels[channel1].ProfitMsat != 900_000 || channels[channel3].ProfitMsat != -100_000 {
+		testutil.Errorf(t, "unexpected profit %v and %v", channels[channel1].ProfitMsat, channels[channel3].ProfitMsat)
+	}
+	// 1_000_000 sat capacity for 10 days
+	assertAlmostEqual(t, "capital deployed", channels[channel1].CapitalDeployedSatDays, 10_000_000)
+	// 900 sat profit on 10_000_000 sat-days, times 365 days
+	assertAlmostEqual(t, "annualized ROI", *channels[channel1].AnnualizedRoi, 900.0/10_000_000*365)
+	testutil.Successf(t, "revenue, costs and the annualized ROI are calculated per channel")
+
+	testutil.Given(t, "the profit and loss per peer")
+	peers := getRows(GroupByPeer)
+	if len(peers) != 1 {
+		testutil.Fatalf(t, "expected 1 peer, got %v", len(peers))
+	}
+	for _, peer := range peers {
+		if peer.ChannelCount != 4 || peer.ProfitMsat != 800_000 || peer.RebalancingCostMsat != 200_000 {
+			testutil.Errorf(t, "unexpected peer %+v", peer)
+		}
+		assertAlmostEqual(t, "peer annualized ROI", *peer.AnnualizedRoi, 800.0/40_000_000*365)
+	}
+	testutil.Successf(t, "the channels are grouped by peer")
+
+	testutil.Given(t, "the profit and loss per tag and category")
+	for groupBy, id := range map[GroupBy]int{GroupByTag: tagId, GroupByCategory: categoryId} {
+		row, exists := getRows(groupBy)[id]
+		if !exists {
+			testutil.Fatalf(t, "expected a row for %v %v", groupBy, id)
+		}
+		if row.ChannelCount != 2 || row.ProfitMsat != 800_000 || row.Name == nil || *row.Name != "test" {
+			testutil.Errorf(t, "unexpected %v row %+v", groupBy, row)
+		}
+		assertAlmostEqual(t, string(groupBy)+" capital deployed", row.CapitalDeployedSatDays, 20_000_000)
+	}
+	testutil.Successf(t, "the tagged channels are grouped by tag and category")
+}
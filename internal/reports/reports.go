@@ -0,0 +1,338 @@
+package reports
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/database"
+)
+
+type Period string
+
+const (
+	PeriodMonth   = Period("month")
+	PeriodQuarter = Period("quarter")
+	PeriodYear    = Period("year")
+)
+
+type LineType string
+
+const (
+	LineTypeIncome  = LineType("income")
+	LineTypeExpense = LineType("expense")
+	// Transfers are value moving in or out of the node, they are reported but not part of the net income
+	LineTypeTransfer = LineType("transfer")
+)
+
+const (
+	lineRoutingRevenue   = "routing_revenue"
+	lineRebalancingFees  = "rebalancing_fees"
+	linePaymentFees      = "payment_fees"
+	lineChannelOpenFees  = "channel_open_fees"
+	lineChannelCloseFees = "channel_close_fees"
+	lineOtherOnChainFees = "other_on_chain_fees"
+	lineInvoicesReceived = "invoices_received"
+	linePaymentsSent     = "payments_sent"
+)
+
+// statementLines is also the order in which the lines are reported
+var statementLines = []struct { //nolint:gochecknoglobals
+	name     string
+	lineType LineType
+}{
+	{lineRoutingRevenue, LineTypeIncome},
+	{lineRebalancingFees, LineTypeExpense},
+	{linePaymentFees, LineTypeExpense},
+	{lineChannelOpenFees, LineTypeExpense},
+	{lineChannelCloseFees, LineTypeExpense},
+	{lineOtherOnChainFees, LineTypeExpense},
+	{lineInvoicesReceived, LineTypeTransfer},
+	{linePaymentsSent, LineTypeTransfer},
+}
+
+const msatPerBtc = 100_000_000_000
+
+type dailyAmount struct {
+	NodeId     int       `db:"node_id"`
+	Day        time.Time `db:"day"`
+	Line       string    `db:"line"`
+	AmountMsat int64     `db:"amount_msat"`
+	// Price of 1 BTC on that day (or the closest day before it), nil when no price is known
+	Price *float64 `db:"price"`
+}
+
+type StatementLine struct {
+	Line       string   `json:"line"`
+	Type       LineType `json:"type"`
+	AmountMsat int64    `json:"amountMsat"`
+	AmountFiat *float64 `json:"amountFiat"`
+}
+
+type Statement struct {
+	Period      string    `json:"period"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	// NodeId is nil for the total over all nodes
+	NodeId        *int            `json:"nodeId"`
+	NodeName      string          `json:"nodeName"`
+	Lines         []StatementLine `json:"lines"`
+	IncomeMsat    int64           `json:"incomeMsat"`
+	ExpenseMsat   int64           `json:"expenseMsat"`
+	NetIncomeMsat int64           `json:"netIncomeMsat"`
+	IncomeFiat    *float64        `json:"incomeFiat"`
+	ExpenseFiat   *float64        `json:"expenseFiat"`
+	NetIncomeFiat *float64        `json:"netIncomeFiat"`
+	// MissingPrices is true when one or more days in the period had no fiat price
+	MissingPrices bool `json:"missingPrices"`
+}
+
+type IncomeStatementReport struct {
+	From       time.Time   `json:"from"`
+	To         time.Time   `json:"to"`
+	Period     Period      `json:"period"`
+	Currency   string      `json:"currency"`
+	Statements []Statement `json:"statements"`
+}
+
+func getDailyAmounts(db *sqlx.DB, nodeIds []int, from time.Time, to time.Time, currency string) ([]dailyAmount, error) {
+	var publicKeys []string
+	for _, nodeId := range nodeIds {
+		publicKeys = append(publicKeys, cache.GetNodeSettingsByNodeId(nodeId).PublicKey)
+	}
+
+	// Rebalances are payments where the last hop is one of our own nodes (see getRebalancingCost in channel_history)
+	q := `
+		WITH amounts AS (
+			SELECT node_id, (time::timestamptz AT TIME ZONE $4)::date AS day,
+				'` + lineRoutingRevenue + `' AS line,
+				SUM(fee_msat) AS amount_msat
+			FROM forward
+			WHERE node_id = ANY($1) AND (time::timestamptz AT TIME ZONE $4)::date BETWEEN $2 AND $3
+			GROUP BY 1, 2
+		UNION ALL
+			SELECT node_id, (creation_timestamp AT TIME ZONE $4)::date AS day,
+				CASE WHEN htlcs->-1->'route'->'hops'->-1->>'pub_key' = ANY($5)
+					THEN '` + lineRebalancingFees + `'
+					ELSE '` + linePaymentFees + `'
+				END AS line,
+				SUM(fee_msat) AS amount_msat
+			FROM payment
+			WHERE status = 'SUCCEEDED' AND node_id = ANY($1)
+				AND (creation_timestamp AT TIME ZONE $4)::date BETWEEN $2 AND $3
+			GROUP BY 1, 2, 3
+		UNION ALL
+			SELECT node_id, (creation_timestamp AT TIME ZONE $4)::date AS day,
+				'` + linePaymentsSent + `' AS line,
+				SUM(value_msat) AS amount_msat
+			FROM payment
+			WHERE status = 'SUCCEEDED' AND node_id = ANY($1)
+				AND (creation_timestamp AT TIME ZONE $4)::date BETWEEN $2 AND $3
+				AND (htlcs->-1->'route'->'hops'->-1->>'pub_key' IS NULL
+					OR NOT htlcs->-1->'route'->'hops'->-1->>'pub_key' = ANY($5))
+			GROUP BY 1, 2
+		UNION ALL
+			SELECT node_id, (timestamp::timestamptz AT TIME ZONE $4)::date AS day,
+				CASE WHEN label ~ '\d{1,}:openchannel' THEN '` + lineChannelOpenFees + `'
+					WHEN label ~ '\d{1,}:closechannel' THEN '` + lineChannelCloseFees + `'
+					ELSE '` + lineOtherOnChainFees + `'
+				END AS line,
+				SUM(total_fees) * 1000 AS amount_msat
+			FROM tx
+			WHERE node_id = ANY($1) AND total_fees > 0
+				AND (timestamp::timestamptz AT TIME ZONE $4)::date BETWEEN $2 AND $3
+			GROUP BY 1, 2, 3
+		UNION ALL
+			SELECT i.node_id, (i.settle_date AT TIME ZONE $4)::date AS day,
+				'` + lineInvoicesReceived + `' AS line,
+				SUM(i.amt_paid_msat) AS amount_msat
+			FROM invoice i
+			WHERE i.invoice_state = 'SETTLED' AND i.node_id = ANY($1)
+				AND (i.settle_date AT TIME ZONE $4)::date BETWEEN $2 AND $3
+				-- Invoices paid by our own rebalances are not received funds
+				AND NOT EXISTS (
+					SELECT 1
+					FROM payment p
+					WHERE p.payment_hash = i.r_hash AND p.status = 'SUCCEEDED' AND p.node_id = ANY($1)
+				)
+			GROUP BY 1, 2
+		)
+		SELECT a.node_id, a.day::timestamp AS day, a.line, ROUND(SUM(a.amount_msat))::bigint AS amount_msat, fp.price
+		FROM amounts a
+		LEFT JOIN LATERAL (
+			SELECT price
+			FROM fiat_price
+			WHERE currency = $6 AND date <= a.day
+			ORDER BY date DESC
+			LIMIT 1
+		) fp ON true
+		GROUP BY a.node_id, a.day, a.line, fp.price
+		ORDER BY a.day, a.node_id, a.line;`
+
+	var amounts []dailyAmount
+	err := db.Select(&amounts, q, pq.Array(nodeIds), from, to, cache.GetSettings().PreferredTimeZone,
+		pq.Array(publicKeys), currency)
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return amounts, nil
+}
+
+func getIncomeStatementReport(db *sqlx.DB, nodeIds []int, from time.Time, to time.Time,
+	period Period, currency string) (IncomeStatementReport, error) {
+
+	amounts, err := getDailyAmounts(db, nodeIds, from, to, currency)
+	if err != nil {
+		return IncomeStatementReport{}, err
+	}
+
+	nodeNames := make(map[int]string)
+	for _, nodeId := range nodeIds {
+		nodeSettings := cache.GetNodeSettingsByNodeId(nodeId)
+		if nodeSettings.Name != nil {
+			nodeNames[nodeId] = *nodeSettings.Name
+		} else {
+			nodeNames[nodeId] = nodeSettings.PublicKey
+		}
+	}
+
+	return IncomeStatementReport{
+		From:       from,
+		To:         to,
+		Period:     period,
+		Currency:   currency,
+		Statements: buildStatements(amounts, period, nodeNames, currency != ""),
+	}, nil
+}
+
+// buildStatements rolls the daily amounts up into one statement per period per node and one total per period.
+func buildStatements(amounts []dailyAmount, period Period, nodeNames map[int]string, withFiat bool) []Statement {
+	type statementKey struct {
+		periodStart time.Time
+		nodeId      int
+	}
+	// nodeId 0 is used for the total
+	statements := make(map[statementKey]*Statement)
+	getStatement := func(periodStart time.Time, nodeId int) *Statement {
+		key := statementKey{periodStart: periodStart, nodeId: nodeId}
+		statement, exists := statements[key]
+		if !exists {
+			statement = &Statement{
+				Period:      getPeriodLabel(periodStart, period),
+				PeriodStart: periodStart,
+				PeriodEnd:   getPeriodEnd(periodStart, period),
+				NodeName:    "Total",
+			}
+			if nodeId != 0 {
+				id := nodeId
+				statement.NodeId = &id
+				statement.NodeName = nodeNames[nodeId]
+			}
+			for _, line := range statementLines {
+				statementLine := StatementLine{Line: line.name, Type: line.lineType}
+				if withFiat {
+					statementLine.AmountFiat = new(float64)
+				}
+				statement.Lines = append(statement.Lines, statementLine)
+			}
+			statements[key] = statement
+		}
+		return statement
+	}
+
+	for _, amount := range amounts {
+		periodStart := getPeriodStart(amount.Day, period)
+		for _, statement := range []*Statement{getStatement(periodStart, amount.NodeId), getStatement(periodStart, 0)} {
+			for i := range statement.Lines {
+				if statement.Lines[i].Line != amount.Line {
+					continue
+				}
+				statement.Lines[i].AmountMsat += amount.AmountMsat
+				if withFiat {
+					if amount.Price == nil {
+						statement.MissingPrices = true
+					} else {
+						*statement.Lines[i].AmountFiat += float64(amount.AmountMsat) / msatPerBtc * *amount.Price
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]Statement, 0, len(statements))
+	for _, statement := range statements {
+		if withFiat {
+			statement.IncomeFiat = new(float64)
+			statement.ExpenseFiat = new(float64)
+			statement.NetIncomeFiat = new(float64)
+		}
+		for _, line := range statement.Lines {
+			switch line.Type {
+			case LineTypeIncome:
+				statement.IncomeMsat += line.AmountMsat
+				if withFiat {
+					*statement.IncomeFiat += *line.AmountFiat
+				}
+			case LineTypeExpense:
+				statement.ExpenseMsat += line.AmountMsat
+				if withFiat {
+					*statement.ExpenseFiat += *line.AmountFiat
+				}
+			}
+		}
+		statement.NetIncomeMsat = statement.IncomeMsat - statement.ExpenseMsat
+		if withFiat {
+			*statement.NetIncomeFiat = *statement.IncomeFiat - *statement.ExpenseFiat
+		}
+		result = append(result, *statement)
+	}
+
+	// Per period: the nodes in order followed by the total
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].PeriodStart.Equal(result[j].PeriodStart) {
+			return result[i].PeriodStart.Before(result[j].PeriodStart)
+		}
+		if result[i].NodeId == nil || result[j].NodeId == nil {
+			return result[j].NodeId == nil && result[i].NodeId != nil
+		}
+		return *result[i].NodeId < *result[j].NodeId
+	})
+	return result
+}
+
+func getPeriodStart(day time.Time, period Period) time.Time {
+	switch period {
+	case PeriodYear:
+		return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	case PeriodQuarter:
+		month := time.Month((int(day.Month())-1)/3*3 + 1)
+		return time.Date(day.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// getPeriodEnd returns the last day of the period
+func getPeriodEnd(periodStart time.Time, period Period) time.Time {
+	switch period {
+	case PeriodYear:
+		return periodStart.AddDate(1, 0, -1)
+	case PeriodQuarter:
+		return periodStart.AddDate(0, 3, -1)
+	}
+	return periodStart.AddDate(0, 1, -1)
+}
+
+func getPeriodLabel(periodStart time.Time, period Period) string {
+	switch period {
+	case PeriodYear:
+		return fmt.Sprintf("%d", periodStart.Year())
+	case PeriodQuarter:
+		return fmt.Sprintf("%d-Q%d", periodStart.Year(), (int(periodStart.Month())-1)/3+1)
+	}
+	return periodStart.Format("2006-01")
+}
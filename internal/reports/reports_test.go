@@ -0,0 +1,84 @@
+package reports
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetPeriodStart(t *testing.T) {
+	day := time.Date(2023, time.August, 17, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		period    Period
+		wantStart time.Time
+		wantEnd   time.Time
+		wantLabel string
+	}{
+		{PeriodMonth, time.Date(2023, time.August, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, time.August, 31, 0, 0, 0, 0, time.UTC), "2023-08"},
+		{PeriodQuarter, time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, time.September, 30, 0, 0, 0, 0, time.UTC), "2023-Q3"},
+		{PeriodYear, time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC), "2023"},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.period), func(t *testing.T) {
+			start := getPeriodStart(day, tc.period)
+			if !start.Equal(tc.wantStart) {
+				t.Errorf("start: got %v, want %v", start, tc.wantStart)
+			}
+			if end := getPeriodEnd(start, tc.period); !end.Equal(tc.wantEnd) {
+				t.Errorf("end: got %v, want %v", end, tc.wantEnd)
+			}
+			if label := getPeriodLabel(start, tc.period); label != tc.wantLabel {
+				t.Errorf("label: got %v, want %v", label, tc.wantLabel)
+			}
+		})
+	}
+}
+
+func TestBuildStatements(t *testing.T) {
+	price := 20000.0
+	day := time.Date(2023, time.January, 5, 0, 0, 0, 0, time.UTC)
+	amounts := []dailyAmount{
+		{NodeId: 1, Day: day, Line: lineRoutingRevenue, AmountMsat: 5_000_000, Price: &price},
+		{NodeId: 1, Day: day, Line: lineRebalancingFees, AmountMsat: 1_000_000, Price: &price},
+		{NodeId: 2, Day: day, Line: lineRoutingRevenue, AmountMsat: 3_000_000, Price: &price},
+		{NodeId: 2, Day: day, Line: lineInvoicesReceived, AmountMsat: 100_000_000, Price: nil},
+	}
+	statements := buildStatements(amounts, PeriodMonth, map[int]string{1: "alpha", 2: "beta"}, true)
+	if len(statements) != 3 {
+		t.Fatalf("got %v statements, want 3", len(statements))
+	}
+	if statements[0].NodeName != "alpha" || statements[1].NodeName != "beta" || statements[2].NodeId != nil {
+		t.Fatalf("unexpected statement order %v, %v, %v",
+			statements[0].NodeName, statements[1].NodeName, statements[2].NodeName)
+	}
+	total := statements[2]
+	if total.IncomeMsat != 8_000_000 || total.ExpenseMsat != 1_000_000 || total.NetIncomeMsat != 7_000_000 {
+		t.Errorf("unexpected totals %v %v %v", total.IncomeMsat, total.ExpenseMsat, total.NetIncomeMsat)
+	}
+	// 7_000_000 msat = 0.00007 BTC
+	if *total.NetIncomeFiat < 1.3999 || *total.NetIncomeFiat > 1.4001 {
+		t.Errorf("got net income fiat %v, want 1.4", *total.NetIncomeFiat)
+	}
+	// Transfers without a price mark the statement but do not affect the net income
+	if statements[0].MissingPrices || !statements[1].MissingPrices || !total.MissingPrices {
+		t.Errorf("unexpected missing prices flags")
+	}
+}
+
+func TestParseFiatPriceCsv(t *testing.T) {
+	prices, err := parseFiatPriceCsv(strings.NewReader("date;price\n2023-01-01;16500.5\n2023-01-02; 16600\n"), "eur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prices) != 2 || prices[0].Currency != "EUR" || prices[1].Price != 16600 {
+		t.Errorf("unexpected prices %v", prices)
+	}
+
+	_, err = parseFiatPriceCsv(strings.NewReader("2023-01-01,abc\n"), "usd")
+	if err == nil {
+		t.Errorf("expected an error for an invalid price")
+	}
+}
@@ -0,0 +1,20 @@
+package reports
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func RegisterReportsRoutes(r *gin.RouterGroup, db *sqlx.DB) {
+	r.GET("income-statement", func(c *gin.Context) { getIncomeStatementHandler(c, db) })
+	r.GET("profit-and-loss", func(c *gin.Context) { getProfitAndLossHandler(c, db) })
+
+	prices := r.Group("prices")
+	{
+		prices.GET("", func(c *gin.Context) { getFiatCurrenciesHandler(c, db) })
+		prices.GET(":currency", func(c *gin.Context) { getFiatPricesHandler(c, db) })
+		prices.POST(":currency", func(c *gin.Context) { setFiatPricesHandler(c, db) })
+		prices.POST(":currency/import", func(c *gin.Context) { importFiatPricesHandler(c, db) })
+		prices.DELETE(":currency", func(c *gin.Context) { removeFiatPricesHandler(c, db) })
+	}
+}
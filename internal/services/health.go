@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/lncapital/torq/build"
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/services_helpers"
+)
+
+const healthCheckTimeout = 5 * time.Second
+
+type ReadinessSettings struct {
+	// MaxNodesDown is the number of nodes that can be down while Torq is still reported as ready
+	MaxNodesDown int
+}
+
+var readinessSettings struct { //nolint:gochecknoglobals
+	mu       sync.RWMutex
+	settings ReadinessSettings
+}
+
+func SetReadinessSettings(settings ReadinessSettings) {
+	readinessSettings.mu.Lock()
+	defer readinessSettings.mu.Unlock()
+	readinessSettings.settings = settings
+}
+
+func getReadinessSettings() ReadinessSettings {
+	readinessSettings.mu.RLock()
+	defer readinessSettings.mu.RUnlock()
+	return readinessSettings.settings
+}
+
+type Liveness struct {
+	Alive   bool   `json:"alive"`
+	Version string `json:"version"`
+}
+
+type ServiceHealth struct {
+	ServiceType       services_helpers.ServiceType   `json:"type"`
+	ServiceTypeString string                         `json:"typeString"`
+	Status            services_helpers.ServiceStatus `json:"status"`
+	StatusString      string                         `json:"statusString"`
+	// Required services must be active for the node to be ready
+	Required bool `json:"required"`
+	Ready    bool `json:"ready"`
+}
+
+type DatabaseHealth struct {
+	Ready bool   `json:"ready"`
+	Error string `json:"error,omitempty"`
+}
+
+type NodeHealth struct {
+	NodeId         int                 `json:"nodeId"`
+	Implementation core.Implementation `json:"implementation"`
+	Ready          bool                `json:"ready"`
+	Services       []ServiceHealth     `json:"services"`
+}
+
+type Readiness struct {
+	Ready        bool           `json:"ready"`
+	ShuttingDown bool           `json:"shuttingDown"`
+	RootService  ServiceHealth  `json:"rootService"`
+	Database     DatabaseHealth `json:"database"`
+	Nodes        []NodeHealth   `json:"nodes"`
+	NodesDown    int            `json:"nodesDown"`
+	MaxNodesDown int            `json:"maxNodesDown"`
+}
+
+// RegisterHealthRoutes registers the liveness (healthz) and readiness (readyz) probes, they don't require
+// authentication.
+func RegisterHealthRoutes(r gin.IRoutes, db *sqlx.DB) {
+	r.GET("healthz", healthzHandler)
+	r.GET("readyz", func(c *gin.Context) { readyzHandler(c, db) })
+}
+
+// healthzHandler reports Torq as alive as long as the service cache responds. The database and the nodes are left
+// out on purpose, restarting Torq doesn't fix those.
+func healthzHandler(c *gin.Context) {
+	responded := make(chan struct{}, 1)
+	go func() {
+		cache.GetCurrentCoreServiceState(services_helpers.RootService)
+		responded <- struct{}{}
+	}()
+	select {
+	case <-responded:
+		c.JSON(http.StatusOK, Liveness{Alive: true, Version: build.ExtendedVersion()})
+	case <-time.After(healthCheckTimeout):
+		c.JSON(http.StatusServiceUnavailable, Liveness{Alive: false, Version: build.ExtendedVersion()})
+	}
+}
+
+func readyzHandler(c *gin.Context, db *sqlx.DB) {
+	readiness := getReadiness(c.Request.Context(), db)
+	if !readiness.Ready {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+	c.JSON(http.StatusOK, readiness)
+}
+
+func getReadiness(ctx context.Context, db *sqlx.DB) Readiness {
+	readiness := Readiness{
+		ShuttingDown: cache.IsShuttingDown(),
+		RootService: newServiceHealth(services_helpers.RootService,
+			cache.GetCurrentCoreServiceState(services_helpers.RootService).Status, true),
+		Nodes:        []NodeHealth{},
+		MaxNodesDown: getReadinessSettings().MaxNodesDown,
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		readiness.Database.Error = err.Error()
+	} else {
+		readiness.Database.Ready = true
+	}
+
+	for _, nodeId := range cache.GetLndNodeIds() {
+		if nodeHealth, monitored := getNodeHealth(nodeId, core.LND, services_helpers.GetLndServiceTypes()); monitored {
+			readiness.Nodes = append(readiness.Nodes, nodeHealth)
+		}
+	}
+	for _, nodeId := range cache.GetClnNodeIds() {
+		if nodeHealth, monitored := getNodeHealth(nodeId, core.CLN, services_helpers.GetClnServiceTypes()); monitored {
+			readiness.Nodes = append(readiness.Nodes, nodeHealth)
+		}
+	}
+	readiness.evaluate()
+	return readiness
+}
+
+// getNodeHealth only reports the services that should be running, a node without any is not monitored.
+func getNodeHealth(nodeId int,
+	implementation core.Implementation,
+	serviceTypes []services_helpers.ServiceType) (NodeHealth, bool) {
+
+	nodeHealth := NodeHealth{NodeId: nodeId, Implementation: implementation}
+	for _, serviceType := range serviceTypes {
+		if cache.GetDesiredNodeServiceState(serviceType, nodeId).Status != services_helpers.Active {
+			continue
+		}
+		nodeHealth.Services = append(nodeHealth.Services, newServiceHealth(serviceType,
+			cache.GetCurrentNodeServiceState(serviceType, nodeId).Status, !isExternalService(serviceType)))
+	}
+	if len(nodeHealth.Services) == 0 {
+		return NodeHealth{}, false
+	}
+	nodeHealth.Ready = isNodeReady(nodeHealth.Services)
+	return nodeHealth, true
+}
+
+func newServiceHealth(serviceType services_helpers.ServiceType,
+	status services_helpers.ServiceStatus,
+	required bool) ServiceHealth {
+
+	return ServiceHealth{
+		ServiceType:       serviceType,
+		ServiceTypeString: serviceType.String(),
+		Status:            status,
+		StatusString:      status.String(),
+		Required:          required,
+		Ready:             status == services_helpers.Active,
+	}
+}
+
+// isExternalService is true for the services that talk to a third party, the node works fine without them.
+func isExternalService(serviceType services_helpers.ServiceType) bool {
+	switch serviceType {
+	case services_helpers.LndServiceVectorService, services_helpers.LndServiceAmbossService,
+		services_helpers.ClnServiceVectorService, services_helpers.ClnServiceAmbossService:
+		return true
+	}
+	return false
+}
+
+func isNodeReady(services []ServiceHealth) bool {
+	for _, service := range services {
+		if service.Required && !service.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+func (readiness *Readiness) evaluate() {
+	readiness.NodesDown = 0
+	for _, node := range readiness.Nodes {
+		if !node.Ready {
+			readiness.NodesDown++
+		}
+	}
+	readiness.Ready = !readiness.ShuttingDown &&
+		readiness.RootService.Ready &&
+		readiness.Database.Ready &&
+		readiness.NodesDown <= readiness.MaxNodesDown
+}
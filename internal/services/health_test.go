@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/services_helpers"
+)
+
+func testNode(nodeId int, streamStatus services_helpers.ServiceStatus,
+	vectorStatus services_helpers.ServiceStatus) NodeHealth {
+
+	services := []ServiceHealth{
+		newServiceHealth(services_helpers.LndServiceChannelEventStream, streamStatus,
+			!isExternalService(services_helpers.LndServiceChannelEventStream)),
+		newServiceHealth(services_helpers.LndServiceVectorService, vectorStatus,
+			!isExternalService(services_helpers.LndServiceVectorService)),
+	}
+	return NodeHealth{NodeId: nodeId, Implementation: core.LND, Ready: isNodeReady(services), Services: services}
+}
+
+func TestReadinessEvaluate(t *testing.T) {
+	readiness := Readiness{
+		RootService: newServiceHealth(services_helpers.RootService, services_helpers.Active, true),
+		Database:    DatabaseHealth{Ready: true},
+		Nodes: []NodeHealth{
+			// A failing external service doesn't take the node down
+			testNode(1, services_helpers.Active, services_helpers.Pending),
+			testNode(2, services_helpers.Pending, services_helpers.Active),
+		},
+	}
+	readiness.evaluate()
+	if readiness.Ready || readiness.NodesDown != 1 {
+		t.Errorf("expected not ready with 1 node down, got ready: %v with %v nodes down",
+			readiness.Ready, readiness.NodesDown)
+	}
+
+	readiness.MaxNodesDown = 1
+	readiness.evaluate()
+	if !readiness.Ready {
+		t.Error("expected ready when one node is allowed to be down")
+	}
+
+	readiness.Database = DatabaseHealth{Error: "connection refused"}
+	readiness.evaluate()
+	if readiness.Ready {
+		t.Error("expected not ready without database")
+	}
+
+	readiness.Database = DatabaseHealth{Ready: true}
+	readiness.RootService = newServiceHealth(services_helpers.RootService, services_helpers.Initializing, true)
+	readiness.evaluate()
+	if readiness.Ready {
+		t.Error("expected not ready while the root service is initializing")
+	}
+
+	readiness.RootService = newServiceHealth(services_helpers.RootService, services_helpers.Active, true)
+	readiness.ShuttingDown = true
+	readiness.evaluate()
+	if readiness.Ready {
+		t.Error("expected not ready while shutting down")
+	}
+}
@@ -42,6 +42,14 @@ const (
 	ClnServicePaymentsService
 	ClnServiceHtlcsService
 	ClnServiceTransactionsService
+	ForecastService
+	DatabaseService
+	LndServiceGraphSnapshotService
+	ClnServiceGraphSnapshotService
+	PeerReliabilityService
+	PeerReconnectService
+	FeeEstimateService
+	ForceCloseMonitorService
 )
 
 type ServiceStatus int
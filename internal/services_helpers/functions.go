@@ -26,12 +26,18 @@ func (s *ServiceStatus) String() string {
 func GetCoreServiceTypes() []ServiceType {
 	return []ServiceType{
 		RootService,
+		DatabaseService,
 		MaintenanceService,
 		AutomationIntervalTriggerService,
 		AutomationChannelBalanceEventTriggerService,
 		AutomationChannelEventTriggerService,
 		AutomationScheduledTriggerService,
 		CronService,
+		ForecastService,
+		PeerReliabilityService,
+		PeerReconnectService,
+		FeeEstimateService,
+		ForceCloseMonitorService,
 		NotifierService,
 		SlackService,
 		TelegramHighService,
@@ -54,6 +60,7 @@ func GetLndServiceTypes() []ServiceType {
 		LndServicePeerEventStream,
 		LndServiceInFlightPaymentsService,
 		LndServiceChannelBalanceCacheService,
+		LndServiceGraphSnapshotService,
 	}
 }
 
@@ -67,9 +74,11 @@ func GetClnServiceTypes() []ServiceType {
 		ClnServiceFundsService,
 		ClnServiceNodesService,
 		ClnServiceTransactionsService,
+		ClnServiceHtlcsService,
 		ClnServiceForwardsService,
 		ClnServiceInvoicesService,
 		ClnServicePaymentsService,
+		ClnServiceGraphSnapshotService,
 	}
 }
 
@@ -98,6 +107,18 @@ func (st *ServiceType) String() string {
 		return "MaintenanceService"
 	case CronService:
 		return "CronService"
+	case ForecastService:
+		return "ForecastService"
+	case PeerReliabilityService:
+		return "PeerReliabilityService"
+	case PeerReconnectService:
+		return "PeerReconnectService"
+	case FeeEstimateService:
+		return "FeeEstimateService"
+	case ForceCloseMonitorService:
+		return "ForceCloseMonitorService"
+	case DatabaseService:
+		return "DatabaseService"
 	case NotifierService:
 		return "NotifierService"
 	case SlackService:
@@ -126,6 +147,8 @@ func (st *ServiceType) String() string {
 		return "LndServiceInFlightPaymentsService"
 	case LndServiceChannelBalanceCacheService:
 		return "LndServiceChannelBalanceCacheService"
+	case LndServiceGraphSnapshotService:
+		return "LndServiceGraphSnapshotService"
 	case ClnServiceVectorService:
 		return "ClnServiceVectorService"
 	case ClnServiceAmbossService:
@@ -142,12 +165,16 @@ func (st *ServiceType) String() string {
 		return "ClnServiceNodesService"
 	case ClnServiceTransactionsService:
 		return "ClnServiceTransactionsService"
+	case ClnServiceHtlcsService:
+		return "ClnServiceHtlcsService"
 	case ClnServiceForwardsService:
 		return "ClnServiceForwardsService"
 	case ClnServiceInvoicesService:
 		return "ClnServiceInvoicesService"
 	case ClnServicePaymentsService:
 		return "ClnServicePaymentsService"
+	case ClnServiceGraphSnapshotService:
+		return "ClnServiceGraphSnapshotService"
 	}
 	return core.UnknownEnumString
 }
@@ -180,7 +207,8 @@ func (st *ServiceType) IsLndService() bool {
 		*st == LndServicePaymentsService ||
 		*st == LndServicePeerEventStream ||
 		*st == LndServiceInFlightPaymentsService ||
-		*st == LndServiceChannelBalanceCacheService) {
+		*st == LndServiceChannelBalanceCacheService ||
+		*st == LndServiceGraphSnapshotService) {
 		return true
 	}
 	return false
@@ -195,9 +223,11 @@ func (st *ServiceType) IsClnService() bool {
 		*st == ClnServiceFundsService ||
 		*st == ClnServiceNodesService ||
 		*st == ClnServiceTransactionsService ||
+		*st == ClnServiceHtlcsService ||
 		*st == ClnServiceForwardsService ||
 		*st == ClnServiceInvoicesService ||
-		*st == ClnServicePaymentsService) {
+		*st == ClnServicePaymentsService ||
+		*st == ClnServiceGraphSnapshotService) {
 		return true
 	}
 	return false
@@ -233,6 +263,8 @@ func (st *ServiceType) GetNodeConnectionDetailCustomSettings() []core.NodeConnec
 		return []core.NodeConnectionDetailCustomSettings{core.ImportInvoices}
 	case LndServiceForwardsService, ClnServiceForwardsService:
 		return []core.NodeConnectionDetailCustomSettings{core.ImportForwards, core.ImportHistoricForwards}
+	case LndServiceGraphSnapshotService, ClnServiceGraphSnapshotService:
+		return []core.NodeConnectionDetailCustomSettings{core.ImportGraphSnapshots}
 	default:
 		log.Error().Msgf("DEVELOPMENT ERROR: ServiceType not supported")
 		return nil
@@ -274,6 +306,12 @@ func GetNodeConnectionDetailServiceType(implementation core.Implementation,
 			f = ClnServiceForwardsService
 		}
 		return &f
+	case cs.HasNodeConnectionDetailCustomSettings(core.ImportGraphSnapshots):
+		g := LndServiceGraphSnapshotService
+		if implementation == core.CLN {
+			g = ClnServiceGraphSnapshotService
+		}
+		return &g
 	default:
 		log.Error().Msgf("DEVELOPMENT ERROR: NodeConnectionDetailCustomSettings not supported")
 		return nil
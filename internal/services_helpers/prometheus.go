@@ -4,15 +4,52 @@ import (
 	"strconv"
 	"sync"
 
+	"github.com/cockroachdb/errors"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/lncapital/torq/build"
 )
 
+// ChannelLabels controls the cardinality of the channel metrics
+type ChannelLabels string
+
+const (
+	// ChannelLabelsNone aggregates the channel metrics per node
+	ChannelLabelsNone = ChannelLabels("none")
+	// ChannelLabelsChannel adds the channelId and shortChannelId labels
+	ChannelLabelsChannel = ChannelLabels("channel")
+	// ChannelLabelsPeer adds the peerAlias label on top of ChannelLabelsChannel
+	ChannelLabelsPeer = ChannelLabels("peer")
+)
+
+func ParseChannelLabels(channelLabels string) (ChannelLabels, error) {
+	switch ChannelLabels(channelLabels) {
+	case ChannelLabelsNone, ChannelLabelsChannel, ChannelLabelsPeer:
+		return ChannelLabels(channelLabels), nil
+	}
+	return "", errors.Newf("Unknown channel labels %v (none|channel|peer)", channelLabels)
+}
+
+// ChannelLabelResolver returns the shortChannelId and the peer alias of a channel
+type ChannelLabelResolver func(nodeId int, channelId int) (string, string)
+
 type Metrics struct {
 	State            *prometheus.GaugeVec
 	CoreServiceState *prometheus.GaugeVec
 	NodeServiceState *prometheus.GaugeVec
+
+	Forwards           *prometheus.CounterVec
+	ForwardAmountMsat  *prometheus.CounterVec
+	ForwardFeeMsat     *prometheus.CounterVec
+	HtlcFailures       *prometheus.CounterVec
+	RebalanceAttempts  *prometheus.CounterVec
+	RebalanceSuccesses *prometheus.CounterVec
+	RebalanceFeeMsat   *prometheus.CounterVec
+	WorkflowRuns       *prometheus.CounterVec
+	WorkflowFailures   *prometheus.CounterVec
+
+	ChannelLabels        ChannelLabels
+	channelLabelResolver ChannelLabelResolver
 }
 
 func (m *Metrics) SetState(status ServiceStatus) {
@@ -31,6 +68,101 @@ func (m *Metrics) SetNodeServiceState(serviceType ServiceType, nodeId int, statu
 		}).Set(float64(status))
 }
 
+// ChannelLabelNames returns the label names of the channel metrics for the configured ChannelLabels
+func (m *Metrics) ChannelLabelNames() []string {
+	return channelLabelNames(m.ChannelLabels)
+}
+
+func channelLabelNames(channelLabels ChannelLabels) []string {
+	switch channelLabels {
+	case ChannelLabelsChannel:
+		return []string{"nodeId", "channelId", "shortChannelId"}
+	case ChannelLabelsPeer:
+		return []string{"nodeId", "channelId", "shortChannelId", "peerAlias"}
+	}
+	return []string{"nodeId"}
+}
+
+// ChannelLabelValues returns the values matching ChannelLabelNames
+func (m *Metrics) ChannelLabelValues(nodeId int, channelId int) []string {
+	if m.ChannelLabels != ChannelLabelsChannel && m.ChannelLabels != ChannelLabelsPeer {
+		return []string{strconv.Itoa(nodeId)}
+	}
+	var shortChannelId, peerAlias string
+	if channelId != 0 && m.channelLabelResolver != nil {
+		shortChannelId, peerAlias = m.channelLabelResolver(nodeId, channelId)
+	}
+	if m.ChannelLabels == ChannelLabelsChannel {
+		return []string{strconv.Itoa(nodeId), strconv.Itoa(channelId), shortChannelId}
+	}
+	return []string{strconv.Itoa(nodeId), strconv.Itoa(channelId), shortChannelId, peerAlias}
+}
+
+func (m *Metrics) SetChannelLabelResolver(channelLabelResolver ChannelLabelResolver) {
+	m.channelLabelResolver = channelLabelResolver
+}
+
+// AddForward counts a settled forward on both the incoming and the outgoing channel.
+// The fee is attributed to the outgoing channel.
+func (m *Metrics) AddForward(nodeId int, incomingChannelId *int, outgoingChannelId *int,
+	amountInMsat uint64, amountOutMsat uint64, feeMsat uint64) {
+
+	if m == nil {
+		return
+	}
+	var incoming, outgoing int
+	if incomingChannelId != nil {
+		incoming = *incomingChannelId
+	}
+	if outgoingChannelId != nil {
+		outgoing = *outgoingChannelId
+	}
+	incomingLabels := append(m.ChannelLabelValues(nodeId, incoming), "in")
+	outgoingLabels := append(m.ChannelLabelValues(nodeId, outgoing), "out")
+	m.Forwards.WithLabelValues(incomingLabels...).Inc()
+	m.Forwards.WithLabelValues(outgoingLabels...).Inc()
+	m.ForwardAmountMsat.WithLabelValues(incomingLabels...).Add(float64(amountInMsat))
+	m.ForwardAmountMsat.WithLabelValues(outgoingLabels...).Add(float64(amountOutMsat))
+	m.ForwardFeeMsat.WithLabelValues(m.ChannelLabelValues(nodeId, outgoing)...).Add(float64(feeMsat))
+}
+
+func (m *Metrics) AddHtlcFailure(nodeId int, eventType string, failureCode string) {
+	if m == nil {
+		return
+	}
+	if failureCode == "" {
+		failureCode = "UNKNOWN"
+	}
+	m.HtlcFailures.With(prometheus.Labels{
+		"nodeId":      strconv.Itoa(nodeId),
+		"eventType":   eventType,
+		"failureCode": failureCode,
+	}).Inc()
+}
+
+func (m *Metrics) AddRebalanceAttempt(nodeId int, origin string, success bool, feeMsat uint64) {
+	if m == nil {
+		return
+	}
+	labels := prometheus.Labels{"nodeId": strconv.Itoa(nodeId), "origin": origin}
+	m.RebalanceAttempts.With(labels).Inc()
+	if success {
+		m.RebalanceSuccesses.With(labels).Inc()
+		m.RebalanceFeeMsat.With(labels).Add(float64(feeMsat))
+	}
+}
+
+func (m *Metrics) AddWorkflowRun(workflowId int, failed bool) {
+	if m == nil {
+		return
+	}
+	labels := prometheus.Labels{"workflowId": strconv.Itoa(workflowId)}
+	m.WorkflowRuns.With(labels).Inc()
+	if failed {
+		m.WorkflowFailures.With(labels).Inc()
+	}
+}
+
 var (
 	metricsOnce    sync.Once       //nolint:gochecknoglobals
 	metricsWrapped *metricsWrapper //nolint:gochecknoglobals
@@ -53,14 +185,21 @@ func SetMetrics(m *Metrics) *Metrics {
 	return m
 }
 
+// GetMetrics returns nil when the metrics are not initialised (i.e. in unit tests)
 func GetMetrics() *Metrics {
+	if metricsWrapped == nil {
+		return nil
+	}
 	metricsWrapped.mu.Lock()
 	defer metricsWrapped.mu.Unlock()
 	return metricsWrapped.metrics
 }
 
-func NewMetrics(registry *prometheus.Registry) *Metrics {
+func NewMetrics(registry *prometheus.Registry, channelLabels ChannelLabels) *Metrics {
+	channelDirectionLabelNames := append(channelLabelNames(channelLabels), "direction")
+	rebalanceLabelNames := []string{"nodeId", "origin"}
 	m := &Metrics{
+		ChannelLabels: channelLabels,
 		State: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: "torq",
@@ -82,7 +221,73 @@ func NewMetrics(registry *prometheus.Registry) *Metrics {
 				Help:      "State of a Torq node service",
 			},
 			[]string{"serviceType", "nodeId"}),
+		Forwards: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "torq",
+				Name:      "forwards_total",
+				Help:      "Settled forwards per channel and direction",
+			},
+			channelDirectionLabelNames),
+		ForwardAmountMsat: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "torq",
+				Name:      "forward_amount_msat_total",
+				Help:      "Forwarded amount per channel and direction",
+			},
+			channelDirectionLabelNames),
+		ForwardFeeMsat: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "torq",
+				Name:      "forward_fee_msat_total",
+				Help:      "Earned forwarding fees per outgoing channel",
+			},
+			channelLabelNames(channelLabels)),
+		HtlcFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "torq",
+				Name:      "htlc_failures_total",
+				Help:      "Failed HTLCs per failure code",
+			},
+			[]string{"nodeId", "eventType", "failureCode"}),
+		RebalanceAttempts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "torq",
+				Name:      "rebalance_attempts_total",
+				Help:      "Rebalance payment attempts",
+			},
+			rebalanceLabelNames),
+		RebalanceSuccesses: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "torq",
+				Name:      "rebalance_successes_total",
+				Help:      "Successful rebalance payments",
+			},
+			rebalanceLabelNames),
+		RebalanceFeeMsat: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "torq",
+				Name:      "rebalance_fee_msat_total",
+				Help:      "Fees spent on successful rebalances",
+			},
+			rebalanceLabelNames),
+		WorkflowRuns: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "torq",
+				Name:      "workflow_runs_total",
+				Help:      "Workflow runs per workflow",
+			},
+			[]string{"workflowId"}),
+		WorkflowFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "torq",
+				Name:      "workflow_failures_total",
+				Help:      "Failed workflow runs per workflow",
+			},
+			[]string{"workflowId"}),
 	}
-	registry.MustRegister(m.State, m.CoreServiceState, m.NodeServiceState)
+	registry.MustRegister(m.State, m.CoreServiceState, m.NodeServiceState,
+		m.Forwards, m.ForwardAmountMsat, m.ForwardFeeMsat, m.HtlcFailures,
+		m.RebalanceAttempts, m.RebalanceSuccesses, m.RebalanceFeeMsat,
+		m.WorkflowRuns, m.WorkflowFailures)
 	return m
 }
@@ -0,0 +1,66 @@
+package services_helpers
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestChannelLabels(t *testing.T) {
+	resolver := func(nodeId int, channelId int) (string, string) { return "800000x1x0", "ACINQ" }
+	testCases := []struct {
+		channelLabels ChannelLabels
+		want          []string
+	}{
+		{ChannelLabelsNone, []string{"1"}},
+		{ChannelLabelsChannel, []string{"1", "7", "800000x1x0"}},
+		{ChannelLabelsPeer, []string{"1", "7", "800000x1x0", "ACINQ"}},
+	}
+	for _, tc := range testCases {
+		m := &Metrics{ChannelLabels: tc.channelLabels}
+		m.SetChannelLabelResolver(resolver)
+		got := m.ChannelLabelValues(1, 7)
+		if len(got) != len(m.ChannelLabelNames()) || len(got) != len(tc.want) {
+			t.Fatalf("%v: got %v for names %v", tc.channelLabels, got, m.ChannelLabelNames())
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%v: got %v, want %v", tc.channelLabels, got, tc.want)
+			}
+		}
+	}
+	if _, err := ParseChannelLabels("all"); err == nil {
+		t.Errorf("expected an error for unknown channel labels")
+	}
+}
+
+func TestCounters(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry(), ChannelLabelsNone)
+	incoming, outgoing := 3, 4
+	m.AddForward(1, &incoming, &outgoing, 1_001_000, 1_000_000, 1_000)
+	m.AddForward(1, &incoming, nil, 2_000_000, 1_999_000, 1_000)
+	if got := testutil.ToFloat64(m.Forwards.WithLabelValues("1", "out")); got != 2 {
+		t.Errorf("got %v outgoing forwards, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.ForwardFeeMsat.WithLabelValues("1")); got != 2_000 {
+		t.Errorf("got %v fee, want 2000", got)
+	}
+
+	m.AddRebalanceAttempt(1, "Manual", false, 0)
+	m.AddRebalanceAttempt(1, "Manual", true, 500)
+	if got := testutil.ToFloat64(m.RebalanceAttempts.WithLabelValues("1", "Manual")); got != 2 {
+		t.Errorf("got %v rebalance attempts, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.RebalanceFeeMsat.WithLabelValues("1", "Manual")); got != 500 {
+		t.Errorf("got %v rebalance fee, want 500", got)
+	}
+
+	m.AddWorkflowRun(5, true)
+	if got := testutil.ToFloat64(m.WorkflowFailures.WithLabelValues("5")); got != 1 {
+		t.Errorf("got %v workflow failures, want 1", got)
+	}
+
+	var uninitialised *Metrics
+	uninitialised.AddHtlcFailure(1, "LinkFailEvent", "")
+}
@@ -790,6 +790,7 @@ func setNodeConnectionDetailsCustomSettingsHandler(c *gin.Context, db *sqlx.DB)
 	appendCustomSettingServiceType(ncd.Implementation, cs, services, core.ImportTransactions)
 	appendCustomSettingServiceType(ncd.Implementation, cs, services, core.ImportInvoices)
 	appendCustomSettingServiceType(ncd.Implementation, cs, services, core.ImportForwards, core.ImportHistoricForwards)
+	appendCustomSettingServiceType(ncd.Implementation, cs, services, core.ImportGraphSnapshots)
 
 	_, err = setCustomSettings(db, nodeId, cs, ps)
 	if err != nil {
@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"sync/atomic"
+
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type samplerHolder struct {
+	sampler tracesdk.Sampler
+}
+
+// Sampler samples a fraction of the traces and follows the decision of the parent span. The fraction can change
+// while Torq is running (configuration reload).
+type Sampler struct {
+	current atomic.Value
+}
+
+func NewSampler(fraction float64) *Sampler {
+	sampler := &Sampler{}
+	sampler.SetFraction(fraction)
+	return sampler
+}
+
+func (s *Sampler) SetFraction(fraction float64) {
+	s.current.Store(samplerHolder{sampler: tracesdk.ParentBased(tracesdk.TraceIDRatioBased(fraction))})
+}
+
+func (s *Sampler) ShouldSample(parameters tracesdk.SamplingParameters) tracesdk.SamplingResult {
+	return s.current.Load().(samplerHolder).sampler.ShouldSample(parameters)
+}
+
+func (s *Sampler) Description() string {
+	return s.current.Load().(samplerHolder).sampler.Description()
+}
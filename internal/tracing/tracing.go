@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogHook adds the trace and span id of the span in the context of the log event (when there is one) so log lines
+// can be looked up from a trace and the other way around. It's added to the global logger, so every line logged with a
+// context (Log(ctx), log.Ctx(ctx) or Event.Ctx(ctx)) carries the ids.
+type LogHook struct{}
+
+func (LogHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	spanContext := trace.SpanContextFromContext(e.GetCtx())
+	if !spanContext.IsValid() {
+		return
+	}
+	e.Str("traceId", spanContext.TraceID().String()).
+		Str("spanId", spanContext.SpanID().String())
+}
+
+// Log returns the global logger bound to the context, LogHook adds the trace and span id of its span.
+func Log(ctx context.Context) *zerolog.Logger {
+	logger := log.With().Ctx(ctx).Logger()
+	return &logger
+}
+
+// End marks the span as failed when there is an error and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Detach keeps the span of the context but drops its cancellation and deadline. Requests to the node that are
+// processed by their own goroutines use it so their spans end up in the trace of the caller.
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func testSpanContext() trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03},
+		SpanID:     trace.SpanID{0x04, 0x05},
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+func TestLog(t *testing.T) {
+	var buffer bytes.Buffer
+	globalLogger := log.Logger
+	log.Logger = log.Output(&buffer).Hook(LogHook{})
+	defer func() { log.Logger = globalLogger }()
+
+	Log(context.Background()).Info().Msg("without span")
+	if strings.Contains(buffer.String(), "traceId") {
+		t.Errorf("expected no trace id without span, got %v", buffer.String())
+	}
+
+	buffer.Reset()
+	ctx := trace.ContextWithSpanContext(context.Background(), testSpanContext())
+	Log(ctx).Info().Msg("with span")
+	if !strings.Contains(buffer.String(), `"traceId":"01020300000000000000000000000000"`) {
+		t.Errorf("expected the trace id in the log line, got %v", buffer.String())
+	}
+
+	buffer.Reset()
+	log.Info().Ctx(ctx).Msg("with span in the event")
+	if !strings.Contains(buffer.String(), `"spanId":"0405000000000000"`) {
+		t.Errorf("expected the span id in the log line, got %v", buffer.String())
+	}
+}
+
+func TestDetach(t *testing.T) {
+	ctx, cancel := context.WithTimeout(trace.ContextWithSpanContext(context.Background(), testSpanContext()),
+		time.Minute)
+	cancel()
+
+	detached := Detach(ctx)
+	if detached.Err() != nil {
+		t.Errorf("expected the detached context not to be cancelled")
+	}
+	if _, hasDeadline := detached.Deadline(); hasDeadline {
+		t.Errorf("expected the detached context to have no deadline")
+	}
+	if trace.SpanContextFromContext(detached).TraceID() != testSpanContext().TraceID() {
+		t.Errorf("expected the detached context to keep the span")
+	}
+}
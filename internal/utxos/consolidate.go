@@ -0,0 +1,51 @@
+package utxos
+
+import (
+	"github.com/cockroachdb/errors"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/lightning_helpers"
+)
+
+const consolidationLabel = "Torq consolidation"
+
+// selectConsolidationOutpoints returns the outpoints that are consolidated into one output. When no outpoints are
+// given every confirmed output that isn't leased and holds at most maxAmountSat (when set) is selected.
+func selectConsolidationOutpoints(utxos []lightning_helpers.Utxo,
+	outpoints []string,
+	maxAmountSat int64) ([]string, error) {
+
+	var selected []string
+	if len(outpoints) != 0 {
+		for _, outpoint := range outpoints {
+			if slices.Contains(selected, outpoint) {
+				continue
+			}
+			utxoIndex := slices.IndexFunc(utxos, func(utxo lightning_helpers.Utxo) bool {
+				return utxo.Outpoint == outpoint
+			})
+			if utxoIndex == -1 {
+				return nil, errors.Newf("Outpoint %v is not an unspent output of the node", outpoint)
+			}
+			if !isConsolidationCandidate(utxos[utxoIndex]) {
+				return nil, errors.Newf("Outpoint %v is unconfirmed or leased", outpoint)
+			}
+			selected = append(selected, outpoint)
+		}
+	} else {
+		for _, utxo := range utxos {
+			if !isConsolidationCandidate(utxo) || (maxAmountSat > 0 && utxo.AmountSat > maxAmountSat) {
+				continue
+			}
+			selected = append(selected, utxo.Outpoint)
+		}
+	}
+	if len(selected) < 2 {
+		return nil, errors.New("At least two confirmed outputs that aren't leased are required to consolidate")
+	}
+	return selected, nil
+}
+
+func isConsolidationCandidate(utxo lightning_helpers.Utxo) bool {
+	return utxo.Confirmations > 0 && !utxo.Leased
+}
@@ -0,0 +1,51 @@
+package utxos
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/lightning_helpers"
+)
+
+func TestSelectConsolidationOutpoints(t *testing.T) {
+	utxos := []lightning_helpers.Utxo{
+		{Outpoint: "a:0", AmountSat: 10_000, Confirmations: 10},
+		{Outpoint: "b:1", AmountSat: 20_000, Confirmations: 3},
+		{Outpoint: "c:0", AmountSat: 5_000_000, Confirmations: 100},
+		{Outpoint: "d:2", AmountSat: 15_000, Confirmations: 0},
+		{Outpoint: "e:0", AmountSat: 12_000, Confirmations: 5, Leased: true},
+	}
+
+	tests := []struct {
+		name          string
+		outpoints     []string
+		maxAmountSat  int64
+		expected      []string
+		expectedError bool
+	}{
+		{"every confirmed output", nil, 0, []string{"a:0", "b:1", "c:0"}, false},
+		{"up to the maximum amount", nil, 50_000, []string{"a:0", "b:1"}, false},
+		{"one output below the maximum amount", nil, 15_000, nil, true},
+		{"selected outpoints", []string{"c:0", "a:0", "c:0"}, 0, []string{"c:0", "a:0"}, false},
+		{"selected unconfirmed outpoint", []string{"a:0", "d:2"}, 0, nil, true},
+		{"selected leased outpoint", []string{"a:0", "e:0"}, 0, nil, true},
+		{"selected unknown outpoint", []string{"a:0", "f:0"}, 0, nil, true},
+	}
+	for _, test := range tests {
+		selected, err := selectConsolidationOutpoints(utxos, test.outpoints, test.maxAmountSat)
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%v: expected an error, got %v", test.name, selected)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", test.name, err)
+			continue
+		}
+		if !slices.Equal(selected, test.expected) {
+			t.Errorf("%v: expected %v, got %v", test.name, test.expected, selected)
+		}
+	}
+}
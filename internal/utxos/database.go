@@ -0,0 +1,36 @@
+package utxos
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/lncapital/torq/internal/database"
+)
+
+// getTransactionLabels returns the wallet label of every known transaction by its hash.
+func getTransactionLabels(db *sqlx.DB, nodeId int, txIds []string) (map[string]string, error) {
+	labels := make(map[string]string)
+	if len(txIds) == 0 {
+		return labels, nil
+	}
+	rows, err := db.Queryx(`
+		SELECT tx_hash, label
+		FROM tx
+		WHERE node_id = $1 AND tx_hash = ANY($2) AND label IS NOT NULL AND label != '';`,
+		nodeId, pq.Array(txIds))
+	if err != nil {
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var txHash string
+		var label string
+		err = rows.Scan(&txHash, &label)
+		if err != nil {
+			return nil, errors.Wrap(err, database.SqlExecutionError)
+		}
+		labels[txHash] = label
+	}
+	return labels, nil
+}
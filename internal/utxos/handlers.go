@@ -0,0 +1,207 @@
+package utxos
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/fee_estimates"
+	"github.com/lncapital/torq/internal/lightning"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+	"github.com/lncapital/torq/pkg/server_errors"
+)
+
+// defaultConsolidationTargetConf is the confirmation target of the estimate that is compared with maxSatPerVbyte
+const defaultConsolidationTargetConf = 144
+
+type Utxo struct {
+	lightning_helpers.Utxo
+	Label string `json:"label"`
+}
+
+type OutputRequest struct {
+	NodeId   int    `json:"nodeId"`
+	Outpoint string `json:"outpoint"`
+	// ExpirationSeconds is the duration of the lease, the node default is used when it's 0
+	ExpirationSeconds uint64 `json:"expirationSeconds"`
+}
+
+type ConsolidateRequest struct {
+	NodeId int `json:"nodeId"`
+	// Outpoints (txid:index) to consolidate, when empty every confirmed output up to maxAmountSat is consolidated
+	Outpoints         []string  `json:"outpoints"`
+	MaxAmountSat      int64     `json:"maxAmountSat"`
+	MaxSatPerVbyte    float64   `json:"maxSatPerVbyte"`
+	TargetConf        *int32    `json:"targetConf"`
+	Deadline          time.Time `json:"deadline"`
+	ExecuteAtDeadline bool      `json:"executeAtDeadline"`
+}
+
+func getUtxosHandler(c *gin.Context, db *sqlx.DB) {
+	nodeId, err := strconv.Atoi(c.Param("nodeId"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Failed to find/parse nodeId in the request.")
+		return
+	}
+	if !slices.Contains(cache.GetAllTorqNodeIds(), nodeId) {
+		server_errors.SendBadRequest(c, "Unknown nodeId.")
+		return
+	}
+	var minConfs int64
+	if c.Query("minConfs") != "" {
+		minConfs, err = strconv.ParseInt(c.Query("minConfs"), 10, 32)
+		if err != nil || minConfs < 0 {
+			server_errors.SendBadRequest(c, "Can't process minConfs")
+			return
+		}
+	}
+
+	lightningUtxos, err := lightning.ListUnspent(c.Request.Context(), nodeId, int32(minConfs))
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Listing unspent outputs")
+		return
+	}
+	txIds := make([]string, 0, len(lightningUtxos))
+	for _, utxo := range lightningUtxos {
+		txIds = append(txIds, utxo.TxId)
+	}
+	labels, err := getTransactionLabels(db, nodeId, txIds)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting transaction labels")
+		return
+	}
+	utxos := make([]Utxo, 0, len(lightningUtxos))
+	for _, utxo := range lightningUtxos {
+		utxos = append(utxos, Utxo{Utxo: utxo, Label: labels[utxo.TxId]})
+	}
+	c.JSON(http.StatusOK, utxos)
+}
+
+func leaseOutputHandler(c *gin.Context) {
+	var request OutputRequest
+	if err := c.BindJSON(&request); err != nil {
+		server_errors.SendBadRequestFromError(c, errors.Wrap(err, server_errors.JsonParseError))
+		return
+	}
+	if !slices.Contains(cache.GetAllTorqNodeIds(), request.NodeId) {
+		server_errors.SendBadRequest(c, "Unknown nodeId.")
+		return
+	}
+	response, err := lightning.LeaseOutput(c.Request.Context(), lightning_helpers.LeaseOutputRequest{
+		CommunicationRequest: lightning_helpers.CommunicationRequest{NodeId: request.NodeId},
+		Outpoint:             request.Outpoint,
+		ExpirationSeconds:    request.ExpirationSeconds,
+	})
+	if err != nil {
+		server_errors.SendBadRequestFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func releaseOutputHandler(c *gin.Context) {
+	var request OutputRequest
+	if err := c.BindJSON(&request); err != nil {
+		server_errors.SendBadRequestFromError(c, errors.Wrap(err, server_errors.JsonParseError))
+		return
+	}
+	if !slices.Contains(cache.GetAllTorqNodeIds(), request.NodeId) {
+		server_errors.SendBadRequest(c, "Unknown nodeId.")
+		return
+	}
+	_, err := lightning.ReleaseOutput(c.Request.Context(), lightning_helpers.ReleaseOutputRequest{
+		CommunicationRequest: lightning_helpers.CommunicationRequest{NodeId: request.NodeId},
+		Outpoint:             request.Outpoint,
+	})
+	if err != nil {
+		server_errors.SendBadRequestFromError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// consolidateHandler queues a deferred send of the selected outputs to a new address of the node, it's executed once
+// the fee estimate is at most maxSatPerVbyte.
+func consolidateHandler(c *gin.Context, db *sqlx.DB) {
+	var request ConsolidateRequest
+	if err := c.BindJSON(&request); err != nil {
+		server_errors.SendBadRequestFromError(c, errors.Wrap(err, server_errors.JsonParseError))
+		return
+	}
+	if !slices.Contains(cache.GetAllTorqNodeIds(), request.NodeId) {
+		server_errors.SendBadRequest(c, "Unknown nodeId.")
+		return
+	}
+	if request.MaxSatPerVbyte <= 0 {
+		server_errors.SendBadRequest(c, "maxSatPerVbyte must be above 0")
+		return
+	}
+	targetConf := int32(defaultConsolidationTargetConf)
+	if request.TargetConf != nil {
+		if *request.TargetConf < 1 {
+			server_errors.SendBadRequest(c, "targetConf must be at least 1")
+			return
+		}
+		targetConf = *request.TargetConf
+	}
+	if !request.Deadline.After(time.Now()) {
+		server_errors.SendBadRequest(c, "The deadline must be in the future")
+		return
+	}
+
+	lightningUtxos, err := lightning.ListUnspent(c.Request.Context(), request.NodeId, 0)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Listing unspent outputs")
+		return
+	}
+	outpoints, err := selectConsolidationOutpoints(lightningUtxos, request.Outpoints, request.MaxAmountSat)
+	if err != nil {
+		server_errors.SendBadRequestFromError(c, err)
+		return
+	}
+
+	address, err := lightning.NewAddress(c.Request.Context(), lightning_helpers.NewAddressRequest{
+		CommunicationRequest: lightning_helpers.CommunicationRequest{NodeId: request.NodeId},
+		Type:                 lightning_helpers.P2WKH,
+	})
+	if err != nil {
+		server_errors.SendBadRequestFromError(c, err)
+		return
+	}
+
+	sendAll := true
+	label := consolidationLabel
+	onChainPaymentRequest, err := json.Marshal(lightning_helpers.OnChainPaymentRequest{
+		CommunicationRequest: lightning_helpers.CommunicationRequest{NodeId: request.NodeId},
+		Address:              address,
+		SendAll:              &sendAll,
+		Label:                &label,
+		Outpoints:            outpoints,
+	})
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Marshalling the consolidation request")
+		return
+	}
+
+	deferredOperation, err := fee_estimates.AddDeferredOperation(db, fee_estimates.DeferredOperation{
+		NodeId:            request.NodeId,
+		OperationType:     fee_estimates.DeferredOperationSend,
+		Request:           onChainPaymentRequest,
+		MaxSatPerVbyte:    request.MaxSatPerVbyte,
+		TargetConf:        targetConf,
+		Deadline:          request.Deadline.UTC(),
+		ExecuteAtDeadline: request.ExecuteAtDeadline,
+	})
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Adding the consolidation")
+		return
+	}
+	c.JSON(http.StatusOK, deferredOperation)
+}
@@ -0,0 +1,13 @@
+package utxos
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func RegisterUtxoRoutes(r *gin.RouterGroup, db *sqlx.DB) {
+	r.GET(":nodeId", func(c *gin.Context) { getUtxosHandler(c, db) })
+	r.POST("lease", func(c *gin.Context) { leaseOutputHandler(c) })
+	r.POST("release", func(c *gin.Context) { releaseOutputHandler(c) })
+	r.POST("consolidate", func(c *gin.Context) { consolidateHandler(c, db) })
+}
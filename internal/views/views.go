@@ -927,6 +927,329 @@ func getTableViewColumnDefinitions() []tableViewColumnDefinition {
 				PageChannels: 54,
 			},
 		},
+		{
+			key:        "outboundFlowPerDay",
+			sortable:   true,
+			filterable: true,
+			heading:    "Outbound Flow (sat/day)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			pages: map[TableViewPage]int{
+				PageChannels: 56,
+			},
+		},
+		{
+			key:        "inboundFlowPerDay",
+			sortable:   true,
+			filterable: true,
+			heading:    "Inbound Flow (sat/day)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			pages: map[TableViewPage]int{
+				PageChannels: 57,
+			},
+		},
+		{
+			key:        "netFlowPerDay",
+			sortable:   true,
+			filterable: true,
+			heading:    "Net Flow (sat/day)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			pages: map[TableViewPage]int{
+				PageChannels: 58,
+			},
+		},
+		{
+			key:        "depletionOn",
+			sortable:   true,
+			filterable: true,
+			heading:    "Expected Depletion Date",
+			visualType: "DateCell",
+			valueType:  "date",
+			pages: map[TableViewPage]int{
+				PageChannels: 59,
+			},
+		},
+		{
+			key:        "depletionSecondsDelta",
+			sortable:   true,
+			filterable: true,
+			heading:    "Expected Depletion (Seconds)",
+			visualType: "DurationCell",
+			valueType:  "duration",
+			pages: map[TableViewPage]int{
+				PageChannels: 60,
+			},
+		},
+		{
+			key:        "saturationOn",
+			sortable:   true,
+			filterable: true,
+			heading:    "Expected Saturation Date",
+			visualType: "DateCell",
+			valueType:  "date",
+			pages: map[TableViewPage]int{
+				PageChannels: 61,
+			},
+		},
+		{
+			key:        "saturationSecondsDelta",
+			sortable:   true,
+			filterable: true,
+			heading:    "Expected Saturation (Seconds)",
+			visualType: "DurationCell",
+			valueType:  "duration",
+			pages: map[TableViewPage]int{
+				PageChannels: 62,
+			},
+		},
+		{
+			key:        "competitorCount",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Count",
+			visualType: "NumericCell",
+			valueType:  "number",
+			pages: map[TableViewPage]int{
+				PageChannels: 63,
+			},
+		},
+		{
+			key:        "competitorFeeRateMin",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Fee Rate Min (PPM)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "ppm",
+			pages: map[TableViewPage]int{
+				PageChannels: 64,
+			},
+		},
+		{
+			key:        "competitorFeeRateP10",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Fee Rate 10th Percentile (PPM)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "ppm",
+			pages: map[TableViewPage]int{
+				PageChannels: 65,
+			},
+		},
+		{
+			key:        "competitorFeeRateP20",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Fee Rate 20th Percentile (PPM)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "ppm",
+			pages: map[TableViewPage]int{
+				PageChannels: 66,
+			},
+		},
+		{
+			key:        "competitorFeeRateP30",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Fee Rate 30th Percentile (PPM)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "ppm",
+			pages: map[TableViewPage]int{
+				PageChannels: 67,
+			},
+		},
+		{
+			key:        "competitorFeeRateP40",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Fee Rate 40th Percentile (PPM)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "ppm",
+			pages: map[TableViewPage]int{
+				PageChannels: 68,
+			},
+		},
+		{
+			key:        "competitorFeeRateMedian",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Fee Rate Median (PPM)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "ppm",
+			pages: map[TableViewPage]int{
+				PageChannels: 69,
+			},
+		},
+		{
+			key:        "competitorFeeRateP60",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Fee Rate 60th Percentile (PPM)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "ppm",
+			pages: map[TableViewPage]int{
+				PageChannels: 70,
+			},
+		},
+		{
+			key:        "competitorFeeRateP70",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Fee Rate 70th Percentile (PPM)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "ppm",
+			pages: map[TableViewPage]int{
+				PageChannels: 71,
+			},
+		},
+		{
+			key:        "competitorFeeRateP80",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Fee Rate 80th Percentile (PPM)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "ppm",
+			pages: map[TableViewPage]int{
+				PageChannels: 72,
+			},
+		},
+		{
+			key:        "competitorFeeRateP90",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Fee Rate 90th Percentile (PPM)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "ppm",
+			pages: map[TableViewPage]int{
+				PageChannels: 73,
+			},
+		},
+		{
+			key:        "competitorFeeRateMax",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Fee Rate Max (PPM)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "ppm",
+			pages: map[TableViewPage]int{
+				PageChannels: 74,
+			},
+		},
+		{
+			key:        "competitorFeeBaseMedian",
+			sortable:   true,
+			filterable: true,
+			heading:    "Competitor Base Fee Median",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "msat",
+			pages: map[TableViewPage]int{
+				PageChannels: 75,
+			},
+		},
+		{
+			key:        "feeRateCompetitorPercentile",
+			sortable:   true,
+			filterable: true,
+			heading:    "Fee Rate Competitor Percentile",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "%",
+			pages: map[TableViewPage]int{
+				PageChannels: 76,
+			},
+		},
+		{
+			key:        "peerUptimePercentage",
+			sortable:   true,
+			filterable: true,
+			heading:    "Peer Uptime (%)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "%",
+			pages: map[TableViewPage]int{
+				PageChannels: 77,
+			},
+		},
+		{
+			key:        "peerFlapCount",
+			sortable:   true,
+			filterable: true,
+			heading:    "Peer Disconnects",
+			visualType: "NumericCell",
+			valueType:  "number",
+			pages: map[TableViewPage]int{
+				PageChannels: 78,
+			},
+		},
+		{
+			key:        "peerMeanSecondsBetweenDisconnects",
+			sortable:   true,
+			filterable: true,
+			heading:    "Peer Mean Time Between Disconnects",
+			visualType: "DurationCell",
+			valueType:  "duration",
+			pages: map[TableViewPage]int{
+				PageChannels: 79,
+			},
+		},
+		{
+			key:        "peerLongestOutageSeconds",
+			sortable:   true,
+			filterable: true,
+			heading:    "Peer Longest Outage",
+			visualType: "DurationCell",
+			valueType:  "duration",
+			pages: map[TableViewPage]int{
+				PageChannels: 80,
+			},
+		},
+		{
+			key:        "peerChannelActivePercentage",
+			sortable:   true,
+			filterable: true,
+			heading:    "Peer Channels Active (%)",
+			visualType: "NumericCell",
+			valueType:  "number",
+			suffix:     "%",
+			pages: map[TableViewPage]int{
+				PageChannels: 81,
+			},
+		},
+		{
+			key:        "peerChannelFlapCount",
+			sortable:   true,
+			filterable: true,
+			heading:    "Peer Channel Flaps",
+			visualType: "NumericCell",
+			valueType:  "number",
+			pages: map[TableViewPage]int{
+				PageChannels: 82,
+			},
+		},
+		{
+			key:        "peerReliabilityScore",
+			sortable:   true,
+			filterable: true,
+			heading:    "Peer Reliability Score",
+			visualType: "NumericCell",
+			valueType:  "number",
+			pages: map[TableViewPage]int{
+				PageChannels: 83,
+			},
+		},
 		{
 			key:        "date",
 			sortable:   true,
@@ -24,6 +24,8 @@ const (
 	WorkflowNodeRebalanceAutoRun
 	WorkflowNodeDataSourceTorqChannels
 	WorkflowNodeChannelBalanceEventFilter
+	WorkflowNodeOpenChannels
+	WorkflowNodeBumpFees
 )
 
 type WorkflowParameterType string
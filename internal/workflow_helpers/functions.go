@@ -107,6 +107,13 @@ func GetWorkflowNodes() map[WorkflowNodeType]WorkflowNodeTypeParameters {
 	removeTagOptionalOutputs[WorkflowParameterLabelChannels] = WorkflowParameterTypeChannelIds
 	removeTagOptionalOutputs[WorkflowParameterLabelTagSettings] = WorkflowParameterTypeTagSettings
 
+	openChannelsOptionalInputs := channelsOnly
+	openChannelsOptionalOutputs := make(map[WorkflowParameterLabel]WorkflowParameterType)
+	openChannelsOptionalOutputs[WorkflowParameterLabelStatus] = WorkflowParameterTypeStatus
+
+	bumpFeesOptionalOutputs := make(map[WorkflowParameterLabel]WorkflowParameterType)
+	bumpFeesOptionalOutputs[WorkflowParameterLabelStatus] = WorkflowParameterTypeStatus
+
 	return map[WorkflowNodeType]WorkflowNodeTypeParameters{
 		WorkflowTrigger: {
 			WorkflowNodeType: WorkflowTrigger,
@@ -241,6 +248,20 @@ func GetWorkflowNodes() map[WorkflowNodeType]WorkflowNodeTypeParameters {
 			RequiredOutputs:  make(map[WorkflowParameterLabel]WorkflowParameterType),
 			OptionalOutputs:  removeTagOptionalOutputs,
 		},
+		WorkflowNodeOpenChannels: {
+			WorkflowNodeType: WorkflowNodeOpenChannels,
+			RequiredInputs:   make(map[WorkflowParameterLabel]WorkflowParameterType),
+			OptionalInputs:   openChannelsOptionalInputs,
+			RequiredOutputs:  make(map[WorkflowParameterLabel]WorkflowParameterType),
+			OptionalOutputs:  openChannelsOptionalOutputs,
+		},
+		WorkflowNodeBumpFees: {
+			WorkflowNodeType: WorkflowNodeBumpFees,
+			RequiredInputs:   make(map[WorkflowParameterLabel]WorkflowParameterType),
+			OptionalInputs:   make(map[WorkflowParameterLabel]WorkflowParameterType),
+			RequiredOutputs:  make(map[WorkflowParameterLabel]WorkflowParameterType),
+			OptionalOutputs:  bumpFeesOptionalOutputs,
+		},
 		WorkflowNodeSetVariable: {
 			WorkflowNodeType: WorkflowNodeSetVariable,
 			RequiredInputs:   make(map[WorkflowParameterLabel]WorkflowParameterType),
@@ -0,0 +1,71 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/fee_bumps"
+)
+
+type BumpFeesConfiguration struct {
+	// NodeId is the Torq node of the unconfirmed transactions
+	NodeId int `json:"nodeId"`
+	// Blocks is the number of blocks a transaction is unconfirmed, since it was first seen or last bumped, before
+	// it's bumped
+	Blocks uint32 `json:"blocks"`
+	// Either TargetConf or SatPerVbyte is used for the first bump, every next bump of a transaction escalates it
+	TargetConf  *int32  `json:"targetConf"`
+	SatPerVbyte *uint64 `json:"satPerVbyte"`
+	// MaxSatPerVbyte is required, the escalating bumps never exceed it
+	MaxSatPerVbyte uint64 `json:"maxSatPerVbyte"`
+	// BumpIncoming also bumps the transactions that someone else sent to the node, they are skipped by default
+	BumpIncoming bool `json:"bumpIncoming"`
+	Force        bool `json:"force"`
+}
+
+type BumpFeesResult struct {
+	NodeId       int                    `json:"nodeId"`
+	Transactions []fee_bumps.BumpResult `json:"transactions"`
+}
+
+func processBumpFees(ctx context.Context, db *sqlx.DB, workflowNode WorkflowNode) (BumpFeesResult, error) {
+	var params BumpFeesConfiguration
+	err := json.Unmarshal([]byte(workflowNode.Parameters), &params)
+	if err != nil {
+		return BumpFeesResult{}, errors.Wrapf(err, "Parse parameters for WorkflowVersionNodeId: %v", workflowNode.WorkflowVersionNodeId)
+	}
+	if !slices.Contains(cache.GetAllTorqNodeIds(), params.NodeId) {
+		return BumpFeesResult{}, errors.New(fmt.Sprintf("Bumping fees of unmanaged nodeId: %v for WorkflowVersionNodeId: %v",
+			params.NodeId, workflowNode.WorkflowVersionNodeId))
+	}
+	if params.Blocks == 0 {
+		return BumpFeesResult{}, errors.New(fmt.Sprintf("Blocks must be at least 1 for WorkflowVersionNodeId: %v",
+			workflowNode.WorkflowVersionNodeId))
+	}
+	if (params.TargetConf == nil) == (params.SatPerVbyte == nil) {
+		return BumpFeesResult{}, errors.New(fmt.Sprintf("Either targetConf or satPerVbyte is required for WorkflowVersionNodeId: %v",
+			workflowNode.WorkflowVersionNodeId))
+	}
+
+	if params.MaxSatPerVbyte == 0 {
+		return BumpFeesResult{}, errors.New(fmt.Sprintf("A maximum fee rate is required for WorkflowVersionNodeId: %v",
+			workflowNode.WorkflowVersionNodeId))
+	}
+	if params.SatPerVbyte != nil && *params.SatPerVbyte > params.MaxSatPerVbyte {
+		return BumpFeesResult{}, errors.New(fmt.Sprintf("The fee rate is above the maximum fee rate for WorkflowVersionNodeId: %v",
+			workflowNode.WorkflowVersionNodeId))
+	}
+
+	transactions, err := fee_bumps.BumpUnconfirmedTransactions(ctx, db, params.NodeId, params.Blocks,
+		params.TargetConf, params.SatPerVbyte, params.MaxSatPerVbyte, params.BumpIncoming, params.Force)
+	if err != nil {
+		return BumpFeesResult{}, errors.Wrapf(err, "Bumping fees for WorkflowVersionNodeId: %v", workflowNode.WorkflowVersionNodeId)
+	}
+	return BumpFeesResult{NodeId: params.NodeId, Transactions: transactions}, nil
+}
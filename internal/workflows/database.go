@@ -1327,3 +1327,49 @@ func GetWorkflowLogs(db *sqlx.DB, workflowId int, maximumResultCount int) ([]Wor
 	}
 	return wfvnls, nil
 }
+
+func addInterruptedOperation(db *sqlx.DB, interruptedOperation InterruptedOperation) error {
+	_, err := db.Exec(`
+		INSERT INTO interrupted_operation (node_id, operation_type, request, payment_hash, created_on)
+		VALUES ($1, $2, $3, $4, $5);`,
+		interruptedOperation.NodeId, interruptedOperation.OperationType, []byte(interruptedOperation.Request),
+		interruptedOperation.PaymentHash, interruptedOperation.CreatedOn)
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	return nil
+}
+
+// getInterruptedOperations returns the interrupted operations of the nodes, nil nodeIds returns those of every node.
+func getInterruptedOperations(db *sqlx.DB, nodeIds []int) ([]InterruptedOperation, error) {
+	var interruptedOperations []InterruptedOperation
+	err := db.Select(&interruptedOperations, `
+		SELECT interrupted_operation_id, node_id, operation_type, request, payment_hash, notified, created_on
+		FROM interrupted_operation
+		WHERE $1::INTEGER[] IS NULL OR node_id = ANY($1)
+		ORDER BY interrupted_operation_id;`, pq.Array(nodeIds))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, database.SqlExecutionError)
+	}
+	return interruptedOperations, nil
+}
+
+func setInterruptedOperationNotified(db *sqlx.DB, interruptedOperationId int) error {
+	_, err := db.Exec(`UPDATE interrupted_operation SET notified=TRUE WHERE interrupted_operation_id=$1;`,
+		interruptedOperationId)
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	return nil
+}
+
+func removeInterruptedOperation(db *sqlx.DB, interruptedOperationId int) error {
+	_, err := db.Exec(`DELETE FROM interrupted_operation WHERE interrupted_operation_id=$1;`, interruptedOperationId)
+	if err != nil {
+		return errors.Wrap(err, database.SqlExecutionError)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/communications"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+)
+
+// resumeServiceTimeout is how long the resume waits for the service of a node to become active, operations of a node
+// that stays inactive are kept for the next start
+const resumeServiceTimeout = 10 * time.Minute
+const resumeServicePollSeconds = 5
+
+// InterruptedOperation is a rebalance, routing policy update or channel open that didn't finish before the shutdown.
+type InterruptedOperation struct {
+	InterruptedOperationId int                     `json:"interruptedOperationId" db:"interrupted_operation_id"`
+	NodeId                 int                     `json:"nodeId" db:"node_id"`
+	OperationType          lightning.OperationType `json:"operationType" db:"operation_type"`
+	Request                json.RawMessage         `json:"request" db:"request"`
+	// PaymentHash is the last attempt of an interrupted rebalance
+	PaymentHash *string   `json:"paymentHash" db:"payment_hash"`
+	Notified    bool      `json:"notified" db:"notified"`
+	CreatedOn   time.Time `json:"createdOn" db:"created_on"`
+}
+
+func storeInterruptedOperation(db *sqlx.DB,
+	nodeId int,
+	operationType lightning.OperationType,
+	request interface{},
+	paymentHash *string) error {
+
+	requestJson, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrapf(err, "Marshalling the %v request", operationType)
+	}
+	return addInterruptedOperation(db, InterruptedOperation{
+		NodeId:        nodeId,
+		OperationType: operationType,
+		Request:       requestJson,
+		PaymentHash:   paymentHash,
+		CreatedOn:     time.Now().UTC(),
+	})
+}
+
+// ShutdownLightningOperations gives the in-flight routing policy updates and channel opens up to gracePeriod to
+// return. The remaining operations are stored so they are resumed or reported on the next start.
+func ShutdownLightningOperations(db *sqlx.DB, gracePeriod time.Duration) {
+	operations := lightning.WaitForInFlightOperations(time.Now().Add(gracePeriod))
+	for _, operation := range operations {
+		err := storeInterruptedOperation(db, operation.NodeId, operation.OperationType, operation.Request, nil)
+		if err != nil {
+			log.Error().Err(err).Msgf("Storing the interrupted %v operation for nodeId: %v",
+				operation.OperationType, operation.NodeId)
+			continue
+		}
+		log.Info().Msgf("Interrupted %v operation for nodeId: %v, it's resumed or reported on the next start",
+			operation.OperationType, operation.NodeId)
+	}
+}
+
+// ResumeInterruptedOperations resumes the operations that were interrupted by the previous shutdown once the
+// service of their node is active. Operations that could spend funds twice are reported instead of executed again.
+func ResumeInterruptedOperations(ctx context.Context, db *sqlx.DB) {
+	interruptedOperations, err := getInterruptedOperations(db, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("Obtaining the operations that were interrupted by the previous shutdown")
+		return
+	}
+	interruptedOperationsByNodeId := make(map[int][]InterruptedOperation)
+	for _, interruptedOperation := range interruptedOperations {
+		interruptedOperationsByNodeId[interruptedOperation.NodeId] =
+			append(interruptedOperationsByNodeId[interruptedOperation.NodeId], interruptedOperation)
+	}
+	for nodeId, nodeInterruptedOperations := range interruptedOperationsByNodeId {
+		go resumeInterruptedOperations(ctx, db, nodeId, nodeInterruptedOperations)
+	}
+}
+
+func resumeInterruptedOperations(ctx context.Context,
+	db *sqlx.DB,
+	nodeId int,
+	interruptedOperations []InterruptedOperation) {
+
+	if !waitForActiveService(ctx, nodeId) {
+		log.Warn().Msgf("Service of nodeId: %v is not active, %v interrupted operations are resumed on the next start",
+			nodeId, len(interruptedOperations))
+		return
+	}
+	for _, interruptedOperation := range interruptedOperations {
+		done, err := resumeInterruptedOperation(ctx, db, interruptedOperation)
+		if err != nil {
+			log.Error().Err(err).Msgf("Resuming the interrupted %v operation %v for nodeId: %v",
+				interruptedOperation.OperationType, interruptedOperation.InterruptedOperationId, nodeId)
+		}
+		if !done {
+			continue
+		}
+		// A failed operation is not retried, the node refused it
+		err = removeInterruptedOperation(db, interruptedOperation.InterruptedOperationId)
+		if err != nil {
+			log.Error().Err(err).Msgf("Removing the interrupted operation %v",
+				interruptedOperation.InterruptedOperationId)
+		}
+	}
+}
+
+func waitForActiveService(ctx context.Context, nodeId int) bool {
+	deadline := time.Now().Add(resumeServiceTimeout)
+	for time.Now().Before(deadline) {
+		switch cache.GetNodeConnectionDetails(nodeId).Implementation {
+		case core.LND:
+			if cache.IsLndServiceActive(nodeId) {
+				return true
+			}
+		case core.CLN:
+			if cache.IsClnServiceActive(nodeId) {
+				return true
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(resumeServicePollSeconds * time.Second):
+		}
+	}
+	return false
+}
+
+// resumeInterruptedOperation returns false when the operation stays stored: a channel open is reported until it's
+// dismissed and a rebalance with an attempt in flight is checked again on the next start.
+func resumeInterruptedOperation(ctx context.Context,
+	db *sqlx.DB,
+	interruptedOperation InterruptedOperation) (bool, error) {
+
+	switch interruptedOperation.OperationType {
+	case lightning.RebalanceOperation:
+		return resumeInterruptedRebalance(ctx, db, interruptedOperation)
+	case lightning.RoutingPolicyOperation:
+		var request lightning_helpers.RoutingPolicyUpdateRequest
+		if err := json.Unmarshal(interruptedOperation.Request, &request); err != nil {
+			return true, errors.Wrap(err, "Parsing the routing policy request")
+		}
+		// Updating the routing policy again is harmless when the update reached the node before the shutdown
+		request.Db = db
+		_, err := lightning.SetRoutingPolicy(ctx, request)
+		if err == nil {
+			log.Info().Msgf("Resumed the interrupted routing policy update for nodeId: %v",
+				interruptedOperation.NodeId)
+		}
+		return true, err
+	case lightning.OpenChannelOperation, lightning.BatchOpenChannelOperation:
+		// The open could have reached the node before the shutdown and opening it again funds a second channel, the
+		// user checks the pending and open channels of the node and dismisses the interrupted open.
+		notifyInterruptedOperation(db, interruptedOperation,
+			"A channel open was interrupted by the shutdown of Torq and is not executed again, "+
+				"check the pending channels of the node and open it again when it's missing")
+		return false, nil
+	}
+	return true, errors.Newf("Unknown interrupted operation type: %v", interruptedOperation.OperationType)
+}
+
+// resumeInterruptedRebalance requests a manual rebalance again when its last attempt didn't succeed, workflows request
+// their rebalances again when they are triggered.
+func resumeInterruptedRebalance(ctx context.Context,
+	db *sqlx.DB,
+	interruptedOperation InterruptedOperation) (bool, error) {
+
+	var request lightning_helpers.RebalanceRequest
+	if err := json.Unmarshal(interruptedOperation.Request, &request); err != nil {
+		return true, errors.Wrap(err, "Parsing the rebalance request")
+	}
+	if request.Origin == lightning_helpers.RebalanceWorkflowNode {
+		log.Info().Msgf("Interrupted rebalance for workflow originId: %v is requested again by its workflow",
+			request.OriginId)
+		return true, nil
+	}
+	if interruptedOperation.PaymentHash != nil {
+		paymentStatus, err := lightning.PaymentStatus(ctx, interruptedOperation.NodeId,
+			*interruptedOperation.PaymentHash)
+		if err != nil {
+			return false, errors.Wrapf(err, "Obtaining the status of payment %v", *interruptedOperation.PaymentHash)
+		}
+		switch paymentStatus {
+		case lightning_helpers.PaymentStatusSucceeded:
+			log.Info().Msgf("Interrupted rebalance with payment %v succeeded", *interruptedOperation.PaymentHash)
+			return true, nil
+		case lightning_helpers.PaymentStatusInFlight:
+			notifyInterruptedOperation(db, interruptedOperation, fmt.Sprintf(
+				"A rebalance was interrupted by the shutdown of Torq while payment %v was in flight, "+
+					"it's requested again when the payment fails", *interruptedOperation.PaymentHash))
+			return false, nil
+		}
+	}
+	responses := RebalanceRequests(ctx, db, lightning_helpers.RebalanceRequests{
+		CommunicationRequest: lightning_helpers.CommunicationRequest{NodeId: interruptedOperation.NodeId},
+		Requests:             []lightning_helpers.RebalanceRequest{request},
+	}, interruptedOperation.NodeId)
+	for _, response := range responses {
+		if response.Error != "" {
+			return true, errors.New(response.Error)
+		}
+	}
+	log.Info().Msgf("Resumed the interrupted rebalance for nodeId: %v", interruptedOperation.NodeId)
+	return true, nil
+}
+
+// notifyInterruptedOperation sends the notification once, the operation is listed by the API until it's dismissed.
+func notifyInterruptedOperation(db *sqlx.DB, interruptedOperation InterruptedOperation, message string) {
+	if interruptedOperation.Notified {
+		return
+	}
+	// TODO FIXME Language from user for translations
+	message = fmt.Sprintf("%v (%v request: %s)", message, interruptedOperation.OperationType,
+		interruptedOperation.Request)
+	communications.HandleNotification(db, core.NotifierEvent{
+		EventData: core.EventData{
+			EventTime: time.Now().UTC(),
+			NodeId:    interruptedOperation.NodeId,
+		},
+		Notification:     &message,
+		NotificationType: core.InterruptedOperation,
+	})
+	err := setInterruptedOperationNotified(db, interruptedOperation.InterruptedOperationId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Marking the interrupted operation %v as notified",
+			interruptedOperation.InterruptedOperationId)
+	}
+}
@@ -0,0 +1,334 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+
+	"github.com/lncapital/torq/internal/cache"
+	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/database"
+	"github.com/lncapital/torq/internal/lightning"
+	"github.com/lncapital/torq/internal/lightning_helpers"
+	"github.com/lncapital/torq/internal/peers"
+)
+
+// defaultOpenChannelsTargetConf is used for the fee estimate and the funding transaction when none is configured
+const defaultOpenChannelsTargetConf = 6
+
+// openChannelVbytes approximates the size a channel adds to a funding transaction: a P2WPKH input, a P2WPKH change
+// output and the P2WSH funding output, so it also covers the channels CLN opens one by one.
+const openChannelVbytes = 154
+
+type OpenChannelsStatus string
+
+const (
+	OpenChannelsStatusOpened = OpenChannelsStatus("opened")
+	// OpenChannelsStatusSkipped means none of the rules allowed a channel open during this run
+	OpenChannelsStatusSkipped = OpenChannelsStatus("skipped")
+	OpenChannelsStatusFailed  = OpenChannelsStatus("failed")
+)
+
+type OpenChannelsConfiguration struct {
+	// NodeId is the Torq node that opens the channels
+	NodeId int `json:"nodeId"`
+	// Candidates are the nodes tagged with one of the tags or with a tag of one of the categories, nodes of tagged
+	// channels and the peers of the channels linked to the input.
+	TagIds      []int `json:"tagIds"`
+	CategoryIds []int `json:"categoryIds"`
+	// MaxTotalSat is the maximum amount committed to new channels and their funding fees per run, 0 means no maximum
+	MaxTotalSat int64 `json:"maxTotalSat"`
+	// MinReserveSat is the confirmed on-chain balance that is never committed to new channels or their funding fees
+	MinReserveSat int64 `json:"minReserveSat"`
+	// MaxSatPerVbyte skips the run when the fee estimate is higher, 0 means no maximum
+	MaxSatPerVbyte float64 `json:"maxSatPerVbyte"`
+	TargetConf     *int32  `json:"targetConf"`
+	MinChannelSat  int64   `json:"minChannelSat"`
+	MaxChannelSat  int64   `json:"maxChannelSat"`
+	// MaxChannelsPerPeer skips peers that already have this many open or opening channels, it's at least 1
+	MaxChannelsPerPeer int  `json:"maxChannelsPerPeer"`
+	Private            bool `json:"private"`
+}
+
+type OpenChannelsResult struct {
+	NodeId      int                         `json:"nodeId"`
+	Status      OpenChannelsStatus          `json:"status"`
+	Reason      string                      `json:"reason,omitempty"`
+	SatPerVbyte *float64                    `json:"satPerVbyte"`
+	BudgetSat   int64                       `json:"budgetSat"`
+	Candidates  int                         `json:"candidates"`
+	Channels    []OpenChannelsResultChannel `json:"channels"`
+}
+
+type OpenChannelsResultChannel struct {
+	PeerNodeId   int    `json:"peerNodeId"`
+	PublicKey    string `json:"publicKey"`
+	AmountSat    int64  `json:"amountSat"`
+	ChannelPoint string `json:"channelPoint,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+func processOpenChannels(ctx context.Context,
+	db *sqlx.DB,
+	linkedChannelIds []int,
+	workflowNode WorkflowNode) (OpenChannelsResult, error) {
+
+	var params OpenChannelsConfiguration
+	err := json.Unmarshal([]byte(workflowNode.Parameters), &params)
+	if err != nil {
+		return OpenChannelsResult{}, errors.Wrapf(err, "Parse parameters for WorkflowVersionNodeId: %v", workflowNode.WorkflowVersionNodeId)
+	}
+	if !slices.Contains(cache.GetAllTorqNodeIds(), params.NodeId) {
+		return OpenChannelsResult{}, errors.New(fmt.Sprintf("Opening channels from unmanaged nodeId: %v for WorkflowVersionNodeId: %v",
+			params.NodeId, workflowNode.WorkflowVersionNodeId))
+	}
+	if params.MaxChannelSat <= 0 || params.MinChannelSat > params.MaxChannelSat {
+		return OpenChannelsResult{}, errors.New(fmt.Sprintf("Invalid channel size rules for WorkflowVersionNodeId: %v",
+			workflowNode.WorkflowVersionNodeId))
+	}
+	if params.MaxChannelsPerPeer <= 0 {
+		return OpenChannelsResult{}, errors.New(fmt.Sprintf("Invalid maximum channels per peer for WorkflowVersionNodeId: %v",
+			workflowNode.WorkflowVersionNodeId))
+	}
+	targetConf := int32(defaultOpenChannelsTargetConf)
+	if params.TargetConf != nil && *params.TargetConf > 0 {
+		targetConf = *params.TargetConf
+	}
+
+	result := OpenChannelsResult{NodeId: params.NodeId, Status: OpenChannelsStatusSkipped}
+
+	candidatePeerNodeIds, err := getOpenChannelsCandidates(db, params, linkedChannelIds)
+	if err != nil {
+		return OpenChannelsResult{}, errors.Wrapf(err, "Obtaining the candidates for WorkflowVersionNodeId: %v", workflowNode.WorkflowVersionNodeId)
+	}
+	result.Candidates = len(candidatePeerNodeIds)
+	if len(candidatePeerNodeIds) == 0 {
+		result.Reason = "no candidate peers"
+		return result, nil
+	}
+
+	feeEstimate, err := lightning.GetFeeEstimate(ctx, params.NodeId, targetConf)
+	if err != nil {
+		return OpenChannelsResult{}, errors.Wrapf(err, "Obtaining the fee estimate for WorkflowVersionNodeId: %v", workflowNode.WorkflowVersionNodeId)
+	}
+	result.SatPerVbyte = &feeEstimate.SatPerVbyte
+	if params.MaxSatPerVbyte > 0 && feeEstimate.SatPerVbyte > params.MaxSatPerVbyte {
+		result.Reason = fmt.Sprintf("fee estimate of %.1f sat/vB is above the ceiling of %.1f sat/vB",
+			feeEstimate.SatPerVbyte, params.MaxSatPerVbyte)
+		return result, nil
+	}
+	channelFee := getOpenChannelFee(feeEstimate.SatPerVbyte)
+
+	walletBalance, err := lightning.GetWalletBalance(ctx, params.NodeId)
+	if err != nil {
+		return OpenChannelsResult{}, errors.Wrapf(err, "Obtaining the wallet balance for WorkflowVersionNodeId: %v", workflowNode.WorkflowVersionNodeId)
+	}
+	result.BudgetSat = getOpenChannelsBudget(walletBalance.ConfirmedBalance, params)
+	if getOpenChannelAmount(result.BudgetSat, channelFee, params) == 0 {
+		result.Reason = fmt.Sprintf("budget of %v sat is below the minimum channel size plus a funding fee of %v sat",
+			result.BudgetSat, channelFee)
+		return result, nil
+	}
+
+	connectedPeers, err := lightning.ListPeers(ctx, params.NodeId, false)
+	if err != nil {
+		return OpenChannelsResult{}, errors.Wrapf(err, "Obtaining the peers for WorkflowVersionNodeId: %v", workflowNode.WorkflowVersionNodeId)
+	}
+
+	remaining := result.BudgetSat
+	for _, peerNodeId := range candidatePeerNodeIds {
+		amount := getOpenChannelAmount(remaining, channelFee, params)
+		if amount == 0 {
+			break
+		}
+		publicKey := cache.GetNodeSettingsByNodeId(peerNodeId).PublicKey
+		if _, connected := connectedPeers[publicKey]; !connected && !connectOpenChannelsCandidate(ctx, db, params.NodeId, peerNodeId, publicKey) {
+			continue
+		}
+		result.Channels = append(result.Channels, OpenChannelsResultChannel{
+			PeerNodeId: peerNodeId,
+			PublicKey:  publicKey,
+			AmountSat:  amount,
+		})
+		remaining -= amount + channelFee
+	}
+	if len(result.Channels) == 0 {
+		result.Reason = "none of the candidate peers could be connected"
+		return result, nil
+	}
+
+	openChannels(ctx, params, targetConf, &result)
+	return result, nil
+}
+
+// openChannels opens all channels in one batch when the implementation supports it and one by one otherwise.
+func openChannels(ctx context.Context, params OpenChannelsConfiguration, targetConf int32, result *OpenChannelsResult) {
+	private := params.Private
+	if len(result.Channels) > 1 {
+		request := lightning_helpers.BatchOpenChannelRequest{
+			CommunicationRequest: lightning_helpers.CommunicationRequest{
+				NodeId: params.NodeId,
+			},
+			TargetConf: &targetConf,
+		}
+		for _, channel := range result.Channels {
+			request.Channels = append(request.Channels, lightning_helpers.BatchOpenChannel{
+				NodePublicKey:      channel.PublicKey,
+				LocalFundingAmount: channel.AmountSat,
+				Private:            &private,
+			})
+		}
+		response, err := lightning.BatchOpenChannel(ctx, request)
+		switch {
+		case err == nil:
+			result.Status = OpenChannelsStatusOpened
+			for i := range result.Channels {
+				if i < len(response.PendingChannelPoints) {
+					result.Channels[i].ChannelPoint = response.PendingChannelPoints[i]
+				}
+			}
+			return
+		case !errors.Is(err, lightning.UnsupportedOperationError):
+			log.Error().Err(err).Msgf("Failed to batch open %v channels for nodeId: %v", len(result.Channels), params.NodeId)
+			result.Status = OpenChannelsStatusFailed
+			result.Reason = err.Error()
+			return
+		}
+	}
+
+	result.Status = OpenChannelsStatusFailed
+	for i, channel := range result.Channels {
+		response, err := lightning.OpenChannel(ctx, lightning_helpers.OpenChannelRequest{
+			CommunicationRequest: lightning_helpers.CommunicationRequest{
+				NodeId: params.NodeId,
+			},
+			NodePubKey:         channel.PublicKey,
+			LocalFundingAmount: channel.AmountSat,
+			TargetConf:         &targetConf,
+			Private:            &private,
+		})
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to open a channel with peer nodeId: %v for nodeId: %v",
+				channel.PeerNodeId, params.NodeId)
+			result.Channels[i].Error = err.Error()
+			continue
+		}
+		result.Channels[i].ChannelPoint = response.ChannelPoint
+		result.Status = OpenChannelsStatusOpened
+	}
+}
+
+func connectOpenChannelsCandidate(ctx context.Context, db *sqlx.DB, nodeId int, peerNodeId int, publicKey string) bool {
+	addresses, err := peers.GetNodeAddresses(db, peerNodeId)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to obtain the addresses of peer nodeId: %v", peerNodeId)
+		return false
+	}
+	for _, address := range addresses {
+		alreadyConnected, err := lightning.ConnectPeer(ctx, nodeId, publicKey, address)
+		if err == nil || alreadyConnected {
+			return true
+		}
+	}
+	return false
+}
+
+// getOpenChannelsCandidates returns the candidate peers, in order of nodeId, that are on the network of the node and
+// don't exceed the maximum number of channels with the node.
+func getOpenChannelsCandidates(db *sqlx.DB, params OpenChannelsConfiguration, linkedChannelIds []int) ([]int, error) {
+	torqNodeIds := cache.GetAllTorqNodeIds()
+	var channelIds []int
+	var nodeIds []int
+	if len(params.TagIds) != 0 || len(params.CategoryIds) != 0 {
+		var taggedEntities []struct {
+			NodeId    *int `db:"node_id"`
+			ChannelId *int `db:"channel_id"`
+		}
+		err := db.Select(&taggedEntities, `
+			SELECT te.node_id, te.channel_id
+			FROM tagged_entity te
+			JOIN tag t ON t.tag_id=te.tag_id
+			WHERE t.tag_id=ANY($1) OR t.category_id=ANY($2);`,
+			pq.Array(params.TagIds), pq.Array(params.CategoryIds))
+		if err != nil {
+			return nil, errors.Wrap(err, database.SqlExecutionError)
+		}
+		for _, taggedEntity := range taggedEntities {
+			if taggedEntity.NodeId != nil {
+				nodeIds = append(nodeIds, *taggedEntity.NodeId)
+			}
+			if taggedEntity.ChannelId != nil {
+				channelIds = append(channelIds, *taggedEntity.ChannelId)
+			}
+		}
+	}
+	for _, channelId := range append(channelIds, linkedChannelIds...) {
+		channelSettings := cache.GetChannelSettingByChannelId(channelId)
+		nodeIds = append(nodeIds, channelSettings.FirstNodeId, channelSettings.SecondNodeId)
+	}
+
+	nodeSettings := cache.GetNodeSettingsByNodeId(params.NodeId)
+	channelCounts := make(map[int]int)
+	for _, channel := range cache.GetChannelSettingsByNodeId(params.NodeId) {
+		if channel.Status != core.Opening && channel.Status != core.Open {
+			continue
+		}
+		channelCounts[channel.FirstNodeId]++
+		channelCounts[channel.SecondNodeId]++
+	}
+
+	var candidates []int
+	for _, nodeId := range nodeIds {
+		if nodeId == 0 || slices.Contains(torqNodeIds, nodeId) || slices.Contains(candidates, nodeId) {
+			continue
+		}
+		peerSettings := cache.GetNodeSettingsByNodeId(nodeId)
+		if peerSettings.PublicKey == "" || peerSettings.Chain != nodeSettings.Chain || peerSettings.Network != nodeSettings.Network {
+			continue
+		}
+		if channelCounts[nodeId] >= params.MaxChannelsPerPeer {
+			continue
+		}
+		candidates = append(candidates, nodeId)
+	}
+	sort.Ints(candidates)
+	return candidates, nil
+}
+
+// getOpenChannelsBudget is the amount that can be committed to new channels and their funding fees during a run.
+func getOpenChannelsBudget(confirmedBalance int64, params OpenChannelsConfiguration) int64 {
+	budget := confirmedBalance - params.MinReserveSat
+	if params.MaxTotalSat > 0 && budget > params.MaxTotalSat {
+		budget = params.MaxTotalSat
+	}
+	if budget < 0 {
+		return 0
+	}
+	return budget
+}
+
+// getOpenChannelFee is the estimated funding fee per channel in sat.
+func getOpenChannelFee(satPerVbyte float64) int64 {
+	return int64(math.Ceil(satPerVbyte * openChannelVbytes))
+}
+
+// getOpenChannelAmount is the size of the next channel given the remaining budget and the funding fee of the
+// channel, 0 when it's too small.
+func getOpenChannelAmount(remaining int64, channelFee int64, params OpenChannelsConfiguration) int64 {
+	amount := params.MaxChannelSat
+	if remaining-channelFee < amount {
+		amount = remaining - channelFee
+	}
+	if amount <= 0 || amount < params.MinChannelSat {
+		return 0
+	}
+	return amount
+}
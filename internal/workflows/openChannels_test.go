@@ -0,0 +1,56 @@
+package workflows
+
+import "testing"
+
+func TestGetOpenChannelsBudget(t *testing.T) {
+	params := OpenChannelsConfiguration{MinReserveSat: 1_000_000, MaxTotalSat: 5_000_000}
+	tests := map[int64]int64{
+		500_000:    0,
+		3_000_000:  2_000_000,
+		10_000_000: 5_000_000,
+	}
+	for confirmedBalance, expected := range tests {
+		if budget := getOpenChannelsBudget(confirmedBalance, params); budget != expected {
+			t.Errorf("expected a budget of %v for a balance of %v, got %v", expected, confirmedBalance, budget)
+		}
+	}
+
+	params.MaxTotalSat = 0
+	if budget := getOpenChannelsBudget(10_000_000, params); budget != 9_000_000 {
+		t.Errorf("expected the budget without a maximum to be the balance minus the reserve, got %v", budget)
+	}
+}
+
+func TestGetOpenChannelAmount(t *testing.T) {
+	params := OpenChannelsConfiguration{MinChannelSat: 1_000_000, MaxChannelSat: 3_000_000}
+	tests := map[int64]int64{
+		0:         0,
+		999_999:   0,
+		1_000_000: 1_000_000,
+		2_500_000: 2_500_000,
+		7_000_000: 3_000_000,
+	}
+	for remaining, expected := range tests {
+		if amount := getOpenChannelAmount(remaining, 0, params); amount != expected {
+			t.Errorf("expected a channel of %v with %v remaining, got %v", expected, remaining, amount)
+		}
+	}
+
+	// the funding fee is taken from the remaining budget
+	channelFee := getOpenChannelFee(20)
+	if channelFee != 3_080 {
+		t.Errorf("expected a funding fee of 3080 sat at 20 sat/vB, got %v", channelFee)
+	}
+	tests = map[int64]int64{
+		1_000_000: 0,
+		1_003_080: 1_000_000,
+		2_500_000: 2_496_920,
+		7_000_000: 3_000_000,
+	}
+	for remaining, expected := range tests {
+		if amount := getOpenChannelAmount(remaining, channelFee, params); amount != expected {
+			t.Errorf("expected a channel of %v with %v remaining and a fee of %v, got %v",
+				expected, remaining, channelFee, amount)
+		}
+	}
+}
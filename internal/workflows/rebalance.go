@@ -7,11 +7,16 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/exp/slices"
 	"google.golang.org/grpc"
 
@@ -24,7 +29,10 @@ import (
 	"github.com/lncapital/torq/internal/cache"
 	"github.com/lncapital/torq/internal/channels"
 	"github.com/lncapital/torq/internal/core"
+	"github.com/lncapital/torq/internal/lightning"
 	"github.com/lncapital/torq/internal/rebalances"
+	"github.com/lncapital/torq/internal/services_helpers"
+	"github.com/lncapital/torq/internal/tracing"
 )
 
 const rebalanceQueueTickerSeconds = 10
@@ -37,6 +45,7 @@ const rebalanceRoutesTimeoutSeconds = 1 * 60
 const rebalancePayTimeoutSeconds = 10 * 60
 const rebalanceMinimumDeltaSeconds = 10 * 60
 const rebalancePreviousSuccessResultTimeoutMinutes = 5
+const rebalanceShutdownPollMilliseconds = 500
 
 type Rebalancer struct {
 	NodeId          int
@@ -50,6 +59,22 @@ type Rebalancer struct {
 	RebalanceCancel context.CancelFunc
 	Runners         map[int]*RebalanceRunner
 	Request         lightning_helpers.RebalanceRequest
+	// lastPaymentHash is the payment hash of the latest attempt, when Torq shuts down while that attempt is in flight
+	// the rebalance is only requested again once it's known the payment didn't succeed
+	lastPaymentHash      string
+	lastPaymentHashMutex sync.Mutex
+}
+
+func (rebalancer *Rebalancer) setLastPaymentHash(paymentHash []byte) {
+	rebalancer.lastPaymentHashMutex.Lock()
+	defer rebalancer.lastPaymentHashMutex.Unlock()
+	rebalancer.lastPaymentHash = hex.EncodeToString(paymentHash)
+}
+
+func (rebalancer *Rebalancer) getLastPaymentHash() string {
+	rebalancer.lastPaymentHashMutex.Lock()
+	defer rebalancer.lastPaymentHashMutex.Unlock()
+	return rebalancer.lastPaymentHash
 }
 
 type RebalanceRunner struct {
@@ -101,6 +126,9 @@ func RebalanceServiceStart(ctx context.Context, conn *grpc.ClientConn, db *sqlx.
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			if cache.IsShuttingDown() {
+				continue
+			}
 			activeRebalancers := getRebalancers(&active)
 			log.Trace().Msgf("Active rebalancers: %v/%v", len(activeRebalancers), rebalanceMaximumConcurrency)
 			if len(activeRebalancers) >= rebalanceMaximumConcurrency {
@@ -162,6 +190,20 @@ func RebalanceRequests(ctx context.Context,
 	requests lightning_helpers.RebalanceRequests,
 	nodeId int) []lightning_helpers.RebalanceResponse {
 
+	if cache.IsShuttingDown() {
+		var responses []lightning_helpers.RebalanceResponse
+		for _, request := range requests.Requests {
+			responses = append(responses, lightning_helpers.RebalanceResponse{
+				Request: request,
+				CommunicationResponse: lightning_helpers.CommunicationResponse{
+					Status: lightning_helpers.Inactive,
+					Error:  "Torq is shutting down, the rebalance request was not accepted",
+				},
+			})
+		}
+		return responses
+	}
+
 	var incoming bool
 	var outgoing bool
 	for _, request := range requests.Requests {
@@ -603,12 +645,20 @@ func (rebalancer *Rebalancer) startRunner(
 	payTimeout int,
 	result rebalances.RebalanceResult) rebalances.RebalanceResult {
 
-	routesCtx, routesCancel := context.WithTimeout(runner.Ctx, time.Second*time.Duration(routesTimeout))
+	ctx, span := otel.Tracer(name).Start(runner.Ctx, "rebalanceRunner", trace.WithAttributes(
+		attribute.Int("nodeId", rebalancer.NodeId),
+		attribute.Int("incomingChannelId", runner.IncomingChannelId),
+		attribute.Int("outgoingChannelId", runner.OutgoingChannelId),
+		attribute.Int64("amountMsat", int64(rebalancer.Request.AmountMsat))))
+	defer span.End()
+
+	routesCtx, routesCancel := context.WithTimeout(ctx, time.Second*time.Duration(routesTimeout))
 	defer routesCancel()
 	routes, err := runner.getRoutes(routesCtx, client, router, rebalancer.NodeId,
 		rebalancer.Request.AmountMsat, rebalancer.Request.MaximumCostMsat)
 	if err != nil {
-		log.Debug().Err(err).Msgf(
+		span.RecordError(err)
+		tracing.Log(ctx).Debug().Err(err).Msgf(
 			"Failed to obtain routes from LND for incomingChannelId: %v, outgoingChannelId: %v",
 			runner.IncomingChannelId, runner.OutgoingChannelId)
 		result.Status = core.Inactive
@@ -621,7 +671,12 @@ func (rebalancer *Rebalancer) startRunner(
 	routesCancel()
 
 	for _, route := range routes {
-		payCtx, payCancel := context.WithTimeout(runner.Ctx, time.Second*time.Duration(payTimeout))
+		payCtx, payCancel := context.WithTimeout(ctx, time.Second*time.Duration(payTimeout))
+		// The invoice is cached by the runner so pay uses the same payment hash
+		invoice, err := runner.createInvoice(payCtx, client, rebalancer.Request.AmountMsat)
+		if err == nil {
+			rebalancer.setLastPaymentHash(invoice.RHash)
+		}
 		result = runner.pay(payCtx, client, router, rebalancer.Request.AmountMsat, route)
 		payCancel()
 		if payCtx.Err() == context.DeadlineExceeded {
@@ -629,8 +684,12 @@ func (rebalancer *Rebalancer) startRunner(
 		}
 		rebalancer.processResult(db, result)
 		if result.Status == core.Active {
+			span.SetStatus(codes.Ok, "")
 			return result
 		}
+		if result.Error != "" {
+			span.SetStatus(codes.Error, result.Error)
+		}
 	}
 
 	if result.Status == core.Pending {
@@ -797,6 +856,8 @@ func (rebalancer *Rebalancer) processResult(db *sqlx.DB, result rebalances.Rebal
 		log.Error().Err(err).Msgf("Failed to add rebalance log entry for rebalanceId: %v (ref: %v)",
 			rebalancer.RebalanceId, rebalancer.Request.OriginReference)
 	}
+	services_helpers.GetMetrics().AddRebalanceAttempt(rebalancer.NodeId, rebalancer.Request.Origin.String(),
+		result.Status == core.Active, result.TotalFeeMsat)
 }
 
 func (runner *RebalanceRunner) getRoutes(
@@ -1178,3 +1239,59 @@ func AddRebalance(db *sqlx.DB, rebalancer *Rebalancer) error {
 	}
 	return nil
 }
+
+// ShutdownRebalancers gives the active rebalancers up to gracePeriod to finish.
+// The remaining rebalancers are cancelled, the active ones are stored with the payment hash of their last attempt so
+// they are reported and checked on the next start.
+func ShutdownRebalancers(db *sqlx.DB, gracePeriod time.Duration) {
+	active := core.Active
+	deadline := time.Now().Add(gracePeriod)
+	for {
+		activeRebalancers := getRebalancers(&active)
+		if len(activeRebalancers) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			log.Info().Msgf("Grace period expired with %v active rebalancers", len(activeRebalancers))
+			break
+		}
+		time.Sleep(rebalanceShutdownPollMilliseconds * time.Millisecond)
+	}
+
+	for _, rebalancer := range getRebalancers(nil) {
+		wasActive := rebalancer.Status == core.Active
+		removeRebalancer(rebalancer)
+		rebalancer.RebalanceCancel()
+		// Inactive stops the runners from picking up new channels
+		rebalancer.Status = core.Inactive
+		rebalancer.UpdateOn = time.Now().UTC()
+		if rebalancer.RebalanceId != 0 {
+			err := rebalances.SetRebalanceStatus(db, rebalancer.RebalanceId, rebalancer.Status, rebalancer.UpdateOn)
+			if err != nil {
+				log.Error().Err(err).Msgf("Updating the status of interrupted rebalance %v", rebalancer.RebalanceId)
+			}
+		}
+		// Queued rebalancers didn't send anything yet
+		if !wasActive {
+			continue
+		}
+		var paymentHash *string
+		if lastPaymentHash := rebalancer.getLastPaymentHash(); lastPaymentHash != "" {
+			paymentHash = &lastPaymentHash
+		}
+		err := storeInterruptedOperation(db, rebalancer.NodeId, lightning.RebalanceOperation, rebalancer.Request,
+			paymentHash)
+		if err != nil {
+			log.Error().Err(err).Msgf("Storing the interrupted rebalance "+
+				"for origin: %v, originReference: %v, incomingChannelId: %v, outgoingChannelId: %v",
+				rebalancer.Request.Origin, rebalancer.Request.OriginReference,
+				rebalancer.Request.IncomingChannelId, rebalancer.Request.OutgoingChannelId)
+			continue
+		}
+		log.Info().Msgf("Interrupted rebalance "+
+			"for origin: %v, originReference: %v, incomingChannelId: %v, outgoingChannelId: %v, "+
+			"it's checked on the next start",
+			rebalancer.Request.Origin, rebalancer.Request.OriginReference,
+			rebalancer.Request.IncomingChannelId, rebalancer.Request.OutgoingChannelId)
+	}
+}
@@ -80,6 +80,45 @@ func RegisterWorkflowRoutes(r *gin.RouterGroup, db *sqlx.DB) {
 
 }
 
+// RegisterInterruptedOperationRoutes lists the operations that were interrupted by a shutdown and weren't resumed.
+func RegisterInterruptedOperationRoutes(r *gin.RouterGroup, db *sqlx.DB) {
+	r.GET("", func(c *gin.Context) { getInterruptedOperationsHandler(c, db) })
+	r.DELETE(":interruptedOperationId", func(c *gin.Context) { removeInterruptedOperationHandler(c, db) })
+}
+
+func getInterruptedOperationsHandler(c *gin.Context, db *sqlx.DB) {
+	network, err := strconv.Atoi(c.Query("network"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Can't process network")
+		return
+	}
+	interruptedOperations, err := getInterruptedOperations(db,
+		cache.GetAllTorqNodeIdsByNetwork(core.Bitcoin, core.Network(network)))
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Getting interrupted operations")
+		return
+	}
+	if interruptedOperations == nil {
+		interruptedOperations = []InterruptedOperation{}
+	}
+	c.JSON(http.StatusOK, interruptedOperations)
+}
+
+// removeInterruptedOperationHandler dismisses an interrupted operation after the user checked it on the node.
+func removeInterruptedOperationHandler(c *gin.Context, db *sqlx.DB) {
+	interruptedOperationId, err := strconv.Atoi(c.Param("interruptedOperationId"))
+	if err != nil {
+		server_errors.SendBadRequest(c, "Failed to find/parse interruptedOperationId in the request.")
+		return
+	}
+	err = removeInterruptedOperation(db, interruptedOperationId)
+	if err != nil {
+		server_errors.WrapLogAndSendServerError(c, err, "Removing interrupted operation")
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
 func getWorkflowsHandler(c *gin.Context, db *sqlx.DB) {
 	workflows, err := GetWorkflows(db)
 	if err != nil {
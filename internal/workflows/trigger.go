@@ -11,6 +11,9 @@ import (
 	"github.com/cockroachdb/errors"
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/exp/slices"
 
 	"github.com/lncapital/torq/internal/cache"
@@ -20,9 +23,12 @@ import (
 	"github.com/lncapital/torq/internal/lightning_helpers"
 	"github.com/lncapital/torq/internal/services_helpers"
 	"github.com/lncapital/torq/internal/tags"
+	"github.com/lncapital/torq/internal/tracing"
 	"github.com/lncapital/torq/internal/workflow_helpers"
 )
 
+const name = "workflows"
+
 type workflowVersionNodeIdType int
 type stageType int
 
@@ -95,28 +101,28 @@ func ProcessWorkflow(ctx context.Context, db *sqlx.DB,
 
 	switch workflowTriggerNode.Type {
 	case workflow_helpers.WorkflowNodeIntervalTrigger:
-		log.Debug().Msgf("Interval Trigger Fired for WorkflowVersionNodeId: %v",
+		tracing.Log(ctx).Debug().Msgf("Interval Trigger Fired for WorkflowVersionNodeId: %v",
 			workflowTriggerNode.WorkflowVersionNodeId)
 	case workflow_helpers.WorkflowNodeCronTrigger:
-		log.Debug().Msgf("Cron Trigger Fired for WorkflowVersionNodeId: %v",
+		tracing.Log(ctx).Debug().Msgf("Cron Trigger Fired for WorkflowVersionNodeId: %v",
 			workflowTriggerNode.WorkflowVersionNodeId)
 	case workflow_helpers.WorkflowNodeChannelBalanceEventTrigger:
-		log.Debug().Msgf("Channel Balance Event Trigger Fired for WorkflowVersionNodeId: %v",
+		tracing.Log(ctx).Debug().Msgf("Channel Balance Event Trigger Fired for WorkflowVersionNodeId: %v",
 			workflowTriggerNode.WorkflowVersionNodeId)
 		workflowNodeOutputCache[workflowVersionNodeIdType(workflowTriggerNode.WorkflowVersionNodeId)][workflow_helpers.WorkflowParameterLabelChannels] = string(marshalledEventChannelIdsFromEvents)
 	case workflow_helpers.WorkflowNodeChannelOpenEventTrigger:
-		log.Debug().Msgf("Channel Open Event Trigger Fired for WorkflowVersionNodeId: %v",
+		tracing.Log(ctx).Debug().Msgf("Channel Open Event Trigger Fired for WorkflowVersionNodeId: %v",
 			workflowTriggerNode.WorkflowVersionNodeId)
 		workflowNodeOutputCache[workflowVersionNodeIdType(workflowTriggerNode.WorkflowVersionNodeId)][workflow_helpers.WorkflowParameterLabelChannels] = string(marshalledEventChannelIdsFromEvents)
 	case workflow_helpers.WorkflowNodeChannelCloseEventTrigger:
-		log.Debug().Msgf("Channel Close Event Trigger Fired for WorkflowVersionNodeId: %v",
+		tracing.Log(ctx).Debug().Msgf("Channel Close Event Trigger Fired for WorkflowVersionNodeId: %v",
 			workflowTriggerNode.WorkflowVersionNodeId)
 		workflowNodeOutputCache[workflowVersionNodeIdType(workflowTriggerNode.WorkflowVersionNodeId)][workflow_helpers.WorkflowParameterLabelChannels] = string(marshalledEventChannelIdsFromEvents)
 	case workflow_helpers.WorkflowTrigger:
-		log.Debug().Msgf("Trigger Fired for WorkflowVersionNodeId: %v",
+		tracing.Log(ctx).Debug().Msgf("Trigger Fired for WorkflowVersionNodeId: %v",
 			workflowTriggerNode.WorkflowVersionNodeId)
 	case workflow_helpers.WorkflowNodeManualTrigger:
-		log.Debug().Msgf("Manual Trigger Fired for WorkflowVersionNodeId: %v",
+		tracing.Log(ctx).Debug().Msgf("Manual Trigger Fired for WorkflowVersionNodeId: %v",
 			workflowTriggerNode.WorkflowVersionNodeId)
 	}
 
@@ -305,6 +311,11 @@ linkedInputLoop:
 		return core.Pending, nil
 	}
 
+	ctx, span := otel.Tracer(name).Start(ctx, "processWorkflowNode", trace.WithAttributes(
+		attribute.Int("workflowVersionNodeId", workflowNode.WorkflowVersionNodeId),
+		attribute.Int("workflowNodeType", int(workflowNode.Type))))
+	defer span.End()
+
 	inputs := workflowNodeInputCache[workflowVersionNodeIdType(workflowNode.WorkflowVersionNodeId)]
 	inputsByReferenceId := workflowNodeInputByReferenceIdCache[workflowVersionNodeIdType(workflowNode.WorkflowVersionNodeId)]
 	outputs := workflowNodeOutputCache[workflowVersionNodeIdType(workflowNode.WorkflowVersionNodeId)]
@@ -480,6 +491,33 @@ linkedInputLoop:
 		if err != nil {
 			return core.Inactive, errors.Wrapf(err, "Adding or removing tags with ChannelIds: %v for WorkflowVersionNodeId: %v", linkedChannelIds, workflowNode.WorkflowVersionNodeId)
 		}
+	case workflow_helpers.WorkflowNodeOpenChannels:
+		// The channels are optional, without them the candidates come from the tags and categories
+		linkedChannelIds, _ := getChannelIds(inputs, workflow_helpers.WorkflowParameterLabelChannels)
+
+		result, err := processOpenChannels(ctx, db, linkedChannelIds, workflowNode)
+		if err != nil {
+			return core.Inactive, errors.Wrapf(err, "Opening channels for WorkflowVersionNodeId: %v", workflowNode.WorkflowVersionNodeId)
+		}
+
+		marshalledResult, err := json.Marshal(result)
+		if err != nil {
+			return core.Inactive, errors.Wrapf(err, "Marshalling Open Channels Result for WorkflowVersionNodeId: %v", workflowNode.WorkflowVersionNodeId)
+		}
+		// TODO FIXME create a more uniform status object
+		outputs[workflow_helpers.WorkflowParameterLabelStatus] = string(marshalledResult)
+	case workflow_helpers.WorkflowNodeBumpFees:
+		result, err := processBumpFees(ctx, db, workflowNode)
+		if err != nil {
+			return core.Inactive, errors.Wrapf(err, "Bumping fees for WorkflowVersionNodeId: %v", workflowNode.WorkflowVersionNodeId)
+		}
+
+		marshalledResult, err := json.Marshal(result)
+		if err != nil {
+			return core.Inactive, errors.Wrapf(err, "Marshalling Bump Fees Result for WorkflowVersionNodeId: %v", workflowNode.WorkflowVersionNodeId)
+		}
+		// TODO FIXME create a more uniform status object
+		outputs[workflow_helpers.WorkflowParameterLabelStatus] = string(marshalledResult)
 	case workflow_helpers.WorkflowNodeChannelPolicyConfigurator:
 		linkedChannelIds, err := getChannelIds(inputs, workflow_helpers.WorkflowParameterLabelChannels)
 		if err != nil {
@@ -64,6 +64,7 @@ func Connect(host string,
 		grpc.WithChainUnaryInterceptor(
 			timeout.UnaryClientInterceptor(grpc_helpers.UnaryTimeout),
 			otelgrpc.UnaryClientInterceptor(),
+			grpc_helpers.UnaryClientMetricsInterceptor(),
 			clMetrics.UnaryClientInterceptor(grpcprom.WithExemplarFromContext(exemplarFromContext)),
 			logging.UnaryClientInterceptor(grpc_helpers.InterceptorLogger(logger), loggerOpts...),
 		),
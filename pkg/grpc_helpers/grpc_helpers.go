@@ -8,7 +8,12 @@ import (
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
 )
 
 const UnaryTimeout = 2 * time.Minute
@@ -16,6 +21,9 @@ const UnaryTimeout = 2 * time.Minute
 // RecvMsgSize max size to 25mb
 const RecvMsgSize = 25 << (10 * 2)
 
+// GraphRecvMsgSize max size of the full network graph to 256mb
+const GraphRecvMsgSize = 256 << (10 * 2)
+
 // InterceptorLogger adapts zerolog logger to interceptor logger.
 // This code is simple enough to be copied and not imported.
 func InterceptorLogger(l zerolog.Logger) logging.Logger {
@@ -64,3 +72,24 @@ func GetExemplarFromContext() func(ctx context.Context) prometheus.Labels {
 	}
 	return exemplarFromContext
 }
+
+// UnaryClientMetricsInterceptor records the duration of the unary requests to the node with the global OpenTelemetry
+// meter provider, the measurements are only exported when an OTLP exporter is configured.
+func UnaryClientMetricsInterceptor() grpc.UnaryClientInterceptor {
+	duration, err := global.Meter("github.com/lncapital/torq/pkg/grpc_helpers").Float64Histogram(
+		"rpc.client.duration",
+		metric.WithUnit("ms"),
+		metric.WithDescription("Duration of the gRPC requests to the node"))
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		start := time.Now()
+		invokeErr := invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil {
+			duration.Record(ctx, float64(time.Since(start))/float64(time.Millisecond), metric.WithAttributes(
+				attribute.String("rpc.method", method),
+				attribute.String("rpc.grpc.status_code", status.Code(invokeErr).String())))
+		}
+		return invokeErr
+	}
+}
@@ -54,6 +54,7 @@ func Connect(host string, tlsCert []byte, macaroonBytes []byte) (*grpc.ClientCon
 		grpc.WithChainUnaryInterceptor(
 			timeout.UnaryClientInterceptor(grpc_helpers.UnaryTimeout),
 			otelgrpc.UnaryClientInterceptor(),
+			grpc_helpers.UnaryClientMetricsInterceptor(),
 			clMetrics.UnaryClientInterceptor(grpcprom.WithExemplarFromContext(exemplarFromContext)),
 			logging.UnaryClientInterceptor(grpc_helpers.InterceptorLogger(logger), loggerOpts...),
 		),
@@ -166,7 +166,7 @@ func (srv *Server) NewTestDatabase() (*sqlx.DB, context.CancelFunc, error) {
 	ctx, cancel := context.WithCancel(ctx)
 
 	registry := prometheus.NewRegistry()
-	services_helpers.SetMetrics(services_helpers.NewMetrics(registry))
+	services_helpers.SetMetrics(services_helpers.NewMetrics(registry, services_helpers.ChannelLabelsChannel))
 	prometheus2.SetRegistry(registry)
 
 	// Migrate the new test database
@@ -231,6 +231,10 @@ func (srv *Server) NewTestDatabase() (*sqlx.DB, context.CancelFunc, error) {
 	go cache.ChannelsCacheHandler(cache.ChannelsCacheChannel, ctx)
 	go cache.TaggedCacheHandler(cache.TaggedCacheChannel, ctx)
 	go cache.TriggersCacheHandler(cache.TriggersCacheChannel, ctx)
+	go cache.ForecastsCacheHandler(cache.ForecastsCacheChannel, ctx)
+	go cache.CompetitorFeesCacheHandler(cache.CompetitorFeesCacheChannel, ctx)
+	go cache.PeerReliabilityCacheHandler(cache.PeerReliabilityCacheChannel, ctx)
+	go cache.PeerReconnectsCacheHandler(cache.PeerReconnectsCacheChannel, ctx)
 	go tags.TagsCacheHandler(tags.TagsCacheChannel, ctx)
 	// TODO FIXME cyclic dependency so if you need this in tests then initialise it in the test
 	//go automation.RebalanceCache(automation.ManagedRebalanceChannel, ctx)
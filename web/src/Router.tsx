@@ -33,6 +33,7 @@ import ManageTags from "pages/tags/manageTagsPopout/ManageTags";
 import MessageVerificationModal from "pages/messageVerificationPage/MessageVerificationModal";
 import ClosedChannelsPage from "features/channelsClosed/ChannelsClosedPage";
 import ChannelsPendingPage from "features/channelsPending/ChannelsPendingPage";
+import ForceClosesPage from "features/forceCloses/ForceClosesPage";
 import FowardsSummaryPage from "features/channel/ForwardsSummary";
 import PeersPage from "features/peers/PeersPage";
 import PeerConnectModal from "features/peers/peerConnectModal";
@@ -99,6 +100,7 @@ const authenticatedRoutes: RouteObject = {
             { path: routes.OPEN_CHANNELS, element: <ChannelsPage /> },
             { path: routes.PENDING_CHANNELS, element: <ChannelsPendingPage /> },
             { path: routes.CLOSED_CHANNELS, element: <ClosedChannelsPage /> },
+            { path: routes.FORCE_CLOSES, element: <ForceClosesPage /> },
           ],
         },
         {
@@ -20,6 +20,7 @@ import type {
 } from "types/api";
 import { queryParamsBuilder } from "utils/queryParamsBuilder";
 import { Forward } from "features/forwards/forwardsTypes";
+import { ForceClosingChannel } from "features/forceCloses/forceClosesTypes";
 import type { nodeConfiguration, settings, timeZone, services, updateSettingsRequest } from "apiTypes";
 import { createSelector } from "@reduxjs/toolkit";
 import { Network } from "features/network/networkSlice";
@@ -126,6 +127,12 @@ export const torqApi = createApi({
       }),
       providesTags: ["channelsPending"],
     }),
+    getForceClosingChannels: builder.query<ForceClosingChannel[], ActiveNetwork>({
+      query: (params) => ({
+        url: `force-closes` + queryParamsBuilder(params),
+        method: "GET",
+      }),
+    }),
     updateChannel: builder.mutation<UpdateChannelResponse, PolicyInterface>({
       query: (data: PolicyInterface) => ({
         url: "lightning/updateRoutingPolicy",
@@ -299,6 +306,7 @@ export const {
   useGetAutoLoginSettingQuery,
   useGetChannelsClosedQuery,
   useGetChannelsPendingQuery,
+  useGetForceClosingChannelsQuery,
 } = torqApi;
 
 export const SelectChannel = (props: { network: Network; channelId: number }) => {
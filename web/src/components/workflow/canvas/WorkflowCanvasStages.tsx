@@ -25,6 +25,8 @@ import {
   ChannelCloseTriggerNode,
   DataSourceTorqChannelsNode,
   ChannelBalanceEventFilterNode,
+  OpenChannelsNode,
+  BumpFeesNode,
 } from "components/workflow/nodes/nodes";
 import { WorkflowVersionNode } from "pages/WorkflowPage/workflowTypes";
 import classNames from "classnames";
@@ -223,6 +225,10 @@ function getNodeComponent(node: WorkflowVersionNode) {
       return <DataSourceTorqChannelsNode {...node} key={"node-id-" + node.workflowVersionNodeId} />;
     case WorkflowNodeType.ChannelBalanceEventFilter:
       return <ChannelBalanceEventFilterNode {...node} key={"node-id-" + node.workflowVersionNodeId} />;
+    case WorkflowNodeType.OpenChannels:
+      return <OpenChannelsNode {...node} key={"node-id-" + node.workflowVersionNodeId} />;
+    case WorkflowNodeType.BumpFees:
+      return <BumpFeesNode {...node} key={"node-id-" + node.workflowVersionNodeId} />;
     default:
       return null;
   }
@@ -0,0 +1,217 @@
+import React, { useContext, useEffect, useState } from "react";
+import { FastForward20Regular as BumpFeesIcon, Save16Regular as SaveIcon } from "@fluentui/react-icons";
+import useTranslations from "services/i18n/useTranslations";
+import WorkflowNodeWrapper, { WorkflowNodeProps } from "components/workflow/nodeWrapper/WorkflowNodeWrapper";
+import { NodeColorVariant } from "components/workflow/nodes/nodeVariants";
+import { useUpdateNodeMutation } from "pages/WorkflowPage/workflowApi";
+import Button, { ColorVariant, SizeVariant } from "components/buttons/Button";
+import { NumberFormatValues } from "react-number-format";
+import Spinny from "features/spinny/Spinny";
+import { WorkflowContext } from "components/workflow/WorkflowContext";
+import { Status } from "constants/backend";
+import ToastContext from "features/toast/context";
+import { toastCategory } from "features/toast/Toasts";
+import { Input, InputSizeVariant, Form, RadioChips, Select } from "components/forms/forms";
+import { useGetNodeConfigurationsQuery } from "apiSlice";
+import { nodeConfiguration } from "apiTypes";
+
+type BumpFeesNodeProps = Omit<WorkflowNodeProps, "colorVariant">;
+
+type SelectOption = {
+  label?: string;
+  value: number;
+};
+
+export type BumpFeesConfiguration = {
+  nodeId?: number;
+  blocks?: number;
+  targetConf?: number;
+  satPerVbyte?: number;
+  maxSatPerVbyte?: number;
+  bumpIncoming: boolean;
+  force: boolean;
+};
+
+export function BumpFeesNode({ ...wrapperProps }: BumpFeesNodeProps) {
+  const { t } = useTranslations();
+  const { workflowStatus } = useContext(WorkflowContext);
+  const editingDisabled = workflowStatus === Status.Active;
+  const toastRef = React.useContext(ToastContext);
+
+  const [updateNode] = useUpdateNodeMutation();
+
+  const [configuration, setConfiguration] = useState<BumpFeesConfiguration>({
+    bumpIncoming: false,
+    force: false,
+    ...wrapperProps.parameters,
+  });
+
+  const [dirty, setDirty] = useState(false);
+  const [processing, setProcessing] = useState(false);
+  useEffect(() => {
+    setDirty(
+      JSON.stringify(wrapperProps.parameters, Object.keys(wrapperProps.parameters).sort()) !==
+        JSON.stringify(configuration, Object.keys(configuration).sort())
+    );
+  }, [configuration, wrapperProps.parameters]);
+
+  const { data: nodeConfigurations } = useGetNodeConfigurationsQuery();
+  const nodeOptions: SelectOption[] = (nodeConfigurations || []).map((node: nodeConfiguration) => {
+    return { label: node.name, value: node.nodeId };
+  });
+
+  function createChangeHandler(key: keyof BumpFeesConfiguration) {
+    return (e: NumberFormatValues) => {
+      setConfiguration((prev) => ({
+        ...prev,
+        [key]: e.floatValue,
+      }));
+    };
+  }
+
+  // Only one of the confirmation target and the fee rate is used
+  function createFeeChangeHandler(key: "targetConf" | "satPerVbyte") {
+    const other = key === "targetConf" ? "satPerVbyte" : "targetConf";
+    return (e: NumberFormatValues) => {
+      setConfiguration((prev) => ({
+        ...prev,
+        [key]: e.floatValue,
+        [other]: e.floatValue === undefined ? prev[other] : undefined,
+      }));
+    };
+  }
+
+  function handleSubmit(e: React.FormEvent<HTMLFormElement>) {
+    e.preventDefault();
+
+    if (editingDisabled) {
+      toastRef?.current?.addToast(t.toast.cannotModifyWorkflowActive, toastCategory.warn);
+      return;
+    }
+
+    setProcessing(true);
+    updateNode({
+      workflowVersionNodeId: wrapperProps.workflowVersionNodeId,
+      parameters: configuration,
+    }).finally(() => {
+      setProcessing(false);
+    });
+  }
+
+  return (
+    <WorkflowNodeWrapper
+      {...wrapperProps}
+      heading={t.workflowNodes.bumpFees}
+      headerIcon={<BumpFeesIcon />}
+      colorVariant={NodeColorVariant.accent2}
+    >
+      <Form onSubmit={handleSubmit} intercomTarget={"bump-fees-node-form"}>
+        <Select
+          intercomTarget={"bump-fees-node-node-select"}
+          options={nodeOptions}
+          onChange={(newValue: unknown) =>
+            setConfiguration((prev) => ({ ...prev, nodeId: (newValue as SelectOption).value }))
+          }
+          label={t.node}
+          sizeVariant={InputSizeVariant.small}
+          value={nodeOptions.find((option) => option.value === configuration.nodeId)}
+          isDisabled={editingDisabled}
+        />
+        <Input
+          intercomTarget={"bump-fees-node-blocks-input"}
+          formatted={true}
+          value={configuration.blocks}
+          thousandSeparator={","}
+          suffix={" blocks"}
+          onValueChange={createChangeHandler("blocks")}
+          label={t.bumpFeesNode.blocks}
+          sizeVariant={InputSizeVariant.small}
+          disabled={editingDisabled}
+        />
+        <Input
+          intercomTarget={"bump-fees-node-target-conf-input"}
+          formatted={true}
+          value={configuration.targetConf}
+          thousandSeparator={","}
+          suffix={" blocks"}
+          onValueChange={createFeeChangeHandler("targetConf")}
+          label={t.bumpFeesNode.targetConf}
+          sizeVariant={InputSizeVariant.small}
+          disabled={editingDisabled}
+        />
+        <Input
+          intercomTarget={"bump-fees-node-fee-rate-input"}
+          formatted={true}
+          value={configuration.satPerVbyte}
+          thousandSeparator={","}
+          suffix={" sat/vB"}
+          onValueChange={createFeeChangeHandler("satPerVbyte")}
+          label={t.bumpFeesNode.satPerVbyte}
+          sizeVariant={InputSizeVariant.small}
+          disabled={editingDisabled}
+        />
+        <Input
+          intercomTarget={"bump-fees-node-max-fee-rate-input"}
+          formatted={true}
+          value={configuration.maxSatPerVbyte}
+          thousandSeparator={","}
+          suffix={" sat/vB"}
+          onValueChange={createChangeHandler("maxSatPerVbyte")}
+          label={t.bumpFeesNode.maxSatPerVbyte}
+          sizeVariant={InputSizeVariant.small}
+          disabled={editingDisabled}
+        />
+        <RadioChips
+          label={t.bumpFeesNode.incoming}
+          sizeVariant={InputSizeVariant.small}
+          groupName={"bump-incoming-switch-" + wrapperProps.workflowVersionNodeId}
+          options={[
+            {
+              label: t.bumpFeesNode.skipIncoming,
+              id: "bump-incoming-switch-false-" + wrapperProps.workflowVersionNodeId,
+              checked: !configuration.bumpIncoming,
+              onChange: () => setConfiguration((prev) => ({ ...prev, bumpIncoming: false })),
+            },
+            {
+              label: t.bumpFeesNode.bumpIncoming,
+              id: "bump-incoming-switch-true-" + wrapperProps.workflowVersionNodeId,
+              checked: configuration.bumpIncoming,
+              onChange: () => setConfiguration((prev) => ({ ...prev, bumpIncoming: true })),
+            },
+          ]}
+          editingDisabled={editingDisabled}
+        />
+        <RadioChips
+          label={t.bumpFeesNode.force}
+          sizeVariant={InputSizeVariant.small}
+          groupName={"force-switch-" + wrapperProps.workflowVersionNodeId}
+          options={[
+            {
+              label: t.bumpFeesNode.onlyWhenEconomical,
+              id: "force-switch-false-" + wrapperProps.workflowVersionNodeId,
+              checked: !configuration.force,
+              onChange: () => setConfiguration((prev) => ({ ...prev, force: false })),
+            },
+            {
+              label: t.bumpFeesNode.always,
+              id: "force-switch-true-" + wrapperProps.workflowVersionNodeId,
+              checked: configuration.force,
+              onChange: () => setConfiguration((prev) => ({ ...prev, force: true })),
+            },
+          ]}
+          editingDisabled={editingDisabled}
+        />
+        <Button
+          intercomTarget={"bump-fees-node-save-button"}
+          type="submit"
+          buttonColor={ColorVariant.success}
+          buttonSize={SizeVariant.small}
+          icon={!processing ? <SaveIcon /> : <Spinny />}
+          disabled={!dirty || processing || editingDisabled}
+        >
+          {!processing ? t.save.toString() : t.saving.toString()}
+        </Button>
+      </Form>
+    </WorkflowNodeWrapper>
+  );
+}
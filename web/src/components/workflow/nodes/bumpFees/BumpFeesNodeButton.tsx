@@ -0,0 +1,20 @@
+import { FastForward20Regular as BumpFeesIcon } from "@fluentui/react-icons";
+import useTranslations from "services/i18n/useTranslations";
+import WorkflowNodeButtonWrapper from "components/workflow/nodeButtonWrapper/NodeButtonWrapper";
+import { WorkflowNodeType } from "pages/WorkflowPage/constants";
+import { NodeColorVariant } from "../nodeVariants";
+
+export function BumpFeesNodeButton() {
+  const { t } = useTranslations();
+
+  return (
+    <WorkflowNodeButtonWrapper
+      intercomTarget={"bump-fees-node-button"}
+      colorVariant={NodeColorVariant.accent2}
+      nodeType={WorkflowNodeType.BumpFees}
+      icon={<BumpFeesIcon />}
+      title={t.workflowNodes.bumpFees}
+      parameters={'{ "blocks": 6, "targetConf": 2, "force": false }'}
+    />
+  );
+}
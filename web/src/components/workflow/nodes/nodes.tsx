@@ -31,3 +31,7 @@ export { DataSourceTorqChannelsNodeButton } from "components/workflow/nodes/data
 export { DataSourceTorqChannelsNode } from "components/workflow/nodes/dataSourceTorqChannels/DataSourceTorqChannelsNode";
 export { ChannelBalanceEventFilterNode } from "components/workflow/nodes/channelBalanceEventsFilter/ChannelBalanceEventFilterNode";
 export { ChannelBalanceEventFilterNodeButton } from "components/workflow/nodes/channelBalanceEventsFilter/ChannelBalanceEventFilterNodeButton";
+export { OpenChannelsNodeButton } from "components/workflow/nodes/openChannels/OpenChannelsNodeButton";
+export { OpenChannelsNode } from "components/workflow/nodes/openChannels/OpenChannelsNode";
+export { BumpFeesNodeButton } from "components/workflow/nodes/bumpFees/BumpFeesNodeButton";
+export { BumpFeesNode } from "components/workflow/nodes/bumpFees/BumpFeesNode";
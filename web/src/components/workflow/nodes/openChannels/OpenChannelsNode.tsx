@@ -0,0 +1,295 @@
+import React, { useContext, useEffect, useState } from "react";
+import { AddSquare20Regular as OpenChannelsIcon, Save16Regular as SaveIcon } from "@fluentui/react-icons";
+import useTranslations from "services/i18n/useTranslations";
+import WorkflowNodeWrapper, { WorkflowNodeProps } from "components/workflow/nodeWrapper/WorkflowNodeWrapper";
+import { NodeColorVariant } from "components/workflow/nodes/nodeVariants";
+import { SelectWorkflowNodeLinks, SelectWorkflowNodes, useUpdateNodeMutation } from "pages/WorkflowPage/workflowApi";
+import Button, { ColorVariant, SizeVariant } from "components/buttons/Button";
+import { NumberFormatValues } from "react-number-format";
+import { useSelector } from "react-redux";
+import Spinny from "features/spinny/Spinny";
+import { WorkflowContext } from "components/workflow/WorkflowContext";
+import { Status } from "constants/backend";
+import ToastContext from "features/toast/context";
+import { toastCategory } from "features/toast/Toasts";
+import { Input, InputSizeVariant, Socket, Form, RadioChips, Select } from "components/forms/forms";
+import { useGetTagsQuery } from "pages/tags/tagsApi";
+import { Tag } from "pages/tags/tagsTypes";
+import { useGetNodeConfigurationsQuery } from "apiSlice";
+import { nodeConfiguration } from "apiTypes";
+
+type OpenChannelsNodeProps = Omit<WorkflowNodeProps, "colorVariant">;
+
+type SelectOption = {
+  label?: string;
+  value: number;
+};
+
+export type OpenChannelsConfiguration = {
+  nodeId?: number;
+  tagIds: number[];
+  categoryIds: number[];
+  maxTotalSat?: number;
+  minReserveSat?: number;
+  maxSatPerVbyte?: number;
+  targetConf?: number;
+  minChannelSat?: number;
+  maxChannelSat?: number;
+  maxChannelsPerPeer?: number;
+  private: boolean;
+};
+
+export function OpenChannelsNode({ ...wrapperProps }: OpenChannelsNodeProps) {
+  const { t } = useTranslations();
+  const { workflowStatus } = useContext(WorkflowContext);
+  const editingDisabled = workflowStatus === Status.Active;
+  const toastRef = React.useContext(ToastContext);
+
+  const [updateNode] = useUpdateNodeMutation();
+
+  const [configuration, setConfiguration] = useState<OpenChannelsConfiguration>({
+    tagIds: [],
+    categoryIds: [],
+    maxChannelsPerPeer: 1,
+    private: false,
+    ...wrapperProps.parameters,
+  });
+
+  const [dirty, setDirty] = useState(false);
+  const [processing, setProcessing] = useState(false);
+  useEffect(() => {
+    setDirty(
+      JSON.stringify(wrapperProps.parameters, Object.keys(wrapperProps.parameters).sort()) !==
+        JSON.stringify(configuration, Object.keys(configuration).sort())
+    );
+  }, [configuration, wrapperProps.parameters]);
+
+  const { data: nodeConfigurations } = useGetNodeConfigurationsQuery();
+  const nodeOptions: SelectOption[] = (nodeConfigurations || []).map((node: nodeConfiguration) => {
+    return { label: node.name, value: node.nodeId };
+  });
+
+  const { data: tagsResponse } = useGetTagsQuery<{
+    data: Array<Tag>;
+  }>();
+  const tagOptions: SelectOption[] = (tagsResponse || []).map((tag) => {
+    return { label: tag.name, value: tag.tagId || 0 };
+  });
+  const categoryOptions: SelectOption[] = [];
+  (tagsResponse || []).forEach((tag) => {
+    if (tag.categoryId && !categoryOptions.some((option) => option.value === tag.categoryId)) {
+      categoryOptions.push({ label: tag.categoryName, value: tag.categoryId });
+    }
+  });
+
+  function createChangeHandler(key: keyof OpenChannelsConfiguration) {
+    return (e: NumberFormatValues) => {
+      setConfiguration((prev) => ({
+        ...prev,
+        [key]: e.floatValue,
+      }));
+    };
+  }
+
+  function createMultiSelectHandler(key: "tagIds" | "categoryIds") {
+    return (newValue: unknown) => {
+      setConfiguration((prev) => ({
+        ...prev,
+        [key]: ((newValue as SelectOption[]) || []).map((option) => option.value),
+      }));
+    };
+  }
+
+  function handleSubmit(e: React.FormEvent<HTMLFormElement>) {
+    e.preventDefault();
+
+    if (editingDisabled) {
+      toastRef?.current?.addToast(t.toast.cannotModifyWorkflowActive, toastCategory.warn);
+      return;
+    }
+
+    setProcessing(true);
+    updateNode({
+      workflowVersionNodeId: wrapperProps.workflowVersionNodeId,
+      parameters: configuration,
+    }).finally(() => {
+      setProcessing(false);
+    });
+  }
+
+  const { childLinks } = useSelector(
+    SelectWorkflowNodeLinks({
+      version: wrapperProps.version,
+      workflowId: wrapperProps.workflowId,
+      nodeId: wrapperProps.workflowVersionNodeId,
+      stage: wrapperProps.stage,
+    })
+  );
+
+  const parentNodeIds = childLinks?.map((link) => link.parentWorkflowVersionNodeId) ?? [];
+  const parentNodes = useSelector(
+    SelectWorkflowNodes({
+      version: wrapperProps.version,
+      workflowId: wrapperProps.workflowId,
+      nodeIds: parentNodeIds,
+    })
+  );
+
+  return (
+    <WorkflowNodeWrapper
+      {...wrapperProps}
+      heading={t.workflowNodes.openChannels}
+      headerIcon={<OpenChannelsIcon />}
+      colorVariant={NodeColorVariant.accent2}
+    >
+      <Form onSubmit={handleSubmit} intercomTarget={"open-channels-node-form"}>
+        <Socket
+          collapsed={wrapperProps.visibilitySettings.collapsed}
+          label={t.openChannelsNode.peersOfChannels}
+          selectedNodes={parentNodes || []}
+          workflowVersionId={wrapperProps.workflowVersionId}
+          workflowVersionNodeId={wrapperProps.workflowVersionNodeId}
+          inputName={"channels"}
+          editingDisabled={editingDisabled}
+        />
+        <Select
+          intercomTarget={"open-channels-node-node-select"}
+          options={nodeOptions}
+          onChange={(newValue: unknown) =>
+            setConfiguration((prev) => ({ ...prev, nodeId: (newValue as SelectOption).value }))
+          }
+          label={t.node}
+          sizeVariant={InputSizeVariant.small}
+          value={nodeOptions.find((option) => option.value === configuration.nodeId)}
+          isDisabled={editingDisabled}
+        />
+        <Select
+          intercomTarget={"open-channels-node-tags-select"}
+          isMulti={true}
+          options={tagOptions}
+          onChange={createMultiSelectHandler("tagIds")}
+          label={t.tags}
+          sizeVariant={InputSizeVariant.small}
+          value={tagOptions.filter((option) => configuration.tagIds.includes(option.value))}
+          isDisabled={editingDisabled}
+        />
+        <Select
+          intercomTarget={"open-channels-node-categories-select"}
+          isMulti={true}
+          options={categoryOptions}
+          onChange={createMultiSelectHandler("categoryIds")}
+          label={t.openChannelsNode.categories}
+          sizeVariant={InputSizeVariant.small}
+          value={categoryOptions.filter((option) => configuration.categoryIds.includes(option.value))}
+          isDisabled={editingDisabled}
+        />
+        <Input
+          intercomTarget={"open-channels-node-max-total-input"}
+          formatted={true}
+          value={configuration.maxTotalSat}
+          thousandSeparator={","}
+          suffix={" sat"}
+          onValueChange={createChangeHandler("maxTotalSat")}
+          label={t.openChannelsNode.maxTotalSat}
+          sizeVariant={InputSizeVariant.small}
+          disabled={editingDisabled}
+        />
+        <Input
+          intercomTarget={"open-channels-node-min-reserve-input"}
+          formatted={true}
+          value={configuration.minReserveSat}
+          thousandSeparator={","}
+          suffix={" sat"}
+          onValueChange={createChangeHandler("minReserveSat")}
+          label={t.openChannelsNode.minReserveSat}
+          sizeVariant={InputSizeVariant.small}
+          disabled={editingDisabled}
+        />
+        <Input
+          intercomTarget={"open-channels-node-max-fee-rate-input"}
+          formatted={true}
+          value={configuration.maxSatPerVbyte}
+          thousandSeparator={","}
+          suffix={" sat/vB"}
+          onValueChange={createChangeHandler("maxSatPerVbyte")}
+          label={t.openChannelsNode.maxSatPerVbyte}
+          sizeVariant={InputSizeVariant.small}
+          disabled={editingDisabled}
+        />
+        <Input
+          intercomTarget={"open-channels-node-target-conf-input"}
+          formatted={true}
+          value={configuration.targetConf}
+          thousandSeparator={","}
+          suffix={" blocks"}
+          onValueChange={createChangeHandler("targetConf")}
+          label={t.openChannelsNode.targetConf}
+          sizeVariant={InputSizeVariant.small}
+          disabled={editingDisabled}
+        />
+        <Input
+          intercomTarget={"open-channels-node-min-channel-input"}
+          formatted={true}
+          value={configuration.minChannelSat}
+          thousandSeparator={","}
+          suffix={" sat"}
+          onValueChange={createChangeHandler("minChannelSat")}
+          label={t.openChannelsNode.minChannelSat}
+          sizeVariant={InputSizeVariant.small}
+          disabled={editingDisabled}
+        />
+        <Input
+          intercomTarget={"open-channels-node-max-channel-input"}
+          formatted={true}
+          value={configuration.maxChannelSat}
+          thousandSeparator={","}
+          suffix={" sat"}
+          onValueChange={createChangeHandler("maxChannelSat")}
+          label={t.openChannelsNode.maxChannelSat}
+          sizeVariant={InputSizeVariant.small}
+          disabled={editingDisabled}
+        />
+        <Input
+          intercomTarget={"open-channels-node-max-channels-per-peer-input"}
+          formatted={true}
+          value={configuration.maxChannelsPerPeer}
+          thousandSeparator={","}
+          onValueChange={createChangeHandler("maxChannelsPerPeer")}
+          label={t.openChannelsNode.maxChannelsPerPeer}
+          sizeVariant={InputSizeVariant.small}
+          disabled={editingDisabled}
+        />
+        <RadioChips
+          label={t.openChannelsNode.announcement}
+          sizeVariant={InputSizeVariant.small}
+          groupName={"private-switch-" + wrapperProps.workflowVersionNodeId}
+          options={[
+            {
+              label: t.openChannelsNode.public,
+              id: "private-switch-public-" + wrapperProps.workflowVersionNodeId,
+              checked: !configuration.private,
+              onChange: () => setConfiguration((prev) => ({ ...prev, private: false })),
+            },
+            {
+              label: t.Private,
+              id: "private-switch-private-" + wrapperProps.workflowVersionNodeId,
+              checked: configuration.private,
+              onChange: () => setConfiguration((prev) => ({ ...prev, private: true })),
+            },
+          ]}
+          editingDisabled={editingDisabled}
+        />
+        <Button
+          intercomTarget={"open-channels-node-save-button"}
+          type="submit"
+          buttonColor={ColorVariant.success}
+          buttonSize={SizeVariant.small}
+          icon={!processing ? <SaveIcon /> : <Spinny />}
+          disabled={!dirty || processing || editingDisabled}
+        >
+          {!processing ? t.save.toString() : t.saving.toString()}
+        </Button>
+      </Form>
+    </WorkflowNodeWrapper>
+  );
+}
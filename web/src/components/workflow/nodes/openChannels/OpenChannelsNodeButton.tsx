@@ -0,0 +1,20 @@
+import { AddSquare20Regular as OpenChannelsIcon } from "@fluentui/react-icons";
+import useTranslations from "services/i18n/useTranslations";
+import WorkflowNodeButtonWrapper from "components/workflow/nodeButtonWrapper/NodeButtonWrapper";
+import { WorkflowNodeType } from "pages/WorkflowPage/constants";
+import { NodeColorVariant } from "../nodeVariants";
+
+export function OpenChannelsNodeButton() {
+  const { t } = useTranslations();
+
+  return (
+    <WorkflowNodeButtonWrapper
+      intercomTarget={"open-channels-node-button"}
+      colorVariant={NodeColorVariant.accent2}
+      nodeType={WorkflowNodeType.OpenChannels}
+      icon={<OpenChannelsIcon />}
+      title={t.workflowNodes.openChannels}
+      parameters={'{ "tagIds": [], "categoryIds": [], "private": false }'}
+    />
+  );
+}
@@ -27,6 +27,8 @@ import {
   ChannelOpenTriggerNodeButton,
   DataSourceTorqChannelsNodeButton,
   ChannelBalanceEventFilterNodeButton,
+  OpenChannelsNodeButton,
+  BumpFeesNodeButton,
 } from "components/workflow/nodes/nodes";
 import { userEvents } from "utils/userEvents";
 
@@ -96,6 +98,8 @@ export default function WorkflowSidebar(props: WorkflowSidebarProps) {
           <RebalanceAutoRunNodeButton />
           <AddTagNodeButton />
           <RemoveTagNodeButton />
+          <OpenChannelsNodeButton />
+          <BumpFeesNodeButton />
         </SectionContainer>
         <SectionContainer
           intercomTarget={"workflow-advanced-actions-section"}
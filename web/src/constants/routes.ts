@@ -14,6 +14,7 @@ export const CHANNELS = "channels";
 export const OPEN_CHANNELS = "open-channels";
 export const PENDING_CHANNELS = "pending-channels";
 export const CLOSED_CHANNELS = "closed-channels";
+export const FORCE_CLOSES = "force-closes";
 export const INSPECT_CHANNEL = "/analyse/inspect/:chanId";
 export const FORWARDS_SUMMARY = "forwards-summary";
 
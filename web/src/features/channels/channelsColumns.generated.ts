@@ -344,6 +344,174 @@ export const AllChannelsColumns: ColumnMetaData<channel>[] = [
 		key: "private",
 		valueType: "boolean",
 	},
+	{
+		heading: "Outbound Flow (sat/day)",
+		type: "NumericCell",
+		key: "outboundFlowPerDay",
+		valueType: "number",
+	},
+	{
+		heading: "Inbound Flow (sat/day)",
+		type: "NumericCell",
+		key: "inboundFlowPerDay",
+		valueType: "number",
+	},
+	{
+		heading: "Net Flow (sat/day)",
+		type: "NumericCell",
+		key: "netFlowPerDay",
+		valueType: "number",
+	},
+	{
+		heading: "Expected Depletion Date",
+		type: "DateCell",
+		key: "depletionOn",
+		valueType: "date",
+	},
+	{
+		heading: "Expected Depletion (Seconds)",
+		type: "DurationCell",
+		key: "depletionSecondsDelta",
+		valueType: "duration",
+	},
+	{
+		heading: "Expected Saturation Date",
+		type: "DateCell",
+		key: "saturationOn",
+		valueType: "date",
+	},
+	{
+		heading: "Expected Saturation (Seconds)",
+		type: "DurationCell",
+		key: "saturationSecondsDelta",
+		valueType: "duration",
+	},
+	{
+		heading: "Competitor Count",
+		type: "NumericCell",
+		key: "competitorCount",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Fee Rate Min (PPM)",
+		type: "NumericCell",
+		key: "competitorFeeRateMin",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Fee Rate 10th Percentile (PPM)",
+		type: "NumericCell",
+		key: "competitorFeeRateP10",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Fee Rate 20th Percentile (PPM)",
+		type: "NumericCell",
+		key: "competitorFeeRateP20",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Fee Rate 30th Percentile (PPM)",
+		type: "NumericCell",
+		key: "competitorFeeRateP30",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Fee Rate 40th Percentile (PPM)",
+		type: "NumericCell",
+		key: "competitorFeeRateP40",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Fee Rate Median (PPM)",
+		type: "NumericCell",
+		key: "competitorFeeRateMedian",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Fee Rate 60th Percentile (PPM)",
+		type: "NumericCell",
+		key: "competitorFeeRateP60",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Fee Rate 70th Percentile (PPM)",
+		type: "NumericCell",
+		key: "competitorFeeRateP70",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Fee Rate 80th Percentile (PPM)",
+		type: "NumericCell",
+		key: "competitorFeeRateP80",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Fee Rate 90th Percentile (PPM)",
+		type: "NumericCell",
+		key: "competitorFeeRateP90",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Fee Rate Max (PPM)",
+		type: "NumericCell",
+		key: "competitorFeeRateMax",
+		valueType: "number",
+	},
+	{
+		heading: "Competitor Base Fee Median",
+		type: "NumericCell",
+		key: "competitorFeeBaseMedian",
+		valueType: "number",
+	},
+	{
+		heading: "Fee Rate Competitor Percentile",
+		type: "NumericCell",
+		key: "feeRateCompetitorPercentile",
+		valueType: "number",
+	},
+	{
+		heading: "Peer Uptime (%)",
+		type: "NumericCell",
+		key: "peerUptimePercentage",
+		valueType: "number",
+	},
+	{
+		heading: "Peer Disconnects",
+		type: "NumericCell",
+		key: "peerFlapCount",
+		valueType: "number",
+	},
+	{
+		heading: "Peer Mean Time Between Disconnects",
+		type: "DurationCell",
+		key: "peerMeanSecondsBetweenDisconnects",
+		valueType: "duration",
+	},
+	{
+		heading: "Peer Longest Outage",
+		type: "DurationCell",
+		key: "peerLongestOutageSeconds",
+		valueType: "duration",
+	},
+	{
+		heading: "Peer Channels Active (%)",
+		type: "NumericCell",
+		key: "peerChannelActivePercentage",
+		valueType: "number",
+	},
+	{
+		heading: "Peer Channel Flaps",
+		type: "NumericCell",
+		key: "peerChannelFlapCount",
+		valueType: "number",
+	},
+	{
+		heading: "Peer Reliability Score",
+		type: "NumericCell",
+		key: "peerReliabilityScore",
+		valueType: "number",
+	},
 ];
 
 
@@ -402,6 +570,34 @@ export const ChannelsSortableColumns: Array<keyof channel> = [
 	"remotePubkey",
 	"peerGauge",
 	"private",
+	"outboundFlowPerDay",
+	"inboundFlowPerDay",
+	"netFlowPerDay",
+	"depletionOn",
+	"depletionSecondsDelta",
+	"saturationOn",
+	"saturationSecondsDelta",
+	"competitorCount",
+	"competitorFeeRateMin",
+	"competitorFeeRateP10",
+	"competitorFeeRateP20",
+	"competitorFeeRateP30",
+	"competitorFeeRateP40",
+	"competitorFeeRateMedian",
+	"competitorFeeRateP60",
+	"competitorFeeRateP70",
+	"competitorFeeRateP80",
+	"competitorFeeRateP90",
+	"competitorFeeRateMax",
+	"competitorFeeBaseMedian",
+	"feeRateCompetitorPercentile",
+	"peerUptimePercentage",
+	"peerFlapCount",
+	"peerMeanSecondsBetweenDisconnects",
+	"peerLongestOutageSeconds",
+	"peerChannelActivePercentage",
+	"peerChannelFlapCount",
+	"peerReliabilityScore",
 ];
 
 
@@ -461,4 +657,32 @@ export const ChannelsFilterableColumns: Array<keyof channel> = [
 	"remotePubkey",
 	"peerGauge",
 	"private",
+	"outboundFlowPerDay",
+	"inboundFlowPerDay",
+	"netFlowPerDay",
+	"depletionOn",
+	"depletionSecondsDelta",
+	"saturationOn",
+	"saturationSecondsDelta",
+	"competitorCount",
+	"competitorFeeRateMin",
+	"competitorFeeRateP10",
+	"competitorFeeRateP20",
+	"competitorFeeRateP30",
+	"competitorFeeRateP40",
+	"competitorFeeRateMedian",
+	"competitorFeeRateP60",
+	"competitorFeeRateP70",
+	"competitorFeeRateP80",
+	"competitorFeeRateP90",
+	"competitorFeeRateMax",
+	"competitorFeeBaseMedian",
+	"feeRateCompetitorPercentile",
+	"peerUptimePercentage",
+	"peerFlapCount",
+	"peerMeanSecondsBetweenDisconnects",
+	"peerLongestOutageSeconds",
+	"peerChannelActivePercentage",
+	"peerChannelFlapCount",
+	"peerReliabilityScore",
 ];
\ No newline at end of file
@@ -91,6 +91,41 @@ export type channel = {
   peerGauge: number;
   private: boolean;
   nodeCssColour?: string;
+  outboundFlowPerDay: number;
+  inboundFlowPerDay: number;
+  netFlowPerDay: number;
+  depletionOn?: Date;
+  depletionSecondsDelta?: number;
+  saturationOn?: Date;
+  saturationSecondsDelta?: number;
+  competitorCount?: number;
+  competitorFeeRateMin?: number;
+  competitorFeeRateP10?: number;
+  competitorFeeRateP20?: number;
+  competitorFeeRateP30?: number;
+  competitorFeeRateP40?: number;
+  competitorFeeRateMedian?: number;
+  competitorFeeRateP60?: number;
+  competitorFeeRateP70?: number;
+  competitorFeeRateP80?: number;
+  competitorFeeRateP90?: number;
+  competitorFeeRateMax?: number;
+  competitorFeeBaseMedian?: number;
+  cheapestCompetitors?: Array<{
+    publicKey: string;
+    alias: string;
+    feeRateMilliMsat: number;
+    feeBaseMsat: number;
+    capacity: number;
+  }>;
+  feeRateCompetitorPercentile?: number;
+  peerUptimePercentage?: number;
+  peerFlapCount?: number;
+  peerMeanSecondsBetweenDisconnects?: number;
+  peerLongestOutageSeconds?: number;
+  peerChannelActivePercentage?: number;
+  peerChannelFlapCount?: number;
+  peerReliabilityScore?: number;
 };
 
 export type PolicyInterface = {
@@ -0,0 +1,71 @@
+import { Link } from "react-router-dom";
+import { ArrowSync20Regular as RefreshIcon } from "@fluentui/react-icons";
+import TablePageTemplate, {
+  TableControlSection,
+  TableControlsButtonGroup,
+} from "features/templates/tablePageTemplate/TablePageTemplate";
+import { ForceClosingChannel } from "features/forceCloses/forceClosesTypes";
+import * as Routes from "constants/routes";
+import useTranslations from "services/i18n/useTranslations";
+import Table from "features/table/Table";
+import { useGetForceClosingChannelsQuery } from "apiSlice";
+import { useAppSelector } from "store/hooks";
+import { selectActiveNetwork } from "features/network/networkSlice";
+import forceClosesCellRenderer from "features/forceCloses/forceClosesCellRenderer";
+import { ForceClosesColumns } from "features/forceCloses/forceClosesColumns";
+import Button, { ColorVariant } from "components/buttons/Button";
+import { userEvents } from "utils/userEvents";
+
+function ForceClosesPage() {
+  const { t } = useTranslations();
+  const { track } = userEvents();
+  const activeNetwork = useAppSelector(selectActiveNetwork);
+
+  const forceClosesResponse = useGetForceClosingChannelsQuery<{
+    data: Array<ForceClosingChannel>;
+    isLoading: boolean;
+    isFetching: boolean;
+    isUninitialized: boolean;
+    isSuccess: boolean;
+  }>({ network: activeNetwork }, { pollingInterval: 60000 });
+
+  const tableControls = (
+    <TableControlSection intercomTarget={"table-page-controls"}>
+      <div />
+      <TableControlsButtonGroup intercomTarget="table-page-controls-right">
+        <Button
+          intercomTarget="refresh-table"
+          buttonColor={ColorVariant.primary}
+          icon={<RefreshIcon />}
+          onClick={() => {
+            track("Refresh Table", { page: "Force Closes" });
+            forceClosesResponse.refetch();
+          }}
+        />
+      </TableControlsButtonGroup>
+    </TableControlSection>
+  );
+
+  const breadcrumbs = [
+    <span key="b1">{t.channels}</span>,
+    <Link key="b2" to={`/${Routes.CHANNELS}/${Routes.FORCE_CLOSES}`}>
+      {t.forceCloses}
+    </Link>,
+  ];
+
+  return (
+    <TablePageTemplate title={t.forceCloses} breadcrumbs={breadcrumbs} tableControls={tableControls}>
+      <Table
+        intercomTarget={"force-closes-table"}
+        cellRenderer={forceClosesCellRenderer}
+        data={forceClosesResponse.data || []}
+        activeColumns={ForceClosesColumns}
+        isLoading={
+          forceClosesResponse.isLoading || forceClosesResponse.isFetching || forceClosesResponse.isUninitialized
+        }
+      />
+    </TablePageTemplate>
+  );
+}
+
+export default ForceClosesPage;
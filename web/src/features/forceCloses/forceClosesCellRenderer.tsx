@@ -0,0 +1,60 @@
+import cellStyles from "components/table/cells/cell.module.scss";
+import { ColumnMetaData } from "features/table/types";
+import { ForceClosingChannel } from "features/forceCloses/forceClosesTypes";
+import DefaultCellRenderer from "features/table/DefaultCellRenderer";
+import ChannelCell from "components/table/cells/channelCell/ChannelCell";
+import LongTextCell from "components/table/cells/longText/LongTextCell";
+import BooleanCell from "components/table/cells/boolean/BooleanCell";
+import TextCell from "components/table/cells/text/TextCell";
+
+export default function forceClosesCellRenderer(
+  row: ForceClosingChannel,
+  rowIndex: number,
+  column: ColumnMetaData<ForceClosingChannel>,
+  columnIndex: number,
+  isTotalsRow?: boolean,
+  maxRow?: ForceClosingChannel
+): JSX.Element {
+  switch (column.key) {
+    case "peerAlias":
+      return (
+        <ChannelCell
+          alias={row.peerAlias || row.remotePublicKey}
+          open={false}
+          channelId={row.channelId}
+          nodeId={row.nodeId}
+          className={cellStyles.locked}
+          key={"channelsCell" + rowIndex}
+          hideActionButtons
+        />
+      );
+    case "closingTxId":
+      return (
+        <LongTextCell
+          key={"closingTxIdCell" + rowIndex}
+          text={row.closingTxId}
+          link={row.closingTxId ? "https://mempool.space/tx/" + row.closingTxId : undefined}
+          copyText={row.closingTxId}
+        />
+      );
+    case "waitingClose":
+      return (
+        <BooleanCell
+          falseTitle={"Confirmed"}
+          trueTitle={"Waiting"}
+          value={row.waitingClose}
+          key={"waitingCloseCell" + rowIndex + columnIndex}
+        />
+      );
+    case "maturityHeight":
+    case "blocksTilMaturity":
+    case "nextHtlcBlocksTilMaturity":
+      // Channels without a time locked output or pending HTLCs have nothing to count down
+      if (row[column.key] === undefined || row[column.key] === null) {
+        return <TextCell text={"-"} key={column.key + rowIndex} />;
+      }
+      break;
+  }
+
+  return DefaultCellRenderer(row, rowIndex, column, columnIndex, false, maxRow);
+}
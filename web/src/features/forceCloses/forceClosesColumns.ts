@@ -0,0 +1,84 @@
+import { ColumnMetaData } from "features/table/types";
+import { ForceClosingChannel } from "features/forceCloses/forceClosesTypes";
+
+export const ForceClosesColumns: ColumnMetaData<ForceClosingChannel>[] = [
+  {
+    heading: "Peer Alias",
+    type: "AliasCell",
+    key: "peerAlias",
+    valueType: "string",
+    locked: true,
+  },
+  {
+    heading: "Torq Node Name",
+    type: "TextCell",
+    key: "nodeName",
+    valueType: "string",
+  },
+  {
+    heading: "Channel Point",
+    type: "LongTextCell",
+    key: "channelPoint",
+    valueType: "string",
+  },
+  {
+    heading: "Closing Transaction",
+    type: "LongTextCell",
+    key: "closingTxId",
+    valueType: "string",
+  },
+  {
+    heading: "Waiting Close",
+    type: "BooleanCell",
+    key: "waitingClose",
+    valueType: "boolean",
+  },
+  {
+    heading: "Limbo Balance",
+    type: "NumericCell",
+    key: "limboBalanceSat",
+    valueType: "number",
+  },
+  {
+    heading: "Recovered Balance",
+    type: "NumericCell",
+    key: "recoveredBalanceSat",
+    valueType: "number",
+  },
+  {
+    heading: "Maturity Height",
+    type: "NumericCell",
+    key: "maturityHeight",
+    valueType: "number",
+  },
+  {
+    heading: "Blocks Until Maturity",
+    type: "NumericCell",
+    key: "blocksTilMaturity",
+    valueType: "number",
+  },
+  {
+    heading: "Pending HTLCs",
+    type: "NumericCell",
+    key: "pendingHtlcCount",
+    valueType: "number",
+  },
+  {
+    heading: "Pending HTLC Amount",
+    type: "NumericCell",
+    key: "pendingHtlcAmountSat",
+    valueType: "number",
+  },
+  {
+    heading: "Blocks Until Next HTLC",
+    type: "NumericCell",
+    key: "nextHtlcBlocksTilMaturity",
+    valueType: "number",
+  },
+  {
+    heading: "Anchor",
+    type: "TextCell",
+    key: "anchorState",
+    valueType: "string",
+  },
+];
@@ -0,0 +1,30 @@
+export type PendingHtlc = {
+  incoming: boolean;
+  amountSat: number;
+  outpoint: string;
+  maturityHeight: number;
+  blocksTilMaturity: number;
+  stage: number;
+};
+
+export type ForceClosingChannel = {
+  nodeId: number;
+  nodeName: string;
+  channelId: number;
+  peerNodeId: number;
+  peerAlias: string;
+  channelPoint: string;
+  remotePublicKey: string;
+  closingTxId: string;
+  waitingClose: boolean;
+  limboBalanceSat: number;
+  recoveredBalanceSat: number;
+  maturityHeight?: number;
+  blocksTilMaturity?: number;
+  anchorState: string;
+  pendingHtlcs: Array<PendingHtlc>;
+  pendingHtlcCount: number;
+  pendingHtlcAmountSat: number;
+  nextHtlcBlocksTilMaturity?: number;
+  status?: Array<string>;
+};
@@ -18,6 +18,7 @@ import {
   ArrowRouting20Regular as ChannelsIcon,
   ArrowWrapOff20Regular as ChannelsClosedIcon,
   ArrowRoutingRectangleMultiple20Regular as ChannelsPendingIcon,
+  Timer20Regular as ForceClosesIcon,
   Signature20Regular as MessageVerificationIcon,
   Flash20Regular as WorkflowsIcon,
   Tag20Regular as TagsIcon,
@@ -126,6 +127,15 @@ function Navigation() {
               track("Navigate to Closed Channels");
             }}
           />
+          <MenuItem
+            intercomTarget="force-closes-nav-button"
+            text={t.forceCloses}
+            icon={<ForceClosesIcon />}
+            routeTo={`/${routes.CHANNELS}/${routes.FORCE_CLOSES}`}
+            onClick={() => {
+              track("Navigate to Force Closes");
+            }}
+          />
         </NavCategory>
 
         <NavCategory text={t.manage} collapsed={false} intercomTarget={"manage-nav-section"}>
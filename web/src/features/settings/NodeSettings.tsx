@@ -86,6 +86,8 @@ const importForwards = "importForwards";
 const importForwardsValue = 64;
 const importForwardsHistory = "importForwardsHistory";
 const importForwardsHistoryValue = 128;
+const importGraphSnapshots = "importGraphSnapshots";
+const importGraphSnapshotsValue = 256;
 
 const customSettingsDefault = {
   importFailedPayments: true,
@@ -95,6 +97,7 @@ const customSettingsDefault = {
   importInvoices: true,
   importForwards: true,
   importForwardsHistory: true,
+  importGraphSnapshots: false,
 };
 
 interface importProps {
@@ -189,6 +192,7 @@ const NodeSettings = React.forwardRef(function NodeSettings(
     [importInvoices, { value: importInvoicesValue, label: t.importInvoices }],
     [importForwards, { value: importForwardsValue, label: t.importForwards }],
     [importForwardsHistory, { value: importForwardsHistoryValue, label: t.importForwardsHistory }],
+    [importGraphSnapshots, { value: importGraphSnapshotsValue, label: t.importGraphSnapshots }],
     [importHtlcEvents, { value: importHtlcEventsValue, label: t.importHtlcEvents }],
     [importFailedPayments, { value: importFailedPaymentsValue, label: undefined }],
   ]);
@@ -367,6 +371,8 @@ const NodeSettings = React.forwardRef(function NodeSettings(
         importForwards: nodeConfigurationData.customSettings % (importForwardsValue * 2) >= importForwardsValue,
         importForwardsHistory:
           nodeConfigurationData.customSettings % (importForwardsHistoryValue * 2) >= importForwardsHistoryValue,
+        importGraphSnapshots:
+          nodeConfigurationData.customSettings % (importGraphSnapshotsValue * 2) >= importGraphSnapshotsValue,
       });
       setCustomSettingsCollapsedState(false);
     }
@@ -21,7 +21,9 @@ export enum WorkflowNodeType {
   ChannelPolicyAutoRun,
   RebalanceAutoRun,
   DataSourceTorqChannels,
-  ChannelBalanceEventFilter
+  ChannelBalanceEventFilter,
+  OpenChannels,
+  BumpFees
 }
 
 export const TriggerNodeTypes = [